@@ -0,0 +1,566 @@
+package arithexp
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"orglang/go-runtime/adt/symbol"
+)
+
+// aka arith
+//
+// Linear expression over natural indices.
+type ExpSpec interface {
+	exp()
+}
+
+type NumSpec struct {
+	N int64
+}
+
+func (NumSpec) exp() {}
+
+type VarSpec struct {
+	IdxVar symbol.ADT
+}
+
+func (VarSpec) exp() {}
+
+type AddSpec struct {
+	X ExpSpec
+	Y ExpSpec
+}
+
+func (AddSpec) exp() {}
+
+type SubSpec struct {
+	X ExpSpec
+	Y ExpSpec
+}
+
+func (SubSpec) exp() {}
+
+// multiplication by a constant keeps linearity
+type MulSpec struct {
+	K int64
+	X ExpSpec
+}
+
+func (MulSpec) exp() {}
+
+// aka prop
+//
+// Constraint on indices.
+type PropSpec interface {
+	prop()
+}
+
+type TrueSpec struct{}
+
+func (TrueSpec) prop() {}
+
+type FalseSpec struct{}
+
+func (FalseSpec) prop() {}
+
+type EqSpec struct {
+	X ExpSpec
+	Y ExpSpec
+}
+
+func (EqSpec) prop() {}
+
+type LtSpec struct {
+	X ExpSpec
+	Y ExpSpec
+}
+
+func (LtSpec) prop() {}
+
+type LeSpec struct {
+	X ExpSpec
+	Y ExpSpec
+}
+
+func (LeSpec) prop() {}
+
+type NotSpec struct {
+	P PropSpec
+}
+
+func (NotSpec) prop() {}
+
+type AndSpec struct {
+	P PropSpec
+	Q PropSpec
+}
+
+func (AndSpec) prop() {}
+
+type OrSpec struct {
+	P PropSpec
+	Q PropSpec
+}
+
+func (OrSpec) prop() {}
+
+// substitutes expressions for index variables
+func SubstExp(e ExpSpec, args map[symbol.ADT]ExpSpec) ExpSpec {
+	switch exp := e.(type) {
+	case NumSpec:
+		return exp
+	case VarSpec:
+		arg, ok := args[exp.IdxVar]
+		if !ok {
+			return exp
+		}
+		return arg
+	case AddSpec:
+		return AddSpec{X: SubstExp(exp.X, args), Y: SubstExp(exp.Y, args)}
+	case SubSpec:
+		return SubSpec{X: SubstExp(exp.X, args), Y: SubstExp(exp.Y, args)}
+	case MulSpec:
+		return MulSpec{K: exp.K, X: SubstExp(exp.X, args)}
+	default:
+		panic(ErrExpTypeUnexpected(e))
+	}
+}
+
+func SubstProp(p PropSpec, args map[symbol.ADT]ExpSpec) PropSpec {
+	switch prop := p.(type) {
+	case TrueSpec, FalseSpec:
+		return prop
+	case EqSpec:
+		return EqSpec{X: SubstExp(prop.X, args), Y: SubstExp(prop.Y, args)}
+	case LtSpec:
+		return LtSpec{X: SubstExp(prop.X, args), Y: SubstExp(prop.Y, args)}
+	case LeSpec:
+		return LeSpec{X: SubstExp(prop.X, args), Y: SubstExp(prop.Y, args)}
+	case NotSpec:
+		return NotSpec{P: SubstProp(prop.P, args)}
+	case AndSpec:
+		return AndSpec{P: SubstProp(prop.P, args), Q: SubstProp(prop.Q, args)}
+	case OrSpec:
+		return OrSpec{P: SubstProp(prop.P, args), Q: SubstProp(prop.Q, args)}
+	default:
+		panic(ErrPropTypeUnexpected(p))
+	}
+}
+
+// index variables of the expression
+func CollectVars(e ExpSpec) []symbol.ADT {
+	return collectExpVars(e, []symbol.ADT{})
+}
+
+// index variables of the constraint
+func CollectPropVars(p PropSpec) []symbol.ADT {
+	return collectPropVars(p, []symbol.ADT{})
+}
+
+func collectExpVars(e ExpSpec, idxVars []symbol.ADT) []symbol.ADT {
+	switch exp := e.(type) {
+	case VarSpec:
+		if slices.Contains(idxVars, exp.IdxVar) {
+			return idxVars
+		}
+		return append(idxVars, exp.IdxVar)
+	case AddSpec:
+		return collectExpVars(exp.Y, collectExpVars(exp.X, idxVars))
+	case SubSpec:
+		return collectExpVars(exp.Y, collectExpVars(exp.X, idxVars))
+	case MulSpec:
+		return collectExpVars(exp.X, idxVars)
+	default:
+		return idxVars
+	}
+}
+
+func collectPropVars(p PropSpec, idxVars []symbol.ADT) []symbol.ADT {
+	switch prop := p.(type) {
+	case EqSpec:
+		return collectExpVars(prop.Y, collectExpVars(prop.X, idxVars))
+	case LtSpec:
+		return collectExpVars(prop.Y, collectExpVars(prop.X, idxVars))
+	case LeSpec:
+		return collectExpVars(prop.Y, collectExpVars(prop.X, idxVars))
+	case NotSpec:
+		return collectPropVars(prop.P, idxVars)
+	case AndSpec:
+		return collectPropVars(prop.Q, collectPropVars(prop.P, idxVars))
+	case OrSpec:
+		return collectPropVars(prop.Q, collectPropVars(prop.P, idxVars))
+	default:
+		return idxVars
+	}
+}
+
+// aka entails
+//
+// Whether goal holds for all natural values of the variables
+// that satisfy facts. The solver is incomplete: a negative answer
+// only means that no proof was found. Coefficients that overflow
+// int64 leave the answer unknown, which counts as not entailed.
+func Entails(facts []PropSpec, goal PropSpec) bool {
+	var hyp PropSpec = NotSpec{P: goal}
+	for _, fact := range facts {
+		hyp = AndSpec{P: fact, Q: hyp}
+	}
+	conjs, ok := toDNF(hyp, false)
+	if !ok {
+		return false
+	}
+	for _, conj := range conjs {
+		if !unsat(conj) {
+			return false
+		}
+	}
+	return true
+}
+
+// Checks equality of expressions under facts.
+func CheckEqual(facts []PropSpec, got, want ExpSpec) error {
+	if !Entails(facts, EqSpec{X: got, Y: want}) {
+		return fmt.Errorf("index mismatch: want %v, got %v", ConvertExpToString(want), ConvertExpToString(got))
+	}
+	return nil
+}
+
+// Checks that the constraint follows from facts.
+func CheckProp(facts []PropSpec, goal PropSpec) error {
+	if !Entails(facts, goal) {
+		return fmt.Errorf("constraint unprovable: %v", ConvertPropToString(goal))
+	}
+	return nil
+}
+
+// limits after which the solver gives up
+const (
+	maxConjs       = 1 << 8
+	maxConstraints = 1 << 10
+)
+
+// linear form: sum(coefs[v] * v) + c
+type linear struct {
+	coefs map[symbol.ADT]int64
+	c     int64
+}
+
+// false on overflow
+func toLinear(e ExpSpec) (linear, bool) {
+	switch exp := e.(type) {
+	case NumSpec:
+		if exp.N == math.MinInt64 {
+			return linear{}, false
+		}
+		return linear{map[symbol.ADT]int64{}, exp.N}, true
+	case VarSpec:
+		return linear{map[symbol.ADT]int64{exp.IdxVar: 1}, 0}, true
+	case AddSpec:
+		return combineExps(1, exp.X, 1, exp.Y)
+	case SubSpec:
+		return combineExps(1, exp.X, -1, exp.Y)
+	case MulSpec:
+		x, ok := toLinear(exp.X)
+		if !ok {
+			return linear{}, false
+		}
+		return combine(exp.K, x, 0, linear{})
+	default:
+		panic(ErrExpTypeUnexpected(e))
+	}
+}
+
+func combineExps(a int64, x ExpSpec, b int64, y ExpSpec) (linear, bool) {
+	lx, ok := toLinear(x)
+	if !ok {
+		return linear{}, false
+	}
+	ly, ok := toLinear(y)
+	if !ok {
+		return linear{}, false
+	}
+	return combine(a, lx, b, ly)
+}
+
+// a*x + b*y, false on overflow
+func combine(a int64, x linear, b int64, y linear) (linear, bool) {
+	c, ok := addMul(a, x.c, b, y.c)
+	if !ok {
+		return linear{}, false
+	}
+	res := linear{make(map[symbol.ADT]int64, len(x.coefs)+len(y.coefs)), c}
+	for v := range x.coefs {
+		res.coefs[v], ok = addMul(a, x.coefs[v], b, y.coefs[v])
+		if !ok {
+			return linear{}, false
+		}
+	}
+	for v := range y.coefs {
+		res.coefs[v], ok = addMul(a, x.coefs[v], b, y.coefs[v])
+		if !ok {
+			return linear{}, false
+		}
+	}
+	for v, k := range res.coefs {
+		if k == 0 {
+			delete(res.coefs, v)
+		}
+	}
+	return res, true
+}
+
+// a*x + b*y
+//
+// MinInt64 counts as overflow too, so negation
+// and abs of a result never wrap.
+func addMul(a, x, b, y int64) (int64, bool) {
+	ax, ok := mul(a, x)
+	if !ok {
+		return 0, false
+	}
+	by, ok := mul(b, y)
+	if !ok {
+		return 0, false
+	}
+	return add(ax, by)
+}
+
+func mul(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	p := a * b
+	if p/b != a || p == math.MinInt64 {
+		return 0, false
+	}
+	return p, true
+}
+
+func add(a, b int64) (int64, bool) {
+	s := a + b
+	if (s > a) != (b > 0) || s == math.MinInt64 {
+		return 0, false
+	}
+	return s, true
+}
+
+// x - y + d <= 0
+func le(x, y ExpSpec, d int64) (linear, bool) {
+	l, ok := combineExps(1, x, -1, y)
+	if !ok {
+		return linear{}, false
+	}
+	l.c, ok = add(l.c, d)
+	return l, ok
+}
+
+func single(l linear, ok bool) ([][]linear, bool) {
+	if !ok {
+		return nil, false
+	}
+	return [][]linear{{l}}, true
+}
+
+// Disjunctive normal form: each conjunction is a set
+// of inequalities l <= 0. Negation is pushed inwards.
+func toDNF(p PropSpec, neg bool) ([][]linear, bool) {
+	switch prop := p.(type) {
+	case TrueSpec:
+		if neg {
+			return [][]linear{}, true
+		}
+		return [][]linear{{}}, true
+	case FalseSpec:
+		if neg {
+			return [][]linear{{}}, true
+		}
+		return [][]linear{}, true
+	case LeSpec:
+		if neg {
+			// x > y  <=>  y - x + 1 <= 0
+			return single(le(prop.Y, prop.X, 1))
+		}
+		return single(le(prop.X, prop.Y, 0))
+	case LtSpec:
+		if neg {
+			return single(le(prop.Y, prop.X, 0))
+		}
+		return single(le(prop.X, prop.Y, 1))
+	case EqSpec:
+		var d int64
+		if neg {
+			d = 1
+		}
+		xy, ok := le(prop.X, prop.Y, d)
+		if !ok {
+			return nil, false
+		}
+		yx, ok := le(prop.Y, prop.X, d)
+		if !ok {
+			return nil, false
+		}
+		if neg {
+			return [][]linear{{xy}, {yx}}, true
+		}
+		return [][]linear{{xy, yx}}, true
+	case NotSpec:
+		return toDNF(prop.P, !neg)
+	case AndSpec:
+		if neg {
+			return union(prop.P, prop.Q, neg)
+		}
+		return product(prop.P, prop.Q, neg)
+	case OrSpec:
+		if neg {
+			return product(prop.P, prop.Q, neg)
+		}
+		return union(prop.P, prop.Q, neg)
+	default:
+		panic(ErrPropTypeUnexpected(p))
+	}
+}
+
+func union(p, q PropSpec, neg bool) ([][]linear, bool) {
+	ps, ok := toDNF(p, neg)
+	if !ok {
+		return nil, false
+	}
+	qs, ok := toDNF(q, neg)
+	if !ok {
+		return nil, false
+	}
+	if len(ps)+len(qs) > maxConjs {
+		return nil, false
+	}
+	return append(ps, qs...), true
+}
+
+func product(p, q PropSpec, neg bool) ([][]linear, bool) {
+	ps, ok := toDNF(p, neg)
+	if !ok {
+		return nil, false
+	}
+	qs, ok := toDNF(q, neg)
+	if !ok {
+		return nil, false
+	}
+	if len(ps)*len(qs) > maxConjs {
+		return nil, false
+	}
+	res := make([][]linear, 0, len(ps)*len(qs))
+	for _, pc := range ps {
+		for _, qc := range qs {
+			res = append(res, append(slices.Clip(pc), qc...))
+		}
+	}
+	return res, true
+}
+
+// aka Fourier-Motzkin
+//
+// Unsatisfiability of a system of inequalities over naturals.
+// Each inequality is tightened by dividing by the GCD of coefficients,
+// so some systems without integer solutions are cut off too.
+func unsat(conj []linear) bool {
+	var idxVars []symbol.ADT
+	for _, l := range conj {
+		for v := range l.coefs {
+			if !slices.Contains(idxVars, v) {
+				idxVars = append(idxVars, v)
+			}
+		}
+	}
+	// variables are natural: -v <= 0
+	for _, v := range idxVars {
+		conj = append(conj, linear{map[symbol.ADT]int64{v: -1}, 0})
+	}
+	slices.Sort(idxVars)
+	for _, v := range idxVars {
+		var lower, upper, rest []linear
+		for _, l := range conj {
+			l, ok := tighten(l)
+			if !ok {
+				return true
+			}
+			switch k := l.coefs[v]; {
+			case k > 0:
+				upper = append(upper, l)
+			case k < 0:
+				lower = append(lower, l)
+			default:
+				rest = append(rest, l)
+			}
+		}
+		for _, u := range upper {
+			for _, l := range lower {
+				// unknown, so not proven
+				c, ok := combine(-l.coefs[v], u, u.coefs[v], l)
+				if !ok {
+					return false
+				}
+				rest = append(rest, c)
+			}
+		}
+		if len(rest) > maxConstraints {
+			return false
+		}
+		conj = rest
+	}
+	for _, l := range conj {
+		_, ok := tighten(l)
+		if !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// false if the inequality is trivially false
+func tighten(l linear) (linear, bool) {
+	if len(l.coefs) == 0 {
+		return l, l.c <= 0
+	}
+	var g int64
+	for _, k := range l.coefs {
+		g = gcd(g, abs(k))
+	}
+	if g <= 1 {
+		return l, true
+	}
+	coefs := make(map[symbol.ADT]int64, len(l.coefs))
+	for v, k := range l.coefs {
+		coefs[v] = k / g
+	}
+	return linear{coefs, ceilDiv(l.c, g)}, true
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func abs(a int64) int64 {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func ceilDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a > 0) == (b > 0) {
+		q++
+	}
+	return q
+}
+
+func ErrExpTypeUnexpected(got ExpSpec) error {
+	return fmt.Errorf("arith exp unexpected: %T", got)
+}
+
+func ErrPropTypeUnexpected(got PropSpec) error {
+	return fmt.Errorf("arith prop unexpected: %T", got)
+}
@@ -0,0 +1,126 @@
+package arithexp
+
+import (
+	"fmt"
+	"testing"
+)
+
+func parseProps(t *testing.T, strs ...string) []PropSpec {
+	t.Helper()
+	props := make([]PropSpec, 0, len(strs))
+	for _, str := range strs {
+		prop, err := ConvertPropFromString(str)
+		if err != nil {
+			t.Fatalf("unexpected error %q", err)
+		}
+		props = append(props, prop)
+	}
+	return props
+}
+
+func TestEntails(t *testing.T) {
+	tests := []struct {
+		name  string
+		facts []string
+		goal  string
+		want  bool
+	}{
+		{"reflexive", nil, "x <= x", true},
+		{"naturals", nil, "x >= 0", true},
+		{"not negative", nil, "x < 0", false},
+		{"strict to succ", []string{"x < y"}, "x + 1 <= y", true},
+		{"strict to double succ", []string{"x < y"}, "x + 2 <= y", false},
+		{"symmetric", []string{"x = y"}, "y = x", true},
+		{"substitution", []string{"x + y = 3", "x = 1"}, "y = 2", true},
+		{"scaled", []string{"2*x <= 6"}, "x <= 3", true},
+		{"negated fact", []string{"x != 0"}, "x >= 1", true},
+		{"contradictory facts", []string{"x < 0"}, "false", true},
+		{"no facts for false", nil, "false", false},
+		// rational solutions exist, integer ones do not
+		{"odd double", []string{"2*x = 1"}, "false", true},
+		{"odd difference", []string{"2*x = 2*y + 1"}, "false", true},
+		{"even double", []string{"2*x = 2"}, "false", false},
+		{"disjunctive fact", []string{"x = 1 || x = 2"}, "x <= 2", true},
+		{"disjunctive fact, one case", []string{"x = 1 || x = 2"}, "x = 1", false},
+		{"disjunctive goal", []string{"x <= 1"}, "x = 0 || x = 1", true},
+		{"disjunctive goal, gap", []string{"x <= 2"}, "x = 0 || x = 2", false},
+		{"conjunctive goal", []string{"x = 1", "y = x"}, "y = 1 && x + y = 2", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			facts := parseProps(t, test.facts...)
+			goal := parseProps(t, test.goal)[0]
+			got := Entails(facts, goal)
+			if got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEntailsLimits(t *testing.T) {
+	// n binary facts give 2^n conjunctions
+	choices := func(n int) []string {
+		facts := make([]string, 0, n)
+		for i := range n {
+			facts = append(facts, fmt.Sprintf("x%v = 0 || x%v = 1", i, i))
+		}
+		return facts
+	}
+	// v between n lower and n upper bounds gives n^2 constraints once v is eliminated
+	bounds := func(n int) []string {
+		facts := make([]string, 0, 2*n)
+		for i := range n {
+			facts = append(facts, fmt.Sprintf("v <= y%v", i), fmt.Sprintf("z%v <= v", i))
+		}
+		return facts
+	}
+	tests := []struct {
+		name  string
+		facts []string
+		goal  string
+		want  bool
+	}{
+		{"conjs within limit", choices(8), "x0 <= 1", true},
+		{"conjs over limit", choices(9), "x0 <= 1", false},
+		{"constraints within limit", bounds(30), "z0 <= y0", true},
+		{"constraints over limit", bounds(33), "z0 <= y0", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			facts := parseProps(t, test.facts...)
+			goal := parseProps(t, test.goal)[0]
+			got := Entails(facts, goal)
+			if got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEntailsOverflow(t *testing.T) {
+	tests := []struct {
+		name  string
+		facts []string
+		goal  string
+		want  bool
+	}{
+		{"large within range", []string{"4611686018427387903*x >= 1"}, "x >= 1", true},
+		// wraps to -2*x >= 1, which would prove anything
+		{"sum overflows", []string{"9223372036854775807*x + 9223372036854775807*x >= 1"}, "false", false},
+		{"product overflows", []string{"2*(4611686018427387904*x) >= 1"}, "false", false},
+		{"constant overflows", []string{"x = 9223372036854775807 + 1"}, "false", false},
+		// coprime coefficients multiply past int64 when x is eliminated
+		{"elimination overflows", []string{"3037000507*x <= y", "3037000493*x >= y + 1"}, "false", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			facts := parseProps(t, test.facts...)
+			goal := parseProps(t, test.goal)[0]
+			got := Entails(facts, goal)
+			if got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
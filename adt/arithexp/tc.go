@@ -0,0 +1,371 @@
+package arithexp
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"orglang/go-runtime/adt/symbol"
+)
+
+// Grammar:
+//
+//	prop := conj ("||" conj)*
+//	conj := atom ("&&" atom)*
+//	atom := "true" | "false" | "!" atom | "(" prop ")" | exp cmp exp
+//	cmp  := "=" | "!=" | "<" | "<=" | ">" | ">="
+//	exp  := term (("+" | "-") term)*
+//	term := fact ("*" fact)*
+//	fact := num | var | "(" exp ")"
+func ConvertExpFromString(str string) (ExpSpec, error) {
+	p := parser{toks: tokenize(str)}
+	exp, err := p.exp()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, errUnexpectedToken(p.peek())
+	}
+	return exp, nil
+}
+
+func ConvertPropFromString(str string) (PropSpec, error) {
+	p := parser{toks: tokenize(str)}
+	prop, err := p.prop()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, errUnexpectedToken(p.peek())
+	}
+	return prop, nil
+}
+
+func ConvertExpToString(e ExpSpec) string {
+	switch exp := e.(type) {
+	case NumSpec:
+		return strconv.FormatInt(exp.N, 10)
+	case VarSpec:
+		return symbol.ConvertToString(exp.IdxVar)
+	case AddSpec:
+		return ConvertExpToString(exp.X) + "+" + ConvertExpToString(exp.Y)
+	case SubSpec:
+		return ConvertExpToString(exp.X) + "-" + wrapExp(exp.Y)
+	case MulSpec:
+		return strconv.FormatInt(exp.K, 10) + "*" + wrapExp(exp.X)
+	default:
+		panic(ErrExpTypeUnexpected(e))
+	}
+}
+
+func ConvertPropToString(p PropSpec) string {
+	switch prop := p.(type) {
+	case TrueSpec:
+		return "true"
+	case FalseSpec:
+		return "false"
+	case EqSpec:
+		return ConvertExpToString(prop.X) + "=" + ConvertExpToString(prop.Y)
+	case LtSpec:
+		return ConvertExpToString(prop.X) + "<" + ConvertExpToString(prop.Y)
+	case LeSpec:
+		return ConvertExpToString(prop.X) + "<=" + ConvertExpToString(prop.Y)
+	case NotSpec:
+		return "!" + wrapProp(prop.P)
+	case AndSpec:
+		return wrapProp(prop.P) + "&&" + wrapProp(prop.Q)
+	case OrSpec:
+		return wrapProp(prop.P) + "||" + wrapProp(prop.Q)
+	default:
+		panic(ErrPropTypeUnexpected(p))
+	}
+}
+
+func ConvertExpsFromStrings(strs []string) ([]ExpSpec, error) {
+	var exps []ExpSpec
+	for _, str := range strs {
+		exp, err := ConvertExpFromString(str)
+		if err != nil {
+			return nil, err
+		}
+		exps = append(exps, exp)
+	}
+	return exps, nil
+}
+
+func ConvertExpsToStrings(exps []ExpSpec) []string {
+	var strs []string
+	for _, exp := range exps {
+		strs = append(strs, ConvertExpToString(exp))
+	}
+	return strs
+}
+
+func wrapExp(e ExpSpec) string {
+	switch e.(type) {
+	case AddSpec, SubSpec:
+		return "(" + ConvertExpToString(e) + ")"
+	default:
+		return ConvertExpToString(e)
+	}
+}
+
+func wrapProp(p PropSpec) string {
+	switch p.(type) {
+	case TrueSpec, FalseSpec:
+		return ConvertPropToString(p)
+	default:
+		return "(" + ConvertPropToString(p) + ")"
+	}
+}
+
+type parser struct {
+	toks []string
+	pos  int
+}
+
+func (p *parser) done() bool { return p.pos >= len(p.toks) }
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.toks[p.pos]
+}
+
+func (p *parser) accept(tok string) bool {
+	if p.peek() != tok {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *parser) expect(tok string) error {
+	if !p.accept(tok) {
+		return errUnexpectedToken(p.peek())
+	}
+	return nil
+}
+
+func (p *parser) prop() (PropSpec, error) {
+	left, err := p.conj()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.conj()
+		if err != nil {
+			return nil, err
+		}
+		left = OrSpec{P: left, Q: right}
+	}
+	return left, nil
+}
+
+func (p *parser) conj() (PropSpec, error) {
+	left, err := p.atom()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		left = AndSpec{P: left, Q: right}
+	}
+	return left, nil
+}
+
+func (p *parser) atom() (PropSpec, error) {
+	switch {
+	case p.accept("true"):
+		return TrueSpec{}, nil
+	case p.accept("false"):
+		return FalseSpec{}, nil
+	case p.accept("!"):
+		prop, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		return NotSpec{P: prop}, nil
+	}
+	start := p.pos
+	cmp, err := p.cmp()
+	if err == nil {
+		return cmp, nil
+	}
+	// a paren may open either a constraint or an expression
+	p.pos = start
+	if !p.accept("(") {
+		return nil, err
+	}
+	prop, err := p.prop()
+	if err != nil {
+		return nil, err
+	}
+	return prop, p.expect(")")
+}
+
+func (p *parser) cmp() (PropSpec, error) {
+	x, err := p.exp()
+	if err != nil {
+		return nil, err
+	}
+	op := p.peek()
+	p.pos++
+	y, err := p.exp()
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "=":
+		return EqSpec{X: x, Y: y}, nil
+	case "!=":
+		return NotSpec{P: EqSpec{X: x, Y: y}}, nil
+	case "<":
+		return LtSpec{X: x, Y: y}, nil
+	case "<=":
+		return LeSpec{X: x, Y: y}, nil
+	case ">":
+		return LtSpec{X: y, Y: x}, nil
+	case ">=":
+		return LeSpec{X: y, Y: x}, nil
+	default:
+		return nil, errUnexpectedToken(op)
+	}
+}
+
+func (p *parser) exp() (ExpSpec, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("+"):
+			right, err := p.term()
+			if err != nil {
+				return nil, err
+			}
+			left = AddSpec{X: left, Y: right}
+		case p.accept("-"):
+			right, err := p.term()
+			if err != nil {
+				return nil, err
+			}
+			left = SubSpec{X: left, Y: right}
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (p *parser) term() (ExpSpec, error) {
+	left, err := p.fact()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("*") {
+		right, err := p.fact()
+		if err != nil {
+			return nil, err
+		}
+		// one of the factors must be a constant
+		switch {
+		case isNum(left):
+			left = MulSpec{K: left.(NumSpec).N, X: right}
+		case isNum(right):
+			left = MulSpec{K: right.(NumSpec).N, X: left}
+		default:
+			return nil, fmt.Errorf("nonlinear product: %v*%v", ConvertExpToString(left), ConvertExpToString(right))
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) fact() (ExpSpec, error) {
+	tok := p.peek()
+	var r rune
+	if tok != "" {
+		r = []rune(tok)[0]
+	}
+	switch {
+	case tok == "(":
+		p.pos++
+		exp, err := p.exp()
+		if err != nil {
+			return nil, err
+		}
+		return exp, p.expect(")")
+	case unicode.IsDigit(r):
+		p.pos++
+		n, err := strconv.ParseInt(tok, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return NumSpec{N: n}, nil
+	case isIdent(r):
+		p.pos++
+		return VarSpec{IdxVar: symbol.New(tok)}, nil
+	default:
+		return nil, errUnexpectedToken(tok)
+	}
+}
+
+func isNum(e ExpSpec) bool {
+	_, ok := e.(NumSpec)
+	return ok
+}
+
+func isIdent(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func tokenize(str string) []string {
+	var toks []string
+	rs := []rune(str)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(rs) && unicode.IsDigit(rs[j]) {
+				j++
+			}
+			toks = append(toks, string(rs[i:j]))
+			i = j
+		case isIdent(r):
+			j := i
+			for j < len(rs) && (isIdent(rs[j]) || unicode.IsDigit(rs[j])) {
+				j++
+			}
+			toks = append(toks, string(rs[i:j]))
+			i = j
+		default:
+			two := string(rs[i:min(i+2, len(rs))])
+			if slices.Contains(twoRuneOps, two) {
+				toks = append(toks, two)
+				i += 2
+				continue
+			}
+			toks = append(toks, string(r))
+			i++
+		}
+	}
+	return toks
+}
+
+var twoRuneOps = []string{"<=", ">=", "!=", "&&", "||"}
+
+func errUnexpectedToken(tok string) error {
+	if tok == "" {
+		return fmt.Errorf("unexpected end of input")
+	}
+	return fmt.Errorf("unexpected token: %v", strings.TrimSpace(tok))
+}
@@ -1,6 +1,7 @@
 package identity
 
 import (
+	"crypto/sha256"
 	"errors"
 
 	"github.com/rs/xid"
@@ -22,6 +23,14 @@ func New() ADT {
 	return ADT(xid.New())
 }
 
+// equal keys give equal ids
+func Derive(key string) ADT {
+	sum := sha256.Sum256([]byte(key))
+	var id xid.ID
+	copy(id[:], sum[:])
+	return ADT(id)
+}
+
 func Empty() ADT {
 	return ADT(xid.NilID())
 }
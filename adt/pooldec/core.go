@@ -1,23 +1,28 @@
 package pooldec
 
 import (
+	"context"
 	"log/slog"
+	"reflect"
+	"slices"
 
 	"orglang/go-runtime/lib/db"
 
 	"orglang/go-runtime/adt/identity"
 	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/syndec"
+	"orglang/go-runtime/adt/typedef"
+	"orglang/go-runtime/adt/uniqref"
 	"orglang/go-runtime/adt/uniqsym"
 )
 
 // Port
 type API interface {
-	Create(DecSpec) (DecRef, error)
-}
-
-// for compilation purposes
-func newAPI() API {
-	return &service{}
+	Create(context.Context, DecSpec) (DecRef, error)
+	RetrieveSnap(context.Context, DecRef) (DecSnap, error)
+	RetrieveSnapByQN(context.Context, uniqsym.ADT) (DecSnap, error)
+	RetreiveRefs(context.Context) ([]DecRef, error)
 }
 
 type DecSpec struct {
@@ -32,20 +37,142 @@ type DecSpec struct {
 	OutsiderReceptionBCs []procbind.BindSpec
 }
 
-type DecRef struct {
-	DecID identity.ADT
-}
+type DecRef = uniqref.ADT
 
 type DecRec struct {
-	DecID identity.ADT
+	DecRef               DecRef
+	InsiderProvisionBCs  []procbind.BindSpec
+	InsiderReceptionBCs  []procbind.BindSpec
+	OutsiderProvisionBCs []procbind.BindSpec
+	OutsiderReceptionBCs []procbind.BindSpec
+}
+
+type DecSnap struct {
+	DecRef               DecRef
+	InsiderProvisionBCs  []procbind.BindSpec
+	InsiderReceptionBCs  []procbind.BindSpec
+	OutsiderProvisionBCs []procbind.BindSpec
+	OutsiderReceptionBCs []procbind.BindSpec
 }
 
 type service struct {
-	poolDecs repo
+	poolDecs Repo
+	synDecs  syndec.Repo
+	typeDefs typedef.Repo
 	operator db.Operator
 	log      *slog.Logger
 }
 
-func (s *service) Create(spec DecSpec) (DecRef, error) {
-	return DecRef{}, nil
+// for compilation purposes
+func newAPI() API {
+	return &service{}
+}
+
+func newService(
+	poolDecs Repo,
+	synDecs syndec.Repo,
+	typeDefs typedef.Repo,
+	operator db.Operator,
+	l *slog.Logger,
+) *service {
+	name := slog.String("name", reflect.TypeFor[service]().Name())
+	return &service{poolDecs, synDecs, typeDefs, operator, l.With(name)}
+}
+
+func (s *service) Create(ctx context.Context, spec DecSpec) (_ DecRef, err error) {
+	qnAttr := slog.Any("poolQN", spec.PoolQN)
+	s.log.Debug("creation started", qnAttr, slog.Any("spec", spec))
+	typeQNs := CollectEnv(spec)
+	var typeDefs map[uniqsym.ADT]typedef.DefRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		typeDefs, err = s.typeDefs.SelectEnv(ds, typeQNs)
+		return err
+	})
+	if err != nil {
+		s.log.Error("creation failed", qnAttr, slog.Any("types", typeQNs))
+		return DecRef{}, err
+	}
+	for _, typeQN := range typeQNs {
+		_, ok := typeDefs[typeQN]
+		if !ok {
+			s.log.Error("creation failed", qnAttr, slog.Any("type", typeQN))
+			return DecRef{}, typedef.ErrSymMissingInEnv(typeQN)
+		}
+	}
+	newSyn := syndec.DecRec{DecQN: spec.PoolQN, DecID: identity.New(), DecRN: revnum.New()}
+	newRec := DecRec{
+		DecRef:               DecRef{ID: newSyn.DecID, RN: newSyn.DecRN},
+		InsiderProvisionBCs:  spec.InsiderProvisionBCs,
+		InsiderReceptionBCs:  spec.InsiderReceptionBCs,
+		OutsiderProvisionBCs: spec.OutsiderProvisionBCs,
+		OutsiderReceptionBCs: spec.OutsiderReceptionBCs,
+	}
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		err = s.synDecs.Insert(ds, newSyn)
+		if err != nil {
+			return err
+		}
+		return s.poolDecs.InsertRec(ds, newRec)
+	})
+	if err != nil {
+		s.log.Error("creation failed", qnAttr)
+		return DecRef{}, err
+	}
+	s.log.Debug("creation succeed", qnAttr, slog.Any("decRef", newRec.DecRef))
+	return newRec.DecRef, nil
+}
+
+func (s *service) RetrieveSnap(ctx context.Context, ref DecRef) (_ DecSnap, err error) {
+	var rec DecRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		rec, err = s.poolDecs.SelectRecByID(ds, ref.ID)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", slog.Any("decRef", ref))
+		return DecSnap{}, err
+	}
+	return ConvertRecToSnap(rec), nil
+}
+
+func (s *service) RetrieveSnapByQN(ctx context.Context, poolQN uniqsym.ADT) (_ DecSnap, err error) {
+	var rec DecRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		synDR, err := s.synDecs.SelectRecByQN(ds, poolQN)
+		if err != nil {
+			return err
+		}
+		rec, err = s.poolDecs.SelectRecByID(ds, synDR.DecID)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", slog.Any("poolQN", poolQN))
+		return DecSnap{}, err
+	}
+	return ConvertRecToSnap(rec), nil
+}
+
+func (s *service) RetreiveRefs(ctx context.Context) (refs []DecRef, err error) {
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		refs, err = s.poolDecs.SelectRefs(ds)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed")
+		return nil, err
+	}
+	return refs, nil
+}
+
+func CollectEnv(spec DecSpec) []uniqsym.ADT {
+	typeQNs := []uniqsym.ADT{}
+	for _, bc := range slices.Concat(
+		spec.InsiderProvisionBCs,
+		spec.InsiderReceptionBCs,
+		spec.OutsiderProvisionBCs,
+		spec.OutsiderReceptionBCs,
+	) {
+		typeQNs = append(typeQNs, bc.TypeQN)
+	}
+	return typeQNs
 }
@@ -1,10 +1,41 @@
 package pooldec
 
 import (
+	"log/slog"
+
 	"orglang/go-runtime/lib/db"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/uniqref"
 )
 
 // Port
-type repo interface {
-	Insert(db.Source, DecRec) error
+type Repo interface {
+	InsertRec(db.Source, DecRec) error
+	SelectRefs(db.Source) ([]DecRef, error)
+	SelectRecByID(db.Source, identity.ADT) (DecRec, error)
+}
+
+// storage adapter is chosen by the operator
+func newDAO(l *slog.Logger, o db.Operator) Repo {
+	switch o.(type) {
+	case *db.OperatorMem:
+		return newMemDAO(l)
+	case *db.OperatorSqlite:
+		return newSqliteDAO(l)
+	default:
+		return newPgxDAO(l)
+	}
+}
+
+type decRefDS = uniqref.Data
+
+type decRecDS struct {
+	ID                   string                `db:"dec_id"`
+	RN                   int64                 `db:"dec_rn"`
+	InsiderProvisionBCs  []procbind.BindSpecDS `db:"ipbs"`
+	InsiderReceptionBCs  []procbind.BindSpecDS `db:"irbs"`
+	OutsiderProvisionBCs []procbind.BindSpecDS `db:"opbs"`
+	OutsiderReceptionBCs []procbind.BindSpecDS `db:"orbs"`
 }
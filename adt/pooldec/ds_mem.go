@@ -0,0 +1,71 @@
+package pooldec
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/uniqref"
+)
+
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(l *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{l.With(name)}
+}
+
+func (dao *memDAO) InsertRec(source db.Source, rec DecRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("decRef", rec.DecRef)
+	dto, err := DataFromDecRec(rec)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return err
+	}
+	db.InsertMem(ds, poolDecs, dto)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", refAttr)
+	return nil
+}
+
+func (dao *memDAO) SelectRecByID(source db.Source, decID identity.ADT) (DecRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	idAttr := slog.Any("decID", decID)
+	dto, err := db.SelectLatestMem(ds, poolDecs,
+		func(dto decRecDS) bool { return dto.ID == decID.String() },
+		func(dto decRecDS) int64 { return dto.RN },
+	)
+	if err != nil {
+		dao.log.Error("selection failed", idAttr)
+		return DecRec{}, err
+	}
+	rec, err := DataToDecRec(dto)
+	if err != nil {
+		dao.log.Error("conversion failed", idAttr)
+		return DecRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", idAttr)
+	return rec, nil
+}
+
+func (dao *memDAO) SelectRefs(source db.Source) ([]DecRef, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	latest := db.LatestMem(db.SelectMem[decRecDS](ds, poolDecs),
+		func(dto decRecDS) string { return dto.ID },
+		func(dto decRecDS) int64 { return dto.RN },
+	)
+	dtos := make([]decRefDS, 0, len(latest))
+	for _, dto := range latest {
+		dtos = append(dtos, decRefDS{ID: dto.ID, RN: dto.RN})
+	}
+	return uniqref.DataToADTs(dtos)
+}
+
+const (
+	poolDecs = "pool_decs"
+)
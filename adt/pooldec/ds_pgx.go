@@ -1 +1,117 @@
 package pooldec
+
+import (
+	"log/slog"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/uniqref"
+)
+
+// Adapter
+type pgxDAO struct {
+	log *slog.Logger
+}
+
+func newPgxDAO(l *slog.Logger) *pgxDAO {
+	name := slog.String("name", reflect.TypeFor[pgxDAO]().Name())
+	return &pgxDAO{l.With(name)}
+}
+
+// for compilation purposes
+func newRepo() Repo {
+	return &pgxDAO{}
+}
+
+func (dao *pgxDAO) InsertRec(source db.Source, rec DecRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("decRef", rec.DecRef)
+	dto, err := DataFromDecRec(rec)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return err
+	}
+	args := pgx.NamedArgs{
+		"dec_id": dto.ID,
+		"dec_rn": dto.RN,
+		"ipbs":   dto.InsiderProvisionBCs,
+		"irbs":   dto.InsiderReceptionBCs,
+		"opbs":   dto.OutsiderProvisionBCs,
+		"orbs":   dto.OutsiderReceptionBCs,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertRec, args)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", refAttr)
+	return nil
+}
+
+func (dao *pgxDAO) SelectRecByID(source db.Source, decID identity.ADT) (DecRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("decID", decID)
+	rows, err := ds.Conn.Query(ds.Ctx, selectByID, decID.String())
+	if err != nil {
+		dao.log.Error("execution failed", idAttr)
+		return DecRec{}, err
+	}
+	defer rows.Close()
+	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[decRecDS])
+	if err != nil {
+		dao.log.Error("collection failed", idAttr, slog.Any("t", reflect.TypeOf(dto)))
+		return DecRec{}, err
+	}
+	rec, err := DataToDecRec(dto)
+	if err != nil {
+		dao.log.Error("conversion failed", idAttr)
+		return DecRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", idAttr)
+	return rec, nil
+}
+
+func (dao *pgxDAO) SelectRefs(source db.Source) ([]DecRef, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	rows, err := ds.Conn.Query(ds.Ctx, selectRefs)
+	if err != nil {
+		dao.log.Error("execution failed")
+		return nil, err
+	}
+	defer rows.Close()
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[decRefDS])
+	if err != nil {
+		dao.log.Error("collection failed", slog.Any("t", reflect.TypeOf(dtos)))
+		return nil, err
+	}
+	return uniqref.DataToADTs(dtos)
+}
+
+const (
+	insertRec = `
+		insert into pool_decs (
+			dec_id, dec_rn, ipbs, irbs, opbs, orbs
+		) values (
+			@dec_id, @dec_rn, @ipbs, @irbs, @opbs, @orbs
+		)`
+
+	// latest revision
+	selectByID = `
+		select
+			dec_id, dec_rn, ipbs, irbs, opbs, orbs
+		from pool_decs
+		where dec_id = $1
+		order by dec_rn desc
+		limit 1`
+
+	selectRefs = `
+		select distinct on (dec_id)
+			dec_id as id, dec_rn as rn
+		from pool_decs
+		order by dec_id, dec_rn desc`
+)
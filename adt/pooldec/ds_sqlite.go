@@ -0,0 +1,109 @@
+package pooldec
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/uniqref"
+)
+
+// Adapter
+type sqliteDAO struct {
+	log *slog.Logger
+}
+
+func newSqliteDAO(l *slog.Logger) *sqliteDAO {
+	name := slog.String("name", reflect.TypeFor[sqliteDAO]().Name())
+	return &sqliteDAO{l.With(name)}
+}
+
+func (dao *sqliteDAO) InsertRec(source db.Source, rec DecRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("decRef", rec.DecRef)
+	dto, err := DataFromDecRec(rec)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return err
+	}
+	args := db.NamedArgsSqlite{
+		"dec_id": dto.ID,
+		"dec_rn": dto.RN,
+		"ipbs":   dto.InsiderProvisionBCs,
+		"irbs":   dto.InsiderReceptionBCs,
+		"opbs":   dto.OutsiderProvisionBCs,
+		"orbs":   dto.OutsiderReceptionBCs,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertRecSqlite, args)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", refAttr)
+	return nil
+}
+
+func (dao *sqliteDAO) SelectRecByID(source db.Source, decID identity.ADT) (DecRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	idAttr := slog.Any("decID", decID)
+	rows, err := ds.Conn.Query(ds.Ctx, selectByIDSqlite, db.NamedArgsSqlite{"dec_id": decID.String()})
+	if err != nil {
+		dao.log.Error("execution failed", idAttr)
+		return DecRec{}, err
+	}
+	dto, err := db.CollectOneRowSqlite[decRecDS](rows)
+	if err != nil {
+		dao.log.Error("collection failed", idAttr, slog.Any("t", reflect.TypeOf(dto)))
+		return DecRec{}, err
+	}
+	rec, err := DataToDecRec(dto)
+	if err != nil {
+		dao.log.Error("conversion failed", idAttr)
+		return DecRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", idAttr)
+	return rec, nil
+}
+
+func (dao *sqliteDAO) SelectRefs(source db.Source) ([]DecRef, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	rows, err := ds.Conn.Query(ds.Ctx, selectRefsSqlite, nil)
+	if err != nil {
+		dao.log.Error("execution failed")
+		return nil, err
+	}
+	dtos, err := db.CollectRowsSqlite[decRefDS](rows)
+	if err != nil {
+		dao.log.Error("collection failed", slog.Any("t", reflect.TypeOf(dtos)))
+		return nil, err
+	}
+	return uniqref.DataToADTs(dtos)
+}
+
+const (
+	insertRecSqlite = `
+		insert into pool_decs (
+			dec_id, dec_rn, ipbs, irbs, opbs, orbs
+		) values (
+			:dec_id, :dec_rn, :ipbs, :irbs, :opbs, :orbs
+		)`
+
+	// latest revision
+	selectByIDSqlite = `
+		select
+			dec_id, dec_rn, ipbs, irbs, opbs, orbs
+		from pool_decs
+		where dec_id = :dec_id
+		order by dec_rn desc
+		limit 1`
+
+	selectRefsSqlite = `
+		select
+			dec_id as id, max(dec_rn) as rn
+		from pool_decs
+		group by dec_id
+		order by dec_id`
+)
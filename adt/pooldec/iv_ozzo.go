@@ -0,0 +1,13 @@
+package pooldec
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/orglang/go-sdk/adt/uniqsym"
+)
+
+func (dto DecSpecVP) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.PoolQN, uniqsym.Required...),
+	)
+}
@@ -0,0 +1,101 @@
+package pooldec
+
+import (
+	"log/slog"
+	"net/http"
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+
+	"orglang/go-runtime/adt/uniqref"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+// Server-side primary adapter
+type echoController struct {
+	api API
+	log *slog.Logger
+}
+
+func newEchoController(a API, l *slog.Logger) *echoController {
+	name := slog.String("name", reflect.TypeFor[echoController]().Name())
+	return &echoController{a, l.With(name)}
+}
+
+func cfgEchoController(e *echo.Echo, h *echoController) error {
+	e.POST("/api/v1/pooldecs", h.PostSpec)
+	e.GET("/api/v1/pooldecs", h.GetRefs)
+	e.GET("/api/v1/pooldecs/:id", h.GetSnap)
+	e.GET("/api/v1/pooldecs/qns/:qn", h.GetSnapByQN)
+	return nil
+}
+
+func (h *echoController) PostSpec(c echo.Context) error {
+	var dto DecSpecVP
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	validationErr := dto.Validate()
+	if validationErr != nil {
+		h.log.Error("validation failed", slog.Any("dto", dto))
+		return validationErr
+	}
+	spec, conversionErr := ViewToDecSpec(dto)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	ref, creationErr := h.api.Create(c.Request().Context(), spec)
+	if creationErr != nil {
+		return creationErr
+	}
+	return c.JSON(http.StatusCreated, uniqref.MsgFromADT(ref))
+}
+
+func (h *echoController) GetRefs(c echo.Context) error {
+	refs, retrievalErr := h.api.RetreiveRefs(c.Request().Context())
+	if retrievalErr != nil {
+		return retrievalErr
+	}
+	return c.JSON(http.StatusOK, uniqref.MsgFromADTs(refs))
+}
+
+func (h *echoController) GetSnap(c echo.Context) error {
+	var dto DecRefVP
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	ref, conversionErr := uniqref.MsgToADT(dto)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	snap, retrievalErr := h.api.RetrieveSnap(c.Request().Context(), ref)
+	if retrievalErr != nil {
+		return retrievalErr
+	}
+	return c.JSON(http.StatusOK, ViewFromDecSnap(snap))
+}
+
+func (h *echoController) GetSnapByQN(c echo.Context) error {
+	var dto DecQNVP
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	qn, conversionErr := uniqsym.ConvertFromString(dto.PoolQN)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	snap, retrievalErr := h.api.RetrieveSnapByQN(c.Request().Context(), qn)
+	if retrievalErr != nil {
+		return retrievalErr
+	}
+	return c.JSON(http.StatusOK, ViewFromDecSnap(snap))
+}
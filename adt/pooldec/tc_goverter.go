@@ -0,0 +1,29 @@
+package pooldec
+
+// goverter:variables
+// goverter:output:format assign-variable
+var (
+	ConvertRecToSnap func(DecRec) DecSnap
+)
+
+// goverter:variables
+// goverter:output:format assign-variable
+// goverter:extend orglang/go-runtime/adt/uniqsym:Convert.*
+// goverter:extend orglang/go-runtime/adt/uniqref:Msg.*
+// goverter:extend orglang/go-runtime/adt/procbind:Msg.*
+var (
+	ViewToDecSpec   func(DecSpecVP) (DecSpec, error)
+	ViewFromDecSnap func(DecSnap) DecSnapVP
+)
+
+// goverter:variables
+// goverter:output:format assign-variable
+// goverter:extend orglang/go-runtime/adt/identity:Convert.*
+// goverter:extend orglang/go-runtime/adt/revnum:Convert.*
+// goverter:extend orglang/go-runtime/adt/procbind:Data.*
+var (
+	// goverter:map . DecRef
+	DataToDecRec func(decRecDS) (DecRec, error)
+	// goverter:autoMap DecRef
+	DataFromDecRec func(DecRec) (decRecDS, error)
+)
@@ -0,0 +1,28 @@
+package pooldec
+
+import (
+	"github.com/orglang/go-sdk/adt/procbind"
+	"github.com/orglang/go-sdk/adt/uniqref"
+)
+
+type DecRefVP = uniqref.Msg
+
+type DecSpecVP struct {
+	PoolQN               string              `json:"qn"`
+	InsiderProvisionBCs  []procbind.BindSpec `json:"insider_provision_bcs"`
+	InsiderReceptionBCs  []procbind.BindSpec `json:"insider_reception_bcs"`
+	OutsiderProvisionBCs []procbind.BindSpec `json:"outsider_provision_bcs"`
+	OutsiderReceptionBCs []procbind.BindSpec `json:"outsider_reception_bcs"`
+}
+
+type DecSnapVP struct {
+	DecRef               DecRefVP            `json:"ref"`
+	InsiderProvisionBCs  []procbind.BindSpec `json:"insider_provision_bcs"`
+	InsiderReceptionBCs  []procbind.BindSpec `json:"insider_reception_bcs"`
+	OutsiderProvisionBCs []procbind.BindSpec `json:"outsider_provision_bcs"`
+	OutsiderReceptionBCs []procbind.BindSpec `json:"outsider_reception_bcs"`
+}
+
+type DecQNVP struct {
+	PoolQN string `param:"qn"`
+}
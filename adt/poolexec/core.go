@@ -2,28 +2,36 @@ package poolexec
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"reflect"
+	"time"
 
 	"orglang/go-runtime/lib/db"
 
 	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/poolexp"
 	"orglang/go-runtime/adt/poolstep"
+	"orglang/go-runtime/adt/procbind"
 	"orglang/go-runtime/adt/procdec"
+	"orglang/go-runtime/adt/procdef"
 	"orglang/go-runtime/adt/procexec"
+	"orglang/go-runtime/adt/procstep"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/syndec"
 	"orglang/go-runtime/adt/typedef"
-	"orglang/go-runtime/adt/typeexp"
 	"orglang/go-runtime/adt/uniqref"
 	"orglang/go-runtime/adt/uniqsym"
 )
 
 // Port
 type API interface {
-	Run(ExecSpec) (ExecRef, error) // aka Create
-	RetrieveSnap(ExecRef) (ExecSnap, error)
-	RetreiveRefs() ([]ExecRef, error)
-	Take(poolstep.StepSpec) error
-	Poll(PollSpec) (procexec.ExecRef, error)
+	Run(context.Context, ExecSpec) (ExecRef, error) // aka Create
+	RetrieveSnap(context.Context, ExecRef) (ExecSnap, error)
+	RetreiveRefs(context.Context) ([]ExecRef, error)
+	Take(context.Context, poolstep.StepSpec) error
+	Poll(context.Context, PollSpec) (procexec.ExecRef, error)
 }
 
 type ExecSpec struct {
@@ -35,6 +43,7 @@ type ExecRef = uniqref.ADT
 
 type ExecRec struct {
 	ExecRef ExecRef
+	PoolQN  uniqsym.ADT
 	SupID   identity.ADT
 }
 
@@ -56,13 +65,66 @@ type Liab struct {
 	ProcID  identity.ADT
 }
 
+// right to provide processes of a signature
+type CapRec struct {
+	// positive on hire
+	// negative on fire
+	ExecRef ExecRef
+	SigID   identity.ADT
+}
+
+// right to consume processes of a signature
+type DepRec struct {
+	// positive on apply
+	// negative on quit
+	ExecRef ExecRef
+	SigID   identity.ADT
+}
+
+// client end of a channel held by the pool
+type AssetRec struct {
+	// positive on acquire
+	// negative on release
+	ExecRef ExecRef
+	ChnlPH  symbol.ADT
+	ChnlID  identity.ADT
+	ProcID  identity.ADT
+	SigID   identity.ADT
+}
+
+// aka Configuration
+type ExecCfg struct {
+	ExecRef ExecRef
+	CapRs   map[identity.ADT]CapRec
+	DepRs   map[identity.ADT]DepRec
+	AssetRs map[symbol.ADT]AssetRec
+}
+
+type ExecMod struct {
+	Locks  []ExecRef
+	Caps   []CapRec
+	Deps   []DepRec
+	Liabs  []Liab
+	Assets []AssetRec
+}
+
+func CapSig(rec CapRec) identity.ADT { return rec.SigID }
+
+func DepSig(rec DepRec) identity.ADT { return rec.SigID }
+
+func ChnlPH(rec AssetRec) symbol.ADT { return rec.ChnlPH }
+
 type service struct {
 	poolExecs Repo
+	procExecs procexec.Repo
 	procDecs  procdec.Repo
+	procDefs  procdef.Repo
+	synDecs   syndec.Repo
 	typeDefs  typedef.Repo
-	typeExps  typeexp.Repo
 	operator  db.Operator
-	log       *slog.Logger
+	// deadline after which a claimed execution is ready again
+	lease time.Duration
+	log   *slog.Logger
 }
 
 // for compilation purposes
@@ -72,21 +134,24 @@ func newAPI() API {
 
 func newService(
 	poolExecs Repo,
+	procExecs procexec.Repo,
 	procDecs procdec.Repo,
+	procDefs procdef.Repo,
+	synDecs syndec.Repo,
 	typeDefs typedef.Repo,
-	typeExps typeexp.Repo,
 	operator db.Operator,
+	cs pollingCS,
 	log *slog.Logger,
 ) *service {
 	name := slog.String("name", reflect.TypeFor[service]().Name())
-	return &service{poolExecs, procDecs, typeDefs, typeExps, operator, log.With(name)}
+	return &service{poolExecs, procExecs, procDecs, procDefs, synDecs, typeDefs, operator, cs.Lease, log.With(name)}
 }
 
-func (s *service) Run(spec ExecSpec) (ExecRef, error) {
-	ctx := context.Background()
+func (s *service) Run(ctx context.Context, spec ExecSpec) (ExecRef, error) {
 	s.log.Debug("creation started", slog.Any("spec", spec))
 	execRec := ExecRec{
 		ExecRef: uniqref.New(),
+		PoolQN:  spec.PoolQN,
 		SupID:   spec.SupID,
 	}
 	err := s.operator.Explicit(ctx, func(ds db.Source) error {
@@ -105,18 +170,340 @@ func (s *service) Run(spec ExecSpec) (ExecRef, error) {
 	return execRec.ExecRef, nil
 }
 
-func (s *service) Poll(spec PollSpec) (procexec.ExecRef, error) {
-	return procexec.ExecRef{}, nil
+// returns zero ref when no execution is ready
+func (s *service) Poll(ctx context.Context, spec PollSpec) (_ procexec.ExecRef, err error) {
+	idAttr := slog.Any("execID", spec.ExecID)
+	s.log.Debug("polling started", idAttr)
+	var procRef procexec.ExecRef
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		procRef, err = s.poolExecs.SelectReady(ds, spec.ExecID, s.lease)
+		return err
+	})
+	if err != nil {
+		s.log.Error("polling failed", idAttr)
+		return procexec.ExecRef{}, err
+	}
+	s.log.Debug("polling succeed", idAttr, slog.Any("procRef", procRef))
+	return procRef, nil
 }
 
-func (s *service) Take(spec poolstep.StepSpec) (err error) {
-	qnAttr := slog.Any("procQN", spec.ProcQN)
-	s.log.Debug("spawning started", qnAttr)
+func (s *service) Take(ctx context.Context, spec poolstep.StepSpec) (err error) {
+	refAttr := slog.Any("execRef", spec.ExecRef)
+	s.log.Debug("taking started", refAttr)
+	var execCfg ExecCfg
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		execCfg, err = s.poolExecs.SelectCfg(ds, spec.ExecRef)
+		return err
+	})
+	if err != nil {
+		s.log.Error("taking failed", refAttr)
+		return err
+	}
+	poolMod, procMod, err := s.takeWith(ctx, execCfg, spec)
+	if err != nil {
+		s.log.Error("taking failed", refAttr)
+		return err
+	}
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		if len(procMod.Execs) > 0 {
+			err = s.procExecs.UpdateProc(ds, procMod)
+			if err != nil {
+				return err
+			}
+			err = s.procExecs.InsertRuns(ds, startRuns(procMod)...)
+			if err != nil {
+				return err
+			}
+		}
+		return s.poolExecs.UpdateCfg(ds, poolMod)
+	})
+	if err != nil {
+		s.log.Error("taking failed", refAttr)
+		return err
+	}
+	s.log.Debug("taking succeed", refAttr)
 	return nil
 }
 
-func (s *service) RetrieveSnap(ref ExecRef) (snap ExecSnap, err error) {
-	ctx := context.Background()
+func (s *service) takeWith(
+	ctx context.Context,
+	execCfg ExecCfg,
+	stepSpec poolstep.StepSpec,
+) (
+	poolMod ExecMod,
+	procMod procexec.ExecMod,
+	_ error,
+) {
+	nextRef := ExecRef{ID: execCfg.ExecRef.ID, RN: execCfg.ExecRef.RN.Next()}
+	poolMod.Locks = append(poolMod.Locks, execCfg.ExecRef)
+	switch expSpec := stepSpec.ProcES.(type) {
+	case poolexp.HireSpec:
+		synDR, err := s.selectSyn(ctx, expSpec.ProcQN)
+		if err != nil {
+			return ExecMod{}, procexec.ExecMod{}, err
+		}
+		_, ok := execCfg.CapRs[synDR.DecID]
+		if ok {
+			return ExecMod{}, procexec.ExecMod{}, errDuplicateCap(synDR.DecID)
+		}
+		newCap := CapRec{ExecRef: nextRef, SigID: synDR.DecID}
+		poolMod.Caps = append(poolMod.Caps, newCap)
+		return poolMod, procexec.ExecMod{}, nil
+	case poolexp.FireSpec:
+		synDR, err := s.selectSyn(ctx, expSpec.ProcQN)
+		if err != nil {
+			return ExecMod{}, procexec.ExecMod{}, err
+		}
+		_, ok := execCfg.CapRs[synDR.DecID]
+		if !ok {
+			return ExecMod{}, procexec.ExecMod{}, errMissingCap(synDR.DecID)
+		}
+		oldCap := CapRec{
+			ExecRef: ExecRef{ID: nextRef.ID, RN: -nextRef.RN},
+			SigID:   synDR.DecID,
+		}
+		poolMod.Caps = append(poolMod.Caps, oldCap)
+		return poolMod, procexec.ExecMod{}, nil
+	case poolexp.ApplySpec:
+		synDR, err := s.selectSyn(ctx, expSpec.ProcQN)
+		if err != nil {
+			return ExecMod{}, procexec.ExecMod{}, err
+		}
+		_, ok := execCfg.DepRs[synDR.DecID]
+		if ok {
+			return ExecMod{}, procexec.ExecMod{}, errDuplicateDep(synDR.DecID)
+		}
+		newDep := DepRec{ExecRef: nextRef, SigID: synDR.DecID}
+		poolMod.Deps = append(poolMod.Deps, newDep)
+		return poolMod, procexec.ExecMod{}, nil
+	case poolexp.QuitSpec:
+		synDR, err := s.selectSyn(ctx, expSpec.ProcQN)
+		if err != nil {
+			return ExecMod{}, procexec.ExecMod{}, err
+		}
+		_, ok := execCfg.DepRs[synDR.DecID]
+		if !ok {
+			return ExecMod{}, procexec.ExecMod{}, errMissingDep(synDR.DecID)
+		}
+		oldDep := DepRec{
+			ExecRef: ExecRef{ID: nextRef.ID, RN: -nextRef.RN},
+			SigID:   synDR.DecID,
+		}
+		poolMod.Deps = append(poolMod.Deps, oldDep)
+		return poolMod, procexec.ExecMod{}, nil
+	case poolexp.AcquireSpec:
+		_, ok := execCfg.AssetRs[expSpec.BindPH]
+		if ok {
+			return ExecMod{}, procexec.ExecMod{}, errDuplicateAsset(expSpec.BindPH)
+		}
+		synDR, err := s.selectSyn(ctx, stepSpec.ProcQN)
+		if err != nil {
+			return ExecMod{}, procexec.ExecMod{}, err
+		}
+		_, ok = execCfg.DepRs[synDR.DecID]
+		if !ok {
+			return ExecMod{}, procexec.ExecMod{}, errMissingDep(synDR.DecID)
+		}
+		providerCfg, err := s.selectCfg(ctx, expSpec.PoolQN)
+		if err != nil {
+			return ExecMod{}, procexec.ExecMod{}, err
+		}
+		_, ok = providerCfg.CapRs[synDR.DecID]
+		if !ok {
+			return ExecMod{}, procexec.ExecMod{}, errMissingCap(synDR.DecID)
+		}
+		var procDRs map[identity.ADT]procdec.DecRec
+		err = s.operator.Implicit(ctx, func(ds db.Source) error {
+			procDRs, err = s.procDecs.SelectEnv(ds, []identity.ADT{synDR.DecID})
+			return err
+		})
+		if err != nil {
+			return ExecMod{}, procexec.ExecMod{}, err
+		}
+		procDR, ok := procDRs[synDR.DecID]
+		if !ok {
+			return ExecMod{}, procexec.ExecMod{}, procdec.ErrRootMissingInEnv(synDR.DecID)
+		}
+		// the pool holds only the provided channel, so nothing is there to pass in
+		if len(procDR.ClientBSs) > 0 {
+			return ExecMod{}, procexec.ExecMod{}, procdef.ErrCtxMismatch(len(procDR.ClientBSs), 0)
+		}
+		var procDefs map[identity.ADT]procdef.DefRec
+		err = s.operator.Implicit(ctx, func(ds db.Source) error {
+			procDefs, err = s.procDefs.SelectEnv(ds, []identity.ADT{synDR.DecID})
+			return err
+		})
+		if err != nil {
+			return ExecMod{}, procexec.ExecMod{}, err
+		}
+		procDef, ok := procDefs[synDR.DecID]
+		if !ok {
+			return ExecMod{}, procexec.ExecMod{}, procdef.ErrDoesNotExist(synDR.DecID)
+		}
+		typeQN := procDR.ProviderBS.TypeQN
+		// an expression with type variables cannot be bound without substitution
+		if len(procDR.ProviderBS.TypeArgs) > 0 || len(procDR.ProviderBS.IdxArgs) > 0 {
+			return ExecMod{}, procexec.ExecMod{}, errParametricProvider(typeQN)
+		}
+		var typeDRs map[uniqsym.ADT]typedef.DefRec
+		err = s.operator.Implicit(ctx, func(ds db.Source) error {
+			typeDRs, err = s.typeDefs.SelectEnv(ds, []uniqsym.ADT{typeQN})
+			return err
+		})
+		if err != nil {
+			return ExecMod{}, procexec.ExecMod{}, err
+		}
+		typeDR, ok := typeDRs[typeQN]
+		if !ok {
+			return ExecMod{}, procexec.ExecMod{}, typedef.ErrSymMissingInEnv(typeQN)
+		}
+		// the process is spawned on the provider side
+		newExec := procexec.ExecRec{ExecRef: uniqref.New(), DecRef: procDR.DecRef}
+		newChnlID := identity.New()
+		procMod.Execs = append(procMod.Execs, newExec)
+		procMod.Binds = append(procMod.Binds, procbind.BindRec{
+			ExecRef: newExec.ExecRef,
+			ChnlBS:  procbind.ProviderSide,
+			ChnlPH:  procDR.ProviderBS.ChnlPH,
+			ChnlID:  newChnlID,
+			ExpID:   typeDR.ExpID,
+		})
+		// the body runs on the provider binds and starts once the mod is stored
+		procMod.Wakes = append(procMod.Wakes, procstep.StepSpec{
+			ExecRef: newExec.ExecRef,
+			ProcES:  procDef.ProcES,
+		})
+		providerRef := nextRef
+		if providerCfg.ExecRef.ID != execCfg.ExecRef.ID {
+			providerRef = ExecRef{ID: providerCfg.ExecRef.ID, RN: providerCfg.ExecRef.RN.Next()}
+			poolMod.Locks = append(poolMod.Locks, providerCfg.ExecRef)
+		}
+		poolMod.Liabs = append(poolMod.Liabs, Liab{ExecRef: providerRef, ProcID: newExec.ExecRef.ID})
+		// the channel is held on the client side
+		poolMod.Assets = append(poolMod.Assets, AssetRec{
+			ExecRef: nextRef,
+			ChnlPH:  expSpec.BindPH,
+			ChnlID:  newChnlID,
+			ProcID:  newExec.ExecRef.ID,
+			SigID:   synDR.DecID,
+		})
+		return poolMod, procMod, nil
+	case poolexp.ReleaseSpec:
+		asset, ok := execCfg.AssetRs[expSpec.BindPH]
+		if !ok {
+			return ExecMod{}, procexec.ExecMod{}, errMissingAsset(expSpec.BindPH)
+		}
+		asset.ExecRef = ExecRef{ID: nextRef.ID, RN: -nextRef.RN}
+		poolMod.Assets = append(poolMod.Assets, asset)
+		return poolMod, procexec.ExecMod{}, nil
+	case poolexp.DetachSpec:
+		asset, ok := execCfg.AssetRs[expSpec.ValPH]
+		if !ok {
+			return ExecMod{}, procexec.ExecMod{}, errMissingAsset(expSpec.ValPH)
+		}
+		clientCfg, err := s.selectCfg(ctx, expSpec.PoolQN)
+		if err != nil {
+			return ExecMod{}, procexec.ExecMod{}, err
+		}
+		_, ok = clientCfg.AssetRs[expSpec.ValPH]
+		if ok {
+			return ExecMod{}, procexec.ExecMod{}, errDuplicateAsset(expSpec.ValPH)
+		}
+		_, ok = clientCfg.DepRs[asset.SigID]
+		if !ok {
+			return ExecMod{}, procexec.ExecMod{}, errMissingDep(asset.SigID)
+		}
+		oldAsset := asset
+		oldAsset.ExecRef = ExecRef{ID: nextRef.ID, RN: -nextRef.RN}
+		newAsset := asset
+		newAsset.ExecRef = ExecRef{ID: clientCfg.ExecRef.ID, RN: clientCfg.ExecRef.RN.Next()}
+		poolMod.Locks = append(poolMod.Locks, clientCfg.ExecRef)
+		poolMod.Assets = append(poolMod.Assets, oldAsset, newAsset)
+		return poolMod, procexec.ExecMod{}, nil
+	case poolexp.AcceptSpec:
+		clientCfg, err := s.selectCfg(ctx, expSpec.PoolQN)
+		if err != nil {
+			return ExecMod{}, procexec.ExecMod{}, err
+		}
+		asset, ok := clientCfg.AssetRs[expSpec.ValPH]
+		if !ok {
+			return ExecMod{}, procexec.ExecMod{}, errMissingAsset(expSpec.ValPH)
+		}
+		_, ok = execCfg.CapRs[asset.SigID]
+		if !ok {
+			return ExecMod{}, procexec.ExecMod{}, errMissingCap(asset.SigID)
+		}
+		var providerCfg ExecCfg
+		err = s.operator.Implicit(ctx, func(ds db.Source) error {
+			liab, err := s.poolExecs.SelectLiab(ds, asset.ProcID)
+			if err != nil {
+				return err
+			}
+			providerCfg, err = s.poolExecs.SelectCfg(ds, liab.ExecRef)
+			return err
+		})
+		if err != nil {
+			return ExecMod{}, procexec.ExecMod{}, err
+		}
+		if providerCfg.ExecRef.ID == execCfg.ExecRef.ID {
+			return ExecMod{}, procexec.ExecMod{}, errDuplicateLiab(asset.ProcID)
+		}
+		// liability passes to the receiving pool
+		oldLiab := Liab{
+			ExecRef: ExecRef{ID: providerCfg.ExecRef.ID, RN: -providerCfg.ExecRef.RN.Next()},
+			ProcID:  asset.ProcID,
+		}
+		newLiab := Liab{ExecRef: nextRef, ProcID: asset.ProcID}
+		poolMod.Locks = append(poolMod.Locks, providerCfg.ExecRef)
+		poolMod.Liabs = append(poolMod.Liabs, oldLiab, newLiab)
+		return poolMod, procexec.ExecMod{}, nil
+	default:
+		return ExecMod{}, procexec.ExecMod{}, poolexp.ErrExpTypeUnexpected(expSpec)
+	}
+}
+
+// acquired processes are started through the run queue
+func startRuns(procMod procexec.ExecMod) []procexec.RunRec {
+	runs := make([]procexec.RunRec, 0, len(procMod.Wakes))
+	for _, wake := range procMod.Wakes {
+		runs = append(runs, procexec.RunRec{
+			RunID:     identity.New(),
+			TicketRef: identity.New(),
+			StepSpec:  wake,
+		})
+	}
+	return runs
+}
+
+func (s *service) selectSyn(ctx context.Context, procQN uniqsym.ADT) (synDR syndec.DecRec, err error) {
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		synDR, err = s.synDecs.SelectRecByQN(ds, procQN)
+		return err
+	})
+	if err != nil {
+		s.log.Error("selection failed", slog.Any("procQN", procQN))
+		return syndec.DecRec{}, err
+	}
+	return synDR, nil
+}
+
+func (s *service) selectCfg(ctx context.Context, poolQN uniqsym.ADT) (execCfg ExecCfg, err error) {
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		execRec, err := s.poolExecs.SelectRecByQN(ds, poolQN)
+		if err != nil {
+			return err
+		}
+		execCfg, err = s.poolExecs.SelectCfg(ds, execRec.ExecRef)
+		return err
+	})
+	if err != nil {
+		s.log.Error("selection failed", slog.Any("poolQN", poolQN))
+		return ExecCfg{}, err
+	}
+	return execCfg, nil
+}
+
+func (s *service) RetrieveSnap(ctx context.Context, ref ExecRef) (snap ExecSnap, err error) {
 	err = s.operator.Implicit(ctx, func(ds db.Source) error {
 		snap, err = s.poolExecs.SelectSubs(ds, ref)
 		return err
@@ -128,8 +515,7 @@ func (s *service) RetrieveSnap(ref ExecRef) (snap ExecSnap, err error) {
 	return snap, nil
 }
 
-func (s *service) RetreiveRefs() (refs []ExecRef, err error) {
-	ctx := context.Background()
+func (s *service) RetreiveRefs(ctx context.Context) (refs []ExecRef, err error) {
 	err = s.operator.Implicit(ctx, func(ds db.Source) error {
 		refs, err = s.poolExecs.SelectRefs(ds)
 		return err
@@ -140,3 +526,39 @@ func (s *service) RetreiveRefs() (refs []ExecRef, err error) {
 	}
 	return refs, nil
 }
+
+func errMissingCap(want identity.ADT) error {
+	return fmt.Errorf("cap missing in cfg: %v", want)
+}
+
+func errDuplicateCap(got identity.ADT) error {
+	return fmt.Errorf("cap duplicate in cfg: %v", got)
+}
+
+func errMissingDep(want identity.ADT) error {
+	return fmt.Errorf("dep missing in cfg: %v", want)
+}
+
+func errDuplicateDep(got identity.ADT) error {
+	return fmt.Errorf("dep duplicate in cfg: %v", got)
+}
+
+func errMissingAsset(want symbol.ADT) error {
+	return fmt.Errorf("asset missing in cfg: %v", want)
+}
+
+func errDuplicateAsset(got symbol.ADT) error {
+	return fmt.Errorf("asset duplicate in cfg: %v", got)
+}
+
+func errDuplicateLiab(got identity.ADT) error {
+	return fmt.Errorf("liab duplicate in cfg: %v", got)
+}
+
+func errParametricProvider(got uniqsym.ADT) error {
+	return fmt.Errorf("parametric provider unsupported: %v", got)
+}
+
+func errOptimisticUpdate(got revnum.ADT) error {
+	return fmt.Errorf("entity concurrent modification: got revision %v", got)
+}
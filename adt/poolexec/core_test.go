@@ -0,0 +1,277 @@
+package poolexec
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"orglang/go-runtime/lib/db"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/poolexp"
+	"orglang/go-runtime/adt/poolstep"
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/procdec"
+	"orglang/go-runtime/adt/procdef"
+	"orglang/go-runtime/adt/procexec"
+	"orglang/go-runtime/adt/procexp"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/syndec"
+	"orglang/go-runtime/adt/typedef"
+	"orglang/go-runtime/adt/uniqref"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+// operations run without storage
+type stubOperator struct{}
+
+func (stubOperator) Explicit(_ context.Context, op func(db.Source) error) error { return op(nil) }
+
+func (stubOperator) Implicit(_ context.Context, op func(db.Source) error) error { return op(nil) }
+
+type stubPools struct {
+	Repo
+	cfgs map[uniqsym.ADT]ExecCfg
+	mods []ExecMod
+}
+
+func (r *stubPools) SelectRecByQN(_ db.Source, poolQN uniqsym.ADT) (ExecRec, error) {
+	return ExecRec{ExecRef: r.cfgs[poolQN].ExecRef, PoolQN: poolQN}, nil
+}
+
+func (r *stubPools) SelectCfg(_ db.Source, ref ExecRef) (ExecCfg, error) {
+	for _, cfg := range r.cfgs {
+		if cfg.ExecRef == ref {
+			return cfg, nil
+		}
+	}
+	return ExecCfg{}, db.ErrNoRows
+}
+
+func (r *stubPools) UpdateCfg(_ db.Source, mod ExecMod) error {
+	r.mods = append(r.mods, mod)
+	return nil
+}
+
+type stubProcs struct {
+	procexec.Repo
+	mods []procexec.ExecMod
+	runs []procexec.RunRec
+}
+
+func (r *stubProcs) UpdateProc(_ db.Source, mod procexec.ExecMod) error {
+	r.mods = append(r.mods, mod)
+	return nil
+}
+
+func (r *stubProcs) InsertRuns(_ db.Source, runs ...procexec.RunRec) error {
+	r.runs = append(r.runs, runs...)
+	return nil
+}
+
+type stubSyns struct {
+	syndec.Repo
+	recs map[uniqsym.ADT]syndec.DecRec
+}
+
+func (r stubSyns) SelectRecByQN(_ db.Source, procQN uniqsym.ADT) (syndec.DecRec, error) {
+	rec, ok := r.recs[procQN]
+	if !ok {
+		return syndec.DecRec{}, syndec.ErrDecMissing
+	}
+	return rec, nil
+}
+
+type stubDecs struct {
+	procdec.Repo
+	recs map[identity.ADT]procdec.DecRec
+}
+
+func (r stubDecs) SelectEnv(db.Source, []identity.ADT) (map[identity.ADT]procdec.DecRec, error) {
+	return r.recs, nil
+}
+
+type stubDefs struct {
+	procdef.Repo
+	recs map[identity.ADT]procdef.DefRec
+}
+
+func (r stubDefs) SelectEnv(db.Source, []identity.ADT) (map[identity.ADT]procdef.DefRec, error) {
+	return r.recs, nil
+}
+
+type stubTypes struct {
+	typedef.Repo
+	recs map[uniqsym.ADT]typedef.DefRec
+}
+
+func (r stubTypes) SelectEnv(db.Source, []uniqsym.ADT) (map[uniqsym.ADT]typedef.DefRec, error) {
+	return r.recs, nil
+}
+
+// client and provider pools around one closer signature
+type fixture struct {
+	svc      *service
+	pools    *stubPools
+	procs    *stubProcs
+	clientQN uniqsym.ADT
+	client   ExecRef
+	sigID    identity.ADT
+	body     procexp.ExpSpec
+}
+
+type fixtureOpt func(*fixture, *procdec.DecRec, map[identity.ADT]procdef.DefRec)
+
+func newFixture(opts ...fixtureOpt) *fixture {
+	oneQN := uniqsym.New("one")
+	closerQN := uniqsym.New("closer")
+	sigID := identity.New()
+	client := uniqref.New()
+	provider := uniqref.New()
+	f := &fixture{
+		clientQN: uniqsym.New("client"),
+		client:   client,
+		sigID:    sigID,
+		body:     procexp.CloseSpec{CommChnlPH: "z"},
+		procs:    &stubProcs{},
+	}
+	f.pools = &stubPools{cfgs: map[uniqsym.ADT]ExecCfg{
+		f.clientQN: {
+			ExecRef: client,
+			DepRs:   map[identity.ADT]DepRec{sigID: {ExecRef: client, SigID: sigID}},
+			AssetRs: map[symbol.ADT]AssetRec{},
+		},
+		uniqsym.New("provider"): {
+			ExecRef: provider,
+			CapRs:   map[identity.ADT]CapRec{sigID: {ExecRef: provider, SigID: sigID}},
+		},
+	}}
+	procDR := procdec.DecRec{
+		DecRef:     procdec.DecRef{ID: sigID, RN: revnum.New()},
+		ProviderBS: procbind.BindSpec{ChnlPH: "z", TypeQN: oneQN},
+	}
+	procDefs := map[identity.ADT]procdef.DefRec{
+		sigID: {DefRef: procdef.DefRef{ID: sigID, RN: revnum.New()}, ProcES: f.body},
+	}
+	for _, opt := range opts {
+		opt(f, &procDR, procDefs)
+	}
+	f.svc = &service{
+		poolExecs: f.pools,
+		procExecs: f.procs,
+		procDecs:  stubDecs{recs: map[identity.ADT]procdec.DecRec{sigID: procDR}},
+		procDefs:  stubDefs{recs: procDefs},
+		synDecs: stubSyns{recs: map[uniqsym.ADT]syndec.DecRec{
+			closerQN: {DecID: sigID, DecRN: revnum.New(), DecQN: closerQN},
+		}},
+		typeDefs: stubTypes{recs: map[uniqsym.ADT]typedef.DefRec{
+			oneQN: {ExpID: identity.New()},
+		}},
+		operator: stubOperator{},
+		log:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	return f
+}
+
+func (f *fixture) step(es poolexp.ExpSpec) poolstep.StepSpec {
+	return poolstep.StepSpec{ExecRef: f.client, ProcQN: uniqsym.New("closer"), ProcES: es}
+}
+
+func TestTakeAcquire(t *testing.T) {
+	acquire := poolexp.AcquireSpec{PoolQN: uniqsym.New("provider"), BindPH: "x"}
+	tests := []struct {
+		name string
+		opts []fixtureOpt
+		err  bool
+	}{
+		{"acquired body starts", nil, false},
+		{"no dep", []fixtureOpt{func(f *fixture, _ *procdec.DecRec, _ map[identity.ADT]procdef.DefRec) {
+			clear(f.pools.cfgs[f.clientQN].DepRs)
+		}}, true},
+		{"no cap", []fixtureOpt{func(f *fixture, _ *procdec.DecRec, _ map[identity.ADT]procdef.DefRec) {
+			clear(f.pools.cfgs[uniqsym.New("provider")].CapRs)
+		}}, true},
+		{"asset held", []fixtureOpt{func(f *fixture, _ *procdec.DecRec, _ map[identity.ADT]procdef.DefRec) {
+			f.pools.cfgs[f.clientQN].AssetRs["x"] = AssetRec{ExecRef: f.client, ChnlPH: "x"}
+		}}, true},
+		{"provider takes clients", []fixtureOpt{func(_ *fixture, procDR *procdec.DecRec, _ map[identity.ADT]procdef.DefRec) {
+			procDR.ClientBSs = []procbind.BindSpec{{ChnlPH: "y"}}
+		}}, true},
+		{"no definition", []fixtureOpt{func(f *fixture, _ *procdec.DecRec, procDefs map[identity.ADT]procdef.DefRec) {
+			delete(procDefs, f.sigID)
+		}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newFixture(test.opts...)
+			err := f.svc.Take(context.Background(), f.step(acquire))
+			if test.err {
+				if err == nil {
+					t.Fatalf("got no error, want one")
+				}
+				if len(f.procs.mods) != 0 || len(f.procs.runs) != 0 || len(f.pools.mods) != 0 {
+					t.Errorf("got mods stored on rejection")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if len(f.procs.mods) != 1 || len(f.procs.mods[0].Execs) != 1 {
+				t.Fatalf("got %v proc mods, want 1 with 1 exec", len(f.procs.mods))
+			}
+			newRef := f.procs.mods[0].Execs[0].ExecRef
+			if len(f.procs.runs) != 1 {
+				t.Fatalf("got %v runs, want 1", len(f.procs.runs))
+			}
+			run := f.procs.runs[0].StepSpec
+			if run.ExecRef != newRef || run.ProcES != f.body {
+				t.Errorf("got run %+v, want body %+v of %v", run, f.body, newRef)
+			}
+			assets := f.pools.mods[0].Assets
+			if len(assets) != 1 || assets[0].ProcID != newRef.ID || assets[0].ChnlPH != "x" {
+				t.Errorf("got assets %+v, want x held on %v", assets, newRef.ID)
+			}
+		})
+	}
+}
+
+func TestTakeRelease(t *testing.T) {
+	tests := []struct {
+		name string
+		held bool
+		err  bool
+	}{
+		{"held asset", true, false},
+		{"no asset", false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newFixture()
+			procID := identity.New()
+			if test.held {
+				f.pools.cfgs[f.clientQN].AssetRs["x"] = AssetRec{ExecRef: f.client, ChnlPH: "x", ProcID: procID, SigID: f.sigID}
+			}
+			err := f.svc.Take(context.Background(), f.step(poolexp.ReleaseSpec{BindPH: "x"}))
+			if test.err {
+				if err == nil {
+					t.Fatalf("got no error, want one")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			assets := f.pools.mods[0].Assets
+			// negative revision removes the asset
+			if len(assets) != 1 || assets[0].ProcID != procID || assets[0].ExecRef.RN >= 0 {
+				t.Errorf("got assets %+v, want removal of %v", assets, procID)
+			}
+			if len(f.procs.mods) != 0 {
+				t.Errorf("got %v proc mods, want none", len(f.procs.mods))
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package poolexec
+
+import (
+	"time"
+
+	"orglang/go-runtime/lib/kv"
+)
+
+func newPollingCS(loader kv.Loader) (pollingCS, error) {
+	dto := &pollingCS{}
+	loadingErr := loader.Load("scheduling", dto)
+	if loadingErr != nil {
+		return pollingCS{}, loadingErr
+	}
+	validationErr := dto.Validate()
+	if validationErr != nil {
+		return pollingCS{}, validationErr
+	}
+	return *dto, nil
+}
+
+type pollingCS struct {
+	// claim duration of a ready execution
+	Lease time.Duration `mapstructure:"lease"`
+}
@@ -10,8 +10,9 @@ var Module = fx.Module("adt/poolexec",
 	),
 	fx.Provide(
 		fx.Private,
+		newPollingCS,
 		newEchoController,
-		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+		newDAO,
 	),
 	fx.Invoke(
 		cfgEchoController,
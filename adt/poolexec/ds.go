@@ -2,8 +2,14 @@ package poolexec
 
 import (
 	"database/sql"
+	"log/slog"
+	"time"
 
 	"orglang/go-runtime/lib/db"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procexec"
+	"orglang/go-runtime/adt/uniqsym"
 )
 
 // Port
@@ -12,6 +18,23 @@ type Repo interface {
 	InsertLiab(db.Source, Liab) error
 	SelectRefs(db.Source) ([]ExecRef, error)
 	SelectSubs(db.Source, ExecRef) (ExecSnap, error)
+	SelectRecByQN(db.Source, uniqsym.ADT) (ExecRec, error)
+	SelectCfg(db.Source, ExecRef) (ExecCfg, error)
+	SelectLiab(db.Source, identity.ADT) (Liab, error)
+	SelectReady(db.Source, identity.ADT, time.Duration) (procexec.ExecRef, error)
+	UpdateCfg(db.Source, ExecMod) error
+}
+
+// storage adapter is chosen by the operator
+func newDAO(l *slog.Logger, o db.Operator) Repo {
+	switch o.(type) {
+	case *db.OperatorMem:
+		return newMemDAO(l)
+	case *db.OperatorSqlite:
+		return newSqliteDAO(l)
+	default:
+		return newPgxDAO(l)
+	}
 }
 
 type execRefDS = struct {
@@ -27,9 +50,18 @@ type execSnapDS struct {
 }
 
 type execRecDS struct {
-	ID    string         `db:"exec_id"`
-	RN    int64          `db:"exec_rn"`
-	SupID sql.NullString `db:"sup_exec_id"`
+	ID     string         `db:"exec_id"`
+	RN     int64          `db:"exec_rn"`
+	PoolQN string         `db:"pool_qn"`
+	SupID  sql.NullString `db:"sup_exec_id"`
+}
+
+type execModDS struct {
+	Locks  []execRefDS
+	Caps   []capRecDS
+	Deps   []depRecDS
+	Liabs  []liabDS
+	Assets []assetRecDS
 }
 
 type liabDS struct {
@@ -37,3 +69,24 @@ type liabDS struct {
 	RN     int64  `db:"exec_rn"`
 	ProcID string `db:"proc_id"`
 }
+
+type capRecDS struct {
+	ID    string `db:"exec_id"`
+	RN    int64  `db:"exec_rn"`
+	SigID string `db:"sig_id"`
+}
+
+type depRecDS struct {
+	ID    string `db:"exec_id"`
+	RN    int64  `db:"exec_rn"`
+	SigID string `db:"sig_id"`
+}
+
+type assetRecDS struct {
+	ID     string `db:"exec_id"`
+	RN     int64  `db:"exec_rn"`
+	ChnlPH string `db:"chnl_ph"`
+	ChnlID string `db:"chnl_id"`
+	ProcID string `db:"proc_id"`
+	SigID  string `db:"sig_id"`
+}
@@ -0,0 +1,289 @@
+package poolexec
+
+import (
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"orglang/go-runtime/lib/db"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/procexec"
+	"orglang/go-runtime/adt/procstep"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/uniqref"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(log *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{log.With(name)}
+}
+
+func (dao *memDAO) InsertRec(source db.Source, rec ExecRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	db.InsertMem(ds, poolExecs, DataFromExecRec(rec))
+	dao.log.Debug("insertion succeed", slog.Any("execRef", rec.ExecRef))
+	return nil
+}
+
+func (dao *memDAO) InsertLiab(source db.Source, liab Liab) error {
+	ds := db.MustConform[db.SourceMem](source)
+	db.InsertMem(ds, poolLiabs, DataFromLiab(liab))
+	dao.log.Debug("insertion succeed", slog.Any("execRef", liab.ExecRef))
+	return nil
+}
+
+func (dao *memDAO) SelectSubs(source db.Source, ref ExecRef) (ExecSnap, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	idAttr := slog.Any("execRef", ref)
+	sup, err := selectExec(ds, ref.ID.String())
+	if err != nil {
+		dao.log.Error("selection failed", idAttr)
+		return ExecSnap{}, err
+	}
+	dto := execSnapDS{ID: sup.ID, RN: sup.RN, Title: sup.PoolQN}
+	for _, sub := range db.SelectMem[execRecDS](ds, poolExecs) {
+		if sub.SupID.Valid && sub.SupID.String == sup.ID {
+			dto.SubExecs = append(dto.SubExecs, execRefDS{ID: sub.ID, RN: sub.RN})
+		}
+	}
+	snap, err := DataToExecSnap(dto)
+	if err != nil {
+		dao.log.Error("conversion failed")
+		return ExecSnap{}, err
+	}
+	dao.log.Debug("selection succeed", idAttr)
+	return snap, nil
+}
+
+func (dao *memDAO) SelectRefs(source db.Source) ([]ExecRef, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	dtos := []execRefDS{}
+	for _, dto := range db.SelectMem[execRecDS](ds, poolExecs) {
+		dtos = append(dtos, execRefDS{ID: dto.ID, RN: dto.RN})
+	}
+	refs, err := DataToExecRefs(dtos)
+	if err != nil {
+		dao.log.Error("conversion failed")
+		return nil, err
+	}
+	return refs, nil
+}
+
+func (dao *memDAO) SelectRecByQN(source db.Source, poolQN uniqsym.ADT) (ExecRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	qnAttr := slog.Any("poolQN", poolQN)
+	qn := uniqsym.ConvertToString(poolQN)
+	i := slices.IndexFunc(db.SelectMem[execRecDS](ds, poolExecs), func(dto execRecDS) bool {
+		return dto.PoolQN == qn
+	})
+	if i < 0 {
+		dao.log.Error("selection failed", qnAttr)
+		return ExecRec{}, db.ErrNoRows
+	}
+	rec, err := DataToExecRec(db.SelectMem[execRecDS](ds, poolExecs)[i])
+	if err != nil {
+		dao.log.Error("conversion failed", qnAttr)
+		return ExecRec{}, err
+	}
+	dao.log.Debug("selection succeed", qnAttr)
+	return rec, nil
+}
+
+// revoked records carry a negative revision
+func (dao *memDAO) SelectCfg(source db.Source, execRef ExecRef) (ExecCfg, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("execRef", execRef)
+	execID := execRef.ID.String()
+	execDto, err := selectExec(ds, execID)
+	if err != nil {
+		dao.log.Error("selection failed", refAttr)
+		return ExecCfg{}, err
+	}
+	ref, err := DataToExecRef(execRefDS{ID: execDto.ID, RN: execDto.RN})
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecCfg{}, err
+	}
+	capDtos := selectLive(ds, poolCaps,
+		func(dto capRecDS) bool { return dto.ID == execID },
+		func(dto capRecDS) string { return dto.SigID },
+		func(dto capRecDS) int64 { return dto.RN },
+	)
+	caps, err := DataToCapRecs(capDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecCfg{}, err
+	}
+	depDtos := selectLive(ds, poolDeps,
+		func(dto depRecDS) bool { return dto.ID == execID },
+		func(dto depRecDS) string { return dto.SigID },
+		func(dto depRecDS) int64 { return dto.RN },
+	)
+	deps, err := DataToDepRecs(depDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecCfg{}, err
+	}
+	assetDtos := selectLive(ds, poolAssets,
+		func(dto assetRecDS) bool { return dto.ID == execID },
+		func(dto assetRecDS) string { return dto.ChnlPH },
+		func(dto assetRecDS) int64 { return dto.RN },
+	)
+	assets, err := DataToAssetRecs(assetDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecCfg{}, err
+	}
+	dao.log.Debug("selection succeed", refAttr)
+	return ExecCfg{
+		ExecRef: ref,
+		CapRs:   procbind.IndexBy(CapSig, caps),
+		DepRs:   procbind.IndexBy(DepSig, deps),
+		AssetRs: procbind.IndexBy(ChnlPH, assets),
+	}, nil
+}
+
+// revisions are comparable only within one pool
+func (dao *memDAO) SelectLiab(source db.Source, procID identity.ADT) (Liab, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	idAttr := slog.Any("procID", procID)
+	dtos := selectLive(ds, poolLiabs,
+		func(dto liabDS) bool { return dto.ProcID == procID.String() },
+		func(dto liabDS) string { return dto.ID },
+		func(dto liabDS) int64 { return dto.RN },
+	)
+	if len(dtos) == 0 {
+		dao.log.Error("selection failed", idAttr)
+		return Liab{}, db.ErrNoRows
+	}
+	liab, err := DataToLiab(dtos[0])
+	if err != nil {
+		dao.log.Error("conversion failed", idAttr)
+		return Liab{}, err
+	}
+	dao.log.Debug("selection succeed", idAttr)
+	return liab, nil
+}
+
+// a process is ready if it has made no steps yet
+// or a counterpart is waiting for it
+func (dao *memDAO) SelectReady(source db.Source, poolID identity.ADT, lease time.Duration) (procexec.ExecRef, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	idAttr := slog.Any("poolID", poolID)
+	now := time.Now()
+	claims := db.SelectMem[claimRowMem](ds, poolClaims)
+	liabs := selectLive(ds, poolLiabs,
+		func(dto liabDS) bool { return dto.ID == poolID.String() },
+		func(dto liabDS) string { return dto.ProcID },
+		func(dto liabDS) int64 { return dto.RN },
+	)
+	binds := db.LatestMem(db.SelectMem[procbind.BindRecDS](ds, procbind.BindsMem),
+		func(dto procbind.BindRecDS) [2]string { return [2]string{dto.ID, dto.ChnlPH} },
+		func(dto procbind.BindRecDS) int64 { return dto.RN },
+	)
+	steps := db.SelectMem[procstep.StepRecDS](ds, procstep.StepsMem)
+	initRN := revnum.ConvertToInt(revnum.New())
+	ready := []uniqref.Data{}
+	for _, exec := range db.SelectMem[uniqref.Data](ds, procbind.ExecsMem) {
+		if !slices.ContainsFunc(liabs, func(liab liabDS) bool { return liab.ProcID == exec.ID }) {
+			continue
+		}
+		awaited := slices.ContainsFunc(binds, func(bind procbind.BindRecDS) bool {
+			return bind.ID == exec.ID && bind.RN > 0 && slices.ContainsFunc(steps, func(step procstep.StepRecDS) bool {
+				return step.ChnlID.String == bind.ChnlID && step.ExecID.String != exec.ID
+			})
+		})
+		claimed := slices.ContainsFunc(claims, func(claim claimRowMem) bool {
+			return claim.ProcID == exec.ID && claim.ClaimedAt.After(now.Add(-lease))
+		})
+		if (exec.RN == initRN || awaited) && !claimed {
+			ready = append(ready, exec)
+		}
+	}
+	if len(ready) == 0 {
+		dao.log.Debug("selection succeed", idAttr)
+		return procexec.ExecRef{}, nil
+	}
+	dto := slices.MinFunc(ready, func(a, b uniqref.Data) int { return strings.Compare(a.ID, b.ID) })
+	renewed := db.UpdateMem(ds, poolClaims,
+		func(claim claimRowMem) bool { return claim.ProcID == dto.ID },
+		func(claim claimRowMem) claimRowMem {
+			claim.ClaimedAt = now
+			return claim
+		},
+	)
+	if renewed == 0 {
+		db.InsertMem(ds, poolClaims, claimRowMem{ProcID: dto.ID, ClaimedAt: now})
+	}
+	ref, err := DataToExecRef(execRefDS{ID: dto.ID, RN: dto.RN})
+	if err != nil {
+		dao.log.Error("conversion failed", idAttr)
+		return procexec.ExecRef{}, err
+	}
+	dao.log.Debug("selection succeed", idAttr)
+	return ref, nil
+}
+
+func (dao *memDAO) UpdateCfg(source db.Source, mod ExecMod) error {
+	if len(mod.Locks) == 0 {
+		panic("empty locks")
+	}
+	ds := db.MustConform[db.SourceMem](source)
+	dto := DataFromMod(mod)
+	db.InsertMem(ds, poolCaps, dto.Caps...)
+	db.InsertMem(ds, poolDeps, dto.Deps...)
+	db.InsertMem(ds, poolLiabs, dto.Liabs...)
+	db.InsertMem(ds, poolAssets, dto.Assets...)
+	for _, lock := range dto.Locks {
+		n := db.UpdateMem(ds, poolExecs,
+			func(dto execRecDS) bool { return dto.ID == lock.ID && dto.RN == lock.RN },
+			func(dto execRecDS) execRecDS {
+				dto.RN++
+				return dto
+			},
+		)
+		if n == 0 {
+			dao.log.Error("update failed")
+			return errOptimisticUpdate(revnum.ADT(lock.RN))
+		}
+	}
+	dao.log.Debug("update succeed")
+	return nil
+}
+
+func selectExec(ds db.SourceMem, execID string) (execRecDS, error) {
+	return db.SelectLatestMem(ds, poolExecs,
+		func(dto execRecDS) bool { return dto.ID == execID },
+		func(dto execRecDS) int64 { return dto.RN },
+	)
+}
+
+// latest records by key, without revoked ones
+func selectLive[R any](ds db.SourceMem, table string, match func(R) bool, key func(R) string, rn func(R) int64) []R {
+	rows := slices.DeleteFunc(slices.Clone(db.SelectMem[R](ds, table)), func(row R) bool { return !match(row) })
+	return slices.DeleteFunc(db.LatestMem(rows, key, rn), func(row R) bool { return rn(row) <= 0 })
+}
+
+// execution claim along with its deadline
+type claimRowMem struct {
+	ProcID    string
+	ClaimedAt time.Time
+}
+
+const (
+	poolExecs  = "pool_execs"
+	poolLiabs  = "pool_liabs"
+	poolCaps   = "pool_caps"
+	poolDeps   = "pool_deps"
+	poolAssets = "pool_assets"
+	poolClaims = "pool_claims"
+)
@@ -1,12 +1,20 @@
 package poolexec
 
 import (
+	"errors"
 	"log/slog"
 	"reflect"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 
 	"orglang/go-runtime/lib/db"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/procexec"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/uniqsym"
 )
 
 type pgxDAO struct {
@@ -29,6 +37,7 @@ func (dao *pgxDAO) InsertRec(source db.Source, rec ExecRec) (err error) {
 	args := pgx.NamedArgs{
 		"exec_id":     dto.ID,
 		"exec_rn":     dto.RN,
+		"pool_qn":     dto.PoolQN,
 		"sup_exec_id": dto.SupID,
 	}
 	_, err = ds.Conn.Exec(ds.Ctx, insertExec, args)
@@ -44,9 +53,9 @@ func (dao *pgxDAO) InsertLiab(source db.Source, liab Liab) (err error) {
 	ds := db.MustConform[db.SourcePgx](source)
 	dto := DataFromLiab(liab)
 	args := pgx.NamedArgs{
-		"exec_id": dto.ID,
-		"exec_rn": dto.RN,
+		"pool_id": dto.ID,
 		"proc_id": dto.ProcID,
+		"rev":     dto.RN,
 	}
 	_, err = ds.Conn.Exec(ds.Ctx, insertLiab, args)
 	if err != nil {
@@ -84,7 +93,7 @@ func (dao *pgxDAO) SelectRefs(source db.Source) ([]ExecRef, error) {
 	ds := db.MustConform[db.SourcePgx](source)
 	query := `
 		select
-			exec_id, title
+			exec_id, exec_rn
 		from pool_execs`
 	rows, err := ds.Conn.Query(ds.Ctx, query)
 	if err != nil {
@@ -105,12 +114,305 @@ func (dao *pgxDAO) SelectRefs(source db.Source) ([]ExecRef, error) {
 	return refs, nil
 }
 
+func (dao *pgxDAO) SelectRecByQN(source db.Source, poolQN uniqsym.ADT) (ExecRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	qnAttr := slog.Any("poolQN", poolQN)
+	rows, err := ds.Conn.Query(ds.Ctx, selectRecByQN, uniqsym.ConvertToString(poolQN))
+	if err != nil {
+		dao.log.Error("execution failed", qnAttr)
+		return ExecRec{}, err
+	}
+	defer rows.Close()
+	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[execRecDS])
+	if err != nil {
+		dao.log.Error("collection failed", qnAttr, slog.Any("t", reflect.TypeOf(dto)))
+		return ExecRec{}, err
+	}
+	rec, err := DataToExecRec(dto)
+	if err != nil {
+		dao.log.Error("conversion failed", qnAttr)
+		return ExecRec{}, err
+	}
+	dao.log.Debug("selection succeed", qnAttr)
+	return rec, nil
+}
+
+func (dao *pgxDAO) SelectCfg(source db.Source, execRef ExecRef) (ExecCfg, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("execRef", execRef)
+	execRows, err := ds.Conn.Query(ds.Ctx, selectRef, execRef.ID.String())
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecCfg{}, err
+	}
+	defer execRows.Close()
+	execDto, err := pgx.CollectExactlyOneRow(execRows, pgx.RowToStructByName[execRefDS])
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(execDto)))
+		return ExecCfg{}, err
+	}
+	ref, err := DataToExecRef(execDto)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecCfg{}, err
+	}
+	capRows, err := ds.Conn.Query(ds.Ctx, selectCaps, execRef.ID.String())
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecCfg{}, err
+	}
+	defer capRows.Close()
+	capDtos, err := pgx.CollectRows(capRows, pgx.RowToStructByName[capRecDS])
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(capDtos)))
+		return ExecCfg{}, err
+	}
+	caps, err := DataToCapRecs(capDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecCfg{}, err
+	}
+	depRows, err := ds.Conn.Query(ds.Ctx, selectDeps, execRef.ID.String())
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecCfg{}, err
+	}
+	defer depRows.Close()
+	depDtos, err := pgx.CollectRows(depRows, pgx.RowToStructByName[depRecDS])
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(depDtos)))
+		return ExecCfg{}, err
+	}
+	deps, err := DataToDepRecs(depDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecCfg{}, err
+	}
+	assetRows, err := ds.Conn.Query(ds.Ctx, selectAssets, execRef.ID.String())
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecCfg{}, err
+	}
+	defer assetRows.Close()
+	assetDtos, err := pgx.CollectRows(assetRows, pgx.RowToStructByName[assetRecDS])
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(assetDtos)))
+		return ExecCfg{}, err
+	}
+	assets, err := DataToAssetRecs(assetDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecCfg{}, err
+	}
+	dao.log.Debug("selection succeed", refAttr)
+	return ExecCfg{
+		ExecRef: ref,
+		CapRs:   procbind.IndexBy(CapSig, caps),
+		DepRs:   procbind.IndexBy(DepSig, deps),
+		AssetRs: procbind.IndexBy(ChnlPH, assets),
+	}, nil
+}
+
+func (dao *pgxDAO) SelectLiab(source db.Source, procID identity.ADT) (Liab, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("procID", procID)
+	rows, err := ds.Conn.Query(ds.Ctx, selectLiab, procID.String())
+	if err != nil {
+		dao.log.Error("execution failed", idAttr)
+		return Liab{}, err
+	}
+	defer rows.Close()
+	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[liabDS])
+	if err != nil {
+		dao.log.Error("collection failed", idAttr, slog.Any("t", reflect.TypeOf(dto)))
+		return Liab{}, err
+	}
+	liab, err := DataToLiab(dto)
+	if err != nil {
+		dao.log.Error("conversion failed", idAttr)
+		return Liab{}, err
+	}
+	dao.log.Debug("selection succeed", idAttr)
+	return liab, nil
+}
+
+func (dao *pgxDAO) SelectReady(source db.Source, poolID identity.ADT, lease time.Duration) (procexec.ExecRef, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("poolID", poolID)
+	args := pgx.NamedArgs{
+		"pool_id": poolID.String(),
+		"init_rn": revnum.ConvertToInt(revnum.New()),
+		"lease":   lease.Seconds(),
+	}
+	rows, err := ds.Conn.Query(ds.Ctx, selectReady, args)
+	if err != nil {
+		dao.log.Error("execution failed", idAttr)
+		return procexec.ExecRef{}, err
+	}
+	defer rows.Close()
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[execRefDS])
+	if err != nil {
+		dao.log.Error("collection failed", idAttr, slog.Any("t", reflect.TypeOf(dtos)))
+		return procexec.ExecRef{}, err
+	}
+	if len(dtos) == 0 {
+		dao.log.Debug("selection succeed", idAttr)
+		return procexec.ExecRef{}, nil
+	}
+	ref, err := DataToExecRef(dtos[0])
+	if err != nil {
+		dao.log.Error("conversion failed", idAttr)
+		return procexec.ExecRef{}, err
+	}
+	dao.log.Debug("selection succeed", idAttr, slog.Any("procRef", ref))
+	return ref, nil
+}
+
+func (dao *pgxDAO) UpdateCfg(source db.Source, mod ExecMod) (err error) {
+	if len(mod.Locks) == 0 {
+		panic("empty locks")
+	}
+	ds := db.MustConform[db.SourcePgx](source)
+	dto := DataFromMod(mod)
+	// caps
+	capReq := pgx.Batch{}
+	for _, dto := range dto.Caps {
+		args := pgx.NamedArgs{
+			"pool_id": dto.ID,
+			"sig_id":  dto.SigID,
+			"rev":     dto.RN,
+		}
+		capReq.Queue(insertCap, args)
+	}
+	if capReq.Len() > 0 {
+		capRes := ds.Conn.SendBatch(ds.Ctx, &capReq)
+		defer func() {
+			err = errors.Join(err, capRes.Close())
+		}()
+		for _, dto := range dto.Caps {
+			_, err = capRes.Exec()
+			if err != nil {
+				dao.log.Error("execution failed", slog.Any("dto", dto))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// deps
+	depReq := pgx.Batch{}
+	for _, dto := range dto.Deps {
+		args := pgx.NamedArgs{
+			"pool_id": dto.ID,
+			"sig_id":  dto.SigID,
+			"rev":     dto.RN,
+		}
+		depReq.Queue(insertDep, args)
+	}
+	if depReq.Len() > 0 {
+		depRes := ds.Conn.SendBatch(ds.Ctx, &depReq)
+		defer func() {
+			err = errors.Join(err, depRes.Close())
+		}()
+		for _, dto := range dto.Deps {
+			_, err = depRes.Exec()
+			if err != nil {
+				dao.log.Error("execution failed", slog.Any("dto", dto))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// liabs
+	liabReq := pgx.Batch{}
+	for _, dto := range dto.Liabs {
+		args := pgx.NamedArgs{
+			"pool_id": dto.ID,
+			"proc_id": dto.ProcID,
+			"rev":     dto.RN,
+		}
+		liabReq.Queue(insertLiab, args)
+	}
+	if liabReq.Len() > 0 {
+		liabRes := ds.Conn.SendBatch(ds.Ctx, &liabReq)
+		defer func() {
+			err = errors.Join(err, liabRes.Close())
+		}()
+		for _, dto := range dto.Liabs {
+			_, err = liabRes.Exec()
+			if err != nil {
+				dao.log.Error("execution failed", slog.Any("dto", dto))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// assets
+	assetReq := pgx.Batch{}
+	for _, dto := range dto.Assets {
+		args := pgx.NamedArgs{
+			"pool_id": dto.ID,
+			"chnl_ph": dto.ChnlPH,
+			"chnl_id": dto.ChnlID,
+			"proc_id": dto.ProcID,
+			"sig_id":  dto.SigID,
+			"rev":     dto.RN,
+		}
+		assetReq.Queue(insertAsset, args)
+	}
+	if assetReq.Len() > 0 {
+		assetRes := ds.Conn.SendBatch(ds.Ctx, &assetReq)
+		defer func() {
+			err = errors.Join(err, assetRes.Close())
+		}()
+		for _, dto := range dto.Assets {
+			_, err = assetRes.Exec()
+			if err != nil {
+				dao.log.Error("execution failed", slog.Any("dto", dto))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// execs
+	execReq := pgx.Batch{}
+	for _, dto := range dto.Locks {
+		args := pgx.NamedArgs{
+			"exec_id": dto.ID,
+			"exec_rn": dto.RN,
+		}
+		execReq.Queue(updateExec, args)
+	}
+	execRes := ds.Conn.SendBatch(ds.Ctx, &execReq)
+	defer func() {
+		err = errors.Join(err, execRes.Close())
+	}()
+	for _, dto := range dto.Locks {
+		ct, err := execRes.Exec()
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+		}
+		if ct.RowsAffected() == 0 {
+			dao.log.Error("update failed")
+			return errOptimisticUpdate(revnum.ADT(dto.RN))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	dao.log.Debug("update succeed")
+	return nil
+}
+
 const (
 	insertExec = `
 		insert into pool_execs (
-			pool_id, title, proc_id, sup_pool_id, rev
+			exec_id, exec_rn, pool_qn, sup_exec_id
 		) values (
-			@pool_id, @title, @proc_id, @sup_pool_id, @rev
+			@exec_id, @exec_rn, @pool_qn, @sup_exec_id
 		)`
 
 	insertLiab = `
@@ -120,62 +422,170 @@ const (
 			@pool_id, @proc_id, @rev
 		)`
 
-	insertBind = `
-		insert into pool_assets (
-			pool_id, chnl_key, state_id, ex_pool_id, rev
+	insertCap = `
+		insert into pool_caps (
+			pool_id, sig_id, rev
 		) values (
-			@pool_id, @chnl_key, @state_id, @ex_pool_id, @rev
+			@pool_id, @sig_id, @rev
 		)`
 
-	insertStep = `
-		insert into pool_steps (
-			proc_id, chnl_id, kind, spec
+	insertDep = `
+		insert into pool_deps (
+			pool_id, sig_id, rev
 		) values (
-			@proc_id, @chnl_id, @kind, @spec
+			@pool_id, @sig_id, @rev
 		)`
 
-	updateRoot = `
-		update pool_roots
-		set rev = @rev + 1
-		where pool_id = @pool_id
-			and rev = @rev`
+	insertAsset = `
+		insert into pool_assets (
+			pool_id, chnl_ph, chnl_id, proc_id, sig_id, rev
+		) values (
+			@pool_id, @chnl_ph, @chnl_id, @proc_id, @sig_id, @rev
+		)`
 
-	selectOrgSnap = `
+	updateExec = `
+		update pool_execs
+		set exec_rn = @exec_rn + 1
+		where exec_id = @exec_id
+			and exec_rn = @exec_rn`
+
+	selectRef = `
+		select
+			exec_id, exec_rn
+		from pool_execs
+		where exec_id = $1`
+
+	selectRecByQN = `
 		select
-			sup.pool_id,
-			sup.title,
-			jsonb_agg(json_build_object('pool_id', sub.pool_id, 'title', sub.title)) as subs
-		from pool_roots sup
-		left join pool_sups rel
-			on rel.sup_pool_id = sup.pool_id
-		left join pool_roots sub
-			on sub.pool_id = rel.pool_id
-			and sub.rev = rel.rev
-		where sup.pool_id = $1
-		group by sup.pool_id, sup.title`
-
-	selectChnls = `
-		with bnds as not materialized (
+			exec_id, exec_rn, pool_qn::text, sup_exec_id
+		from pool_execs
+		where pool_qn = $1
+		limit 1`
+
+	// revoked records carry a negative revision
+	selectCaps = `
+		select
+			*
+		from (
+			select distinct on (sig_id)
+				pool_id as exec_id, rev as exec_rn, sig_id
+			from pool_caps
+			where pool_id = $1
+			order by sig_id, abs(rev) desc
+		) cap
+		where cap.exec_rn > 0`
+
+	selectDeps = `
+		select
+			*
+		from (
+			select distinct on (sig_id)
+				pool_id as exec_id, rev as exec_rn, sig_id
+			from pool_deps
+			where pool_id = $1
+			order by sig_id, abs(rev) desc
+		) dep
+		where dep.exec_rn > 0`
+
+	selectAssets = `
+		select
+			*
+		from (
 			select distinct on (chnl_ph)
-				*
-			from proc_bnds
-			where proc_id = 'proc1'
+				pool_id as exec_id, rev as exec_rn, chnl_ph, chnl_id, proc_id, sig_id
+			from pool_assets
+			where pool_id = $1
 			order by chnl_ph, abs(rev) desc
-		), liabs as not materialized (
+		) asset
+		where asset.exec_rn > 0`
+
+	// revisions are comparable only within one pool
+	selectLiab = `
+		select
+			*
+		from (
+			select distinct on (pool_id)
+				pool_id as exec_id, rev as exec_rn, proc_id
+			from pool_liabs
+			where proc_id = $1
+			order by pool_id, abs(rev) desc
+		) liab
+		where liab.exec_rn > 0`
+
+	// a process is ready if it has made no steps yet
+	// or a counterpart is waiting for it
+	// an execution is handed to one worker for the claim duration:
+	// skip locked holds the execution row, and an expired claim
+	// is overwritten only after a recheck
+	selectReady = `
+		with liabs as not materialized (
 			select distinct on (proc_id)
-				*
+				proc_id, rev
 			from pool_liabs
-			where proc_id = 'proc1'
+			where pool_id = @pool_id
 			order by proc_id, abs(rev) desc
+		), binds as not materialized (
+			select distinct on (bnd.exec_id, bnd.chnl_ph)
+				bnd.exec_id, bnd.exec_rn, bnd.chnl_id
+			from proc_binds bnd
+			join liabs liab
+				on liab.proc_id = bnd.exec_id
+			where liab.rev > 0
+			order by bnd.exec_id, bnd.chnl_ph, abs(bnd.exec_rn) desc
+		), ready as (
+			select
+				exe.exec_id, exe.exec_rn
+			from proc_execs exe
+			join liabs liab
+				on liab.proc_id = exe.exec_id
+			where liab.rev > 0
+				and (
+					exe.exec_rn = @init_rn
+					or exists (
+						select 1
+						from binds bnd
+						join proc_steps stp
+							on stp.chnl_id = bnd.chnl_id
+						where bnd.exec_id = exe.exec_id
+							and bnd.exec_rn > 0
+							and stp.exec_id <> exe.exec_id
+					)
+				)
+				and not exists (
+					select 1
+					from pool_claims clm
+					where clm.proc_id = exe.exec_id
+						and clm.claimed_at > now() - make_interval(secs => @lease)
+				)
+			order by exe.exec_id
+			limit 1
+			for update of exe skip locked
+		), claimed as (
+			insert into pool_claims (proc_id, claimed_at)
+			select exec_id, now()
+			from ready
+			on conflict (proc_id) do update
+			set claimed_at = excluded.claimed_at
+			where pool_claims.claimed_at <= now() - make_interval(secs => @lease)
+			returning proc_id
 		)
 		select
-			bnd.*,
-			prvd.pool_id
-		from bnds bnd
-		left join liabs liab
-			on liab.proc_id = bnd.proc_id
-		left join pool_roots prvd
-			on prvd.pool_id = liab.pool_id`
-
-	selectSteps = ``
+			rdy.exec_id, rdy.exec_rn
+		from ready rdy
+		join claimed clm
+			on clm.proc_id = rdy.exec_id`
+
+	// children are aggregated into jsonb shaped as execRefDS
+	selectOrgSnap = `
+		select
+			sup.exec_id,
+			sup.exec_rn,
+			sup.pool_qn::text as title,
+			(
+				select coalesce(jsonb_agg(jsonb_build_object('ID', sub.exec_id, 'RN', sub.exec_rn)), '[]'::jsonb)
+				from pool_execs sub
+				where sub.sup_exec_id = sup.exec_id
+			) as subs
+		from pool_execs sup
+		where sup.exec_id = $1`
 )
@@ -0,0 +1,555 @@
+package poolexec
+
+import (
+	"log/slog"
+	"reflect"
+	"time"
+
+	"orglang/go-runtime/lib/db"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/procexec"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+type sqliteDAO struct {
+	log *slog.Logger
+}
+
+func newSqliteDAO(log *slog.Logger) *sqliteDAO {
+	name := slog.String("name", reflect.TypeFor[sqliteDAO]().Name())
+	return &sqliteDAO{log.With(name)}
+}
+
+func (dao *sqliteDAO) InsertRec(source db.Source, rec ExecRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	dto := DataFromExecRec(rec)
+	args := db.NamedArgsSqlite{
+		"exec_id":     dto.ID,
+		"exec_rn":     dto.RN,
+		"pool_qn":     dto.PoolQN,
+		"sup_exec_id": dto.SupID,
+	}
+	_, err := ds.Conn.Exec(ds.Ctx, insertExecSqlite, args)
+	if err != nil {
+		dao.log.Error("execution failed")
+		return err
+	}
+	dao.log.Debug("insertion succeed", slog.Any("execRef", rec.ExecRef))
+	return nil
+}
+
+func (dao *sqliteDAO) InsertLiab(source db.Source, liab Liab) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	dto := DataFromLiab(liab)
+	args := db.NamedArgsSqlite{
+		"pool_id": dto.ID,
+		"proc_id": dto.ProcID,
+		"rev":     dto.RN,
+	}
+	_, err := ds.Conn.Exec(ds.Ctx, insertLiabSqlite, args)
+	if err != nil {
+		dao.log.Error("execution failed")
+		return err
+	}
+	dao.log.Debug("insertion succeed", slog.Any("execRef", liab.ExecRef))
+	return nil
+}
+
+func (dao *sqliteDAO) SelectSubs(source db.Source, ref ExecRef) (ExecSnap, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	idAttr := slog.Any("execRef", ref)
+	rows, err := ds.Conn.Query(ds.Ctx, selectSubsSqlite, db.NamedArgsSqlite{"exec_id": ref.ID.String()})
+	if err != nil {
+		dao.log.Error("execution failed", idAttr)
+		return ExecSnap{}, err
+	}
+	dto, err := db.CollectOneRowSqlite[execSnapDS](rows)
+	if err != nil {
+		dao.log.Error("collection failed", idAttr, slog.Any("t", reflect.TypeOf(dto)))
+		return ExecSnap{}, err
+	}
+	snap, err := DataToExecSnap(dto)
+	if err != nil {
+		dao.log.Error("conversion failed")
+		return ExecSnap{}, err
+	}
+	dao.log.Debug("selection succeed", idAttr)
+	return snap, nil
+}
+
+func (dao *sqliteDAO) SelectRefs(source db.Source) ([]ExecRef, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	rows, err := ds.Conn.Query(ds.Ctx, selectRefsSqlite, nil)
+	if err != nil {
+		dao.log.Error("execution failed", slog.String("q", selectRefsSqlite))
+		return nil, err
+	}
+	dtos, err := db.CollectRowsSqlite[execRefDS](rows)
+	if err != nil {
+		dao.log.Error("collection failed", slog.Any("t", reflect.TypeOf(dtos)))
+		return nil, err
+	}
+	refs, err := DataToExecRefs(dtos)
+	if err != nil {
+		dao.log.Error("conversion failed")
+		return nil, err
+	}
+	return refs, nil
+}
+
+func (dao *sqliteDAO) SelectRecByQN(source db.Source, poolQN uniqsym.ADT) (ExecRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	qnAttr := slog.Any("poolQN", poolQN)
+	args := db.NamedArgsSqlite{"pool_qn": uniqsym.ConvertToString(poolQN)}
+	rows, err := ds.Conn.Query(ds.Ctx, selectRecByQNSqlite, args)
+	if err != nil {
+		dao.log.Error("execution failed", qnAttr)
+		return ExecRec{}, err
+	}
+	dto, err := db.CollectOneRowSqlite[execRecDS](rows)
+	if err != nil {
+		dao.log.Error("collection failed", qnAttr, slog.Any("t", reflect.TypeOf(dto)))
+		return ExecRec{}, err
+	}
+	rec, err := DataToExecRec(dto)
+	if err != nil {
+		dao.log.Error("conversion failed", qnAttr)
+		return ExecRec{}, err
+	}
+	dao.log.Debug("selection succeed", qnAttr)
+	return rec, nil
+}
+
+func (dao *sqliteDAO) SelectCfg(source db.Source, execRef ExecRef) (ExecCfg, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("execRef", execRef)
+	args := db.NamedArgsSqlite{"exec_id": execRef.ID.String()}
+	execRows, err := ds.Conn.Query(ds.Ctx, selectRefSqlite, args)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecCfg{}, err
+	}
+	execDto, err := db.CollectOneRowSqlite[execRefDS](execRows)
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(execDto)))
+		return ExecCfg{}, err
+	}
+	ref, err := DataToExecRef(execDto)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecCfg{}, err
+	}
+	capRows, err := ds.Conn.Query(ds.Ctx, selectCapsSqlite, args)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecCfg{}, err
+	}
+	capDtos, err := db.CollectRowsSqlite[capRecDS](capRows)
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(capDtos)))
+		return ExecCfg{}, err
+	}
+	caps, err := DataToCapRecs(capDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecCfg{}, err
+	}
+	depRows, err := ds.Conn.Query(ds.Ctx, selectDepsSqlite, args)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecCfg{}, err
+	}
+	depDtos, err := db.CollectRowsSqlite[depRecDS](depRows)
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(depDtos)))
+		return ExecCfg{}, err
+	}
+	deps, err := DataToDepRecs(depDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecCfg{}, err
+	}
+	assetRows, err := ds.Conn.Query(ds.Ctx, selectAssetsSqlite, args)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecCfg{}, err
+	}
+	assetDtos, err := db.CollectRowsSqlite[assetRecDS](assetRows)
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(assetDtos)))
+		return ExecCfg{}, err
+	}
+	assets, err := DataToAssetRecs(assetDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecCfg{}, err
+	}
+	dao.log.Debug("selection succeed", refAttr)
+	return ExecCfg{
+		ExecRef: ref,
+		CapRs:   procbind.IndexBy(CapSig, caps),
+		DepRs:   procbind.IndexBy(DepSig, deps),
+		AssetRs: procbind.IndexBy(ChnlPH, assets),
+	}, nil
+}
+
+func (dao *sqliteDAO) SelectLiab(source db.Source, procID identity.ADT) (Liab, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	idAttr := slog.Any("procID", procID)
+	rows, err := ds.Conn.Query(ds.Ctx, selectLiabSqlite, db.NamedArgsSqlite{"proc_id": procID.String()})
+	if err != nil {
+		dao.log.Error("execution failed", idAttr)
+		return Liab{}, err
+	}
+	dto, err := db.CollectOneRowSqlite[liabDS](rows)
+	if err != nil {
+		dao.log.Error("collection failed", idAttr, slog.Any("t", reflect.TypeOf(dto)))
+		return Liab{}, err
+	}
+	liab, err := DataToLiab(dto)
+	if err != nil {
+		dao.log.Error("conversion failed", idAttr)
+		return Liab{}, err
+	}
+	dao.log.Debug("selection succeed", idAttr)
+	return liab, nil
+}
+
+// sqlite transactions run one at a time, so no one can claim
+// the selected execution between the select and the claim
+func (dao *sqliteDAO) SelectReady(source db.Source, poolID identity.ADT, lease time.Duration) (procexec.ExecRef, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	idAttr := slog.Any("poolID", poolID)
+	now := time.Now()
+	args := db.NamedArgsSqlite{
+		"pool_id": poolID.String(),
+		"init_rn": revnum.ConvertToInt(revnum.New()),
+		"stale":   now.Add(-lease),
+	}
+	rows, err := ds.Conn.Query(ds.Ctx, selectReadySqlite, args)
+	if err != nil {
+		dao.log.Error("execution failed", idAttr)
+		return procexec.ExecRef{}, err
+	}
+	dtos, err := db.CollectRowsSqlite[execRefDS](rows)
+	if err != nil {
+		dao.log.Error("collection failed", idAttr, slog.Any("t", reflect.TypeOf(dtos)))
+		return procexec.ExecRef{}, err
+	}
+	if len(dtos) == 0 {
+		dao.log.Debug("selection succeed", idAttr)
+		return procexec.ExecRef{}, nil
+	}
+	claimArgs := db.NamedArgsSqlite{
+		"proc_id": dtos[0].ID,
+		"now":     now,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, claimReadySqlite, claimArgs)
+	if err != nil {
+		dao.log.Error("execution failed", idAttr)
+		return procexec.ExecRef{}, err
+	}
+	ref, err := DataToExecRef(dtos[0])
+	if err != nil {
+		dao.log.Error("conversion failed", idAttr)
+		return procexec.ExecRef{}, err
+	}
+	dao.log.Debug("selection succeed", idAttr, slog.Any("procRef", ref))
+	return ref, nil
+}
+
+func (dao *sqliteDAO) UpdateCfg(source db.Source, mod ExecMod) error {
+	if len(mod.Locks) == 0 {
+		panic("empty locks")
+	}
+	ds := db.MustConform[db.SourceSqlite](source)
+	dto := DataFromMod(mod)
+	// caps
+	for _, dto := range dto.Caps {
+		args := db.NamedArgsSqlite{
+			"pool_id": dto.ID,
+			"sig_id":  dto.SigID,
+			"rev":     dto.RN,
+		}
+		_, err := ds.Conn.Exec(ds.Ctx, insertCapSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+			return err
+		}
+	}
+	// deps
+	for _, dto := range dto.Deps {
+		args := db.NamedArgsSqlite{
+			"pool_id": dto.ID,
+			"sig_id":  dto.SigID,
+			"rev":     dto.RN,
+		}
+		_, err := ds.Conn.Exec(ds.Ctx, insertDepSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+			return err
+		}
+	}
+	// liabs
+	for _, dto := range dto.Liabs {
+		args := db.NamedArgsSqlite{
+			"pool_id": dto.ID,
+			"proc_id": dto.ProcID,
+			"rev":     dto.RN,
+		}
+		_, err := ds.Conn.Exec(ds.Ctx, insertLiabSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+			return err
+		}
+	}
+	// assets
+	for _, dto := range dto.Assets {
+		args := db.NamedArgsSqlite{
+			"pool_id": dto.ID,
+			"chnl_ph": dto.ChnlPH,
+			"chnl_id": dto.ChnlID,
+			"proc_id": dto.ProcID,
+			"sig_id":  dto.SigID,
+			"rev":     dto.RN,
+		}
+		_, err := ds.Conn.Exec(ds.Ctx, insertAssetSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+			return err
+		}
+	}
+	// execs
+	for _, dto := range dto.Locks {
+		args := db.NamedArgsSqlite{
+			"exec_id": dto.ID,
+			"exec_rn": dto.RN,
+		}
+		res, err := ds.Conn.Exec(ds.Ctx, updateExecSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			dao.log.Error("update failed")
+			return errOptimisticUpdate(revnum.ADT(dto.RN))
+		}
+	}
+	dao.log.Debug("update succeed")
+	return nil
+}
+
+const (
+	insertExecSqlite = `
+		insert into pool_execs (
+			exec_id, exec_rn, pool_qn, sup_exec_id
+		) values (
+			:exec_id, :exec_rn, :pool_qn, :sup_exec_id
+		)`
+
+	insertLiabSqlite = `
+		insert into pool_liabs (
+			pool_id, proc_id, rev
+		) values (
+			:pool_id, :proc_id, :rev
+		)`
+
+	insertCapSqlite = `
+		insert into pool_caps (
+			pool_id, sig_id, rev
+		) values (
+			:pool_id, :sig_id, :rev
+		)`
+
+	insertDepSqlite = `
+		insert into pool_deps (
+			pool_id, sig_id, rev
+		) values (
+			:pool_id, :sig_id, :rev
+		)`
+
+	insertAssetSqlite = `
+		insert into pool_assets (
+			pool_id, chnl_ph, chnl_id, proc_id, sig_id, rev
+		) values (
+			:pool_id, :chnl_ph, :chnl_id, :proc_id, :sig_id, :rev
+		)`
+
+	updateExecSqlite = `
+		update pool_execs
+		set exec_rn = :exec_rn + 1
+		where exec_id = :exec_id
+			and exec_rn = :exec_rn`
+
+	selectRefsSqlite = `
+		select
+			exec_id, exec_rn
+		from pool_execs`
+
+	selectRefSqlite = `
+		select
+			exec_id, exec_rn
+		from pool_execs
+		where exec_id = :exec_id`
+
+	selectRecByQNSqlite = `
+		select
+			exec_id, exec_rn, pool_qn, sup_exec_id
+		from pool_execs
+		where pool_qn = :pool_qn
+		limit 1`
+
+	// revoked records carry a negative revision
+	selectCapsSqlite = `
+		select
+			exec_id, exec_rn, sig_id
+		from (
+			select
+				pool_id as exec_id, rev as exec_rn, sig_id,
+				row_number() over (
+					partition by sig_id
+					order by abs(rev) desc
+				) as pos
+			from pool_caps
+			where pool_id = :exec_id
+		)
+		where pos = 1
+			and exec_rn > 0`
+
+	selectDepsSqlite = `
+		select
+			exec_id, exec_rn, sig_id
+		from (
+			select
+				pool_id as exec_id, rev as exec_rn, sig_id,
+				row_number() over (
+					partition by sig_id
+					order by abs(rev) desc
+				) as pos
+			from pool_deps
+			where pool_id = :exec_id
+		)
+		where pos = 1
+			and exec_rn > 0`
+
+	selectAssetsSqlite = `
+		select
+			exec_id, exec_rn, chnl_ph, chnl_id, proc_id, sig_id
+		from (
+			select
+				pool_id as exec_id, rev as exec_rn, chnl_ph, chnl_id, proc_id, sig_id,
+				row_number() over (
+					partition by chnl_ph
+					order by abs(rev) desc
+				) as pos
+			from pool_assets
+			where pool_id = :exec_id
+		)
+		where pos = 1
+			and exec_rn > 0`
+
+	// revisions are comparable only within one pool
+	selectLiabSqlite = `
+		select
+			exec_id, exec_rn, proc_id
+		from (
+			select
+				pool_id as exec_id, rev as exec_rn, proc_id,
+				row_number() over (
+					partition by pool_id
+					order by abs(rev) desc
+				) as pos
+			from pool_liabs
+			where proc_id = :proc_id
+		)
+		where pos = 1
+			and exec_rn > 0`
+
+	// a process is ready if it has made no steps yet
+	// or a counterpart is waiting for it
+	selectReadySqlite = `
+		with liabs as (
+			select
+				proc_id, rev
+			from (
+				select
+					proc_id, rev,
+					row_number() over (
+						partition by proc_id
+						order by abs(rev) desc
+					) as pos
+				from pool_liabs
+				where pool_id = :pool_id
+			)
+			where pos = 1
+		), binds as (
+			select
+				exec_id, exec_rn, chnl_id
+			from (
+				select
+					bnd.exec_id, bnd.exec_rn, bnd.chnl_id,
+					row_number() over (
+						partition by bnd.exec_id, bnd.chnl_ph
+						order by abs(bnd.exec_rn) desc
+					) as pos
+				from proc_binds bnd
+				join liabs liab
+					on liab.proc_id = bnd.exec_id
+				where liab.rev > 0
+			)
+			where pos = 1
+		)
+		select
+			exe.exec_id, exe.exec_rn
+		from proc_execs exe
+		join liabs liab
+			on liab.proc_id = exe.exec_id
+		where liab.rev > 0
+			and (
+				exe.exec_rn = :init_rn
+				or exists (
+					select 1
+					from binds bnd
+					join proc_steps stp
+						on stp.chnl_id = bnd.chnl_id
+					where bnd.exec_id = exe.exec_id
+						and bnd.exec_rn > 0
+						and stp.exec_id <> exe.exec_id
+				)
+			)
+			and not exists (
+				select 1
+				from pool_claims clm
+				where clm.proc_id = exe.exec_id
+					and clm.claimed_at > :stale
+			)
+		order by exe.exec_id
+		limit 1`
+
+	claimReadySqlite = `
+		insert into pool_claims (proc_id, claimed_at)
+		values (:proc_id, :now)
+		on conflict (proc_id) do update
+		set claimed_at = excluded.claimed_at`
+
+	// children are aggregated into json shaped as execRefDS
+	selectSubsSqlite = `
+		select
+			sup.exec_id,
+			sup.exec_rn,
+			sup.pool_qn as title,
+			(
+				select json_group_array(json_object('ID', sub.exec_id, 'RN', sub.exec_rn))
+				from pool_execs sub
+				where sub.sup_exec_id = sup.exec_id
+			) as subs
+		from pool_execs sup
+		where sup.exec_id = :exec_id`
+)
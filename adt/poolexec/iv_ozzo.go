@@ -0,0 +1,13 @@
+package poolexec
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func (dto pollingCS) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.Lease, validation.Required, validation.Min(time.Second)),
+	)
+}
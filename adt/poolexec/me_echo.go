@@ -8,8 +8,12 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/orglang/go-sdk/adt/poolexec"
+	sdk "github.com/orglang/go-sdk/adt/poolstep"
 
 	"orglang/go-runtime/lib/te"
+
+	"orglang/go-runtime/adt/poolstep"
+	"orglang/go-runtime/adt/uniqref"
 )
 
 // Server-side primary adapter
@@ -28,6 +32,8 @@ func cfgEchoController(e *echo.Echo, h *echoController) error {
 	e.POST("/api/v1/pools", h.PostOne)
 	e.GET("/api/v1/pools/:id", h.GetOne)
 	e.POST("/api/v1/pools/:id/procs", h.PostProc)
+	e.POST("/api/v1/pools/:id/steps", h.PostStep)
+	e.GET("/api/v1/pools/:id/ready", h.GetReady)
 	return nil
 }
 
@@ -48,7 +54,7 @@ func (h *echoController) PostOne(c echo.Context) error {
 		h.log.Error("conversion failed", slog.Any("dto", dto))
 		return conversionErr
 	}
-	ref, creationErr := h.api.Run(spec)
+	ref, creationErr := h.api.Run(c.Request().Context(), spec)
 	if creationErr != nil {
 		return creationErr
 	}
@@ -65,7 +71,7 @@ func (h *echoController) GetOne(c echo.Context) error {
 	if conversionErr != nil {
 		return conversionErr
 	}
-	snap, retrievalErr := h.api.RetrieveSnap(ref)
+	snap, retrievalErr := h.api.RetrieveSnap(c.Request().Context(), ref)
 	if retrievalErr != nil {
 		return retrievalErr
 	}
@@ -89,9 +95,55 @@ func (h *echoController) PostProc(c echo.Context) error {
 		h.log.Error("conversion failed", slog.Any("dto", dto))
 		return conversionErr
 	}
-	ref, creationErr := h.api.Run(spec)
+	ref, creationErr := h.api.Run(c.Request().Context(), spec)
 	if creationErr != nil {
 		return creationErr
 	}
 	return c.JSON(http.StatusCreated, MsgFromExecRef(ref))
 }
+
+func (h *echoController) PostStep(c echo.Context) error {
+	var dto sdk.StepSpec
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	validationErr := dto.Validate()
+	if validationErr != nil {
+		h.log.Error("validation failed", slog.Any("dto", dto))
+		return validationErr
+	}
+	spec, conversionErr := poolstep.MsgToStepSpec(dto)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	takingErr := h.api.Take(c.Request().Context(), spec)
+	if takingErr != nil {
+		return takingErr
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *echoController) GetReady(c echo.Context) error {
+	var dto poolexec.ExecRef
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	ref, conversionErr := MsgToExecRef(dto)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	procRef, pollingErr := h.api.Poll(c.Request().Context(), PollSpec{ExecID: ref.ID})
+	if pollingErr != nil {
+		return pollingErr
+	}
+	if procRef.ID.IsEmpty() {
+		return c.NoContent(http.StatusNoContent)
+	}
+	return c.JSON(http.StatusOK, uniqref.MsgFromADT(procRef))
+}
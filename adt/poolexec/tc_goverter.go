@@ -21,8 +21,12 @@ var (
 // goverter:variables
 // goverter:output:format assign-variable
 // goverter:extend orglang/go-runtime/adt/identity:Convert.*
+// goverter:extend orglang/go-runtime/adt/revnum:Convert.*
+// goverter:extend orglang/go-runtime/adt/symbol:Convert.*
+// goverter:extend orglang/go-runtime/adt/uniqsym:Convert.*
 // goverter:extend orglang/go-runtime/adt/uniqref:Data.*
 var (
+	DataToExecRef    func(execRefDS) (ExecRef, error)
 	DataToExecRefs   func([]execRefDS) ([]ExecRef, error)
 	DataFromExecRefs func([]ExecRef) []execRefDS
 	// goverter:map . ExecRef
@@ -37,4 +41,20 @@ var (
 	DataToExecSnap func(execSnapDS) (ExecSnap, error)
 	// goverter:autoMap ExecRef
 	DataFromExecSnap func(ExecSnap) execSnapDS
+	DataFromMod      func(ExecMod) execModDS
+	// goverter:map . ExecRef
+	DataToCapRec func(capRecDS) (CapRec, error)
+	// goverter:autoMap ExecRef
+	DataFromCapRec func(CapRec) capRecDS
+	DataToCapRecs  func([]capRecDS) ([]CapRec, error)
+	// goverter:map . ExecRef
+	DataToDepRec func(depRecDS) (DepRec, error)
+	// goverter:autoMap ExecRef
+	DataFromDepRec func(DepRec) depRecDS
+	DataToDepRecs  func([]depRecDS) ([]DepRec, error)
+	// goverter:map . ExecRef
+	DataToAssetRec func(assetRecDS) (AssetRec, error)
+	// goverter:autoMap ExecRef
+	DataFromAssetRec func(AssetRec) assetRecDS
+	DataToAssetRecs  func([]assetRecDS) ([]AssetRec, error)
 )
@@ -1,6 +1,8 @@
 package poolexp
 
 import (
+	"fmt"
+
 	"orglang/go-runtime/adt/symbol"
 	"orglang/go-runtime/adt/uniqsym"
 )
@@ -41,6 +43,7 @@ type AcquireSpec struct {
 func (s AcquireSpec) via() {}
 
 type ReleaseSpec struct {
+	BindPH symbol.ADT
 }
 
 func (s ReleaseSpec) via() {}
@@ -58,3 +61,7 @@ type DetachSpec struct {
 }
 
 func (s DetachSpec) via() {}
+
+func ErrExpTypeUnexpected(got ExpSpec) error {
+	return fmt.Errorf("term spec unexpected: %T", got)
+}
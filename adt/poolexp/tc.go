@@ -0,0 +1,81 @@
+package poolexp
+
+import (
+	"fmt"
+
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/uniqsym"
+
+	"github.com/orglang/go-sdk/adt/poolexp"
+)
+
+func MsgToExpSpec(dto poolexp.ExpSpec) (ExpSpec, error) {
+	switch dto.K {
+	case poolexp.Hire:
+		procQN, err := uniqsym.ConvertFromString(dto.Hire.ProcQN)
+		if err != nil {
+			return nil, err
+		}
+		return HireSpec{ProcQN: procQN}, nil
+	case poolexp.Fire:
+		procQN, err := uniqsym.ConvertFromString(dto.Fire.ProcQN)
+		if err != nil {
+			return nil, err
+		}
+		return FireSpec{ProcQN: procQN}, nil
+	case poolexp.Apply:
+		procQN, err := uniqsym.ConvertFromString(dto.Apply.ProcQN)
+		if err != nil {
+			return nil, err
+		}
+		return ApplySpec{ProcQN: procQN}, nil
+	case poolexp.Quit:
+		procQN, err := uniqsym.ConvertFromString(dto.Quit.ProcQN)
+		if err != nil {
+			return nil, err
+		}
+		return QuitSpec{ProcQN: procQN}, nil
+	case poolexp.Acquire:
+		poolQN, err := uniqsym.ConvertFromString(dto.Acquire.PoolQN)
+		if err != nil {
+			return nil, err
+		}
+		bindPH, err := symbol.ConvertFromString(dto.Acquire.BindPH)
+		if err != nil {
+			return nil, err
+		}
+		return AcquireSpec{PoolQN: poolQN, BindPH: bindPH}, nil
+	case poolexp.Release:
+		bindPH, err := symbol.ConvertFromString(dto.Release.BindPH)
+		if err != nil {
+			return nil, err
+		}
+		return ReleaseSpec{BindPH: bindPH}, nil
+	case poolexp.Accept:
+		poolQN, err := uniqsym.ConvertFromString(dto.Accept.PoolQN)
+		if err != nil {
+			return nil, err
+		}
+		valPH, err := symbol.ConvertFromString(dto.Accept.ValPH)
+		if err != nil {
+			return nil, err
+		}
+		return AcceptSpec{PoolQN: poolQN, ValPH: valPH}, nil
+	case poolexp.Detach:
+		poolQN, err := uniqsym.ConvertFromString(dto.Detach.PoolQN)
+		if err != nil {
+			return nil, err
+		}
+		valPH, err := symbol.ConvertFromString(dto.Detach.ValPH)
+		if err != nil {
+			return nil, err
+		}
+		return DetachSpec{PoolQN: poolQN, ValPH: valPH}, nil
+	default:
+		return nil, errUnexpectedExpKind(dto.K)
+	}
+}
+
+func errUnexpectedExpKind(got poolexp.Kind) error {
+	return fmt.Errorf("exp kind unexpected: %v", got)
+}
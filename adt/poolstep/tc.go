@@ -0,0 +1,29 @@
+package poolstep
+
+import (
+	"orglang/go-runtime/adt/poolexp"
+	"orglang/go-runtime/adt/uniqref"
+	"orglang/go-runtime/adt/uniqsym"
+
+	"github.com/orglang/go-sdk/adt/poolstep"
+)
+
+func MsgToStepSpec(dto poolstep.StepSpec) (StepSpec, error) {
+	execRef, err := uniqref.MsgToADT(dto.ExecRef)
+	if err != nil {
+		return StepSpec{}, err
+	}
+	// the signature is needed only to acquire a process
+	var procQN uniqsym.ADT
+	if dto.ProcQN != "" {
+		procQN, err = uniqsym.ConvertFromString(dto.ProcQN)
+		if err != nil {
+			return StepSpec{}, err
+		}
+	}
+	poolES, err := poolexp.MsgToExpSpec(dto.PoolES)
+	if err != nil {
+		return StepSpec{}, err
+	}
+	return StepSpec{ExecRef: execRef, ProcQN: procQN, ProcES: poolES}, nil
+}
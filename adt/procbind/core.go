@@ -1,6 +1,7 @@
 package procbind
 
 import (
+	"orglang/go-runtime/adt/arithexp"
 	"orglang/go-runtime/adt/identity"
 	"orglang/go-runtime/adt/symbol"
 	"orglang/go-runtime/adt/uniqref"
@@ -12,6 +13,10 @@ type BindSpec struct {
 	ChnlPH symbol.ADT
 	// type qualified name (aka variable type)
 	TypeQN uniqsym.ADT
+	// type arguments
+	TypeArgs []uniqsym.ADT
+	// index arguments of the type
+	IdxArgs []arithexp.ExpSpec
 }
 
 type BindRec struct {
@@ -1,8 +1,10 @@
 package procbind
 
 type BindSpecDS struct {
-	ChnlPH string `json:"chnl_ph"`
-	TypeQN string `json:"type_qn"`
+	ChnlPH   string   `json:"chnl_ph"`
+	TypeQN   string   `json:"type_qn"`
+	TypeArgs []string `json:"type_args,omitempty"`
+	IdxArgs  []string `json:"idx_args,omitempty"`
 }
 
 type BindRecDS struct {
@@ -13,3 +15,10 @@ type BindRecDS struct {
 	ChnlID string `db:"chnl_id"`
 	ExpID  string `db:"exp_id"`
 }
+
+// In-memory tables read by several aggregates:
+// binds are stored as BindRecDS, executions as uniqref.Data.
+const (
+	BindsMem = "proc_binds"
+	ExecsMem = "proc_execs"
+)
@@ -8,6 +8,7 @@ import (
 // goverter:output:format assign-variable
 // goverter:extend orglang/go-runtime/adt/symbol:Convert.*
 // goverter:extend orglang/go-runtime/adt/uniqsym:Convert.*
+// goverter:extend orglang/go-runtime/adt/arithexp:Convert.*
 var (
 	MsgToBindSpec   func(procbind.BindSpec) (BindSpec, error)
 	MsgFromBindSpec func(BindSpec) procbind.BindSpec
@@ -17,6 +18,7 @@ var (
 // goverter:output:format assign-variable
 // goverter:extend orglang/go-runtime/adt/identity:Convert.*
 // goverter:extend orglang/go-runtime/adt/uniqsym:Convert.*
+// goverter:extend orglang/go-runtime/adt/arithexp:Convert.*
 var (
 	DataToBindSpec   func(BindSpecDS) (BindSpec, error)
 	DataFromBindSpec func(BindSpec) BindSpecDS
@@ -5,28 +5,42 @@ import (
 	"fmt"
 	"iter"
 	"log/slog"
+	"slices"
+	"time"
 
 	"orglang/go-runtime/lib/db"
 
+	"orglang/go-runtime/adt/arithexp"
 	"orglang/go-runtime/adt/identity"
 	"orglang/go-runtime/adt/procbind"
 	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
 	"orglang/go-runtime/adt/syndec"
 	"orglang/go-runtime/adt/uniqref"
 	"orglang/go-runtime/adt/uniqsym"
 )
 
 type API interface {
-	Incept(uniqsym.ADT) (DecRef, error)
-	Create(DecSpec) (DecRef, error)
-	RetrieveSnap(DecRef) (DecSnap, error)
-	RetreiveRefs() ([]DecRef, error)
+	Incept(context.Context, uniqsym.ADT) (DecRef, error)
+	Create(context.Context, DecSpec) (DecRef, error)
+	RetrieveSnap(context.Context, DecRef) (DecSnap, error)
+	// the revision given in the ref rather than the latest
+	RetrieveSnapByRN(context.Context, DecRef) (DecSnap, error)
+	// the latest revision as of the given time
+	RetrieveSnapAsOf(context.Context, identity.ADT, time.Time) (DecSnap, error)
+	RetreiveRefs(context.Context) ([]DecRef, error)
+	RetrieveRevs(context.Context, identity.ADT) ([]RevRec, error)
+	Compare(context.Context, identity.ADT, revnum.ADT, revnum.ADT) (DecDiff, error)
 }
 
 type DecRef = uniqref.ADT
 
 type DecSpec struct {
 	ProcQN uniqsym.ADT
+	// index parameters of the process (naturals)
+	IdxVars []symbol.ADT
+	// potential the process starts with
+	Pot int64
 	// endpoint where process acts as a provider
 	ProviderBS procbind.BindSpec
 	// endpoints where process acts as a client
@@ -35,6 +49,8 @@ type DecSpec struct {
 
 type DecRec struct {
 	DecRef     DecRef
+	IdxVars    []symbol.ADT
+	Pot        int64
 	ProviderBS procbind.BindSpec
 	ClientBSs  []procbind.BindSpec
 }
@@ -42,10 +58,33 @@ type DecRec struct {
 // aka ExpDec or ExpDecDef without expression
 type DecSnap struct {
 	DecRef     DecRef
+	IdxVars    []symbol.ADT
+	Pot        int64
 	ProviderBS procbind.BindSpec
 	ClientBSs  []procbind.BindSpec
 }
 
+// revision in the declaration history
+type RevRec struct {
+	DecRef DecRef
+	RevAt  time.Time
+}
+
+// differences between two revisions of a declaration,
+// binds are matched by channel placeholder
+type DecDiff struct {
+	FromRef        DecRef
+	ToRef          DecRef
+	IdxVarsAdded   []symbol.ADT
+	IdxVarsRemoved []symbol.ADT
+	FromPot        int64
+	ToPot          int64
+	BindsAdded     []procbind.BindSpec
+	BindsRemoved   []procbind.BindSpec
+	// binds as of the newer revision
+	BindsChanged []procbind.BindSpec
+}
+
 type service struct {
 	procDecs Repo
 	synDecs  syndec.Repo
@@ -62,8 +101,7 @@ func newService(procDecs Repo, synDecs syndec.Repo, operator db.Operator, log *s
 	return &service{procDecs, synDecs, operator, log}
 }
 
-func (s *service) Incept(procQN uniqsym.ADT) (_ DecRef, err error) {
-	ctx := context.Background()
+func (s *service) Incept(ctx context.Context, procQN uniqsym.ADT) (_ DecRef, err error) {
 	qnAttr := slog.Any("procQN", procQN)
 	s.log.Debug("inception started", qnAttr)
 	newSyn := syndec.DecRec{DecQN: procQN, DecID: identity.New(), DecRN: revnum.New()}
@@ -87,16 +125,27 @@ func (s *service) Incept(procQN uniqsym.ADT) (_ DecRef, err error) {
 	return newRec.DecRef, nil
 }
 
-func (s *service) Create(spec DecSpec) (_ DecRef, err error) {
-	ctx := context.Background()
+func (s *service) Create(ctx context.Context, spec DecSpec) (_ DecRef, err error) {
 	qnAttr := slog.Any("procQN", spec.ProcQN)
 	s.log.Debug("creation started", qnAttr, slog.Any("spec", spec))
+	newSyn := syndec.DecRec{DecQN: spec.ProcQN, DecID: identity.New(), DecRN: revnum.New()}
 	newRec := DecRec{
-		DecRef:     DecRef{ID: identity.New(), RN: revnum.New()},
+		DecRef:     DecRef{ID: newSyn.DecID, RN: newSyn.DecRN},
+		IdxVars:    spec.IdxVars,
+		Pot:        spec.Pot,
 		ProviderBS: spec.ProviderBS,
 		ClientBSs:  spec.ClientBSs,
 	}
+	err = checkDec(newRec)
+	if err != nil {
+		s.log.Error("creation failed", qnAttr)
+		return DecRef{}, err
+	}
 	s.operator.Explicit(ctx, func(ds db.Source) error {
+		err = s.synDecs.Insert(ds, newSyn)
+		if err != nil {
+			return err
+		}
 		err = s.procDecs.InsertRec(ds, newRec)
 		if err != nil {
 			return err
@@ -111,8 +160,7 @@ func (s *service) Create(spec DecSpec) (_ DecRef, err error) {
 	return newRec.DecRef, nil
 }
 
-func (s *service) RetrieveSnap(ref DecRef) (snap DecSnap, err error) {
-	ctx := context.Background()
+func (s *service) RetrieveSnap(ctx context.Context, ref DecRef) (snap DecSnap, err error) {
 	s.operator.Implicit(ctx, func(ds db.Source) error {
 		snap, err = s.procDecs.SelectSnap(ds, ref)
 		return err
@@ -124,8 +172,39 @@ func (s *service) RetrieveSnap(ref DecRef) (snap DecSnap, err error) {
 	return snap, nil
 }
 
-func (s *service) RetreiveRefs() (refs []DecRef, err error) {
-	ctx := context.Background()
+func (s *service) RetrieveSnapByRN(ctx context.Context, ref DecRef) (snap DecSnap, err error) {
+	s.operator.Implicit(ctx, func(ds db.Source) error {
+		snap, err = s.procDecs.SelectSnapByRN(ds, ref)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", slog.Any("decRef", ref))
+		return DecSnap{}, err
+	}
+	return snap, nil
+}
+
+func (s *service) RetrieveSnapAsOf(ctx context.Context, decID identity.ADT, at time.Time) (snap DecSnap, err error) {
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		revs, err := s.procDecs.SelectRevs(ds, decID)
+		if err != nil {
+			return err
+		}
+		rev, ok := revAsOf(revs, at)
+		if !ok {
+			return errRevMissingAt(decID, at)
+		}
+		snap, err = s.procDecs.SelectSnapByRN(ds, rev.DecRef)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", slog.Any("decID", decID), slog.Time("at", at))
+		return DecSnap{}, err
+	}
+	return snap, nil
+}
+
+func (s *service) RetreiveRefs(ctx context.Context) (refs []DecRef, err error) {
 	s.operator.Implicit(ctx, func(ds db.Source) error {
 		refs, err = s.procDecs.SelectRefs(ds)
 		return err
@@ -137,17 +216,122 @@ func (s *service) RetreiveRefs() (refs []DecRef, err error) {
 	return refs, nil
 }
 
+func (s *service) RetrieveRevs(ctx context.Context, decID identity.ADT) (revs []RevRec, err error) {
+	s.operator.Implicit(ctx, func(ds db.Source) error {
+		revs, err = s.procDecs.SelectRevs(ds, decID)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", slog.Any("decID", decID))
+		return nil, err
+	}
+	if len(revs) == 0 {
+		return nil, errDoesNotExist(decID)
+	}
+	return revs, nil
+}
+
+func (s *service) Compare(ctx context.Context, decID identity.ADT, fromRN, toRN revnum.ADT) (DecDiff, error) {
+	idAttr := slog.Any("decID", decID)
+	fromSnap, err := s.RetrieveSnapByRN(ctx, DecRef{ID: decID, RN: fromRN})
+	if err != nil {
+		s.log.Error("comparison failed", idAttr)
+		return DecDiff{}, err
+	}
+	toSnap, err := s.RetrieveSnapByRN(ctx, DecRef{ID: decID, RN: toRN})
+	if err != nil {
+		s.log.Error("comparison failed", idAttr)
+		return DecDiff{}, err
+	}
+	return diffSnaps(fromSnap, toSnap), nil
+}
+
+func diffSnaps(from, to DecSnap) DecDiff {
+	diff := DecDiff{FromRef: from.DecRef, ToRef: to.DecRef, FromPot: from.Pot, ToPot: to.Pot}
+	diff.IdxVarsAdded, diff.IdxVarsRemoved = symbol.Diff(from.IdxVars, to.IdxVars)
+	fromBSs := append([]procbind.BindSpec{from.ProviderBS}, from.ClientBSs...)
+	toBSs := append([]procbind.BindSpec{to.ProviderBS}, to.ClientBSs...)
+	for _, toBS := range toBSs {
+		i := slices.IndexFunc(fromBSs, func(bs procbind.BindSpec) bool { return bs.ChnlPH == toBS.ChnlPH })
+		if i < 0 {
+			diff.BindsAdded = append(diff.BindsAdded, toBS)
+			continue
+		}
+		if !equalBinds(fromBSs[i], toBS) {
+			diff.BindsChanged = append(diff.BindsChanged, toBS)
+		}
+	}
+	for _, fromBS := range fromBSs {
+		if !slices.ContainsFunc(toBSs, func(bs procbind.BindSpec) bool { return bs.ChnlPH == fromBS.ChnlPH }) {
+			diff.BindsRemoved = append(diff.BindsRemoved, fromBS)
+		}
+	}
+	return diff
+}
+
+func equalBinds(a, b procbind.BindSpec) bool {
+	return a.TypeQN.Equal(b.TypeQN) &&
+		slices.EqualFunc(a.TypeArgs, b.TypeArgs, uniqsym.ADT.Equal) &&
+		slices.Equal(arithexp.ConvertExpsToStrings(a.IdxArgs), arithexp.ConvertExpsToStrings(b.IdxArgs))
+}
+
+// revisions are ordered by ascending number
+func revAsOf(revs []RevRec, at time.Time) (RevRec, bool) {
+	for i := len(revs) - 1; i >= 0; i-- {
+		if !revs[i].RevAt.After(at) {
+			return revs[i], true
+		}
+	}
+	return RevRec{}, false
+}
+
 func CollectEnv(recs iter.Seq[DecRec]) []uniqsym.ADT {
 	typeQNs := []uniqsym.ADT{}
 	for rec := range recs {
 		typeQNs = append(typeQNs, rec.ProviderBS.TypeQN)
+		typeQNs = append(typeQNs, rec.ProviderBS.TypeArgs...)
 		for _, y := range rec.ClientBSs {
 			typeQNs = append(typeQNs, y.TypeQN)
+			typeQNs = append(typeQNs, y.TypeArgs...)
 		}
 	}
 	return typeQNs
 }
 
+// potential is non-negative, index arguments of binds refer
+// only to the process parameters
+func checkDec(rec DecRec) error {
+	if rec.Pot < 0 {
+		return errNegativePot(rec.Pot)
+	}
+	for _, bs := range append([]procbind.BindSpec{rec.ProviderBS}, rec.ClientBSs...) {
+		for _, idxArg := range bs.IdxArgs {
+			for _, idxVar := range arithexp.CollectVars(idxArg) {
+				if !slices.Contains(rec.IdxVars, idxVar) {
+					return errIdxVarUnbound(bs.ChnlPH, idxVar)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func ErrRootMissingInEnv(rid identity.ADT) error {
 	return fmt.Errorf("root missing in env: %v", rid)
 }
+
+func errDoesNotExist(want identity.ADT) error {
+	return fmt.Errorf("root doesn't exist: %v", want)
+}
+
+func errRevMissingAt(decID identity.ADT, at time.Time) error {
+	return fmt.Errorf("revision missing: %v has none as of %v", decID, at)
+}
+
+func errNegativePot(got int64) error {
+	return fmt.Errorf("potential negative: %v", got)
+}
+
+func errIdxVarUnbound(chnlPH, idxVar symbol.ADT) error {
+	return fmt.Errorf("index var unbound: %v refers to undeclared %v", chnlPH, idxVar)
+}
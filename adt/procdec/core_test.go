@@ -0,0 +1,236 @@
+package procdec
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"orglang/go-runtime/lib/db"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+// operations run without storage
+type stubOperator struct{}
+
+func (stubOperator) Explicit(_ context.Context, op func(db.Source) error) error { return op(nil) }
+
+func (stubOperator) Implicit(_ context.Context, op func(db.Source) error) error { return op(nil) }
+
+type stubDecs struct {
+	Repo
+	snaps map[identity.ADT][]DecSnap
+	revs  map[identity.ADT][]RevRec
+}
+
+func (r stubDecs) SelectSnapByRN(_ db.Source, ref DecRef) (DecSnap, error) {
+	for _, snap := range r.snaps[ref.ID] {
+		if snap.DecRef.RN == ref.RN {
+			return snap, nil
+		}
+	}
+	return DecSnap{}, errDoesNotExist(ref.ID)
+}
+
+func (r stubDecs) SelectRevs(_ db.Source, decID identity.ADT) ([]RevRec, error) {
+	return r.revs[decID], nil
+}
+
+// three revisions an hour apart: a client and potential added,
+// then the client retyped and the index var dropped
+func storedHistory(start time.Time) (identity.ADT, []revnum.ADT, *service) {
+	decID := identity.New()
+	rn1 := revnum.New()
+	rn2 := revnum.Next(rn1)
+	rn3 := revnum.Next(rn2)
+	provider := procbind.BindSpec{ChnlPH: "z", TypeQN: uniqsym.New("one")}
+	snaps := []DecSnap{
+		{DecRef: DecRef{ID: decID, RN: rn1}, IdxVars: []symbol.ADT{"n"}, ProviderBS: provider},
+		{DecRef: DecRef{ID: decID, RN: rn2}, IdxVars: []symbol.ADT{"n"}, Pot: 2, ProviderBS: provider,
+			ClientBSs: []procbind.BindSpec{{ChnlPH: "x", TypeQN: uniqsym.New("one")}}},
+		{DecRef: DecRef{ID: decID, RN: rn3}, Pot: 2, ProviderBS: provider,
+			ClientBSs: []procbind.BindSpec{{ChnlPH: "x", TypeQN: uniqsym.New("two")}}},
+	}
+	revs := make([]RevRec, 0, len(snaps))
+	for i, snap := range snaps {
+		revs = append(revs, RevRec{DecRef: snap.DecRef, RevAt: start.Add(time.Duration(i) * time.Hour)})
+	}
+	s := &service{
+		procDecs: stubDecs{
+			snaps: map[identity.ADT][]DecSnap{decID: snaps},
+			revs:  map[identity.ADT][]RevRec{decID: revs},
+		},
+		operator: stubOperator{},
+		log:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	return decID, []revnum.ADT{rn1, rn2, rn3}, s
+}
+
+func TestRetrieveSnapByRN(t *testing.T) {
+	decID, rns, s := storedHistory(time.Now())
+	tests := []struct {
+		name string
+		ref  DecRef
+		want int64
+		err  bool
+	}{
+		{"first revision", DecRef{ID: decID, RN: rns[0]}, 0, false},
+		{"second revision", DecRef{ID: decID, RN: rns[1]}, 2, false},
+		{"unknown revision", DecRef{ID: decID, RN: revnum.Next(rns[2])}, 0, true},
+		{"unknown declaration", DecRef{ID: identity.New(), RN: rns[0]}, 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := s.RetrieveSnapByRN(context.Background(), test.ref)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got %v, want error", got.DecRef)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if got.DecRef != test.ref || got.Pot != test.want {
+				t.Errorf("got %v with pot %v, want %v with pot %v", got.DecRef, got.Pot, test.ref, test.want)
+			}
+		})
+	}
+}
+
+func TestRetrieveSnapAsOf(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	decID, rns, s := storedHistory(start)
+	tests := []struct {
+		name string
+		id   identity.ADT
+		at   time.Time
+		want revnum.ADT
+		err  bool
+	}{
+		{"before the first revision", decID, start.Add(-time.Second), 0, true},
+		{"at the first revision", decID, start, rns[0], false},
+		{"at the second revision", decID, start.Add(time.Hour), rns[1], false},
+		{"between revisions", decID, start.Add(90 * time.Minute), rns[1], false},
+		{"after the last revision", decID, start.Add(24 * time.Hour), rns[2], false},
+		{"unknown declaration", identity.New(), start.Add(time.Hour), 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := s.RetrieveSnapAsOf(context.Background(), test.id, test.at)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got %v, want error", got.DecRef)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if got.DecRef.RN != test.want {
+				t.Errorf("got revision %v, want %v", got.DecRef.RN, test.want)
+			}
+		})
+	}
+}
+
+func TestRetrieveRevs(t *testing.T) {
+	decID, rns, s := storedHistory(time.Now())
+	tests := []struct {
+		name string
+		id   identity.ADT
+		want []revnum.ADT
+		err  bool
+	}{
+		{"all revisions", decID, rns, false},
+		{"unknown declaration", identity.New(), nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := s.RetrieveRevs(context.Background(), test.id)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			gotRNs := make([]revnum.ADT, 0, len(got))
+			for _, rev := range got {
+				gotRNs = append(gotRNs, rev.DecRef.RN)
+			}
+			if !slices.Equal(gotRNs, test.want) {
+				t.Errorf("got %v, want %v", gotRNs, test.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	decID, rns, s := storedHistory(time.Now())
+	chnlPHs := func(bss []procbind.BindSpec) []symbol.ADT {
+		phs := make([]symbol.ADT, 0, len(bss))
+		for _, bs := range bss {
+			phs = append(phs, bs.ChnlPH)
+		}
+		return phs
+	}
+	tests := []struct {
+		name    string
+		from    revnum.ADT
+		to      revnum.ADT
+		pots    [2]int64
+		removed []symbol.ADT
+		added   []symbol.ADT
+		changed []symbol.ADT
+		dropped []symbol.ADT
+		err     bool
+	}{
+		{"client added", rns[0], rns[1], [2]int64{0, 2}, nil, []symbol.ADT{"x"}, []symbol.ADT{}, []symbol.ADT{}, false},
+		{"client retyped", rns[1], rns[2], [2]int64{2, 2}, []symbol.ADT{"n"}, []symbol.ADT{}, []symbol.ADT{"x"}, []symbol.ADT{}, false},
+		{"client removed", rns[2], rns[0], [2]int64{2, 0}, nil, []symbol.ADT{}, []symbol.ADT{}, []symbol.ADT{"x"}, false},
+		{"unknown from", revnum.Next(rns[2]), rns[2], [2]int64{}, nil, nil, nil, nil, true},
+		{"unknown to", rns[0], revnum.Next(rns[2]), [2]int64{}, nil, nil, nil, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := s.Compare(context.Background(), decID, test.from, test.to)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if got.FromRef.RN != test.from || got.ToRef.RN != test.to {
+				t.Errorf("got %v..%v, want %v..%v", got.FromRef.RN, got.ToRef.RN, test.from, test.to)
+			}
+			if got.FromPot != test.pots[0] || got.ToPot != test.pots[1] {
+				t.Errorf("got pot %v..%v, want %v..%v", got.FromPot, got.ToPot, test.pots[0], test.pots[1])
+			}
+			if !slices.Equal(got.IdxVarsRemoved, test.removed) {
+				t.Errorf("got idx vars removed %v, want %v", got.IdxVarsRemoved, test.removed)
+			}
+			if !slices.Equal(chnlPHs(got.BindsAdded), test.added) {
+				t.Errorf("got binds added %v, want %v", chnlPHs(got.BindsAdded), test.added)
+			}
+			if !slices.Equal(chnlPHs(got.BindsChanged), test.changed) {
+				t.Errorf("got binds changed %v, want %v", chnlPHs(got.BindsChanged), test.changed)
+			}
+			if !slices.Equal(chnlPHs(got.BindsRemoved), test.dropped) {
+				t.Errorf("got binds removed %v, want %v", chnlPHs(got.BindsRemoved), test.dropped)
+			}
+		})
+	}
+}
@@ -9,7 +9,7 @@ import (
 var Module = fx.Module("adt/procdec",
 	fx.Provide(
 		fx.Annotate(newService, fx.As(new(API))),
-		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+		newDAO,
 	),
 	fx.Provide(
 		fx.Private,
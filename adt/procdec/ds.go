@@ -1,6 +1,9 @@
 package procdec
 
 import (
+	"log/slog"
+	"time"
+
 	"orglang/go-runtime/lib/db"
 
 	"orglang/go-runtime/adt/identity"
@@ -12,15 +15,31 @@ type Repo interface {
 	InsertRec(db.Source, DecRec) error
 	SelectRefs(db.Source) ([]DecRef, error)
 	SelectSnap(db.Source, DecRef) (DecSnap, error)
+	SelectSnapByRN(db.Source, DecRef) (DecSnap, error)
+	SelectRevs(db.Source, identity.ADT) ([]RevRec, error)
 	SelectRecs(db.Source, []identity.ADT) ([]DecRec, error)
 	SelectEnv(db.Source, []identity.ADT) (map[identity.ADT]DecRec, error)
 }
 
+// storage adapter is chosen by the operator
+func newDAO(l *slog.Logger, o db.Operator) Repo {
+	switch o.(type) {
+	case *db.OperatorMem:
+		return newMemDAO(l)
+	case *db.OperatorSqlite:
+		return newSqliteDAO(l)
+	default:
+		return newPgxDAO(l)
+	}
+}
+
 type decRefDS = uniqref.Data
 
 type decRecDS struct {
 	ID         string                `db:"dec_id"`
 	RN         int64                 `db:"dec_rn"`
+	IdxVars    []string              `db:"idx_vars"`
+	Pot        int64                 `db:"pot"`
 	ClientBSs  []procbind.BindSpecDS `db:"ys"`
 	ProviderBS procbind.BindSpecDS   `db:"x"`
 }
@@ -28,6 +47,14 @@ type decRecDS struct {
 type decSnapDS struct {
 	ID         string                `db:"id"`
 	RN         int64                 `db:"rn"`
+	IdxVars    []string              `db:"idx_vars"`
+	Pot        int64                 `db:"pot"`
 	ClientBSs  []procbind.BindSpecDS `db:"ys"`
 	ProviderBS procbind.BindSpecDS   `db:"x"`
 }
+
+type revRecDS struct {
+	ID    string    `db:"dec_id"`
+	RN    int64     `db:"dec_rn"`
+	RevAt time.Time `db:"rev_at"`
+}
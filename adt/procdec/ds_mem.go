@@ -0,0 +1,153 @@
+package procdec
+
+import (
+	"cmp"
+	"log/slog"
+	"reflect"
+	"slices"
+	"time"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/typedef"
+	"orglang/go-runtime/adt/uniqref"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(l *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{l.With(name)}
+}
+
+func (dao *memDAO) InsertRec(source db.Source, rec DecRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("decRef", rec.DecRef)
+	dto, err := DataFromDecRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	db.InsertMem(ds, procDecs, dto)
+	db.InsertMem(ds, decRevs, revRecDS{ID: dto.ID, RN: dto.RN, RevAt: time.Now()})
+	// for type change impact assessment
+	ref := uniqref.Data{ID: dto.ID, RN: dto.RN}
+	db.InsertMem(ds, typedef.DecUsesMem, typedef.UseMem{Ref: ref, TypeQN: dto.ProviderBS.TypeQN})
+	for _, ce := range dto.ClientBSs {
+		db.InsertMem(ds, typedef.DecUsesMem, typedef.UseMem{Ref: ref, TypeQN: ce.TypeQN})
+	}
+	return nil
+}
+
+func (dao *memDAO) SelectSnap(source db.Source, ref DecRef) (DecSnap, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	idAttr := slog.Any("id", ref)
+	dto, err := selectLatest(ds, ref.ID.String())
+	if err != nil {
+		dao.log.Error("entity selection failed", idAttr)
+		return DecSnap{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entitiy selection succeed", slog.Any("dto", dto))
+	return DataToDecSnap(decSnapDS(dto))
+}
+
+func (dao *memDAO) SelectSnapByRN(source db.Source, ref DecRef) (DecSnap, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("decRef", ref)
+	decID, decRN := ref.ID.String(), revnum.ConvertToInt(ref.RN)
+	dto, err := db.SelectLatestMem(ds, procDecs,
+		func(dto decRecDS) bool { return dto.ID == decID && dto.RN == decRN },
+		func(dto decRecDS) int64 { return dto.RN },
+	)
+	if err != nil {
+		dao.log.Error("entity selection failed", refAttr)
+		return DecSnap{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entitiy selection succeed", slog.Any("dto", dto))
+	return DataToDecSnap(decSnapDS(dto))
+}
+
+func (dao *memDAO) SelectRevs(source db.Source, decID identity.ADT) ([]RevRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	dtos := []revRecDS{}
+	for _, dto := range db.SelectMem[revRecDS](ds, decRevs) {
+		if dto.ID == decID.String() {
+			dtos = append(dtos, dto)
+		}
+	}
+	slices.SortFunc(dtos, func(a, b revRecDS) int { return cmp.Compare(a.RN, b.RN) })
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToRevRecs(dtos)
+}
+
+func (dao *memDAO) SelectEnv(source db.Source, ids []identity.ADT) (map[identity.ADT]DecRec, error) {
+	decs, err := dao.SelectRecs(source, ids)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[identity.ADT]DecRec, len(decs))
+	for _, dec := range decs {
+		env[dec.DecRef.ID] = dec
+	}
+	return env, nil
+}
+
+func (dao *memDAO) SelectRecs(source db.Source, ids []identity.ADT) ([]DecRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	dtos := make([]decRecDS, 0, len(ids))
+	for _, rid := range ids {
+		if rid.IsEmpty() {
+			return nil, identity.ErrEmpty
+		}
+		dto, err := selectLatest(ds, rid.String())
+		if err != nil {
+			dao.log.Error("entity selection failed", slog.Any("id", rid))
+			return nil, err
+		}
+		dtos = append(dtos, dto)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToDecRecs(dtos)
+}
+
+func (dao *memDAO) SelectRefs(source db.Source) ([]DecRef, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	latest := db.LatestMem(db.SelectMem[decRecDS](ds, procDecs),
+		func(dto decRecDS) string { return dto.ID },
+		func(dto decRecDS) int64 { return dto.RN },
+	)
+	dtos := make([]decRefDS, 0, len(latest))
+	for _, dto := range latest {
+		dtos = append(dtos, decRefDS{ID: dto.ID, RN: dto.RN})
+	}
+	return uniqref.DataToADTs(dtos)
+}
+
+func selectLatest(ds db.SourceMem, decID string) (decRecDS, error) {
+	return db.SelectLatestMem(ds, procDecs,
+		func(dto decRecDS) bool { return dto.ID == decID },
+		func(dto decRecDS) int64 { return dto.RN },
+	)
+}
+
+// the execution store needs the potential of a given revision
+func SelectPotMem(ds db.SourceMem, decID string, decRN int64) int64 {
+	for _, dto := range db.SelectMem[decRecDS](ds, procDecs) {
+		if dto.ID == decID && dto.RN == decRN {
+			return dto.Pot
+		}
+	}
+	return 0
+}
+
+const (
+	procDecs = "proc_decs"
+	// revRecDS rows
+	decRevs = "proc_dec_revs"
+)
@@ -12,6 +12,7 @@ import (
 	"orglang/go-runtime/lib/lf"
 
 	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/revnum"
 	"orglang/go-runtime/adt/uniqref"
 )
 
@@ -40,13 +41,15 @@ func (dao *pgxDAO) InsertRec(source db.Source, rec DecRec) error {
 	}
 	insertRoot := `
 		insert into proc_decs (
-			dec_id, dec_rn, title
+			dec_id, dec_rn, title, idx_vars, pot
 		) VALUES (
-			@dec_id, @dec_rn, @title
+			@dec_id, @dec_rn, @title, @idx_vars, @pot
 		)`
 	rootArgs := pgx.NamedArgs{
-		"dec_id": dto.ID,
-		"dec_rn": dto.RN,
+		"dec_id":   dto.ID,
+		"dec_rn":   dto.RN,
+		"idx_vars": dto.IdxVars,
+		"pot":      dto.Pot,
 	}
 	_, err = ds.Conn.Exec(ds.Ctx, insertRoot, rootArgs)
 	if err != nil {
@@ -55,16 +58,18 @@ func (dao *pgxDAO) InsertRec(source db.Source, rec DecRec) error {
 	}
 	insertPE := `
 		insert into dec_pes (
-			dec_id, from_rn, to_rn, chnl_ph, type_qn
+			dec_id, from_rn, to_rn, chnl_ph, type_qn, type_args, idx_args
 		) VALUES (
-			@dec_id, @from_rn, @to_rn, @chnl_ph, @type_qn
+			@dec_id, @from_rn, @to_rn, @chnl_ph, @type_qn, @type_args, @idx_args
 		)`
 	peArgs := pgx.NamedArgs{
-		"dec_id":  dto.ID,
-		"from_rn": dto.RN,
-		"to_rn":   math.MaxInt64,
-		"chnl_ph": dto.ProviderBS.ChnlPH,
-		"type_qn": dto.ProviderBS.TypeQN,
+		"dec_id":    dto.ID,
+		"from_rn":   dto.RN,
+		"to_rn":     math.MaxInt64,
+		"chnl_ph":   dto.ProviderBS.ChnlPH,
+		"type_qn":   dto.ProviderBS.TypeQN,
+		"type_args": dto.ProviderBS.TypeArgs,
+		"idx_args":  dto.ProviderBS.IdxArgs,
 	}
 	_, err = ds.Conn.Exec(ds.Ctx, insertPE, peArgs)
 	if err != nil {
@@ -73,18 +78,20 @@ func (dao *pgxDAO) InsertRec(source db.Source, rec DecRec) error {
 	}
 	insertCE := `
 		insert into dec_ces (
-			dec_id, from_rn, to_rn, chnl_ph, type_qn
+			dec_id, from_rn, to_rn, chnl_ph, type_qn, type_args, idx_args
 		) VALUES (
-			@dec_id, @from_rn, @to_rn, @chnl_ph, @type_qn
+			@dec_id, @from_rn, @to_rn, @chnl_ph, @type_qn, @type_args, @idx_args
 		)`
 	batch := pgx.Batch{}
 	for _, ce := range dto.ClientBSs {
 		args := pgx.NamedArgs{
-			"dec_id":  dto.ID,
-			"from_rn": dto.RN,
-			"to_rn":   math.MaxInt64,
-			"chnl_ph": ce.ChnlPH,
-			"type_qn": ce.TypeQN,
+			"dec_id":    dto.ID,
+			"from_rn":   dto.RN,
+			"to_rn":     math.MaxInt64,
+			"chnl_ph":   ce.ChnlPH,
+			"type_qn":   ce.TypeQN,
+			"type_args": ce.TypeArgs,
+			"idx_args":  ce.IdxArgs,
 		}
 		batch.Queue(insertCE, args)
 	}
@@ -113,13 +120,49 @@ func (dao *pgxDAO) SelectSnap(source db.Source, ref DecRef) (DecSnap, error) {
 		return DecSnap{}, err
 	}
 	defer rows.Close()
-	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[decSnapDS])
+	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[decRecDS])
 	if err != nil {
 		dao.log.Error("row collection failed", idAttr)
 		return DecSnap{}, err
 	}
 	dao.log.Log(ds.Ctx, lf.LevelTrace, "entitiy selection succeed", slog.Any("dto", dto))
-	return DataToDecSnap(dto)
+	return DataToDecSnap(decSnapDS(dto))
+}
+
+func (dao *pgxDAO) SelectSnapByRN(source db.Source, ref DecRef) (DecSnap, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("decRef", ref)
+	rows, err := ds.Conn.Query(ds.Ctx, selectByRN, ref.ID.String(), revnum.ConvertToInt(ref.RN))
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", selectByRN))
+		return DecSnap{}, err
+	}
+	defer rows.Close()
+	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[decRecDS])
+	if err != nil {
+		dao.log.Error("row collection failed", refAttr)
+		return DecSnap{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entitiy selection succeed", slog.Any("dto", dto))
+	return DataToDecSnap(decSnapDS(dto))
+}
+
+func (dao *pgxDAO) SelectRevs(source db.Source, decID identity.ADT) ([]RevRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("decID", decID)
+	rows, err := ds.Conn.Query(ds.Ctx, selectRevs, decID.String())
+	if err != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("q", selectRevs))
+		return nil, err
+	}
+	defer rows.Close()
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[revRecDS])
+	if err != nil {
+		dao.log.Error("rows collection failed", idAttr)
+		return nil, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToRevRecs(dtos)
 }
 
 func (dao *pgxDAO) SelectEnv(source db.Source, ids []identity.ADT) (map[identity.ADT]DecRec, error) {
@@ -172,13 +215,9 @@ func (dao *pgxDAO) SelectRecs(source db.Source, ids []identity.ADT) (_ []DecRec,
 
 func (dao *pgxDAO) SelectRefs(source db.Source) ([]DecRef, error) {
 	ds := db.MustConform[db.SourcePgx](source)
-	query := `
-		select
-			dec_id, rev, title
-		from dec_roots`
-	rows, err := ds.Conn.Query(ds.Ctx, query)
+	rows, err := ds.Conn.Query(ds.Ctx, selectRefs)
 	if err != nil {
-		dao.log.Error("query execution failed", slog.String("q", query))
+		dao.log.Error("query execution failed", slog.String("q", selectRefs))
 		return nil, err
 	}
 	defer rows.Close()
@@ -191,22 +230,58 @@ func (dao *pgxDAO) SelectRefs(source db.Source) ([]DecRef, error) {
 }
 
 const (
-	selectById = `
+	selectRefs = `
+		select
+			dec_id as id, max(dec_rn) as rn
+		from proc_decs
+		group by dec_id`
+
+	// binds are aggregated into jsonb shaped as procbind.BindSpecDS
+	selectSnap = `
+		select
+			d.dec_id,
+			d.dec_rn,
+			d.idx_vars,
+			coalesce(d.pot, 0) as pot,
+			(
+				select jsonb_build_object(
+					'chnl_ph', pe.chnl_ph,
+					'type_qn', pe.type_qn::text,
+					'type_args', pe.type_args,
+					'idx_args', pe.idx_args
+				)
+				from dec_pes pe
+				where pe.dec_id = d.dec_id
+					and pe.from_rn <= d.dec_rn
+					and pe.to_rn > d.dec_rn
+			) as x,
+			(
+				select coalesce(jsonb_agg(jsonb_build_object(
+					'chnl_ph', ce.chnl_ph,
+					'type_qn', ce.type_qn::text,
+					'type_args', ce.type_args,
+					'idx_args', ce.idx_args
+				)), '[]'::jsonb)
+				from dec_ces ce
+				where ce.dec_id = d.dec_id
+					and ce.from_rn <= d.dec_rn
+					and ce.to_rn > d.dec_rn
+			) as ys
+		from proc_decs d`
+
+	selectById = selectSnap + `
+		where d.dec_id = $1
+		order by d.dec_rn desc
+		limit 1`
+
+	selectByRN = selectSnap + `
+		where d.dec_id = $1
+			and d.dec_rn = $2`
+
+	selectRevs = `
 		select
-			sr.dec_id,
-			sr.rev,
-			(array_agg(sr.title))[1] as title,
-			(jsonb_agg(to_jsonb((select ep from (select sp.chnl_key, sp.type_qn) ep))))[0] as pe,
-			jsonb_agg(to_jsonb((select ep from (select sc.chnl_key, sc.type_qn) ep))) filter (where sc.dec_id is not null) as ces
-		from dec_roots sr
-		left join dec_pes sp
-			on sp.dec_id = sr.dec_id
-			and sp.from_rn >= sr.rev
-			and sp.to_rn > sr.rev
-		left join dec_ces sc
-			on sc.dec_id = sr.dec_id
-			and sc.from_rn >= sr.rev
-			and sc.to_rn > sr.rev
-		where sr.dec_id = $1
-		group by sr.dec_id, sr.rev`
+			dec_id, dec_rn, rev_at
+		from proc_decs
+		where dec_id = $1
+		order by dec_rn`
 )
@@ -0,0 +1,280 @@
+package procdec
+
+import (
+	"log/slog"
+	"math"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/uniqref"
+)
+
+// Adapter
+type sqliteDAO struct {
+	log *slog.Logger
+}
+
+func newSqliteDAO(l *slog.Logger) *sqliteDAO {
+	name := slog.String("name", reflect.TypeFor[sqliteDAO]().Name())
+	return &sqliteDAO{l.With(name)}
+}
+
+func (dao *sqliteDAO) InsertRec(source db.Source, rec DecRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("decRef", rec.DecRef)
+	dto, err := DataFromDecRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	rootArgs := db.NamedArgsSqlite{
+		"dec_id":   dto.ID,
+		"dec_rn":   dto.RN,
+		"idx_vars": dto.IdxVars,
+		"pot":      dto.Pot,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertRootSqlite, rootArgs)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", insertRootSqlite))
+		return err
+	}
+	// binds of the previous revision are closed
+	closeArgs := db.NamedArgsSqlite{
+		"dec_id":  dto.ID,
+		"from_rn": dto.RN,
+		"to_rn":   math.MaxInt64,
+	}
+	for _, query := range []string{closePEsSqlite, closeCEsSqlite} {
+		_, err = ds.Conn.Exec(ds.Ctx, query, closeArgs)
+		if err != nil {
+			dao.log.Error("query execution failed", refAttr, slog.String("q", query))
+			return err
+		}
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertPESqlite, bindArgsSqlite(dto, dto.ProviderBS))
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", insertPESqlite))
+		return err
+	}
+	for _, ce := range dto.ClientBSs {
+		_, err = ds.Conn.Exec(ds.Ctx, insertCESqlite, bindArgsSqlite(dto, ce))
+		if err != nil {
+			dao.log.Error("query execution failed", refAttr, slog.String("q", insertCESqlite))
+			return err
+		}
+	}
+	return nil
+}
+
+func (dao *sqliteDAO) SelectSnap(source db.Source, ref DecRef) (DecSnap, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	idAttr := slog.Any("id", ref)
+	rows, err := ds.Conn.Query(ds.Ctx, selectByIDSqlite, db.NamedArgsSqlite{"dec_id": ref.ID.String()})
+	if err != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("q", selectByIDSqlite))
+		return DecSnap{}, err
+	}
+	dto, err := db.CollectOneRowSqlite[decRecDS](rows)
+	if err != nil {
+		dao.log.Error("row collection failed", idAttr)
+		return DecSnap{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entitiy selection succeed", slog.Any("dto", dto))
+	return DataToDecSnap(decSnapDS(dto))
+}
+
+func (dao *sqliteDAO) SelectSnapByRN(source db.Source, ref DecRef) (DecSnap, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("decRef", ref)
+	args := db.NamedArgsSqlite{"dec_id": ref.ID.String(), "dec_rn": revnum.ConvertToInt(ref.RN)}
+	rows, err := ds.Conn.Query(ds.Ctx, selectByRNSqlite, args)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", selectByRNSqlite))
+		return DecSnap{}, err
+	}
+	dto, err := db.CollectOneRowSqlite[decRecDS](rows)
+	if err != nil {
+		dao.log.Error("row collection failed", refAttr)
+		return DecSnap{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entitiy selection succeed", slog.Any("dto", dto))
+	return DataToDecSnap(decSnapDS(dto))
+}
+
+func (dao *sqliteDAO) SelectRevs(source db.Source, decID identity.ADT) ([]RevRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	idAttr := slog.Any("decID", decID)
+	rows, err := ds.Conn.Query(ds.Ctx, selectRevsSqlite, db.NamedArgsSqlite{"dec_id": decID.String()})
+	if err != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("q", selectRevsSqlite))
+		return nil, err
+	}
+	dtos, err := db.CollectRowsSqlite[revRecDS](rows)
+	if err != nil {
+		dao.log.Error("rows collection failed", idAttr)
+		return nil, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToRevRecs(dtos)
+}
+
+func (dao *sqliteDAO) SelectEnv(source db.Source, ids []identity.ADT) (map[identity.ADT]DecRec, error) {
+	decs, err := dao.SelectRecs(source, ids)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[identity.ADT]DecRec, len(decs))
+	for _, dec := range decs {
+		env[dec.DecRef.ID] = dec
+	}
+	return env, nil
+}
+
+func (dao *sqliteDAO) SelectRecs(source db.Source, ids []identity.ADT) ([]DecRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	dtos := make([]decRecDS, 0, len(ids))
+	for _, rid := range ids {
+		if rid.IsEmpty() {
+			return nil, identity.ErrEmpty
+		}
+		idAttr := slog.Any("id", rid)
+		rows, err := ds.Conn.Query(ds.Ctx, selectByIDSqlite, db.NamedArgsSqlite{"dec_id": rid.String()})
+		if err != nil {
+			dao.log.Error("query execution failed", idAttr, slog.String("q", selectByIDSqlite))
+			return nil, err
+		}
+		dto, err := db.CollectOneRowSqlite[decRecDS](rows)
+		if err != nil {
+			dao.log.Error("row collection failed", idAttr)
+			return nil, err
+		}
+		dtos = append(dtos, dto)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToDecRecs(dtos)
+}
+
+func (dao *sqliteDAO) SelectRefs(source db.Source) ([]DecRef, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	rows, err := ds.Conn.Query(ds.Ctx, selectRefsSqlite, nil)
+	if err != nil {
+		dao.log.Error("query execution failed", slog.String("q", selectRefsSqlite))
+		return nil, err
+	}
+	dtos, err := db.CollectRowsSqlite[decRefDS](rows)
+	if err != nil {
+		dao.log.Error("rows collection failed")
+		return nil, err
+	}
+	return uniqref.DataToADTs(dtos)
+}
+
+func bindArgsSqlite(dto decRecDS, bs procbind.BindSpecDS) db.NamedArgsSqlite {
+	return db.NamedArgsSqlite{
+		"dec_id":    dto.ID,
+		"from_rn":   dto.RN,
+		"to_rn":     math.MaxInt64,
+		"chnl_ph":   bs.ChnlPH,
+		"type_qn":   bs.TypeQN,
+		"type_args": bs.TypeArgs,
+		"idx_args":  bs.IdxArgs,
+	}
+}
+
+const (
+	// the revision time is set here, see 0002_revisions.sql
+	insertRootSqlite = `
+		insert into proc_decs (
+			dec_id, dec_rn, idx_vars, pot, rev_at
+		) values (
+			:dec_id, :dec_rn, :idx_vars, :pot,
+			strftime('%Y-%m-%d %H:%M:%f000', 'now')
+		)`
+
+	insertPESqlite = `
+		insert into dec_pes (
+			dec_id, from_rn, to_rn, chnl_ph, type_qn, type_args, idx_args
+		) values (
+			:dec_id, :from_rn, :to_rn, :chnl_ph, :type_qn, :type_args, :idx_args
+		)`
+
+	insertCESqlite = `
+		insert into dec_ces (
+			dec_id, from_rn, to_rn, chnl_ph, type_qn, type_args, idx_args
+		) values (
+			:dec_id, :from_rn, :to_rn, :chnl_ph, :type_qn, :type_args, :idx_args
+		)`
+
+	closePEsSqlite = `
+		update dec_pes
+		set to_rn = :from_rn
+		where dec_id = :dec_id
+			and to_rn = :to_rn`
+
+	closeCEsSqlite = `
+		update dec_ces
+		set to_rn = :from_rn
+		where dec_id = :dec_id
+			and to_rn = :to_rn`
+
+	selectRefsSqlite = `
+		select
+			dec_id as id, max(dec_rn) as rn
+		from proc_decs
+		group by dec_id
+		order by dec_id`
+
+	// binds are aggregated into json shaped as procbind.BindSpecDS
+	selectSnapSqlite = `
+		select
+			d.dec_id,
+			d.dec_rn,
+			d.idx_vars,
+			coalesce(d.pot, 0) as pot,
+			(
+				select json_object(
+					'chnl_ph', pe.chnl_ph,
+					'type_qn', pe.type_qn,
+					'type_args', json(pe.type_args),
+					'idx_args', json(pe.idx_args)
+				)
+				from dec_pes pe
+				where pe.dec_id = d.dec_id
+					and pe.from_rn <= d.dec_rn
+					and pe.to_rn > d.dec_rn
+			) as x,
+			(
+				select json_group_array(json_object(
+					'chnl_ph', ce.chnl_ph,
+					'type_qn', ce.type_qn,
+					'type_args', json(ce.type_args),
+					'idx_args', json(ce.idx_args)
+				))
+				from dec_ces ce
+				where ce.dec_id = d.dec_id
+					and ce.from_rn <= d.dec_rn
+					and ce.to_rn > d.dec_rn
+			) as ys
+		from proc_decs d`
+
+	selectByIDSqlite = selectSnapSqlite + `
+		where d.dec_id = :dec_id
+		order by d.dec_rn desc
+		limit 1`
+
+	selectByRNSqlite = selectSnapSqlite + `
+		where d.dec_id = :dec_id
+			and d.dec_rn = :dec_rn`
+
+	selectRevsSqlite = `
+		select
+			dec_id, dec_rn, rev_at
+		from proc_decs
+		where dec_id = :dec_id
+		order by dec_rn`
+)
@@ -3,8 +3,10 @@ package procdec
 import (
 	"log/slog"
 	"net/http"
+	"orglang/go-runtime/adt/revnum"
 	"orglang/go-runtime/adt/uniqref"
 	"reflect"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
@@ -25,6 +27,8 @@ func newEchoController(a API, l *slog.Logger) *echoController {
 func cfgEchoController(e *echo.Echo, h *echoController) error {
 	e.POST("/api/v1/decs", h.PostSpec)
 	e.GET("/api/v1/decs/:id", h.GetSnap)
+	e.GET("/api/v1/decs/:id/revisions", h.GetRevs)
+	e.GET("/api/v1/decs/:id/diff", h.GetDiff)
 	return nil
 }
 
@@ -45,7 +49,7 @@ func (h *echoController) PostSpec(c echo.Context) error {
 		h.log.Error("conversion failed", slog.Any("dto", dto))
 		return conversionErr
 	}
-	ref, creationErr := h.api.Create(spec)
+	ref, creationErr := h.api.Create(c.Request().Context(), spec)
 	if creationErr != nil {
 		return creationErr
 	}
@@ -64,9 +68,77 @@ func (h *echoController) GetSnap(c echo.Context) error {
 		h.log.Error("conversion failed", slog.Any("dto", dto))
 		return conversionErr
 	}
-	snap, retrievalErr := h.api.RetrieveSnap(ref)
+	// the latest revision is returned without parameters
+	var rn int64
+	var at time.Time
+	paramsErr := echo.QueryParamsBinder(c).
+		Int64("rn", &rn).
+		Time("at", &at, time.RFC3339).
+		BindError()
+	if paramsErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", dto))
+		return paramsErr
+	}
+	ctx := c.Request().Context()
+	var snap DecSnap
+	var retrievalErr error
+	switch {
+	case rn != 0:
+		snap, retrievalErr = h.api.RetrieveSnapByRN(ctx, DecRef{ID: ref.ID, RN: revnum.ConvertFromInt(rn)})
+	case !at.IsZero():
+		snap, retrievalErr = h.api.RetrieveSnapAsOf(ctx, ref.ID, at)
+	default:
+		snap, retrievalErr = h.api.RetrieveSnap(ctx, ref)
+	}
 	if retrievalErr != nil {
 		return retrievalErr
 	}
 	return c.JSON(http.StatusOK, MsgFromDecSnap(snap))
 }
+
+func (h *echoController) GetRevs(c echo.Context) error {
+	var dto procdec.DecRef
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	ref, conversionErr := uniqref.MsgToADT(dto)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	revs, retrievalErr := h.api.RetrieveRevs(c.Request().Context(), ref.ID)
+	if retrievalErr != nil {
+		return retrievalErr
+	}
+	return c.JSON(http.StatusOK, ViewFromRevRecs(revs))
+}
+
+func (h *echoController) GetDiff(c echo.Context) error {
+	var dto procdec.DecRef
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	ref, conversionErr := uniqref.MsgToADT(dto)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	var fromRN, toRN int64
+	paramsErr := echo.QueryParamsBinder(c).
+		MustInt64("from", &fromRN).
+		MustInt64("to", &toRN).
+		BindError()
+	if paramsErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", dto))
+		return paramsErr
+	}
+	diff, comparisonErr := h.api.Compare(c.Request().Context(), ref.ID, revnum.ConvertFromInt(fromRN), revnum.ConvertFromInt(toRN))
+	if comparisonErr != nil {
+		return comparisonErr
+	}
+	return c.JSON(http.StatusOK, ViewFromDecDiff(diff))
+}
@@ -6,6 +6,7 @@ import (
 
 // goverter:variables
 // goverter:output:format assign-variable
+// goverter:extend orglang/go-runtime/adt/symbol:Convert.*
 // goverter:extend orglang/go-runtime/adt/uniqsym:Convert.*
 // goverter:extend orglang/go-runtime/adt/procbind:Msg.*
 // goverter:extend orglang/go-runtime/adt/typedef:Msg.*
@@ -19,15 +20,21 @@ var (
 
 // goverter:variables
 // goverter:output:format assign-variable
+// goverter:extend orglang/go-runtime/adt/symbol:Convert.*
 // goverter:extend orglang/go-runtime/adt/uniqsym:Convert.*
 // goverter:extend orglang/go-runtime/adt/uniqref:Msg.*
+// goverter:extend orglang/go-runtime/adt/procbind:Msg.*
 var (
 	ViewFromDecSnap func(DecSnap) DecSnapVP
+	ViewFromRevRec  func(RevRec) RevVP
+	ViewFromRevRecs func([]RevRec) []RevVP
+	ViewFromDecDiff func(DecDiff) DecDiffVP
 )
 
 // goverter:variables
 // goverter:output:format assign-variable
 // goverter:extend orglang/go-runtime/adt/identity:Convert.*
+// goverter:extend orglang/go-runtime/adt/symbol:Convert.*
 // goverter:extend orglang/go-runtime/adt/procbind:Data.*
 var (
 	// goverter:map . DecRef
@@ -42,4 +49,7 @@ var (
 	DataFromDecSnap  func(DecSnap) (decSnapDS, error)
 	DataToDecSnaps   func([]decSnapDS) ([]DecSnap, error)
 	DataFromDecSnaps func([]DecSnap) ([]decSnapDS, error)
+	// goverter:map . DecRef
+	DataToRevRec  func(revRecDS) (RevRec, error)
+	DataToRevRecs func([]revRecDS) ([]RevRec, error)
 )
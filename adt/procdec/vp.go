@@ -1,6 +1,11 @@
 package procdec
 
-import "github.com/orglang/go-sdk/adt/uniqref"
+import (
+	"time"
+
+	"github.com/orglang/go-sdk/adt/procbind"
+	"github.com/orglang/go-sdk/adt/uniqref"
+)
 
 type DecRefVP = uniqref.Msg
 
@@ -11,3 +16,21 @@ type DecSpecVP struct {
 type DecSnapVP struct {
 	DecRef DecRefVP `json:"ref"`
 }
+
+type RevVP struct {
+	DecRef DecRefVP  `json:"ref"`
+	RevAt  time.Time `json:"rev_at"`
+}
+
+// matching parts of revisions are omitted
+type DecDiffVP struct {
+	FromRef        DecRefVP            `json:"from"`
+	ToRef          DecRefVP            `json:"to"`
+	IdxVarsAdded   []string            `json:"idx_vars_added,omitempty"`
+	IdxVarsRemoved []string            `json:"idx_vars_removed,omitempty"`
+	FromPot        int64               `json:"from_pot"`
+	ToPot          int64               `json:"to_pot"`
+	BindsAdded     []procbind.BindSpec `json:"binds_added,omitempty"`
+	BindsRemoved   []procbind.BindSpec `json:"binds_removed,omitempty"`
+	BindsChanged   []procbind.BindSpec `json:"binds_changed,omitempty"`
+}
@@ -51,7 +51,7 @@ func (p *echoPresenter) PostSpec(c echo.Context) error {
 		p.log.Error("conversion failed", slog.Any("dto", dto))
 		return conversionErr
 	}
-	ref, inceptionErr := p.api.Incept(qn)
+	ref, inceptionErr := p.api.Incept(ctx, qn)
 	if inceptionErr != nil {
 		return inceptionErr
 	}
@@ -65,7 +65,7 @@ func (p *echoPresenter) PostSpec(c echo.Context) error {
 }
 
 func (p *echoPresenter) GetRefs(c echo.Context) error {
-	refs, retrievalErr := p.api.RetreiveRefs()
+	refs, retrievalErr := p.api.RetreiveRefs(c.Request().Context())
 	if retrievalErr != nil {
 		return retrievalErr
 	}
@@ -96,7 +96,7 @@ func (p *echoPresenter) GetSnap(c echo.Context) error {
 		p.log.Error("conversion failed", slog.Any("dto", dto))
 		return conversionErr
 	}
-	snap, retrievalErr := p.api.RetrieveSnap(ref)
+	snap, retrievalErr := p.api.RetrieveSnap(ctx, ref)
 	if retrievalErr != nil {
 		return retrievalErr
 	}
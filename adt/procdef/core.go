@@ -1,21 +1,35 @@
 package procdef
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
 
 	"orglang/go-runtime/lib/db"
 
+	"orglang/go-runtime/adt/arithexp"
 	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/procdec"
 	"orglang/go-runtime/adt/procexp"
+	"orglang/go-runtime/adt/revnum"
 	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/syndec"
+	"orglang/go-runtime/adt/typedef"
+	"orglang/go-runtime/adt/typeexp"
 	"orglang/go-runtime/adt/uniqref"
 	"orglang/go-runtime/adt/uniqsym"
 )
 
 type API interface {
-	Create(DefSpec) (DefRef, error)
-	Retrieve(identity.ADT) (DefRec, error)
+	Create(context.Context, DefSpec) (DefRef, error)
+	Retrieve(context.Context, identity.ADT) (DefRec, error)
 }
 
 type DefSpec struct {
@@ -25,16 +39,64 @@ type DefSpec struct {
 
 type DefRef = uniqref.ADT
 
+// definition shares identity with its declaration
 type DefRec struct {
-	Ref DefRef
+	DefRef DefRef
+	ProcES procexp.ExpSpec
 }
 
 type DefSnap struct {
-	Ref DefRef
+	DefRef DefRef
+	ProcES procexp.ExpSpec
+}
+
+// static checking environment
+type Env struct {
+	SynDecs  map[uniqsym.ADT]syndec.DecRec
+	ProcDecs map[identity.ADT]procdec.DecRec
+	TypeDefs map[uniqsym.ADT]typedef.DefRec
+	TypeExps map[identity.ADT]typeexp.ExpRec
+	TypeEnv  typeexp.Env
+}
+
+type CheckErrorKind uint8
+
+const (
+	InvalidExp CheckErrorKind = iota + 1
+	TypeMismatch
+	MissingChnl
+	CtxMismatch
+	LabelMismatch
+	PotInsufficient
+)
+
+// checking error with the path to the subexpression
+type CheckError struct {
+	K      CheckErrorKind
+	Path   []string
+	ChnlPH symbol.ADT
+	Err    error
 }
 
+func (e CheckError) Error() string {
+	return fmt.Sprintf("%v: %v", strings.Join(e.Path, "/"), e.Err)
+}
+
+func (e CheckError) Unwrap() error { return e.Err }
+
+var (
+	errMissingChnl     = errors.New("channel missing")
+	errCtxMismatch     = errors.New("context mismatch")
+	errLabelMismatch   = errors.New("label mismatch")
+	errPotInsufficient = errors.New("potential insufficient")
+)
+
 type service struct {
 	procDefs Repo
+	procDecs procdec.Repo
+	typeDefs typedef.Repo
+	typeExps typeexp.Repo
+	synDecs  syndec.Repo
 	operator db.Operator
 	log      *slog.Logger
 }
@@ -45,33 +107,1319 @@ func newAPI() API {
 }
 
 func newService(
-	procs Repo,
+	procDefs Repo,
+	procDecs procdec.Repo,
+	typeDefs typedef.Repo,
+	typeExps typeexp.Repo,
+	synDecs syndec.Repo,
 	operator db.Operator,
 	l *slog.Logger,
 ) *service {
-	return &service{procs, operator, l}
+	name := slog.String("name", reflect.TypeFor[service]().Name())
+	return &service{procDefs, procDecs, typeDefs, typeExps, synDecs, operator, l.With(name)}
+}
+
+func (s *service) Create(ctx context.Context, spec DefSpec) (_ DefRef, err error) {
+	qnAttr := slog.Any("procQN", spec.ProcQN)
+	s.log.Debug("creation started", qnAttr)
+	procEnv, procCtx, err := s.selectEnv(ctx, spec)
+	if err != nil {
+		s.log.Error("creation failed", qnAttr)
+		return DefRef{}, err
+	}
+	// type checking
+	err = CheckExp(procEnv, procCtx, spec.ProcES)
+	if err != nil {
+		s.log.Error("creation failed", qnAttr, slog.Any("reason", err))
+		return DefRef{}, err
+	}
+	var newRec DefRec
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		synRec, err := s.synDecs.SelectRecByQN(ds, spec.ProcQN)
+		if err != nil {
+			return err
+		}
+		newRec = DefRec{
+			DefRef: DefRef{ID: synRec.DecID, RN: revnum.New()},
+			ProcES: spec.ProcES,
+		}
+		return s.procDefs.InsertRec(ds, newRec)
+	})
+	if err != nil {
+		s.log.Error("creation failed", qnAttr)
+		return DefRef{}, err
+	}
+	s.log.Debug("creation succeed", qnAttr, slog.Any("defRef", newRec.DefRef))
+	return newRec.DefRef, nil
+}
+
+func (s *service) Retrieve(ctx context.Context, recID identity.ADT) (_ DefRec, err error) {
+	var rec DefRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		rec, err = s.procDefs.SelectRecByID(ds, recID)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", slog.Any("defID", recID))
+		return DefRec{}, err
+	}
+	return rec, nil
+}
+
+// env and ctx from the process signature
+func (s *service) selectEnv(ctx context.Context, spec DefSpec) (_ Env, _ typedef.Context, err error) {
+	procQNs := append(procexp.CollectEnv(spec.ProcES), spec.ProcQN)
+	var procEnv Env
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		procEnv.SynDecs, err = s.synDecs.SelectEnv(ds, procQNs)
+		if err != nil {
+			return err
+		}
+		procEnv.ProcDecs, err = s.procDecs.SelectEnv(ds, syndec.CollectEnv(maps.Values(procEnv.SynDecs)))
+		if err != nil {
+			return err
+		}
+		procEnv.TypeDefs, err = s.typeDefs.SelectEnv(ds, procdec.CollectEnv(maps.Values(procEnv.ProcDecs)))
+		if err != nil {
+			return err
+		}
+		procEnv.TypeExps, err = s.typeExps.SelectEnv(ds, typedef.CollectEnv(maps.Values(procEnv.TypeDefs)))
+		if err != nil {
+			return err
+		}
+		// type links are unfolded during checking, so the env is closed over them
+		for {
+			linkQNs := typedef.MissingLinks(maps.Values(procEnv.TypeExps), procEnv.TypeDefs)
+			if len(linkQNs) == 0 {
+				return nil
+			}
+			linkDefs, err := s.typeDefs.SelectEnv(ds, linkQNs)
+			if err != nil {
+				return err
+			}
+			linkExps, err := s.typeExps.SelectEnv(ds, typedef.CollectEnv(maps.Values(linkDefs)))
+			if err != nil {
+				return err
+			}
+			maps.Copy(procEnv.TypeDefs, linkDefs)
+			maps.Copy(procEnv.TypeExps, linkExps)
+		}
+	})
+	if err != nil {
+		return Env{}, typedef.Context{}, err
+	}
+	procEnv.TypeEnv = typedef.ConvertToEnv(procEnv.TypeDefs, procEnv.TypeExps)
+	procSD, ok := procEnv.SynDecs[spec.ProcQN]
+	if !ok {
+		return Env{}, typedef.Context{}, errMissingProc(spec.ProcQN)
+	}
+	procDR, ok := procEnv.ProcDecs[procSD.DecID]
+	if !ok {
+		return Env{}, typedef.Context{}, procdec.ErrRootMissingInEnv(procSD.DecID)
+	}
+	procCtx := typedef.Context{
+		Assets: make(map[symbol.ADT]typeexp.ExpRec, len(procDR.ClientBSs)),
+		Liabs:  make(map[symbol.ADT]typeexp.ExpRec, 1),
+		Pot:    procDR.Pot,
+	}
+	procCtx.Liabs[procDR.ProviderBS.ChnlPH], err = LookupType(procEnv, procDR.ProviderBS)
+	if err != nil {
+		return Env{}, typedef.Context{}, err
+	}
+	for _, bs := range procDR.ClientBSs {
+		procCtx.Assets[bs.ChnlPH], err = LookupType(procEnv, bs)
+		if err != nil {
+			return Env{}, typedef.Context{}, err
+		}
+	}
+	return procEnv, procCtx, nil
+}
+
+// Bind type: the definition exp with the arguments substituted.
+// Without arguments the stored exp itself is returned.
+func LookupType(procEnv Env, bs procbind.BindSpec) (typeexp.ExpRec, error) {
+	if len(bs.TypeArgs) == 0 && len(bs.IdxArgs) == 0 {
+		typeDR, ok := procEnv.TypeDefs[bs.TypeQN]
+		if !ok {
+			return nil, typedef.ErrSymMissingInEnv(bs.TypeQN)
+		}
+		typeER, ok := procEnv.TypeExps[typeDR.ExpID]
+		if !ok {
+			return nil, typedef.ErrMissingInEnv(typeDR.ExpID)
+		}
+		return typeER, nil
+	}
+	typeERs := make([]typeexp.ExpRec, len(bs.TypeArgs))
+	for i, argQN := range bs.TypeArgs {
+		typeERs[i] = typeexp.LinkRec{ExpID: identity.New(), TypeQN: argQN}
+	}
+	return procEnv.TypeEnv.Instance(bs.TypeQN, typeERs, bs.IdxArgs)
+}
+
+// Callee bind with its index parameters
+// replaced by the call arguments.
+func InstanceBind(procDR procdec.DecRec, bs procbind.BindSpec, idxESs []arithexp.ExpSpec) (procbind.BindSpec, error) {
+	if len(idxESs) != len(procDR.IdxVars) {
+		return procbind.BindSpec{}, fmt.Errorf("index arity mismatch: want %v args, got %v", len(procDR.IdxVars), len(idxESs))
+	}
+	if len(idxESs) == 0 {
+		return bs, nil
+	}
+	args := make(map[symbol.ADT]arithexp.ExpSpec, len(idxESs))
+	for i, idxVar := range procDR.IdxVars {
+		args[idxVar] = idxESs[i]
+	}
+	idxArgs := make([]arithexp.ExpSpec, len(bs.IdxArgs))
+	for i, idxArg := range bs.IdxArgs {
+		idxArgs[i] = arithexp.SubstExp(idxArg, args)
+	}
+	bs.IdxArgs = idxArgs
+	return bs, nil
+}
+
+// Checks the whole exp and returns all errors found,
+// each with the path to its subexpression. The ctx is consumed.
+func CheckExp(procEnv Env, procCtx typedef.Context, expSpec procexp.ExpSpec) error {
+	c := checker{procEnv}
+	return c.checkType(nil, procCtx, expSpec)
+}
+
+type checker struct {
+	env Env
+}
+
+func (c *checker) checkType(path []string, procCtx typedef.Context, expSpec procexp.ExpSpec) error {
+	if expSpec == nil {
+		return nil
+	}
+	path = append(slices.Clip(path), segment(expSpec))
+	var err error
+	// spawned channels are not in the snapshot yet
+	_, ok := procCtx.Liabs[expSpec.Via()]
+	workSpec, isWork := expSpec.(procexp.WorkSpec)
+	delaySpec, isDelay := expSpec.(procexp.DelaySpec)
+	switch {
+	case isWork:
+		err = c.checkWork(path, procCtx, workSpec)
+	case isDelay:
+		err = c.checkDelay(path, procCtx, delaySpec)
+	case ok:
+		err = c.checkProvider(path, procCtx, expSpec)
+	default:
+		err = c.checkClient(path, procCtx, expSpec)
+	}
+	return newCheckError(path, expSpec.Via(), err)
+}
+
+// Continuation errors already carry the path; joined errors
+// of branches and labels are wrapped one by one.
+func newCheckError(path []string, chnlPH symbol.ADT, err error) error {
+	if err == nil {
+		return nil
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if ok {
+		errs := make([]error, 0, len(joined.Unwrap()))
+		for _, e := range joined.Unwrap() {
+			errs = append(errs, newCheckError(path, chnlPH, e))
+		}
+		return errors.Join(errs...)
+	}
+	if errors.As(err, new(CheckError)) {
+		return err
+	}
+	return CheckError{K: checkErrorKind(err), Path: path, ChnlPH: chnlPH, Err: err}
+}
+
+func checkErrorKind(err error) CheckErrorKind {
+	switch {
+	case errors.As(err, new(typeexp.MismatchError)):
+		return TypeMismatch
+	case errors.Is(err, errMissingChnl):
+		return MissingChnl
+	case errors.Is(err, errCtxMismatch):
+		return CtxMismatch
+	case errors.Is(err, errLabelMismatch):
+		return LabelMismatch
+	case errors.Is(err, errPotInsufficient):
+		return PotInsufficient
+	default:
+		return InvalidExp
+	}
+}
+
+func (c *checker) checkProvider(path []string, procCtx typedef.Context, es procexp.ExpSpec) error {
+	switch expSpec := es.(type) {
+	case procexp.CloseSpec:
+		// check ctx
+		if len(procCtx.Assets) > 0 {
+			return ErrCtxMismatch(0, len(procCtx.Assets))
+		}
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		err := typeexp.CheckEqual(c.env.TypeEnv, procCtx.Facts, gotVia, typeexp.OneRec{})
+		if err != nil {
+			return err
+		}
+		// no cont to check
+		delete(procCtx.Liabs, expSpec.CommChnlPH)
+		return nil
+	case procexp.WaitSpec:
+		return procexp.ErrExpTypeMismatch(es, procexp.CloseSpec{})
+	case procexp.SendSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.TensorRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check potential
+		procCtx, err = pay(procCtx, wantVia.Pot)
+		if err != nil {
+			return err
+		}
+		// check value
+		gotVal, ok := procCtx.Assets[expSpec.ValChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.ValChnlPH)
+		}
+		err = typeexp.CheckSub(c.env.TypeEnv, procCtx.Facts, gotVal, wantVia.Y)
+		if err != nil {
+			return err
+		}
+		// no cont to check
+		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Z
+		delete(procCtx.Assets, expSpec.ValChnlPH)
+		return nil
+	case procexp.RecvSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.LolliRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check value
+		gotVal, ok := procCtx.Assets[expSpec.BindChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.BindChnlPH)
+		}
+		err = typeexp.CheckSub(c.env.TypeEnv, procCtx.Facts, gotVal, wantVia.Y)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx = gain(procCtx, wantVia.Pot)
+		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Z
+		procCtx.Assets[expSpec.BindChnlPH] = wantVia.Y
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.LabSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.PlusRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check label
+		choice, ok := wantVia.Zs[expSpec.LabelQN]
+		if !ok {
+			return fmt.Errorf("%w: want %v, got %q", errLabelMismatch, maps.Keys(wantVia.Zs), expSpec.LabelQN)
+		}
+		// check potential
+		procCtx, err = pay(procCtx, wantVia.Pot)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Liabs[expSpec.CommChnlPH] = choice
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.CaseSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.WithRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check conts
+		procCtx = gain(procCtx, wantVia.Pot)
+		if len(expSpec.ContESs) != len(wantVia.Zs) {
+			return fmt.Errorf("state mismatch: want %v choices, got %v conts", len(wantVia.Zs), len(expSpec.ContESs))
+		}
+		// branches are checked independently and errors accumulate
+		var errs []error
+		for label, choice := range wantVia.Zs {
+			cont, ok := expSpec.ContESs[label]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%w: want %v, got nothing", errLabelMismatch, label))
+				continue
+			}
+			branchCtx := cloneCtx(procCtx)
+			branchCtx.Liabs[expSpec.CommChnlPH] = choice
+			branchPath := append(slices.Clip(path), uniqsym.ConvertToString(label))
+			errs = append(errs, c.checkType(branchPath, branchCtx, cont))
+		}
+		return errors.Join(errs...)
+	case procexp.FwdSpec:
+		if len(procCtx.Assets) != 1 {
+			return ErrCtxMismatch(1, len(procCtx.Assets))
+		}
+		viaSt, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		fwdSt, ok := procCtx.Assets[expSpec.ContChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.ContChnlPH)
+		}
+		viaSt, err := c.env.TypeEnv.Unfold(viaSt)
+		if err != nil {
+			return err
+		}
+		fwdSt, err = c.env.TypeEnv.Unfold(fwdSt)
+		if err != nil {
+			return err
+		}
+		if fwdSt.Pol() != viaSt.Pol() {
+			return typeexp.ErrPolarityMismatch(fwdSt, viaSt)
+		}
+		err = typeexp.CheckSub(c.env.TypeEnv, procCtx.Facts, fwdSt, viaSt)
+		if err != nil {
+			return err
+		}
+		delete(procCtx.Liabs, expSpec.CommChnlPH)
+		delete(procCtx.Assets, expSpec.ContChnlPH)
+		return nil
+	case procexp.CallSpec:
+		if expSpec.ContES != nil {
+			return ErrNonTailCall(expSpec.ProcQN)
+		}
+		procSD, ok := c.env.SynDecs[expSpec.ProcQN]
+		if !ok {
+			return errMissingProc(expSpec.ProcQN)
+		}
+		procDR, ok := c.env.ProcDecs[procSD.DecID]
+		if !ok {
+			return procdec.ErrRootMissingInEnv(procSD.DecID)
+		}
+		// the callee gets its potential from the caller
+		_, err := pay(procCtx, procDR.Pot)
+		if err != nil {
+			return err
+		}
+		// check vals
+		err = c.checkVals(procCtx, procDR, expSpec.ValChnlPHs, expSpec.IdxESs)
+		if err != nil {
+			return err
+		}
+		linearAssets := make(map[symbol.ADT]typeexp.ExpRec, len(procCtx.Assets))
+		for ph, asset := range procCtx.Assets {
+			if !typeexp.IsShared(asset) {
+				linearAssets[ph] = asset
+			}
+		}
+		if len(linearAssets) > 0 {
+			return ErrCtxMismatch(0, len(linearAssets))
+		}
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.BindChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.BindChnlPH)
+		}
+		wantVia, err := c.lookupBind(procDR, procDR.ProviderBS, expSpec.IdxESs)
+		if err != nil {
+			return err
+		}
+		// the callee provides wantVia in place of gotVia
+		err = typeexp.CheckSub(c.env.TypeEnv, procCtx.Facts, wantVia, gotVia)
+		if err != nil {
+			return err
+		}
+		delete(procCtx.Liabs, expSpec.BindChnlPH)
+		return nil
+	case procexp.AcceptSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.UpRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check cont
+		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.DetachSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.DownRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Z
+		return nil
+	case procexp.AcquireSpec:
+		return procexp.ErrExpTypeMismatch(es, procexp.AcceptSpec{})
+	case procexp.ReleaseSpec:
+		return procexp.ErrExpTypeMismatch(es, procexp.DetachSpec{})
+	case procexp.SendIdxSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.ExistsRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check cont
+		procCtx.Liabs[expSpec.CommChnlPH] = instantiate(wantVia.Z, wantVia.IdxVar, expSpec.IdxES)
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.RecvIdxSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.ForallRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		err = checkIdxVar(procCtx, expSpec.IdxVar)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Liabs[expSpec.CommChnlPH] = instantiate(wantVia.Z, wantVia.IdxVar, arithexp.VarSpec{IdxVar: expSpec.IdxVar})
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.AssertSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.AssertRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check constraint
+		err = checkAssert(procCtx, expSpec.Prop, wantVia.Prop)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, assume(procCtx, expSpec.Prop), expSpec.ContES)
+	case procexp.AssumeSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.AssumeRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check constraint
+		err = arithexp.CheckProp(assume(procCtx, wantVia.Prop).Facts, expSpec.Prop)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, assume(procCtx, wantVia.Prop), expSpec.ContES)
+	case procexp.NowSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.DiamondRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check cont
+		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.WhenSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.BoxRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// the client picks the moment, other channels must be able to wait
+		err = c.checkPatient(procCtx, expSpec.CommChnlPH)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.SendValSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.AndRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check value
+		err = checkVal(procCtx, expSpec, wantVia.T)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.RecvValSpec:
+		// check via
+		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.ImplRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check cont
+		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, bindVal(procCtx, expSpec.BindVar, wantVia.T), expSpec.ContES)
+	default:
+		panic(procexp.ErrExpTypeUnexpected(es))
+	}
 }
 
-func (s *service) Create(spec DefSpec) (DefRef, error) {
-	return DefRef{}, nil
+func (c *checker) checkClient(path []string, procCtx typedef.Context, es procexp.ExpSpec) error {
+	switch expSpec := es.(type) {
+	case procexp.CloseSpec:
+		return procexp.ErrExpTypeMismatch(es, procexp.WaitSpec{})
+	case procexp.WaitSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.OneRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check cont
+		delete(procCtx.Assets, expSpec.CommChnlPH)
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.SendSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.LolliRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check potential
+		procCtx, err = pay(procCtx, wantVia.Pot)
+		if err != nil {
+			return err
+		}
+		// check value
+		gotVal, ok := procCtx.Assets[expSpec.ValChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.ValChnlPH)
+		}
+		err = typeexp.CheckSub(c.env.TypeEnv, procCtx.Facts, gotVal, wantVia.Y)
+		if err != nil {
+			return err
+		}
+		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Z
+		delete(procCtx.Assets, expSpec.ValChnlPH)
+		return nil
+	case procexp.RecvSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.TensorRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check value
+		gotVal, ok := procCtx.Assets[expSpec.BindChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.BindChnlPH)
+		}
+		err = typeexp.CheckSub(c.env.TypeEnv, procCtx.Facts, gotVal, wantVia.Y)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx = gain(procCtx, wantVia.Pot)
+		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Z
+		procCtx.Assets[expSpec.BindChnlPH] = wantVia.Y
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.LabSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.WithRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check label
+		choice, ok := wantVia.Zs[expSpec.LabelQN]
+		if !ok {
+			return fmt.Errorf("%w: want %v, got %q", errLabelMismatch, maps.Keys(wantVia.Zs), expSpec.LabelQN)
+		}
+		// check potential
+		procCtx, err = pay(procCtx, wantVia.Pot)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Assets[expSpec.CommChnlPH] = choice
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.CaseSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.PlusRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check conts
+		procCtx = gain(procCtx, wantVia.Pot)
+		if len(expSpec.ContESs) != len(wantVia.Zs) {
+			return fmt.Errorf("state mismatch: want %v choices, got %v conts", len(wantVia.Zs), len(expSpec.ContESs))
+		}
+		// branches are checked independently and errors accumulate
+		var errs []error
+		for label, choice := range wantVia.Zs {
+			cont, ok := expSpec.ContESs[label]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%w: want %v, got nothing", errLabelMismatch, label))
+				continue
+			}
+			branchCtx := cloneCtx(procCtx)
+			branchCtx.Assets[expSpec.CommChnlPH] = choice
+			branchPath := append(slices.Clip(path), uniqsym.ConvertToString(label))
+			errs = append(errs, c.checkType(branchPath, branchCtx, cont))
+		}
+		return errors.Join(errs...)
+	case procexp.SpawnSpecOld:
+		procDec, ok := c.env.ProcDecs[expSpec.SigID]
+		if !ok {
+			return procdec.ErrRootMissingInEnv(expSpec.SigID)
+		}
+		// check vals
+		if len(expSpec.Ys) != len(procDec.ClientBSs) {
+			return ErrCtxMismatch(len(procDec.ClientBSs), len(expSpec.Ys))
+		}
+		if len(expSpec.Ys) == 0 {
+			return nil
+		}
+		for i, ep := range procDec.ClientBSs {
+			wantVal, err := LookupType(c.env, ep)
+			if err != nil {
+				return err
+			}
+			gotVal, ok := procCtx.Assets[expSpec.Ys[i]]
+			if !ok {
+				return ErrMissingInCtx(ep.ChnlPH)
+			}
+			err = typeexp.CheckSub(c.env.TypeEnv, procCtx.Facts, gotVal, wantVal)
+			if err != nil {
+				return err
+			}
+			delete(procCtx.Assets, expSpec.Ys[i])
+		}
+		// check via
+		wantVia, err := LookupType(c.env, procDec.ProviderBS)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Assets[expSpec.X] = wantVia
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.SpawnSpec:
+		procSD, ok := c.env.SynDecs[expSpec.ProcQN]
+		if !ok {
+			return errMissingProc(expSpec.ProcQN)
+		}
+		procDR, ok := c.env.ProcDecs[procSD.DecID]
+		if !ok {
+			return procdec.ErrRootMissingInEnv(procSD.DecID)
+		}
+		// the child gets its potential from the parent
+		var err error
+		procCtx, err = pay(procCtx, procDR.Pot)
+		if err != nil {
+			return err
+		}
+		// check vals
+		err = c.checkVals(procCtx, procDR, expSpec.BindChnlPHs, expSpec.IdxESs)
+		if err != nil {
+			return err
+		}
+		// check via
+		wantVia, err := c.lookupBind(procDR, procDR.ProviderBS, expSpec.IdxESs)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Assets[expSpec.CommChnlPH] = wantVia
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.CallSpec:
+		// tail call must provide the caller's channel
+		return ErrMissingInCtx(expSpec.BindChnlPH)
+	case procexp.AcquireSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.UpRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check cont
+		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.ReleaseSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.DownRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Z
+		return nil
+	case procexp.AcceptSpec:
+		return procexp.ErrExpTypeMismatch(es, procexp.AcquireSpec{})
+	case procexp.DetachSpec:
+		return procexp.ErrExpTypeMismatch(es, procexp.ReleaseSpec{})
+	case procexp.SendIdxSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.ForallRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check cont
+		procCtx.Assets[expSpec.CommChnlPH] = instantiate(wantVia.Z, wantVia.IdxVar, expSpec.IdxES)
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.RecvIdxSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.ExistsRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		err = checkIdxVar(procCtx, expSpec.IdxVar)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Assets[expSpec.CommChnlPH] = instantiate(wantVia.Z, wantVia.IdxVar, arithexp.VarSpec{IdxVar: expSpec.IdxVar})
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.AssertSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		// the provider relies on the constraint, the client guarantees it
+		wantVia, ok := gotVia.(typeexp.AssumeRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check constraint
+		err = checkAssert(procCtx, expSpec.Prop, wantVia.Prop)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, assume(procCtx, expSpec.Prop), expSpec.ContES)
+	case procexp.AssumeSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.AssertRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check constraint
+		err = arithexp.CheckProp(assume(procCtx, wantVia.Prop).Facts, expSpec.Prop)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, assume(procCtx, wantVia.Prop), expSpec.ContES)
+	case procexp.NowSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.BoxRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check cont
+		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.WhenSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.DiamondRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// the provider picks the moment, other channels must be able to wait
+		err = c.checkPatient(procCtx, expSpec.CommChnlPH)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.SendValSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.ImplRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check value
+		err = checkVal(procCtx, expSpec, wantVia.T)
+		if err != nil {
+			return err
+		}
+		// check cont
+		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, procCtx, expSpec.ContES)
+	case procexp.RecvValSpec:
+		// check via
+		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		if !ok {
+			return ErrMissingInCtx(expSpec.CommChnlPH)
+		}
+		gotVia, err := c.env.TypeEnv.Unfold(gotVia)
+		if err != nil {
+			return err
+		}
+		wantVia, ok := gotVia.(typeexp.AndRec)
+		if !ok {
+			return typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
+		}
+		// check cont
+		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Z
+		return c.checkType(path, bindVal(procCtx, expSpec.BindVar, wantVia.T), expSpec.ContES)
+	default:
+		panic(procexp.ErrExpTypeUnexpected(es))
+	}
 }
 
-func (s *service) Retrieve(recID identity.ADT) (DefRec, error) {
-	return DefRec{}, nil
+// consumes client channels passed to a declared process
+func (c *checker) checkVals(
+	procCtx typedef.Context,
+	procDR procdec.DecRec,
+	valPHs []symbol.ADT,
+	idxESs []arithexp.ExpSpec,
+) error {
+	if len(valPHs) != len(procDR.ClientBSs) {
+		return ErrCtxMismatch(len(procDR.ClientBSs), len(valPHs))
+	}
+	for i, ep := range procDR.ClientBSs {
+		wantVal, err := c.lookupBind(procDR, ep, idxESs)
+		if err != nil {
+			return err
+		}
+		gotVal, ok := procCtx.Assets[valPHs[i]]
+		if !ok {
+			return ErrMissingInCtx(valPHs[i])
+		}
+		err = typeexp.CheckSub(c.env.TypeEnv, procCtx.Facts, gotVal, wantVal)
+		if err != nil {
+			return err
+		}
+		if typeexp.IsShared(gotVal) {
+			continue
+		}
+		delete(procCtx.Assets, valPHs[i])
+	}
+	return nil
+}
+
+// the assertion must follow from the facts and imply the type constraint
+func checkAssert(procCtx typedef.Context, got, want arithexp.PropSpec) error {
+	err := arithexp.CheckProp(procCtx.Facts, got)
+	if err != nil {
+		return err
+	}
+	return arithexp.CheckProp(assume(procCtx, got).Facts, want)
+}
+
+func (c *checker) lookupBind(procDR procdec.DecRec, bs procbind.BindSpec, idxESs []arithexp.ExpSpec) (typeexp.ExpRec, error) {
+	bs, err := InstanceBind(procDR, bs, idxESs)
+	if err != nil {
+		return nil, err
+	}
+	return LookupType(c.env, bs)
+}
+
+// a new index var must not clash with known ones
+func checkIdxVar(procCtx typedef.Context, idxVar symbol.ADT) error {
+	var known []symbol.ADT
+	for _, fact := range procCtx.Facts {
+		known = append(known, arithexp.CollectPropVars(fact)...)
+	}
+	for _, rec := range procCtx.Assets {
+		known = append(known, typeexp.CollectIdxVars(rec)...)
+	}
+	for _, rec := range procCtx.Liabs {
+		known = append(known, typeexp.CollectIdxVars(rec)...)
+	}
+	// shadowing is harmless in a contradictory context
+	if slices.Contains(known, idxVar) && !arithexp.Entails(procCtx.Facts, arithexp.FalseSpec{}) {
+		return fmt.Errorf("index var shadowed: %v", idxVar)
+	}
+	return nil
+}
+
+// substitutes an index exp for the bound var
+func instantiate(rec typeexp.ExpRec, idxVar symbol.ADT, idxES arithexp.ExpSpec) typeexp.ExpRec {
+	return typeexp.Subst(rec, nil, map[symbol.ADT]arithexp.ExpSpec{idxVar: idxES})
+}
+
+func assume(procCtx typedef.Context, prop arithexp.PropSpec) typedef.Context {
+	procCtx.Facts = append(slices.Clip(procCtx.Facts), prop)
+	return procCtx
+}
+
+func cloneCtx(procCtx typedef.Context) typedef.Context {
+	return typedef.Context{
+		Assets: maps.Clone(procCtx.Assets),
+		Liabs:  maps.Clone(procCtx.Liabs),
+		Facts:  procCtx.Facts,
+		Pot:    procCtx.Pot,
+		Vals:   procCtx.Vals,
+	}
+}
+
+// a value is given either by a literal or by a previously received var
+func checkVal(procCtx typedef.Context, expSpec procexp.SendValSpec, want typeexp.DataType) error {
+	if len(expSpec.ValLit) > 0 && len(expSpec.ValVar) > 0 {
+		return fmt.Errorf("value ambiguous: %v", expSpec.ValVar)
+	}
+	if len(expSpec.ValLit) > 0 {
+		return typeexp.CheckData(want, expSpec.ValLit)
+	}
+	got, ok := procCtx.Vals[expSpec.ValVar]
+	if !ok {
+		return fmt.Errorf("value missing in ctx: %v", expSpec.ValVar)
+	}
+	return typeexp.CheckDataType(got, want)
+}
+
+// values are not linear, so binding does not affect other branches
+func bindVal(procCtx typedef.Context, bindVar symbol.ADT, t typeexp.DataType) typedef.Context {
+	vals := make(map[symbol.ADT]typeexp.DataType, len(procCtx.Vals)+1)
+	maps.Copy(vals, procCtx.Vals)
+	vals[bindVar] = t
+	procCtx.Vals = vals
+	return procCtx
+}
+
+// aka Work: work is paid for with the process potential
+func (c *checker) checkWork(path []string, procCtx typedef.Context, expSpec procexp.WorkSpec) error {
+	if expSpec.Work < 0 {
+		return fmt.Errorf("work negative: %v", expSpec.Work)
+	}
+	procCtx, err := pay(procCtx, expSpec.Work)
+	if err != nil {
+		return err
+	}
+	return c.checkType(path, procCtx, expSpec.ContES)
+}
+
+// aka Delay: all process channels advance by ticks
+func (c *checker) checkDelay(path []string, procCtx typedef.Context, expSpec procexp.DelaySpec) error {
+	if expSpec.Ticks < 0 {
+		return fmt.Errorf("delay negative: %v", expSpec.Ticks)
+	}
+	for ph, rec := range procCtx.Assets {
+		next, err := c.advance(rec, expSpec.Ticks, typeexp.IsPatientAsset)
+		if err != nil {
+			return fmt.Errorf("channel not delayable: %v: %w", ph, err)
+		}
+		procCtx.Assets[ph] = next
+	}
+	for ph, rec := range procCtx.Liabs {
+		next, err := c.advance(rec, expSpec.Ticks, typeexp.IsPatientLiab)
+		if err != nil {
+			return fmt.Errorf("channel not delayable: %v: %w", ph, err)
+		}
+		procCtx.Liabs[ph] = next
+	}
+	return c.checkType(path, procCtx, expSpec.ContES)
+}
+
+// every tick strips ◯, while a patient type is kept
+func (c *checker) advance(rec typeexp.ExpRec, ticks int64, patient func(typeexp.ExpRec) bool) (typeexp.ExpRec, error) {
+	for range ticks {
+		var err error
+		rec, err = c.env.TypeEnv.Unfold(rec)
+		if err != nil {
+			return nil, err
+		}
+		if patient(rec) {
+			return rec, nil
+		}
+		next, ok := rec.(typeexp.NextRec)
+		if !ok {
+			return nil, typeexp.ErrSnapTypeMismatch(rec, next)
+		}
+		rec = next.Z
+	}
+	return rec, nil
+}
+
+// waiting for an unknown duration is allowed
+// if the other assets are □ and the liabilities ◇
+func (c *checker) checkPatient(procCtx typedef.Context, commChnlPH symbol.ADT) error {
+	for ph, rec := range procCtx.Assets {
+		if ph == commChnlPH {
+			continue
+		}
+		rec, err := c.env.TypeEnv.Unfold(rec)
+		if err != nil {
+			return err
+		}
+		if !typeexp.IsPatientAsset(rec) {
+			return fmt.Errorf("channel not patient: %v", ph)
+		}
+	}
+	for ph, rec := range procCtx.Liabs {
+		if ph == commChnlPH {
+			continue
+		}
+		rec, err := c.env.TypeEnv.Unfold(rec)
+		if err != nil {
+			return err
+		}
+		if !typeexp.IsPatientLiab(rec) {
+			return fmt.Errorf("channel not patient: %v", ph)
+		}
+	}
+	return nil
+}
+
+// the message sender passes potential to the receiver
+func pay(procCtx typedef.Context, pot int64) (typedef.Context, error) {
+	if procCtx.Pot < pot {
+		return procCtx, fmt.Errorf("%w: want %v, got %v", errPotInsufficient, pot, procCtx.Pot)
+	}
+	procCtx.Pot -= pot
+	return procCtx, nil
+}
+
+func gain(procCtx typedef.Context, pot int64) typedef.Context {
+	// unbounded potential does not overflow
+	if procCtx.Pot > math.MaxInt64-pot {
+		procCtx.Pot = math.MaxInt64
+		return procCtx
+	}
+	procCtx.Pot += pot
+	return procCtx
+}
+
+func segment(expSpec procexp.ExpSpec) string {
+	kind := strings.ToLower(strings.TrimSuffix(reflect.TypeOf(expSpec).Name(), "Spec"))
+	return fmt.Sprintf("%v(%v)", kind, expSpec.Via())
+}
+
+func errMissingProc(want uniqsym.ADT) error {
+	return fmt.Errorf("proc missing in env: %v", want)
+}
+
+func ErrNonTailCall(got uniqsym.ADT) error {
+	return fmt.Errorf("call in non-tail position: %v", got)
 }
 
 func ErrDoesNotExist(want identity.ADT) error {
 	return fmt.Errorf("rec doesn't exist: %v", want)
 }
 
+func ErrCtxMismatch(want, got int) error {
+	return fmt.Errorf("%w: want %v items, got %v items", errCtxMismatch, want, got)
+}
+
 func ErrMissingInCfg(want symbol.ADT) error {
-	return fmt.Errorf("channel missing in cfg: %v", want)
+	return fmt.Errorf("%w in cfg: %v", errMissingChnl, want)
 }
 
 func ErrMissingInCfg2(want identity.ADT) error {
-	return fmt.Errorf("channel missing in cfg: %v", want)
+	return fmt.Errorf("%w in cfg: %v", errMissingChnl, want)
 }
 
 func ErrMissingInCtx(want symbol.ADT) error {
-	return fmt.Errorf("channel missing in ctx: %v", want)
+	return fmt.Errorf("%w in ctx: %v", errMissingChnl, want)
 }
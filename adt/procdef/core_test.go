@@ -0,0 +1,247 @@
+package procdef
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procexp"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/typedef"
+	"orglang/go-runtime/adt/typeexp"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+func one() typeexp.ExpRec {
+	return typeexp.OneRec{ExpID: identity.New()}
+}
+
+// a process providing z of the given type
+func providerCtx(z typeexp.ExpRec, pot int64) typedef.Context {
+	return typedef.Context{
+		Assets: map[symbol.ADT]typeexp.ExpRec{},
+		Liabs:  map[symbol.ADT]typeexp.ExpRec{"z": z},
+		Pot:    pot,
+	}
+}
+
+// check errors in the order they were joined
+func checkErrors(t *testing.T, err error) []CheckError {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	errs := []error{err}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if ok {
+		errs = joined.Unwrap()
+	}
+	checkErrs := make([]CheckError, 0, len(errs))
+	for _, err := range errs {
+		var checkErr CheckError
+		if !errors.As(err, &checkErr) {
+			t.Fatalf("got %v, want CheckError", err)
+		}
+		checkErrs = append(checkErrs, checkErr)
+	}
+	return checkErrs
+}
+
+func TestCheckExpPath(t *testing.T) {
+	a, b := uniqsym.New("a"), uniqsym.New("b")
+	// z : &{a: 1, b: 1}
+	with := typeexp.WithRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]typeexp.ExpRec{a: one(), b: one()}}
+	closeZ := procexp.CloseSpec{CommChnlPH: "z"}
+	sendZ := procexp.SendSpec{CommChnlPH: "z", ValChnlPH: "x"}
+	type wantErr struct {
+		k    CheckErrorKind
+		path []string
+	}
+	tests := []struct {
+		name string
+		es   procexp.ExpSpec
+		want []wantErr
+	}{
+		{"all branches",
+			procexp.CaseSpec{CommChnlPH: "z", ContESs: map[uniqsym.ADT]procexp.ExpSpec{a: closeZ, b: closeZ}},
+			nil},
+		{"failing branch",
+			procexp.CaseSpec{CommChnlPH: "z", ContESs: map[uniqsym.ADT]procexp.ExpSpec{a: closeZ, b: sendZ}},
+			[]wantErr{{TypeMismatch, []string{"case(z)", "b", "send(z)"}}}},
+		{"failing branches",
+			procexp.CaseSpec{CommChnlPH: "z", ContESs: map[uniqsym.ADT]procexp.ExpSpec{a: sendZ, b: sendZ}},
+			[]wantErr{
+				{TypeMismatch, []string{"case(z)", "a", "send(z)"}},
+				{TypeMismatch, []string{"case(z)", "b", "send(z)"}},
+			}},
+		{"missing branch",
+			procexp.CaseSpec{CommChnlPH: "z", ContESs: map[uniqsym.ADT]procexp.ExpSpec{a: closeZ, uniqsym.New("c"): closeZ}},
+			[]wantErr{{LabelMismatch, []string{"case(z)"}}}},
+		{"missing channel",
+			procexp.WaitSpec{CommChnlPH: "y", ContES: closeZ},
+			[]wantErr{{MissingChnl, []string{"wait(y)"}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckExp(Env{}, providerCtx(with, 0), test.es)
+			got := checkErrors(t, err)
+			// branches are checked in map order
+			slices.SortFunc(got, func(x, y CheckError) int {
+				return slices.Compare(x.Path, y.Path)
+			})
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v, want %v errors", err, len(test.want))
+			}
+			for i, want := range test.want {
+				if got[i].K != want.k || !slices.Equal(got[i].Path, want.path) {
+					t.Errorf("got %v at %v, want %v at %v", got[i].K, got[i].Path, want.k, want.path)
+				}
+			}
+		})
+	}
+}
+
+func kinds(t *testing.T, err error) []CheckErrorKind {
+	t.Helper()
+	var got []CheckErrorKind
+	for _, checkErr := range checkErrors(t, err) {
+		got = append(got, checkErr.K)
+	}
+	return got
+}
+
+func TestCheckExpPotential(t *testing.T) {
+	a := uniqsym.New("a")
+	closeZ := procexp.CloseSpec{CommChnlPH: "z"}
+	// ⊕{a: 1} costs the sender 1
+	plus := typeexp.PlusRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]typeexp.ExpRec{a: one()}, Pot: 1}
+	// &{a: 1} pays the receiver 2
+	with := typeexp.WithRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]typeexp.ExpRec{a: one()}, Pot: 2}
+	tests := []struct {
+		name string
+		z    typeexp.ExpRec
+		pot  int64
+		es   procexp.ExpSpec
+		want []CheckErrorKind
+	}{
+		{"work within potential", one(), 2,
+			procexp.WorkSpec{Work: 2, ContES: closeZ}, nil},
+		{"work over potential", one(), 1,
+			procexp.WorkSpec{Work: 2, ContES: closeZ}, []CheckErrorKind{PotInsufficient}},
+		{"negative work", one(), 1,
+			procexp.WorkSpec{Work: -1, ContES: closeZ}, []CheckErrorKind{InvalidExp}},
+		{"label paid", plus, 1,
+			procexp.LabSpec{CommChnlPH: "z", LabelQN: a, ContES: closeZ}, nil},
+		{"label unpaid", plus, 0,
+			procexp.LabSpec{CommChnlPH: "z", LabelQN: a, ContES: closeZ}, []CheckErrorKind{PotInsufficient}},
+		{"received potential spent", with, 0,
+			procexp.CaseSpec{CommChnlPH: "z", ContESs: map[uniqsym.ADT]procexp.ExpSpec{
+				a: procexp.WorkSpec{Work: 2, ContES: closeZ},
+			}}, nil},
+		{"received potential overspent", with, 0,
+			procexp.CaseSpec{CommChnlPH: "z", ContESs: map[uniqsym.ADT]procexp.ExpSpec{
+				a: procexp.WorkSpec{Work: 3, ContES: closeZ},
+			}}, []CheckErrorKind{PotInsufficient}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckExp(Env{}, providerCtx(test.z, test.pot), test.es)
+			got := kinds(t, err)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckExpDelay(t *testing.T) {
+	closeZ := procexp.CloseSpec{CommChnlPH: "z"}
+	next := func(z typeexp.ExpRec) typeexp.ExpRec {
+		return typeexp.NextRec{ExpID: identity.New(), Z: z}
+	}
+	box := typeexp.BoxRec{ExpID: identity.New(), Z: one()}
+	diamond := typeexp.DiamondRec{ExpID: identity.New(), Z: one()}
+	tests := []struct {
+		name string
+		z    typeexp.ExpRec
+		// client channel besides z, if any
+		x    typeexp.ExpRec
+		es   procexp.ExpSpec
+		want []CheckErrorKind
+	}{
+		{"next tick", next(one()), nil,
+			procexp.DelaySpec{Ticks: 1, ContES: closeZ}, nil},
+		{"delay past next", next(one()), nil,
+			procexp.DelaySpec{Ticks: 2, ContES: closeZ}, []CheckErrorKind{TypeMismatch}},
+		{"delay without next", one(), nil,
+			procexp.DelaySpec{Ticks: 1, ContES: closeZ}, []CheckErrorKind{TypeMismatch}},
+		{"negative delay", next(one()), nil,
+			procexp.DelaySpec{Ticks: -1, ContES: closeZ}, []CheckErrorKind{InvalidExp}},
+		// ◇ lets the provider pick the moment after any delay
+		{"patient provider", diamond, nil,
+			procexp.DelaySpec{Ticks: 3, ContES: procexp.NowSpec{CommChnlPH: "z", ContES: closeZ}}, nil},
+		{"now without diamond", one(), nil,
+			procexp.NowSpec{CommChnlPH: "z", ContES: closeZ}, []CheckErrorKind{TypeMismatch}},
+		{"when with patient client", box, box,
+			procexp.WhenSpec{CommChnlPH: "z"}, nil},
+		{"when with impatient client", box, one(),
+			procexp.WhenSpec{CommChnlPH: "z"}, []CheckErrorKind{InvalidExp}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			procCtx := providerCtx(test.z, 0)
+			if test.x != nil {
+				procCtx.Assets["x"] = test.x
+			}
+			err := CheckExp(Env{}, procCtx, test.es)
+			got := kinds(t, err)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckExpVals(t *testing.T) {
+	closeZ := procexp.CloseSpec{CommChnlPH: "z"}
+	and := func(t typeexp.DataType, z typeexp.ExpRec) typeexp.ExpRec {
+		return typeexp.AndRec{ExpID: identity.New(), T: t, Z: z}
+	}
+	impl := func(t typeexp.DataType, z typeexp.ExpRec) typeexp.ExpRec {
+		return typeexp.ImplRec{ExpID: identity.New(), T: t, Z: z}
+	}
+	// receives v, then sends v back
+	echo := procexp.RecvValSpec{CommChnlPH: "z", BindVar: "v",
+		ContES: procexp.SendValSpec{CommChnlPH: "z", ValVar: "v", ContES: closeZ}}
+	tests := []struct {
+		name string
+		z    typeexp.ExpRec
+		es   procexp.ExpSpec
+		want []CheckErrorKind
+	}{
+		{"send literal", and(typeexp.IntData, one()),
+			procexp.SendValSpec{CommChnlPH: "z", ValLit: json.RawMessage("1"), ContES: closeZ}, nil},
+		{"send literal of other type", and(typeexp.IntData, one()),
+			procexp.SendValSpec{CommChnlPH: "z", ValLit: json.RawMessage(`"one"`), ContES: closeZ}, []CheckErrorKind{InvalidExp}},
+		{"send literal and var", and(typeexp.IntData, one()),
+			procexp.SendValSpec{CommChnlPH: "z", ValLit: json.RawMessage("1"), ValVar: "v", ContES: closeZ}, []CheckErrorKind{InvalidExp}},
+		{"send unknown var", and(typeexp.IntData, one()),
+			procexp.SendValSpec{CommChnlPH: "z", ValVar: "v", ContES: closeZ}, []CheckErrorKind{InvalidExp}},
+		{"send without and", one(),
+			procexp.SendValSpec{CommChnlPH: "z", ValLit: json.RawMessage("1"), ContES: closeZ}, []CheckErrorKind{TypeMismatch}},
+		{"send received", impl(typeexp.IntData, and(typeexp.IntData, one())), echo, nil},
+		{"send received of other type", impl(typeexp.StrData, and(typeexp.IntData, one())), echo, []CheckErrorKind{InvalidExp}},
+		{"receive without impl", and(typeexp.IntData, one()), echo, []CheckErrorKind{TypeMismatch}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckExp(Env{}, providerCtx(test.z, 0), test.es)
+			got := kinds(t, err)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
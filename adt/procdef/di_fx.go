@@ -2,10 +2,23 @@ package procdef
 
 import (
 	"go.uber.org/fx"
+
+	"orglang/go-runtime/lib/te"
 )
 
 var Module = fx.Module("adt/procdef",
 	fx.Provide(
-		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+		fx.Annotate(newService, fx.As(new(API))),
+		newDAO,
+	),
+	fx.Provide(
+		fx.Private,
+		newEchoController,
+		newEchoPresenter,
+		fx.Annotate(newRendererStdlib, fx.As(new(te.Renderer))),
+	),
+	fx.Invoke(
+		cfgEchoController,
+		cfgEchoPresenter,
 	),
 )
@@ -1,11 +1,36 @@
 package procdef
 
 import (
+	"log/slog"
+
 	"orglang/go-runtime/lib/db"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procexp"
 )
 
 type Repo interface {
-	InsertProc(db.Source, DefRec) error
+	InsertRec(db.Source, DefRec) error
+	SelectRecByID(db.Source, identity.ADT) (DefRec, error)
+	SelectEnv(db.Source, []identity.ADT) (map[identity.ADT]DefRec, error)
+}
+
+// storage adapter is chosen by the operator
+func newDAO(l *slog.Logger, o db.Operator) Repo {
+	switch o.(type) {
+	case *db.OperatorMem:
+		return newMemDAO(l)
+	case *db.OperatorSqlite:
+		return newSqliteDAO(l)
+	default:
+		return newPgxDAO(l)
+	}
+}
+
+type defRecDS struct {
+	ID     string            `db:"def_id"`
+	RN     int64             `db:"def_rn"`
+	ProcES procexp.ExpSpecDS `db:"proc_es"`
 }
 
 type ExpRecDS struct {
@@ -0,0 +1,68 @@
+package procdef
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/typedef"
+	"orglang/go-runtime/adt/uniqref"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(l *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{l.With(name)}
+}
+
+func (dao *memDAO) InsertRec(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("defRef", rec.DefRef)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	db.InsertMem(ds, procDefs, dto)
+	// for type change impact assessment
+	db.InsertMem(ds, typedef.DefRefsMem, uniqref.Data{ID: dto.ID, RN: dto.RN})
+	return nil
+}
+
+func (dao *memDAO) SelectRecByID(source db.Source, recID identity.ADT) (DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	idAttr := slog.Any("defID", recID)
+	dto, err := db.SelectLatestMem(ds, procDefs,
+		func(dto defRecDS) bool { return dto.ID == recID.String() },
+		func(dto defRecDS) int64 { return dto.RN },
+	)
+	if err != nil {
+		dao.log.Error("entity selection failed", idAttr)
+		return DefRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", idAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *memDAO) SelectEnv(source db.Source, recIDs []identity.ADT) (map[identity.ADT]DefRec, error) {
+	env := make(map[identity.ADT]DefRec, len(recIDs))
+	for _, recID := range recIDs {
+		rec, err := dao.SelectRecByID(source, recID)
+		if err != nil {
+			return nil, err
+		}
+		env[recID] = rec
+	}
+	return env, nil
+}
+
+const (
+	procDefs = "proc_defs"
+)
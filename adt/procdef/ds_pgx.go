@@ -1,8 +1,16 @@
 package procdef
 
 import (
+	"errors"
 	"log/slog"
 	"reflect"
+
+	"github.com/jackc/pgx/v5"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
 )
 
 // Adapter
@@ -10,7 +18,105 @@ type pgxDAO struct {
 	log *slog.Logger
 }
 
+// for compilation purposes
+func newRepo() Repo {
+	return &pgxDAO{}
+}
+
 func newPgxDAO(l *slog.Logger) *pgxDAO {
 	name := slog.String("name", reflect.TypeFor[pgxDAO]().Name())
 	return &pgxDAO{l.With(name)}
 }
+
+func (dao *pgxDAO) InsertRec(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("defRef", rec.DefRef)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	args := pgx.NamedArgs{
+		"def_id":  dto.ID,
+		"def_rn":  dto.RN,
+		"proc_es": dto.ProcES,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertRec, args)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", insertRec))
+		return err
+	}
+	return nil
+}
+
+func (dao *pgxDAO) SelectRecByID(source db.Source, recID identity.ADT) (DefRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("defID", recID)
+	rows, err := ds.Conn.Query(ds.Ctx, selectByID, recID.String())
+	if err != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("q", selectByID))
+		return DefRec{}, err
+	}
+	defer rows.Close()
+	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[defRecDS])
+	if err != nil {
+		dao.log.Error("row collection failed", idAttr)
+		return DefRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", idAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *pgxDAO) SelectEnv(source db.Source, recIDs []identity.ADT) (_ map[identity.ADT]DefRec, err error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	if len(recIDs) == 0 {
+		return map[identity.ADT]DefRec{}, nil
+	}
+	batch := pgx.Batch{}
+	for _, recID := range recIDs {
+		batch.Queue(selectByID, recID.String())
+	}
+	br := ds.Conn.SendBatch(ds.Ctx, &batch)
+	defer func() {
+		err = errors.Join(err, br.Close())
+	}()
+	env := make(map[identity.ADT]DefRec, len(recIDs))
+	for _, recID := range recIDs {
+		idAttr := slog.Any("defID", recID)
+		rows, err := br.Query()
+		if err != nil {
+			dao.log.Error("query execution failed", idAttr, slog.String("q", selectByID))
+			return nil, err
+		}
+		dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[defRecDS])
+		if err != nil {
+			dao.log.Error("row collection failed", idAttr)
+			return nil, err
+		}
+		rec, err := DataToDefRec(dto)
+		if err != nil {
+			dao.log.Error("model conversion failed", idAttr)
+			return nil, err
+		}
+		env[recID] = rec
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("ids", recIDs))
+	return env, nil
+}
+
+const (
+	insertRec = `
+		insert into proc_defs (
+			def_id, def_rn, proc_es
+		) values (
+			@def_id, @def_rn, @proc_es
+		)`
+
+	selectByID = `
+		select
+			def_id, def_rn, proc_es
+		from proc_defs
+		where def_id = $1
+		order by def_rn desc
+		limit 1`
+)
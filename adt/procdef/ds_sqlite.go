@@ -0,0 +1,88 @@
+package procdef
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
+)
+
+// Adapter
+type sqliteDAO struct {
+	log *slog.Logger
+}
+
+func newSqliteDAO(l *slog.Logger) *sqliteDAO {
+	name := slog.String("name", reflect.TypeFor[sqliteDAO]().Name())
+	return &sqliteDAO{l.With(name)}
+}
+
+func (dao *sqliteDAO) InsertRec(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("defRef", rec.DefRef)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	args := db.NamedArgsSqlite{
+		"def_id":  dto.ID,
+		"def_rn":  dto.RN,
+		"proc_es": dto.ProcES,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertRecSqlite, args)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", insertRecSqlite))
+		return err
+	}
+	return nil
+}
+
+func (dao *sqliteDAO) SelectRecByID(source db.Source, recID identity.ADT) (DefRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	idAttr := slog.Any("defID", recID)
+	rows, err := ds.Conn.Query(ds.Ctx, selectByIDSqlite, db.NamedArgsSqlite{"def_id": recID.String()})
+	if err != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("q", selectByIDSqlite))
+		return DefRec{}, err
+	}
+	dto, err := db.CollectOneRowSqlite[defRecDS](rows)
+	if err != nil {
+		dao.log.Error("row collection failed", idAttr)
+		return DefRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", idAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *sqliteDAO) SelectEnv(source db.Source, recIDs []identity.ADT) (map[identity.ADT]DefRec, error) {
+	env := make(map[identity.ADT]DefRec, len(recIDs))
+	for _, recID := range recIDs {
+		rec, err := dao.SelectRecByID(source, recID)
+		if err != nil {
+			return nil, err
+		}
+		env[recID] = rec
+	}
+	return env, nil
+}
+
+const (
+	insertRecSqlite = `
+		insert into proc_defs (
+			def_id, def_rn, proc_es
+		) values (
+			:def_id, :def_rn, :proc_es
+		)`
+
+	selectByIDSqlite = `
+		select
+			def_id, def_rn, proc_es
+		from proc_defs
+		where def_id = :def_id
+		order by def_rn desc
+		limit 1`
+)
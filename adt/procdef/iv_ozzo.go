@@ -0,0 +1,13 @@
+package procdef
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/orglang/go-sdk/adt/uniqsym"
+)
+
+func (dto DefSpecVP) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.ProcQN, uniqsym.Required...),
+	)
+}
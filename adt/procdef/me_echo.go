@@ -0,0 +1,60 @@
+package procdef
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/uniqref"
+)
+
+// Server-side primary adapter
+type echoController struct {
+	api API
+	log *slog.Logger
+}
+
+func newEchoController(a API, l *slog.Logger) *echoController {
+	name := slog.String("name", reflect.TypeFor[echoController]().Name())
+	return &echoController{a, l.With(name)}
+}
+
+func cfgEchoController(e *echo.Echo, h *echoController) error {
+	e.POST("/api/v1/defs", h.PostSpec)
+	return nil
+}
+
+func (h *echoController) PostSpec(c echo.Context) error {
+	var dto DefSpecVP
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	ctx := c.Request().Context()
+	h.log.Log(ctx, lf.LevelTrace, "posting started", slog.Any("dto", dto))
+	validationErr := dto.Validate()
+	if validationErr != nil {
+		h.log.Error("validation failed", slog.Any("dto", dto))
+		return validationErr
+	}
+	spec, conversionErr := MsgToDefSpec(dto)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	ref, creationErr := h.api.Create(ctx, spec)
+	if errors.As(creationErr, new(CheckError)) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, ViewFromCheckErrors(creationErr)).SetInternal(creationErr)
+	}
+	if creationErr != nil {
+		return creationErr
+	}
+	h.log.Log(ctx, lf.LevelTrace, "posting succeed", slog.Any("defRef", ref))
+	return c.JSON(http.StatusCreated, uniqref.MsgFromADT(ref))
+}
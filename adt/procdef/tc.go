@@ -0,0 +1,100 @@
+package procdef
+
+import (
+	"errors"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procexp"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/typeexp"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+func MsgToDefSpec(dto DefSpecVP) (DefSpec, error) {
+	procQN, err := uniqsym.ConvertFromString(dto.ProcQN)
+	if err != nil {
+		return DefSpec{}, err
+	}
+	procES, err := procexp.MsgToExpSpec(dto.ProcES)
+	if err != nil {
+		return DefSpec{}, err
+	}
+	return DefSpec{ProcQN: procQN, ProcES: procES}, nil
+}
+
+func ViewFromCheckErrors(err error) []CheckErrorVP {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if ok {
+		var views []CheckErrorVP
+		for _, e := range joined.Unwrap() {
+			views = append(views, ViewFromCheckErrors(e)...)
+		}
+		return views
+	}
+	var checkErr CheckError
+	if errors.As(err, &checkErr) {
+		return []CheckErrorVP{viewFromCheckError(checkErr)}
+	}
+	return nil
+}
+
+func viewFromCheckError(err CheckError) CheckErrorVP {
+	view := CheckErrorVP{
+		K:      viewFromCheckErrorKind(err.K),
+		Msg:    err.Err.Error(),
+		Path:   err.Path,
+		ChnlPH: symbol.ConvertToString(err.ChnlPH),
+	}
+	var mismatchErr typeexp.MismatchError
+	if errors.As(err.Err, &mismatchErr) {
+		view.Want = typeexp.Key(mismatchErr.Want)
+		view.Got = typeexp.Key(mismatchErr.Got)
+	}
+	return view
+}
+
+func viewFromCheckErrorKind(kind CheckErrorKind) string {
+	switch kind {
+	case TypeMismatch:
+		return "type_mismatch"
+	case MissingChnl:
+		return "missing_chnl"
+	case CtxMismatch:
+		return "ctx_mismatch"
+	case LabelMismatch:
+		return "label_mismatch"
+	case PotInsufficient:
+		return "pot_insufficient"
+	// a kind without a code of its own is still a problem to show
+	default:
+		return "invalid_exp"
+	}
+}
+
+func DataFromDefRec(rec DefRec) (defRecDS, error) {
+	procES, err := procexp.DataFromExpSpec(rec.ProcES)
+	if err != nil {
+		return defRecDS{}, err
+	}
+	return defRecDS{
+		ID:     identity.ConvertToString(rec.DefRef.ID),
+		RN:     revnum.ConvertToInt(rec.DefRef.RN),
+		ProcES: procES,
+	}, nil
+}
+
+func DataToDefRec(dto defRecDS) (DefRec, error) {
+	id, err := identity.ConvertFromString(dto.ID)
+	if err != nil {
+		return DefRec{}, err
+	}
+	procES, err := procexp.DataToExpSpec(dto.ProcES)
+	if err != nil {
+		return DefRec{}, err
+	}
+	return DefRec{
+		DefRef: DefRef{ID: id, RN: revnum.ConvertFromInt(dto.RN)},
+		ProcES: procES,
+	}, nil
+}
@@ -0,0 +1,21 @@
+package procdef
+
+import (
+	"github.com/orglang/go-sdk/adt/procexp"
+)
+
+type DefSpecVP struct {
+	ProcQN string          `json:"proc_qn"`
+	ProcES procexp.ExpSpec `json:"proc_es"`
+}
+
+// type check diagnostic
+type CheckErrorVP struct {
+	K      string   `json:"code"`
+	Msg    string   `json:"message"`
+	Path   []string `json:"path"`
+	ChnlPH string   `json:"chnl_ph,omitempty"`
+	// expected and actual channel types
+	Want string `json:"want,omitempty"`
+	Got  string `json:"got,omitempty"`
+}
@@ -0,0 +1,74 @@
+package procdef
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+
+	"orglang/go-runtime/lib/lf"
+	"orglang/go-runtime/lib/te"
+
+	"orglang/go-runtime/adt/uniqref"
+)
+
+type echoPresenter struct {
+	api API
+	ssr te.Renderer
+	log *slog.Logger
+}
+
+func newEchoPresenter(a API, r te.Renderer, l *slog.Logger) *echoPresenter {
+	name := slog.String("name", reflect.TypeFor[echoPresenter]().Name())
+	return &echoPresenter{a, r, l.With(name)}
+}
+
+func cfgEchoPresenter(e *echo.Echo, p *echoPresenter) error {
+	e.POST("/ssr/defs", p.PostSpec)
+	return nil
+}
+
+func (p *echoPresenter) PostSpec(c echo.Context) error {
+	var dto DefSpecVP
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		p.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	ctx := c.Request().Context()
+	p.log.Log(ctx, lf.LevelTrace, "posting started", slog.Any("dto", dto))
+	validationErr := dto.Validate()
+	if validationErr != nil {
+		p.log.Error("validation failed", slog.Any("dto", dto))
+		return validationErr
+	}
+	spec, conversionErr := MsgToDefSpec(dto)
+	if conversionErr != nil {
+		p.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	ref, creationErr := p.api.Create(ctx, spec)
+	if errors.As(creationErr, new(CheckError)) {
+		html, renderingErr := p.ssr.Render("problems", ViewFromCheckErrors(creationErr))
+		if renderingErr != nil {
+			p.log.Error("rendering failed", slog.Any("reason", creationErr))
+			return renderingErr
+		}
+		// the problems replace the slot above the definition
+		c.Response().Header().Set("HX-Retarget", "#problems")
+		c.Response().Header().Set("HX-Reswap", "outerHTML")
+		return c.HTMLBlob(http.StatusUnprocessableEntity, html)
+	}
+	if creationErr != nil {
+		return creationErr
+	}
+	html, renderingErr := p.ssr.Render("view-one", uniqref.MsgFromADT(ref))
+	if renderingErr != nil {
+		p.log.Error("rendering failed", slog.Any("ref", ref))
+		return renderingErr
+	}
+	p.log.Log(ctx, lf.LevelTrace, "posting succeed", slog.Any("defRef", ref))
+	return c.HTMLBlob(http.StatusOK, html)
+}
@@ -0,0 +1,61 @@
+package procexec
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+
+	"go.uber.org/fx"
+
+	"orglang/go-runtime/lib/db"
+)
+
+// Offline primary adapter: rebuilds binds and steps from the mod journal
+// and stops the application. It is started without the scheduler, and
+// the storage refuses the replay while another node writes the journal.
+type replayerStdlib struct {
+	procExecs Repo
+	operator  db.Operator
+	log       *slog.Logger
+}
+
+func newReplayerStdlib(r Repo, o db.Operator, l *slog.Logger) *replayerStdlib {
+	name := slog.String("name", reflect.TypeFor[replayerStdlib]().Name())
+	return &replayerStdlib{r, o, l.With(name)}
+}
+
+func cfgReplayerStdlib(lc fx.Lifecycle, sd fx.Shutdowner, r *replayerStdlib) {
+	lc.Append(
+		fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				_, err := r.replay(ctx)
+				if err != nil {
+					return err
+				}
+				return sd.Shutdown()
+			},
+		},
+	)
+}
+
+func (r *replayerStdlib) replay(ctx context.Context) (rec ReplayRec, err error) {
+	r.log.Info("replay started")
+	err = r.operator.Explicit(ctx, func(ds db.Source) error {
+		rec, err = r.procExecs.ReplayMods(ds, true)
+		return err
+	})
+	if err != nil {
+		r.log.Error("replay failed", slog.Any("reason", err))
+		return ReplayRec{}, err
+	}
+	for _, drift := range rec.Drifts {
+		r.log.Warn("drift repaired", slog.Any("drift", drift))
+	}
+	// the journal cannot restore what it never recorded
+	for _, execID := range rec.Unverifiable {
+		r.log.Warn("execution unverifiable", slog.Any("execID", execID))
+	}
+	r.log.Info("replay succeed", slog.Int64("seq", rec.LastSeq), slog.Int("drifts", len(rec.Drifts)),
+		slog.Int("unverifiable", len(rec.Unverifiable)))
+	return rec, nil
+}
@@ -0,0 +1,32 @@
+package procexec
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"go.uber.org/fx"
+
+	"orglang/go-runtime/lib/db"
+)
+
+// the offline replay runs once and stops the application
+func TestReplayModule(t *testing.T) {
+	app := fx.New(
+		db.MemModule,
+		fx.NopLogger,
+		fx.Supply(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		ReplayModule,
+	)
+	err := app.Start(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	defer app.Stop(context.Background())
+	select {
+	case <-app.Wait():
+	default:
+		t.Errorf("got application running, want it stopped after the replay")
+	}
+}
@@ -2,14 +2,19 @@ package procexec
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	"log/slog"
 	"maps"
+	"math"
 	"reflect"
+	"slices"
+	"time"
 
 	"orglang/go-runtime/lib/db"
 
+	"orglang/go-runtime/adt/arithexp"
 	"orglang/go-runtime/adt/identity"
 	"orglang/go-runtime/adt/polarity"
 	"orglang/go-runtime/adt/procbind"
@@ -19,6 +24,7 @@ import (
 	"orglang/go-runtime/adt/procstep"
 	"orglang/go-runtime/adt/revnum"
 	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/syndec"
 	"orglang/go-runtime/adt/typedef"
 	"orglang/go-runtime/adt/typeexp"
 	"orglang/go-runtime/adt/uniqref"
@@ -26,8 +32,16 @@ import (
 )
 
 type API interface {
-	Take(procstep.StepSpec) error
-	RetrieveSnap(ExecRef) (ExecSnap, error)
+	// reduces inline; the REST API queues steps with Post instead
+	Take(context.Context, procstep.StepSpec) error
+	Post(context.Context, procstep.StepSpec) (TicketRef, error)
+	Poll(context.Context) (TicketRef, error)
+	RetrieveTicket(context.Context, TicketRef) (TicketSnap, error)
+	RetrieveSnap(context.Context, ExecRef) (ExecSnap, error)
+	Analyze(context.Context) ([]FindingRec, error)
+	Check(context.Context) ([]FindingRec, error)
+	// compares binds and steps with the mod journal
+	Verify(context.Context) (ReplayRec, error)
 }
 
 type ExecSpec struct {
@@ -38,34 +52,183 @@ type ExecSpec struct {
 
 type ExecRef = uniqref.ADT
 
+type ExecRec struct {
+	ExecRef ExecRef
+	DecRef  procdec.DecRef
+}
+
 // aka Configuration
 type ExecSnap struct {
 	ExecRef ExecRef
 	ChnlBRs map[symbol.ADT]procbind.BindRec
 	ProcSRs map[identity.ADT]procstep.StepRec
+	// heads of shared channel queues
+	AcqSRs  map[identity.ADT]procstep.StepRec
+	LeaseRs map[symbol.ADT]LeaseRec
+	// bind types resolved for inspection
+	ChnlESs map[symbol.ADT]typeexp.ExpSpec
+	// work performed so far and its static bound
+	Work int64
+	Pot  int64
+	// latest value received under each name
+	Vals map[symbol.ADT]procstep.ValRec
+}
+
+// shared channel held by a client
+type LeaseRec struct {
+	ChnlID     identity.ADT
+	ProviderID identity.ADT
+	ProviderPH symbol.ADT
+	ClientID   identity.ADT
+	ClientPH   symbol.ADT
 }
 
 type Env struct {
+	SynDecs  map[uniqsym.ADT]syndec.DecRec
+	ProcDefs map[identity.ADT]procdef.DefRec
 	TypeDefs map[uniqsym.ADT]typedef.DefRec
 	TypeExps map[identity.ADT]typeexp.ExpRec
 	ProcDecs map[identity.ADT]procdec.DecRec
+	// for unfolding type links
+	TypeEnv typeexp.Env
+}
+
+func (env Env) checkEnv() procdef.Env {
+	return procdef.Env{
+		SynDecs:  env.SynDecs,
+		ProcDecs: env.ProcDecs,
+		TypeDefs: env.TypeDefs,
+		TypeExps: env.TypeExps,
+		TypeEnv:  env.TypeEnv,
+	}
 }
 
 func ChnlPH(rec procbind.BindRec) symbol.ADT { return rec.ChnlPH }
 
+// Argument substitution derives states with stable IDs; they are stored
+// once along with the step so that later steps can find them by ID.
+func materialize(procEnv Env, rec typeexp.ExpRec, exps []typeexp.ExpRec) []typeexp.ExpRec {
+	_, ok := procEnv.TypeExps[rec.Ident()]
+	if ok {
+		return exps
+	}
+	procEnv.TypeExps[rec.Ident()] = rec
+	return append(exps, rec)
+}
+
+// ticket for a step put on the run queue
+type TicketRef = identity.ADT
+
+type TicketSnap struct {
+	TicketRef TicketRef
+	Status    RunStatus
+	Reason    string
+}
+
+// run queue item
+type RunRec struct {
+	RunID     identity.ADT
+	TicketRef TicketRef
+	StepSpec  procstep.StepSpec
+	Status    RunStatus
+	Reason    string
+	// the step is hidden from workers until then
+	WakeAt time.Time
+}
+
+type RunStatus uint8
+
+const (
+	PendingRun RunStatus = iota + 1
+	RunningRun
+	DoneRun
+	FailedRun
+)
+
+// half of a step awaiting its counterpart
+type WaitRec struct {
+	ExecID identity.ADT
+	ChnlID identity.ADT
+	ChnlPH symbol.ADT
+	// empty if the channel has no counterpart
+	PeerID identity.ADT
+}
+
+type FindingKind uint8
+
+const (
+	DeadlockFinding FindingKind = iota + 1
+	OrphanFinding
+)
+
+// detected stall of executions
+type FindingRec struct {
+	K     FindingKind
+	Waits []WaitRec
+}
+
+// Outcome of a mod journal replay. Only executions spawned after
+// the journal appeared are compared: it lacks the early mods of others,
+// so their rows are reported as unverifiable rather than as verified.
+type ReplayRec struct {
+	// last replayed journal entry
+	LastSeq int64
+	// rows restored from the journal
+	Binds int
+	Steps int
+	// executions spawned before the journal appeared
+	Unverifiable []identity.ADT
+	Drifts       []DriftRec
+}
+
+// the journal vouches for every live row
+func (rec ReplayRec) Verified() bool {
+	return len(rec.Unverifiable) == 0 && len(rec.Drifts) == 0
+}
+
+// journal versus live table mismatch for one execution
+type DriftRec struct {
+	ExecID identity.ADT
+	// journal rows missing from the tables
+	MissingBinds int
+	MissingSteps int
+	// table rows missing from the journal
+	ExtraBinds int
+	ExtraSteps int
+}
+
 type ExecMod struct {
+	Execs []ExecRec
 	Locks []ExecRef
 	Binds []procbind.BindRec
 	Steps []procstep.StepRec
+	// shared channel queues
+	Enqs []procstep.StepRec
+	Deqs []procstep.StepRec
+	// shared channel leases
+	Leases  []LeaseRec
+	Returns []LeaseRec
+	// counterparts resumed and children started by the step
+	Wakes []procstep.StepSpec
+	// exps produced by type argument substitution
+	Exps []typeexp.ExpRec
+	// ticks before the continuation runs
+	Delay int64
 }
 
 type service struct {
 	procExecs Repo
 	procDecs  procdec.Repo
+	procDefs  procdef.Repo
+	synDecs   syndec.Repo
 	typeDefs  typedef.Repo
 	typeExps  typeexp.Repo
 	operator  db.Operator
-	log       *slog.Logger
+	// period after which a stuck step is available again
+	lease time.Duration
+	// duration of one delay tick
+	tick time.Duration
+	log  *slog.Logger
 }
 
 // for compilation purposes
@@ -76,106 +239,451 @@ func newAPI() API {
 func newService(
 	procExecs Repo,
 	procDecs procdec.Repo,
+	procDefs procdef.Repo,
+	synDecs syndec.Repo,
 	typeDefs typedef.Repo,
 	typeExps typeexp.Repo,
 	operator db.Operator,
+	cs schedulingCS,
 	l *slog.Logger,
 ) *service {
 	name := slog.String("name", reflect.TypeFor[service]().Name())
-	return &service{procExecs, procDecs, typeDefs, typeExps, operator, l.With(name)}
+	return &service{procExecs, procDecs, procDefs, synDecs, typeDefs, typeExps, operator, cs.Lease, cs.Tick, l.With(name)}
 }
 
-func (s *service) RetrieveSnap(ref ExecRef) (_ ExecSnap, err error) {
-	return ExecSnap{}, nil
+func (s *service) RetrieveSnap(ctx context.Context, ref ExecRef) (_ ExecSnap, err error) {
+	refAttr := slog.Any("execRef", ref)
+	s.log.Debug("retrieval started", refAttr)
+	var snap ExecSnap
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		snap, err = s.procExecs.SelectSnap(ds, ref)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", refAttr)
+		return ExecSnap{}, err
+	}
+	ctxIDs := CollectCtx(maps.Values(snap.ChnlBRs))
+	var typeExps map[identity.ADT]typeexp.ExpRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		typeExps, err = s.typeExps.SelectEnv(ds, ctxIDs)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", refAttr, slog.Any("ctx", ctxIDs))
+		return ExecSnap{}, err
+	}
+	snap.ChnlESs = make(map[symbol.ADT]typeexp.ExpSpec, len(snap.ChnlBRs))
+	for chnlPH, bind := range snap.ChnlBRs {
+		typeExp, ok := typeExps[bind.ExpID]
+		if !ok {
+			err = typeexp.ErrMissingInEnv(bind.ExpID)
+			s.log.Error("retrieval failed", refAttr)
+			return ExecSnap{}, err
+		}
+		snap.ChnlESs[chnlPH] = typeexp.ConvertRecToSpec(typeExp)
+	}
+	s.log.Debug("retrieval succeed", refAttr)
+	return snap, nil
 }
 
 func ErrMissingChnl(want symbol.ADT) error {
 	return fmt.Errorf("channel missing in cfg: %v", want)
 }
 
-func (s *service) Take(spec procstep.StepSpec) (err error) {
+// takes the step with its continuations and counterparts until it stops,
+// a delayed continuation goes to the run queue
+func (s *service) Take(ctx context.Context, spec procstep.StepSpec) (err error) {
 	refAttr := slog.Any("execRef", spec.ExecRef)
 	s.log.Debug("taking started", refAttr)
-	ctx := context.Background()
-	// initial values
-	execRef := spec.ExecRef
-	expSpec := spec.ProcES
-	for expSpec != nil {
-		var execSnap ExecSnap
-		err = s.operator.Implicit(ctx, func(ds db.Source) error {
-			execSnap, err = s.procExecs.SelectSnap(ds, execRef)
-			return err
-		})
+	specs := []procstep.StepSpec{spec}
+	for len(specs) > 0 {
+		// stop between reductions, committed ones stay
+		err = ctx.Err()
 		if err != nil {
-			s.log.Error("taking failed", refAttr)
+			s.log.Error("taking failed", refAttr, slog.Any("reason", err))
 			return err
 		}
-		if len(execSnap.ChnlBRs) == 0 {
-			panic("zero channel binds")
-		}
-		decIDs := procexp.CollectEnv(expSpec)
-		var procDRs map[identity.ADT]procdec.DecRec
-		err = s.operator.Implicit(ctx, func(ds db.Source) error {
-			procDRs, err = s.procDecs.SelectEnv(ds, decIDs)
-			return err
-		})
+		nextSpec, procMod, err := s.takeOne(ctx, specs[0])
 		if err != nil {
-			s.log.Error("taking failed", refAttr, slog.Any("decs", decIDs))
+			s.log.Error("taking failed", refAttr)
 			return err
 		}
-		typeQNs := procdec.CollectEnv(maps.Values(procDRs))
-		var typeDefs map[uniqsym.ADT]typedef.DefRec
-		err = s.operator.Implicit(ctx, func(ds db.Source) error {
-			typeDefs, err = s.typeDefs.SelectEnv(ds, typeQNs)
-			return err
+		specs = append(specs[1:], procMod.Wakes...)
+		delayed := nextSpec.ProcES != nil && procMod.Delay > 0
+		err = s.operator.Explicit(ctx, func(ds db.Source) error {
+			err = s.updateProc(ds, procMod)
+			if err != nil {
+				return err
+			}
+			if !delayed {
+				return nil
+			}
+			delayedRun := RunRec{
+				RunID:     identity.New(),
+				TicketRef: identity.New(),
+				StepSpec:  nextSpec,
+				WakeAt:    time.Now().Add(time.Duration(procMod.Delay) * s.tick),
+			}
+			return s.procExecs.InsertRuns(ds, delayedRun)
 		})
 		if err != nil {
-			s.log.Error("taking failed", refAttr, slog.Any("types", typeQNs))
+			s.log.Error("taking failed", refAttr)
 			return err
 		}
-		envIDs := typedef.CollectEnv(maps.Values(typeDefs))
-		ctxIDs := CollectCtx(maps.Values(execSnap.ChnlBRs))
-		var typeExps map[identity.ADT]typeexp.ExpRec
-		err = s.operator.Implicit(ctx, func(ds db.Source) error {
-			typeExps, err = s.typeExps.SelectEnv(ds, append(envIDs, ctxIDs...))
-			return err
+		if nextSpec.ProcES != nil && !delayed {
+			specs = append(specs, nextSpec)
+		}
+	}
+	s.log.Debug("taking succeed", refAttr)
+	return nil
+}
+
+func (s *service) Post(ctx context.Context, spec procstep.StepSpec) (_ TicketRef, err error) {
+	refAttr := slog.Any("execRef", spec.ExecRef)
+	s.log.Debug("posting started", refAttr)
+	newRun := RunRec{RunID: identity.New(), TicketRef: identity.New(), StepSpec: spec}
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		return s.procExecs.InsertRuns(ds, newRun)
+	})
+	if err != nil {
+		s.log.Error("posting failed", refAttr)
+		return identity.Empty(), err
+	}
+	s.log.Debug("posting succeed", refAttr, slog.Any("ticketRef", newRun.TicketRef))
+	return newRun.TicketRef, nil
+}
+
+func (s *service) RetrieveTicket(ctx context.Context, ref TicketRef) (_ TicketSnap, err error) {
+	var snap TicketSnap
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		snap, err = s.procExecs.SelectTicket(ds, ref)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", slog.Any("ticketRef", ref))
+		return TicketSnap{}, err
+	}
+	return snap, nil
+}
+
+// takes the next step from the queue and performs one reduction
+func (s *service) Poll(ctx context.Context) (_ TicketRef, err error) {
+	// stop between reductions without claiming a step
+	err = ctx.Err()
+	if err != nil {
+		return identity.Empty(), err
+	}
+	var run RunRec
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		run, err = s.procExecs.SelectNextRun(ds, s.lease)
+		return err
+	})
+	if err != nil {
+		s.log.Error("polling failed")
+		return identity.Empty(), err
+	}
+	if run.RunID.IsEmpty() {
+		return identity.Empty(), nil
+	}
+	runAttr := slog.Any("runID", run.RunID)
+	s.log.Debug("polling started", runAttr, slog.Any("execRef", run.StepSpec.ExecRef))
+	nextSpec, procMod, err := s.takeOne(ctx, run.StepSpec)
+	if err == nil {
+		err = s.operator.Explicit(ctx, func(ds db.Source) error {
+			err = s.updateProc(ds, procMod)
+			if err != nil {
+				return err
+			}
+			var nextRuns []RunRec
+			if nextSpec.ProcES != nil {
+				nextRun := RunRec{RunID: identity.New(), TicketRef: run.TicketRef, StepSpec: nextSpec}
+				// a delayed continuation waits in the queue for its tick
+				if procMod.Delay > 0 {
+					nextRun.WakeAt = time.Now().Add(time.Duration(procMod.Delay) * s.tick)
+				}
+				nextRuns = append(nextRuns, nextRun)
+			}
+			// counterparts run under the same ticket
+			for _, wake := range procMod.Wakes {
+				nextRuns = append(nextRuns, RunRec{RunID: identity.New(), TicketRef: run.TicketRef, StepSpec: wake})
+			}
+			err = s.procExecs.InsertRuns(ds, nextRuns...)
+			if err != nil {
+				return err
+			}
+			run.Status = DoneRun
+			return s.procExecs.UpdateRun(ds, run)
 		})
+	}
+	if err == nil {
+		s.log.Debug("polling succeed", runAttr)
+		return run.TicketRef, nil
+	}
+	s.log.Error("polling failed", runAttr, slog.Any("reason", err))
+	// retry later on concurrent update or cancellation
+	if errors.Is(err, errConcurrentUpdate) || ctx.Err() != nil {
+		run.Status = PendingRun
+	} else {
+		run.Status = FailedRun
+		run.Reason = err.Error()
+	}
+	// requeueing must not depend on cancellation
+	updateErr := s.operator.Explicit(context.WithoutCancel(ctx), func(ds db.Source) error {
+		return s.procExecs.UpdateRun(ds, run)
+	})
+	return run.TicketRef, errors.Join(err, updateErr)
+}
+
+// exps derived by the step are stored along with the mod
+func (s *service) updateProc(ds db.Source, procMod ExecMod) error {
+	for _, exp := range procMod.Exps {
+		err := s.typeExps.InsertRec(ds, exp)
 		if err != nil {
-			s.log.Error("taking failed", refAttr, slog.Any("env", envIDs), slog.Any("ctx", ctxIDs))
 			return err
 		}
-		procEnv := Env{ProcDecs: procDRs, TypeDefs: typeDefs, TypeExps: typeExps}
-		procCtx := convertToCtx(maps.Values(execSnap.ChnlBRs), typeExps)
-		// type checking
-		err = s.checkType(procEnv, procCtx, execSnap, expSpec)
-		if err != nil {
-			s.log.Error("taking failed", refAttr)
-			return err
+	}
+	return s.procExecs.UpdateProc(ds, procMod)
+}
+
+func (s *service) Analyze(ctx context.Context) (_ []FindingRec, err error) {
+	s.log.Debug("analysis started")
+	var waits []WaitRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		waits, err = s.procExecs.SelectWaits(ds)
+		return err
+	})
+	if err != nil {
+		s.log.Error("analysis failed")
+		return nil, err
+	}
+	findings := AnalyzeWaits(waits)
+	s.log.Debug("analysis succeed", slog.Int("waits", len(waits)), slog.Int("findings", len(findings)))
+	return findings, nil
+}
+
+func (s *service) Check(ctx context.Context) (_ []FindingRec, err error) {
+	findings, err := s.Analyze(ctx)
+	if err != nil {
+		s.log.Error("check failed")
+		return nil, err
+	}
+	if len(findings) == 0 {
+		return findings, nil
+	}
+	for _, finding := range findings {
+		s.log.Warn("finding detected", slog.Any("kind", finding.K), slog.Any("waits", finding.Waits))
+	}
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		return s.procExecs.InsertFindings(ds, findings...)
+	})
+	if err != nil {
+		s.log.Error("check failed")
+		return nil, err
+	}
+	return findings, nil
+}
+
+func (s *service) Verify(ctx context.Context) (rec ReplayRec, err error) {
+	s.log.Debug("verification started")
+	// the journal and the tables are read in one transaction
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		rec, err = s.procExecs.ReplayMods(ds, false)
+		return err
+	})
+	if err != nil {
+		s.log.Error("verification failed")
+		return ReplayRec{}, err
+	}
+	for _, drift := range rec.Drifts {
+		s.log.Warn("drift detected", slog.Any("drift", drift))
+	}
+	for _, execID := range rec.Unverifiable {
+		s.log.Warn("execution unverifiable", slog.Any("execID", execID))
+	}
+	s.log.Debug("verification succeed", slog.Int64("seq", rec.LastSeq), slog.Int("drifts", len(rec.Drifts)),
+		slog.Int("unverifiable", len(rec.Unverifiable)))
+	return rec, nil
+}
+
+// builds the wait graph between executions and looks for cycles and orphan waits
+func AnalyzeWaits(waits []WaitRec) []FindingRec {
+	var findings []FindingRec
+	graph := make(map[identity.ADT][]WaitRec, len(waits))
+	for _, wait := range waits {
+		if wait.PeerID.IsEmpty() {
+			findings = append(findings, FindingRec{K: OrphanFinding, Waits: []WaitRec{wait}})
+			continue
+		}
+		graph[wait.ExecID] = append(graph[wait.ExecID], wait)
+	}
+	// Tarjan's strongly connected components
+	index := make(map[identity.ADT]int, len(graph))
+	lowlink := make(map[identity.ADT]int, len(graph))
+	onStack := make(map[identity.ADT]bool, len(graph))
+	var stack []identity.ADT
+	var connect func(identity.ADT)
+	connect = func(v identity.ADT) {
+		index[v] = len(index)
+		lowlink[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, wait := range graph[v] {
+			w := wait.PeerID
+			// a side that is not blocked can proceed
+			if _, blocked := graph[w]; !blocked {
+				continue
+			}
+			if _, visited := index[w]; !visited {
+				connect(w)
+				lowlink[v] = min(lowlink[v], lowlink[w])
+			} else if onStack[w] {
+				lowlink[v] = min(lowlink[v], index[w])
+			}
 		}
-		// step taking
-		nextSpec, procMod, err := s.takeWith(procEnv, execSnap, expSpec)
-		if err != nil {
-			s.log.Error("taking failed", refAttr)
-			return err
+		if lowlink[v] != index[v] {
+			return
+		}
+		component := make(map[identity.ADT]bool)
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component[w] = true
+			if w == v {
+				break
+			}
 		}
-		err = s.operator.Explicit(ctx, func(ds db.Source) error {
-			err = s.procExecs.UpdateProc(ds, procMod)
+		var cycle []WaitRec
+		for _, execID := range slices.SortedFunc(maps.Keys(component), compareIDs) {
+			for _, wait := range graph[execID] {
+				if component[wait.PeerID] {
+					cycle = append(cycle, wait)
+				}
+			}
+		}
+		if len(cycle) > 0 {
+			findings = append(findings, FindingRec{K: DeadlockFinding, Waits: cycle})
+		}
+	}
+	for _, execID := range slices.SortedFunc(maps.Keys(graph), compareIDs) {
+		if _, visited := index[execID]; !visited {
+			connect(execID)
+		}
+	}
+	return findings
+}
+
+func (s *service) takeOne(ctx context.Context, spec procstep.StepSpec) (_ procstep.StepSpec, _ ExecMod, err error) {
+	refAttr := slog.Any("execRef", spec.ExecRef)
+	var execSnap ExecSnap
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		execSnap, err = s.procExecs.SelectSnap(ds, spec.ExecRef)
+		return err
+	})
+	if err != nil {
+		s.log.Error("taking failed", refAttr)
+		return procstep.StepSpec{}, ExecMod{}, err
+	}
+	if len(execSnap.ChnlBRs) == 0 {
+		panic("zero channel binds")
+	}
+	procQNs := procexp.CollectEnv(spec.ProcES)
+	var synDRs map[uniqsym.ADT]syndec.DecRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		synDRs, err = s.synDecs.SelectEnv(ds, procQNs)
+		return err
+	})
+	if err != nil {
+		s.log.Error("taking failed", refAttr, slog.Any("procs", procQNs))
+		return procstep.StepSpec{}, ExecMod{}, err
+	}
+	decIDs := syndec.CollectEnv(maps.Values(synDRs))
+	defIDs := collectDefs(spec.ProcES, synDRs)
+	var procDefs map[identity.ADT]procdef.DefRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		procDefs, err = s.procDefs.SelectEnv(ds, defIDs)
+		return err
+	})
+	if err != nil {
+		s.log.Error("taking failed", refAttr, slog.Any("defs", defIDs))
+		return procstep.StepSpec{}, ExecMod{}, err
+	}
+	var procDRs map[identity.ADT]procdec.DecRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		procDRs, err = s.procDecs.SelectEnv(ds, decIDs)
+		return err
+	})
+	if err != nil {
+		s.log.Error("taking failed", refAttr, slog.Any("decs", decIDs))
+		return procstep.StepSpec{}, ExecMod{}, err
+	}
+	typeQNs := procdec.CollectEnv(maps.Values(procDRs))
+	var typeDefs map[uniqsym.ADT]typedef.DefRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		typeDefs, err = s.typeDefs.SelectEnv(ds, typeQNs)
+		return err
+	})
+	if err != nil {
+		s.log.Error("taking failed", refAttr, slog.Any("types", typeQNs))
+		return procstep.StepSpec{}, ExecMod{}, err
+	}
+	envIDs := typedef.CollectEnv(maps.Values(typeDefs))
+	ctxIDs := CollectCtx(maps.Values(execSnap.ChnlBRs))
+	var typeExps map[identity.ADT]typeexp.ExpRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		typeExps, err = s.typeExps.SelectEnv(ds, append(envIDs, ctxIDs...))
+		return err
+	})
+	if err != nil {
+		s.log.Error("taking failed", refAttr, slog.Any("env", envIDs), slog.Any("ctx", ctxIDs))
+		return procstep.StepSpec{}, ExecMod{}, err
+	}
+	// type links are unfolded during checking, so the env is closed over them
+	for {
+		linkQNs := typedef.MissingLinks(maps.Values(typeExps), typeDefs)
+		if len(linkQNs) == 0 {
+			break
+		}
+		var linkDefs map[uniqsym.ADT]typedef.DefRec
+		var linkExps map[identity.ADT]typeexp.ExpRec
+		err = s.operator.Implicit(ctx, func(ds db.Source) error {
+			linkDefs, err = s.typeDefs.SelectEnv(ds, linkQNs)
 			if err != nil {
-				s.log.Error("taking failed", refAttr)
 				return err
 			}
-			return nil
+			linkExps, err = s.typeExps.SelectEnv(ds, typedef.CollectEnv(maps.Values(linkDefs)))
+			return err
 		})
 		if err != nil {
-			s.log.Error("taking failed", refAttr)
-			return err
+			s.log.Error("taking failed", refAttr, slog.Any("links", linkQNs))
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		// next values
-		execRef = nextSpec.ExecRef
-		expSpec = nextSpec.ProcES
+		maps.Copy(typeDefs, linkDefs)
+		maps.Copy(typeExps, linkExps)
 	}
-	s.log.Debug("taking succeed", refAttr)
-	return nil
+	procEnv := Env{
+		SynDecs:  synDRs,
+		ProcDefs: procDefs,
+		ProcDecs: procDRs,
+		TypeDefs: typeDefs,
+		TypeExps: typeExps,
+		TypeEnv:  typedef.ConvertToEnv(typeDefs, typeExps),
+	}
+	procCtx := convertToCtx(maps.Values(execSnap.ChnlBRs), typeExps, maps.Values(execSnap.Vals))
+	// type checking
+	err = procdef.CheckExp(procEnv.checkEnv(), procCtx, spec.ProcES)
+	if err != nil {
+		s.log.Error("taking failed", refAttr, slog.Any("reason", err))
+		return procstep.StepSpec{}, ExecMod{}, err
+	}
+	// step taking
+	nextSpec, procMod, err := s.takeWith(procEnv, execSnap, spec.ProcES)
+	if err != nil {
+		s.log.Error("taking failed", refAttr)
+		return procstep.StepSpec{}, ExecMod{}, err
+	}
+	return nextSpec, procMod, nil
 }
 
 func (s *service) takeWith(
@@ -333,6 +841,12 @@ func (s *service) takeWith(
 			s.log.Error("taking failed", viaAttr)
 			return procstep.StepSpec{}, ExecMod{}, err
 		}
+		typeER, err := procEnv.TypeEnv.Unfold(typeER)
+		if err != nil {
+			s.log.Error("taking failed", viaAttr)
+			return procstep.StepSpec{}, ExecMod{}, err
+		}
+		execMod.Exps = materialize(procEnv, typeER, execMod.Exps)
 		nextExpID := typeER.(typeexp.ProdRec).Next()
 		valueEP, ok := execSnap.ChnlBRs[expSpec.ValChnlPH]
 		if !ok {
@@ -463,6 +977,12 @@ func (s *service) takeWith(
 				s.log.Error("taking failed", viaAttr)
 				return procstep.StepSpec{}, ExecMod{}, err
 			}
+			typeER, err := procEnv.TypeEnv.Unfold(typeER)
+			if err != nil {
+				s.log.Error("taking failed", viaAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
+			}
+			execMod.Exps = materialize(procEnv, typeER, execMod.Exps)
 			recieverBR := procbind.BindRec{
 				ExecRef: ExecRef{
 					ID: execSnap.ExecRef.ID,
@@ -507,6 +1027,12 @@ func (s *service) takeWith(
 			s.log.Error("taking failed", viaAttr)
 			return procstep.StepSpec{}, ExecMod{}, err
 		}
+		typeER, err := procEnv.TypeEnv.Unfold(typeER)
+		if err != nil {
+			s.log.Error("taking failed", viaAttr)
+			return procstep.StepSpec{}, ExecMod{}, err
+		}
+		execMod.Exps = materialize(procEnv, typeER, execMod.Exps)
 		nextExpID := typeER.(typeexp.SumRec).Next(expSpec.LabelQN)
 		recieverSR := execSnap.ProcSRs[commChnlBR.ChnlID]
 		if recieverSR == nil {
@@ -611,6 +1137,12 @@ func (s *service) takeWith(
 				s.log.Error("taking failed", viaAttr)
 				return procstep.StepSpec{}, ExecMod{}, err
 			}
+			typeER, err := procEnv.TypeEnv.Unfold(typeER)
+			if err != nil {
+				s.log.Error("taking failed", viaAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
+			}
+			execMod.Exps = materialize(procEnv, typeER, execMod.Exps)
 			recieverBR := procbind.BindRec{
 				ExecRef: ExecRef{
 					ID: execSnap.ExecRef.ID,
@@ -777,443 +1309,1000 @@ func (s *service) takeWith(
 		default:
 			panic(typeexp.ErrPolarityUnexpected(commChnlER))
 		}
-	default:
-		panic(procexp.ErrExpTypeUnexpected(es))
-	}
-}
-
-func CollectCtx(chnls iter.Seq[procbind.BindRec]) []identity.ADT {
-	return nil
-}
-
-func convertToCtx(chnlBinds iter.Seq[procbind.BindRec], typeExps map[identity.ADT]typeexp.ExpRec) typedef.Context {
-	assets := make(map[symbol.ADT]typeexp.ExpRec, 1)
-	liabs := make(map[symbol.ADT]typeexp.ExpRec, 1)
-	for bind := range chnlBinds {
-		if bind.ChnlBS == procbind.ProviderSide {
-			liabs[bind.ChnlPH] = typeExps[bind.ExpID]
-		} else {
-			assets[bind.ChnlPH] = typeExps[bind.ExpID]
-		}
-	}
-	return typedef.Context{Assets: assets, Liabs: liabs}
-}
-
-func (s *service) checkType(
-	procEnv Env,
-	procCtx typedef.Context,
-	execSnap ExecSnap,
-	expSpec procexp.ExpSpec,
-) error {
-	chnlBR, ok := execSnap.ChnlBRs[expSpec.Via()]
-	if !ok {
-		panic("no comm chnl in proc snap")
-	}
-	if chnlBR.ChnlBS == procbind.ProviderSide {
-		return s.checkProvider(procEnv, procCtx, execSnap, expSpec)
-	} else {
-		return s.checkClient(procEnv, procCtx, execSnap, expSpec)
-	}
-}
-
-func (s *service) checkProvider(
-	procEnv Env,
-	procCtx typedef.Context,
-	procCfg ExecSnap,
-	es procexp.ExpSpec,
-) error {
-	switch expSpec := es.(type) {
-	case procexp.CloseSpec:
-		// check ctx
-		if len(procCtx.Assets) > 0 {
-			err := fmt.Errorf("context mismatch: want 0 items, got %v items", len(procCtx.Assets))
-			s.log.Error("checking failed")
-			return err
-		}
-		// check via
-		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+	case procexp.SpawnSpec:
+		procSD, ok := procEnv.SynDecs[expSpec.ProcQN]
 		if !ok {
-			err := typedef.ErrMissingInCtx(expSpec.CommChnlPH)
-			s.log.Error("checking failed")
-			return err
-		}
-		err := typeexp.CheckRec(gotVia, typeexp.OneRec{})
-		if err != nil {
-			s.log.Error("checking failed")
-			return err
+			err := errMissingProc(expSpec.ProcQN)
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		// no cont to check
-		delete(procCtx.Liabs, expSpec.CommChnlPH)
-		return nil
-	case procexp.WaitSpec:
-		err := procexp.ErrExpTypeMismatch(es, procexp.CloseSpec{})
-		s.log.Error("checking failed")
-		return err
-	case procexp.SendSpec:
-		// check via
-		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		procDR, ok := procEnv.ProcDecs[procSD.DecID]
 		if !ok {
-			err := typedef.ErrMissingInCtx(expSpec.CommChnlPH)
-			s.log.Error("checking failed")
-			return err
+			err := procdec.ErrRootMissingInEnv(procSD.DecID)
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		wantVia, ok := gotVia.(typeexp.TensorRec)
+		procDef, ok := procEnv.ProcDefs[procSD.DecID]
 		if !ok {
-			err := typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
-			s.log.Error("checking failed")
-			return err
+			err := procdef.ErrDoesNotExist(procSD.DecID)
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		// check value
-		gotVal, ok := procCtx.Assets[expSpec.ValChnlPH]
-		if !ok {
-			err := procdef.ErrMissingInCtx(expSpec.ValChnlPH)
-			s.log.Error("checking failed")
-			return err
+		if len(expSpec.BindChnlPHs) != len(procDR.ClientBSs) {
+			err := procdef.ErrCtxMismatch(len(procDR.ClientBSs), len(expSpec.BindChnlPHs))
+			s.log.Error("taking failed", slog.Any("want", procDR.ClientBSs), slog.Any("got", expSpec.BindChnlPHs))
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		err := typeexp.CheckRec(gotVal, wantVia.Y)
+		viaBS, err := procdef.InstanceBind(procDR, procDR.ProviderBS, expSpec.IdxESs)
 		if err != nil {
-			s.log.Error("checking failed")
-			return err
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		// no cont to check
-		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Z
-		delete(procCtx.Assets, expSpec.ValChnlPH)
-		return nil
-	case procexp.RecvSpec:
-		// check via
-		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
-		if !ok {
-			err := typedef.ErrMissingInCtx(expSpec.CommChnlPH)
-			s.log.Error("checking failed")
-			return err
+		viaER, err := procdef.LookupType(procEnv.checkEnv(), viaBS)
+		if err != nil {
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		wantVia, ok := gotVia.(typeexp.LolliRec)
-		if !ok {
-			err := typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
-			s.log.Error("checking failed")
-			return err
+		execMod.Exps = materialize(procEnv, viaER, execMod.Exps)
+		execMod.Locks = append(execMod.Locks, execSnap.ExecRef)
+		childER := ExecRec{
+			ExecRef: ExecRef{ID: identity.New(), RN: revnum.New()},
+			DecRef:  procDR.DecRef,
+		}
+		execMod.Execs = append(execMod.Execs, childER)
+		childAttr := slog.Any("childRef", childER.ExecRef)
+		newChnlID := identity.New()
+		providerBR := procbind.BindRec{
+			ExecRef: childER.ExecRef,
+			ChnlBS:  procbind.ProviderSide,
+			ChnlPH:  procDR.ProviderBS.ChnlPH,
+			ChnlID:  newChnlID,
+			ExpID:   viaER.Ident(),
+		}
+		execMod.Binds = append(execMod.Binds, providerBR)
+		clientBR := procbind.BindRec{
+			ExecRef: ExecRef{
+				ID: execSnap.ExecRef.ID,
+				RN: execSnap.ExecRef.RN.Next(),
+			},
+			ChnlBS: procbind.ClientSide,
+			ChnlPH: expSpec.CommChnlPH,
+			ChnlID: newChnlID,
+			ExpID:  viaER.Ident(),
+		}
+		execMod.Binds = append(execMod.Binds, clientBR)
+		for i, bindPH := range expSpec.BindChnlPHs {
+			valChnlBR, ok := execSnap.ChnlBRs[bindPH]
+			if !ok {
+				err := procdef.ErrMissingInCfg(bindPH)
+				s.log.Error("taking failed", childAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
+			}
+			// channel moves to child
+			childBR := procbind.BindRec{
+				ExecRef: childER.ExecRef,
+				ChnlBS:  procbind.ClientSide,
+				ChnlPH:  procDR.ClientBSs[i].ChnlPH,
+				ChnlID:  valChnlBR.ChnlID,
+				ExpID:   valChnlBR.ExpID,
+			}
+			execMod.Binds = append(execMod.Binds, childBR)
+			// shared channel stays with parent too
+			valER, ok := procEnv.TypeExps[valChnlBR.ExpID]
+			if ok && typeexp.IsShared(valER) {
+				continue
+			}
+			parentBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: -execSnap.ExecRef.RN.Next(),
+				},
+				ChnlPH: bindPH,
+			}
+			execMod.Binds = append(execMod.Binds, parentBR)
+		}
+		// child binds carry declaration placeholders, so the body runs unrenamed
+		childSS := procstep.StepSpec{
+			ExecRef: childER.ExecRef,
+			ProcES:  procDef.ProcES,
+		}
+		execMod.Wakes = append(execMod.Wakes, childSS)
+		stepSpec = procstep.StepSpec{
+			ExecRef: execSnap.ExecRef,
+			ProcES:  expSpec.ContES,
+		}
+		s.log.Debug("taking succeed", childAttr)
+		return stepSpec, execMod, nil
+	case procexp.CallSpec:
+		if expSpec.ContES != nil {
+			err := procdef.ErrNonTailCall(expSpec.ProcQN)
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		// check value
-		gotVal, ok := procCtx.Assets[expSpec.BindChnlPH]
+		procSD, ok := procEnv.SynDecs[expSpec.ProcQN]
 		if !ok {
-			err := procdef.ErrMissingInCtx(expSpec.BindChnlPH)
-			s.log.Error("checking failed")
-			return err
+			err := errMissingProc(expSpec.ProcQN)
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		err := typeexp.CheckRec(gotVal, wantVia.Y)
-		if err != nil {
-			s.log.Error("checking failed")
-			return err
-		}
-		// check cont
-		procCtx.Liabs[expSpec.CommChnlPH] = wantVia.Z
-		procCtx.Assets[expSpec.BindChnlPH] = wantVia.Y
-		return s.checkType(procEnv, procCtx, procCfg, expSpec.ContES)
-	case procexp.LabSpec:
-		// check via
-		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
-		if !ok {
-			err := typedef.ErrMissingInCtx(expSpec.CommChnlPH)
-			s.log.Error("checking failed")
-			return err
-		}
-		wantVia, ok := gotVia.(typeexp.PlusRec)
-		if !ok {
-			err := typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
-			s.log.Error("checking failed")
-			return err
-		}
-		// check label
-		choice, ok := wantVia.Zs[expSpec.LabelQN]
-		if !ok {
-			err := fmt.Errorf("label mismatch: want %v, got %q", maps.Keys(wantVia.Zs), expSpec.LabelQN)
-			s.log.Error("checking failed")
-			return err
-		}
-		// no cont to check
-		procCtx.Liabs[expSpec.CommChnlPH] = choice
-		return nil
-	case procexp.CaseSpec:
-		// check via
-		gotVia, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		procDR, ok := procEnv.ProcDecs[procSD.DecID]
 		if !ok {
-			err := typedef.ErrMissingInCtx(expSpec.CommChnlPH)
-			s.log.Error("checking failed")
-			return err
+			err := procdec.ErrRootMissingInEnv(procSD.DecID)
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		wantVia, ok := gotVia.(typeexp.WithRec)
+		procDef, ok := procEnv.ProcDefs[procSD.DecID]
 		if !ok {
-			err := typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
-			s.log.Error("checking failed")
-			return err
+			err := procdef.ErrDoesNotExist(procSD.DecID)
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		// check conts
-		if len(expSpec.ContESs) != len(wantVia.Zs) {
-			err := fmt.Errorf("state mismatch: want %v choices, got %v conts", len(wantVia.Zs), len(expSpec.ContESs))
-			s.log.Error("checking failed")
-			return err
+		if len(expSpec.ValChnlPHs) != len(procDR.ClientBSs) {
+			err := procdef.ErrCtxMismatch(len(procDR.ClientBSs), len(expSpec.ValChnlPHs))
+			s.log.Error("taking failed", slog.Any("want", procDR.ClientBSs), slog.Any("got", expSpec.ValChnlPHs))
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		for label, choice := range wantVia.Zs {
-			cont, ok := expSpec.ContESs[label]
-			if !ok {
-				err := fmt.Errorf("label mismatch: want %q, got nothing", label)
-				s.log.Error("checking failed")
-				return err
-			}
-			procCtx.Liabs[expSpec.CommChnlPH] = choice
-			err := s.checkType(procEnv, procCtx, procCfg, cont)
-			if err != nil {
-				s.log.Error("checking failed")
-				return err
-			}
+		// callee placeholders to caller ones
+		chnlPHs := make(map[symbol.ADT]symbol.ADT, len(procDR.ClientBSs)+1)
+		chnlPHs[procDR.ProviderBS.ChnlPH] = expSpec.BindChnlPH
+		for i, bs := range procDR.ClientBSs {
+			chnlPHs[bs.ChnlPH] = expSpec.ValChnlPHs[i]
 		}
-		return nil
-	case procexp.FwdSpec:
-		if len(procCtx.Assets) != 1 {
-			err := fmt.Errorf("context mismatch: want 1 item, got %v items", len(procCtx.Assets))
-			s.log.Error("checking failed")
-			return err
+		execMod.Locks = append(execMod.Locks, execSnap.ExecRef)
+		stepSpec = procstep.StepSpec{
+			ExecRef: execSnap.ExecRef,
+			ProcES:  procexp.RenameSpec(procDef.ProcES, chnlPHs),
 		}
-		viaSt, ok := procCtx.Liabs[expSpec.CommChnlPH]
+		s.log.Debug("taking succeed", slog.Any("procQN", expSpec.ProcQN))
+		return stepSpec, execMod, nil
+	case procexp.AcquireSpec:
+		commChnlBR, ok := execSnap.ChnlBRs[expSpec.CommChnlPH]
 		if !ok {
-			err := typedef.ErrMissingInCtx(expSpec.CommChnlPH)
-			s.log.Error("checking failed")
-			return err
+			err := procdef.ErrMissingInCfg(expSpec.CommChnlPH)
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		fwdSt, ok := procCtx.Assets[expSpec.ContChnlPH]
+		viaAttr := slog.Any("chnlID", commChnlBR.ChnlID)
+		execMod.Locks = append(execMod.Locks, execSnap.ExecRef)
+		typeER, ok := procEnv.TypeExps[commChnlBR.ExpID]
 		if !ok {
-			err := procdef.ErrMissingInCtx(expSpec.ContChnlPH)
-			s.log.Error("checking failed")
-			return err
-		}
-		if fwdSt.Pol() != viaSt.Pol() {
-			err := typeexp.ErrPolarityMismatch(fwdSt, viaSt)
-			s.log.Error("checking failed")
-			return err
+			err := typedef.ErrMissingInEnv(commChnlBR.ExpID)
+			s.log.Error("taking failed", viaAttr)
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		err := typeexp.CheckRec(fwdSt, viaSt)
+		typeER, err := procEnv.TypeEnv.Unfold(typeER)
 		if err != nil {
-			s.log.Error("checking failed")
-			return err
-		}
-		delete(procCtx.Liabs, expSpec.CommChnlPH)
-		delete(procCtx.Assets, expSpec.ContChnlPH)
-		return nil
-	default:
-		panic(procexp.ErrExpTypeUnexpected(es))
-	}
-}
-
-func (s *service) checkClient(
-	procEnv Env,
-	procCtx typedef.Context,
-	procCfg ExecSnap,
-	es procexp.ExpSpec,
-) error {
-	switch expSpec := es.(type) {
-	case procexp.CloseSpec:
-		err := procexp.ErrExpTypeMismatch(es, procexp.WaitSpec{})
-		s.log.Error("checking failed")
-		return err
-	case procexp.WaitSpec:
-		// check via
-		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
-		if !ok {
-			err := procdef.ErrMissingInCtx(expSpec.CommChnlPH)
-			s.log.Error("checking failed")
-			return err
-		}
-		wantVia, ok := gotVia.(typeexp.OneRec)
-		if !ok {
-			err := typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
-			s.log.Error("checking failed")
-			return err
-		}
-		// check cont
-		delete(procCtx.Assets, expSpec.CommChnlPH)
-		return s.checkType(procEnv, procCtx, procCfg, expSpec.ContES)
-	case procexp.SendSpec:
-		// check via
-		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
-		if !ok {
-			err := procdef.ErrMissingInCtx(expSpec.CommChnlPH)
-			s.log.Error("checking failed")
-			return err
+			s.log.Error("taking failed", viaAttr)
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		wantVia, ok := gotVia.(typeexp.LolliRec)
+		shiftER, ok := typeER.(typeexp.UpRec)
 		if !ok {
-			err := typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
-			s.log.Error("checking failed")
-			return err
+			err := typeexp.ErrSnapTypeMismatch(typeER, typeexp.UpRec{})
+			s.log.Error("taking failed", viaAttr)
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		// check value
-		gotVal, ok := procCtx.Assets[expSpec.ValChnlPH]
+		execMod.Exps = materialize(procEnv, typeER, execMod.Exps)
+		nextExpID := shiftER.Z.Ident()
+		serviceSR, ok := execSnap.AcqSRs[commChnlBR.ChnlID].(procstep.SvcRec)
 		if !ok {
-			err := procdef.ErrMissingInCtx(expSpec.ValChnlPH)
-			s.log.Error("checking failed")
-			return err
-		}
-		err := typeexp.CheckRec(gotVal, wantVia.Y)
-		if err != nil {
-			s.log.Error("checking failed")
-			return err
+			clientSR := procstep.MsgRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlID: commChnlBR.ChnlID,
+				ValER: procexp.AcquireRec{
+					CommChnlPH: expSpec.CommChnlPH,
+					ContES:     expSpec.ContES,
+				},
+			}
+			execMod.Enqs = append(execMod.Enqs, clientSR)
+			s.log.Debug("taking half done", viaAttr)
+			return stepSpec, execMod, nil
 		}
-		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Z
-		delete(procCtx.Assets, expSpec.ValChnlPH)
-		return nil
-	case procexp.RecvSpec:
-		// check via
-		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
-		if !ok {
-			err := procdef.ErrMissingInCtx(expSpec.CommChnlPH)
-			s.log.Error("checking failed")
-			return err
+		switch expRec := serviceSR.ContER.(type) {
+		case procexp.AcceptRec:
+			newChnlID := identity.New()
+			clientBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlBS: procbind.ClientSide,
+				ChnlPH: expSpec.CommChnlPH,
+				ChnlID: newChnlID,
+				ExpID:  nextExpID,
+			}
+			execMod.Binds = append(execMod.Binds, clientBR)
+			providerBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: serviceSR.ExecRef.ID,
+					RN: serviceSR.ExecRef.RN.Next(),
+				},
+				ChnlBS: procbind.ProviderSide,
+				ChnlPH: expRec.CommChnlPH,
+				ChnlID: newChnlID,
+				ExpID:  nextExpID,
+			}
+			execMod.Binds = append(execMod.Binds, providerBR)
+			execMod.Deqs = append(execMod.Deqs, serviceSR)
+			leaseRec := LeaseRec{
+				ChnlID:     commChnlBR.ChnlID,
+				ProviderID: serviceSR.ExecRef.ID,
+				ProviderPH: expRec.CommChnlPH,
+				ClientID:   execSnap.ExecRef.ID,
+				ClientPH:   expSpec.CommChnlPH,
+			}
+			execMod.Leases = append(execMod.Leases, leaseRec)
+			providerSS := procstep.StepSpec{
+				ExecRef: serviceSR.ExecRef,
+				ProcES:  expRec.ContES,
+			}
+			execMod.Wakes = append(execMod.Wakes, providerSS)
+			stepSpec = procstep.StepSpec{
+				ExecRef: execSnap.ExecRef,
+				ProcES:  expSpec.ContES,
+			}
+			s.log.Debug("taking succeed", viaAttr)
+			return stepSpec, execMod, nil
+		default:
+			panic(procexp.ErrRecTypeUnexpected(serviceSR.ContER))
 		}
-		wantVia, ok := gotVia.(typeexp.TensorRec)
+	case procexp.AcceptSpec:
+		commChnlBR, ok := execSnap.ChnlBRs[expSpec.CommChnlPH]
 		if !ok {
-			err := typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
-			s.log.Error("checking failed")
-			return err
+			err := procdef.ErrMissingInCfg(expSpec.CommChnlPH)
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		// check value
-		gotVal, ok := procCtx.Assets[expSpec.BindChnlPH]
+		viaAttr := slog.Any("chnlID", commChnlBR.ChnlID)
+		execMod.Locks = append(execMod.Locks, execSnap.ExecRef)
+		typeER, ok := procEnv.TypeExps[commChnlBR.ExpID]
 		if !ok {
-			err := procdef.ErrMissingInCtx(expSpec.BindChnlPH)
-			s.log.Error("checking failed")
-			return err
+			err := typedef.ErrMissingInEnv(commChnlBR.ExpID)
+			s.log.Error("taking failed", viaAttr)
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		err := typeexp.CheckRec(gotVal, wantVia.Y)
+		typeER, err := procEnv.TypeEnv.Unfold(typeER)
 		if err != nil {
-			s.log.Error("checking failed")
-			return err
-		}
-		// check cont
-		procCtx.Assets[expSpec.CommChnlPH] = wantVia.Z
-		procCtx.Assets[expSpec.BindChnlPH] = wantVia.Y
-		return s.checkType(procEnv, procCtx, procCfg, expSpec.ContES)
-	case procexp.LabSpec:
-		// check via
-		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
-		if !ok {
-			err := procdef.ErrMissingInCtx(expSpec.CommChnlPH)
-			s.log.Error("checking failed")
-			return err
-		}
-		wantVia, ok := gotVia.(typeexp.WithRec)
-		if !ok {
-			err := typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
-			s.log.Error("checking failed")
-			return err
-		}
-		// check label
-		choice, ok := wantVia.Zs[expSpec.LabelQN]
-		if !ok {
-			err := fmt.Errorf("label mismatch: want %v, got %q", maps.Keys(wantVia.Zs), expSpec.LabelQN)
-			s.log.Error("checking failed")
-			return err
+			s.log.Error("taking failed", viaAttr)
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		procCtx.Assets[expSpec.CommChnlPH] = choice
-		return nil
-	case procexp.CaseSpec:
-		// check via
-		gotVia, ok := procCtx.Assets[expSpec.CommChnlPH]
+		shiftER, ok := typeER.(typeexp.UpRec)
 		if !ok {
-			err := procdef.ErrMissingInCtx(expSpec.CommChnlPH)
-			s.log.Error("checking failed")
-			return err
+			err := typeexp.ErrSnapTypeMismatch(typeER, typeexp.UpRec{})
+			s.log.Error("taking failed", viaAttr)
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		wantVia, ok := gotVia.(typeexp.PlusRec)
+		execMod.Exps = materialize(procEnv, typeER, execMod.Exps)
+		nextExpID := shiftER.Z.Ident()
+		messageSR, ok := execSnap.AcqSRs[commChnlBR.ChnlID].(procstep.MsgRec)
 		if !ok {
-			err := typeexp.ErrSnapTypeMismatch(gotVia, wantVia)
-			s.log.Error("checking failed")
-			return err
-		}
-		// check conts
-		if len(expSpec.ContESs) != len(wantVia.Zs) {
-			err := fmt.Errorf("state mismatch: want %v choices, got %v conts", len(wantVia.Zs), len(expSpec.ContESs))
-			s.log.Error("checking failed")
-			return err
+			providerSR := procstep.SvcRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlID: commChnlBR.ChnlID,
+				ContER: procexp.AcceptRec{
+					CommChnlPH: expSpec.CommChnlPH,
+					ContES:     expSpec.ContES,
+				},
+			}
+			execMod.Enqs = append(execMod.Enqs, providerSR)
+			s.log.Debug("taking half done", viaAttr)
+			return stepSpec, execMod, nil
 		}
-		for label, choice := range wantVia.Zs {
-			cont, ok := expSpec.ContESs[label]
-			if !ok {
-				err := fmt.Errorf("label mismatch: want %q, got nothing", label)
-				s.log.Error("checking failed")
-				return err
+		switch expRec := messageSR.ValER.(type) {
+		case procexp.AcquireRec:
+			newChnlID := identity.New()
+			providerBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlBS: procbind.ProviderSide,
+				ChnlPH: expSpec.CommChnlPH,
+				ChnlID: newChnlID,
+				ExpID:  nextExpID,
 			}
-			procCtx.Assets[expSpec.CommChnlPH] = choice
-			err := s.checkType(procEnv, procCtx, procCfg, cont)
-			if err != nil {
-				s.log.Error("checking failed")
-				return err
+			execMod.Binds = append(execMod.Binds, providerBR)
+			clientBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: messageSR.ExecRef.ID,
+					RN: messageSR.ExecRef.RN.Next(),
+				},
+				ChnlBS: procbind.ClientSide,
+				ChnlPH: expRec.CommChnlPH,
+				ChnlID: newChnlID,
+				ExpID:  nextExpID,
+			}
+			execMod.Binds = append(execMod.Binds, clientBR)
+			execMod.Deqs = append(execMod.Deqs, messageSR)
+			leaseRec := LeaseRec{
+				ChnlID:     commChnlBR.ChnlID,
+				ProviderID: execSnap.ExecRef.ID,
+				ProviderPH: expSpec.CommChnlPH,
+				ClientID:   messageSR.ExecRef.ID,
+				ClientPH:   expRec.CommChnlPH,
+			}
+			execMod.Leases = append(execMod.Leases, leaseRec)
+			clientSS := procstep.StepSpec{
+				ExecRef: messageSR.ExecRef,
+				ProcES:  expRec.ContES,
+			}
+			execMod.Wakes = append(execMod.Wakes, clientSS)
+			stepSpec = procstep.StepSpec{
+				ExecRef: execSnap.ExecRef,
+				ProcES:  expSpec.ContES,
 			}
+			s.log.Debug("taking succeed", viaAttr)
+			return stepSpec, execMod, nil
+		default:
+			panic(procexp.ErrRecTypeUnexpected(messageSR.ValER))
 		}
-		return nil
-	case procexp.SpawnSpecOld:
-		procDec, ok := procEnv.ProcDecs[expSpec.SigID]
+	case procexp.ReleaseSpec:
+		commChnlBR, ok := execSnap.ChnlBRs[expSpec.CommChnlPH]
 		if !ok {
-			err := procdec.ErrRootMissingInEnv(expSpec.SigID)
-			s.log.Error("checking failed")
-			return err
+			err := procdef.ErrMissingInCfg(expSpec.CommChnlPH)
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
 		}
-		// check vals
-		if len(expSpec.Ys) != len(procDec.ClientBSs) {
-			err := fmt.Errorf("context mismatch: want %v items, got %v items", len(procDec.ClientBSs), len(expSpec.Ys))
-			s.log.Error("checking failed", slog.Any("want", procDec.ClientBSs), slog.Any("got", expSpec.Ys))
-			return err
+		viaAttr := slog.Any("chnlID", commChnlBR.ChnlID)
+		execMod.Locks = append(execMod.Locks, execSnap.ExecRef)
+		recieverSR := execSnap.ProcSRs[commChnlBR.ChnlID]
+		if recieverSR == nil {
+			senderSR := procstep.MsgRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlID: commChnlBR.ChnlID,
+				ValER: procexp.ReleaseRec{
+					CommChnlPH: expSpec.CommChnlPH,
+				},
+			}
+			execMod.Steps = append(execMod.Steps, senderSR)
+			s.log.Debug("taking half done", viaAttr)
+			return stepSpec, execMod, nil
 		}
-		if len(expSpec.Ys) == 0 {
-			return nil
+		serviceSR, ok := recieverSR.(procstep.SvcRec)
+		if !ok {
+			panic(procstep.ErrRecTypeUnexpected(recieverSR))
 		}
-		for i, ep := range procDec.ClientBSs {
-			valRole, ok := procEnv.TypeDefs[ep.TypeQN]
+		switch expRec := serviceSR.ContER.(type) {
+		case procexp.DetachRec:
+			leaseRec, ok := execSnap.LeaseRs[expSpec.CommChnlPH]
 			if !ok {
-				err := typedef.ErrSymMissingInEnv(ep.TypeQN)
-				s.log.Error("checking failed")
-				return err
+				err := errMissingLease(expSpec.CommChnlPH)
+				s.log.Error("taking failed", viaAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
 			}
-			wantVal, ok := procEnv.TypeExps[valRole.ExpID]
+			typeER, ok := procEnv.TypeExps[commChnlBR.ExpID]
 			if !ok {
-				err := typedef.ErrMissingInEnv(valRole.ExpID)
-				s.log.Error("checking failed")
-				return err
+				err := typedef.ErrMissingInEnv(commChnlBR.ExpID)
+				s.log.Error("taking failed", viaAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
 			}
-			gotVal, ok := procCtx.Assets[expSpec.Ys[i]]
+			typeER, err := procEnv.TypeEnv.Unfold(typeER)
+			if err != nil {
+				s.log.Error("taking failed", viaAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
+			}
+			shiftER, ok := typeER.(typeexp.DownRec)
 			if !ok {
-				err := procdef.ErrMissingInCtx(ep.ChnlPH)
-				s.log.Error("checking failed")
-				return err
+				err := typeexp.ErrSnapTypeMismatch(typeER, typeexp.DownRec{})
+				s.log.Error("taking failed", viaAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
 			}
-			err := typeexp.CheckRec(gotVal, wantVal)
-			if err != nil {
-				s.log.Error("checking failed", slog.Any("want", wantVal), slog.Any("got", gotVal))
-				return err
+			execMod.Exps = materialize(procEnv, typeER, execMod.Exps)
+			nextExpID := shiftER.Z.Ident()
+			clientBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlBS: procbind.ClientSide,
+				ChnlPH: expSpec.CommChnlPH,
+				ChnlID: leaseRec.ChnlID,
+				ExpID:  nextExpID,
 			}
-			delete(procCtx.Assets, expSpec.Ys[i])
-		}
-		// check via
-		viaRole, ok := procEnv.TypeDefs[procDec.ProviderBS.TypeQN]
-		if !ok {
-			err := typedef.ErrSymMissingInEnv(procDec.ProviderBS.TypeQN)
-			s.log.Error("checking failed")
-			return err
-		}
-		wantVia, ok := procEnv.TypeExps[viaRole.ExpID]
-		if !ok {
-			err := typedef.ErrMissingInEnv(viaRole.ExpID)
-			s.log.Error("checking failed")
-			return err
-		}
-		// check cont
-		procCtx.Assets[expSpec.X] = wantVia
-		return s.checkType(procEnv, procCtx, procCfg, expSpec.ContES)
-	default:
-		panic(procexp.ErrExpTypeUnexpected(es))
+			execMod.Binds = append(execMod.Binds, clientBR)
+			providerBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: serviceSR.ExecRef.ID,
+					RN: serviceSR.ExecRef.RN.Next(),
+				},
+				ChnlBS: procbind.ProviderSide,
+				ChnlPH: expRec.CommChnlPH,
+				ChnlID: leaseRec.ChnlID,
+				ExpID:  nextExpID,
+			}
+			execMod.Binds = append(execMod.Binds, providerBR)
+			execMod.Returns = append(execMod.Returns, leaseRec)
+			s.log.Debug("taking succeed", viaAttr)
+			return stepSpec, execMod, nil
+		default:
+			panic(procexp.ErrRecTypeUnexpected(serviceSR.ContER))
+		}
+	case procexp.DetachSpec:
+		commChnlBR, ok := execSnap.ChnlBRs[expSpec.CommChnlPH]
+		if !ok {
+			err := procdef.ErrMissingInCfg(expSpec.CommChnlPH)
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
+		}
+		viaAttr := slog.Any("chnlID", commChnlBR.ChnlID)
+		execMod.Locks = append(execMod.Locks, execSnap.ExecRef)
+		senderSR := execSnap.ProcSRs[commChnlBR.ChnlID]
+		if senderSR == nil {
+			recieverSR := procstep.SvcRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlID: commChnlBR.ChnlID,
+				ContER: procexp.DetachRec{
+					CommChnlPH: expSpec.CommChnlPH,
+				},
+			}
+			execMod.Steps = append(execMod.Steps, recieverSR)
+			s.log.Debug("taking half done", viaAttr)
+			return stepSpec, execMod, nil
+		}
+		messageSR, ok := senderSR.(procstep.MsgRec)
+		if !ok {
+			panic(procstep.ErrRecTypeUnexpected(senderSR))
+		}
+		switch expRec := messageSR.ValER.(type) {
+		case procexp.ReleaseRec:
+			leaseRec, ok := execSnap.LeaseRs[expSpec.CommChnlPH]
+			if !ok {
+				err := errMissingLease(expSpec.CommChnlPH)
+				s.log.Error("taking failed", viaAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
+			}
+			typeER, ok := procEnv.TypeExps[commChnlBR.ExpID]
+			if !ok {
+				err := typedef.ErrMissingInEnv(commChnlBR.ExpID)
+				s.log.Error("taking failed", viaAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
+			}
+			typeER, err := procEnv.TypeEnv.Unfold(typeER)
+			if err != nil {
+				s.log.Error("taking failed", viaAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
+			}
+			shiftER, ok := typeER.(typeexp.DownRec)
+			if !ok {
+				err := typeexp.ErrSnapTypeMismatch(typeER, typeexp.DownRec{})
+				s.log.Error("taking failed", viaAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
+			}
+			execMod.Exps = materialize(procEnv, typeER, execMod.Exps)
+			nextExpID := shiftER.Z.Ident()
+			providerBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlBS: procbind.ProviderSide,
+				ChnlPH: expSpec.CommChnlPH,
+				ChnlID: leaseRec.ChnlID,
+				ExpID:  nextExpID,
+			}
+			execMod.Binds = append(execMod.Binds, providerBR)
+			clientBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: messageSR.ExecRef.ID,
+					RN: messageSR.ExecRef.RN.Next(),
+				},
+				ChnlBS: procbind.ClientSide,
+				ChnlPH: expRec.CommChnlPH,
+				ChnlID: leaseRec.ChnlID,
+				ExpID:  nextExpID,
+			}
+			execMod.Binds = append(execMod.Binds, clientBR)
+			execMod.Returns = append(execMod.Returns, leaseRec)
+			s.log.Debug("taking succeed", viaAttr)
+			return stepSpec, execMod, nil
+		default:
+			panic(procexp.ErrRecTypeUnexpected(messageSR.ValER))
+		}
+	case procexp.SendIdxSpec:
+		return s.takeGhost(procEnv, execSnap, expSpec.CommChnlPH, expSpec.ContES, func(typeER typeexp.ExpRec) typeexp.ExpRec {
+			switch rec := typeER.(type) {
+			case typeexp.ExistsRec:
+				return typeexp.Subst(rec.Z, nil, map[symbol.ADT]arithexp.ExpSpec{rec.IdxVar: expSpec.IdxES})
+			case typeexp.ForallRec:
+				return typeexp.Subst(rec.Z, nil, map[symbol.ADT]arithexp.ExpSpec{rec.IdxVar: expSpec.IdxES})
+			default:
+				return nil
+			}
+		})
+	case procexp.RecvIdxSpec:
+		idxES := arithexp.VarSpec{IdxVar: expSpec.IdxVar}
+		return s.takeGhost(procEnv, execSnap, expSpec.CommChnlPH, expSpec.ContES, func(typeER typeexp.ExpRec) typeexp.ExpRec {
+			switch rec := typeER.(type) {
+			case typeexp.ExistsRec:
+				return typeexp.Subst(rec.Z, nil, map[symbol.ADT]arithexp.ExpSpec{rec.IdxVar: idxES})
+			case typeexp.ForallRec:
+				return typeexp.Subst(rec.Z, nil, map[symbol.ADT]arithexp.ExpSpec{rec.IdxVar: idxES})
+			default:
+				return nil
+			}
+		})
+	case procexp.AssertSpec:
+		return s.takeGhost(procEnv, execSnap, expSpec.CommChnlPH, expSpec.ContES, nextCond)
+	case procexp.AssumeSpec:
+		return s.takeGhost(procEnv, execSnap, expSpec.CommChnlPH, expSpec.ContES, nextCond)
+	case procexp.WorkSpec:
+		workAttr := slog.Any("work", expSpec.Work)
+		execMod.Locks = append(execMod.Locks, execSnap.ExecRef)
+		workSR := procstep.WorkRec{
+			ExecRef: ExecRef{
+				ID: execSnap.ExecRef.ID,
+				RN: execSnap.ExecRef.RN.Next(),
+			},
+			Work: expSpec.Work,
+		}
+		execMod.Steps = append(execMod.Steps, workSR)
+		stepSpec = procstep.StepSpec{
+			ExecRef: execSnap.ExecRef,
+			ProcES:  expSpec.ContES,
+		}
+		s.log.Debug("taking succeed", workAttr)
+		return stepSpec, execMod, nil
+	case procexp.DelaySpec:
+		delayAttr := slog.Any("ticks", expSpec.Ticks)
+		execMod.Locks = append(execMod.Locks, execSnap.ExecRef)
+		for chnlPH, chnlBR := range execSnap.ChnlBRs {
+			typeER, ok := procEnv.TypeExps[chnlBR.ExpID]
+			if !ok {
+				err := typedef.ErrMissingInEnv(chnlBR.ExpID)
+				s.log.Error("taking failed", delayAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
+			}
+			patient := typeexp.IsPatientAsset
+			if chnlBR.ChnlBS == procbind.ProviderSide {
+				patient = typeexp.IsPatientLiab
+			}
+			nextER, exps, err := advance(procEnv, typeER, expSpec.Ticks, patient, execMod.Exps)
+			if err != nil {
+				s.log.Error("taking failed", delayAttr, slog.Any("chnlID", chnlBR.ChnlID))
+				return procstep.StepSpec{}, ExecMod{}, err
+			}
+			execMod.Exps = exps
+			// a patient type does not change over time
+			if nextER.Ident() == typeER.Ident() {
+				continue
+			}
+			chnlBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlBS: chnlBR.ChnlBS,
+				ChnlPH: chnlPH,
+				ChnlID: chnlBR.ChnlID,
+				ExpID:  nextER.Ident(),
+			}
+			execMod.Binds = append(execMod.Binds, chnlBR)
+		}
+		execMod.Delay = expSpec.Ticks
+		stepSpec = procstep.StepSpec{
+			ExecRef: execSnap.ExecRef,
+			ProcES:  expSpec.ContES,
+		}
+		s.log.Debug("taking succeed", delayAttr)
+		return stepSpec, execMod, nil
+	case procexp.NowSpec:
+		return s.takeGhost(procEnv, execSnap, expSpec.CommChnlPH, expSpec.ContES, nextModal)
+	case procexp.WhenSpec:
+		return s.takeGhost(procEnv, execSnap, expSpec.CommChnlPH, expSpec.ContES, nextModal)
+	case procexp.SendValSpec:
+		commChnlBR, ok := execSnap.ChnlBRs[expSpec.CommChnlPH]
+		if !ok {
+			err := procdef.ErrMissingInCfg(expSpec.CommChnlPH)
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
+		}
+		viaAttr := slog.Any("chnlID", commChnlBR.ChnlID)
+		execMod.Locks = append(execMod.Locks, execSnap.ExecRef)
+		val := expSpec.ValLit
+		if len(expSpec.ValVar) > 0 {
+			valRec, ok := execSnap.Vals[expSpec.ValVar]
+			if !ok {
+				err := errMissingVal(expSpec.ValVar)
+				s.log.Error("taking failed", viaAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
+			}
+			val = valRec.Val
+		}
+		typeER, ok := procEnv.TypeExps[commChnlBR.ExpID]
+		if !ok {
+			err := typedef.ErrMissingInEnv(commChnlBR.ExpID)
+			s.log.Error("taking failed", viaAttr)
+			return procstep.StepSpec{}, ExecMod{}, err
+		}
+		typeER, err := procEnv.TypeEnv.Unfold(typeER)
+		if err != nil {
+			s.log.Error("taking failed", viaAttr)
+			return procstep.StepSpec{}, ExecMod{}, err
+		}
+		execMod.Exps = materialize(procEnv, typeER, execMod.Exps)
+		nextExpID := typeER.(typeexp.ProdRec).Next()
+		recieverSR := execSnap.ProcSRs[commChnlBR.ChnlID]
+		// the value sender does not wait for the receiver
+		stepSpec = procstep.StepSpec{
+			ExecRef: execSnap.ExecRef,
+			ProcES:  expSpec.ContES,
+		}
+		if recieverSR == nil {
+			newChnlID := identity.New()
+			senderBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlBS: commChnlBR.ChnlBS,
+				ChnlPH: expSpec.CommChnlPH,
+				ChnlID: newChnlID,
+				ExpID:  nextExpID,
+			}
+			execMod.Binds = append(execMod.Binds, senderBR)
+			senderSR := procstep.MsgRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlID: commChnlBR.ChnlID,
+				ValER: procexp.SendValRec{
+					CommChnlPH: expSpec.CommChnlPH,
+					ContChnlID: newChnlID,
+					Val:        val,
+				},
+			}
+			execMod.Steps = append(execMod.Steps, senderSR)
+			s.log.Debug("taking half done", viaAttr)
+			return stepSpec, execMod, nil
+		}
+		serviceSR, ok := recieverSR.(procstep.SvcRec)
+		if !ok {
+			panic(procstep.ErrRecTypeUnexpected(recieverSR))
+		}
+		switch expRec := serviceSR.ContER.(type) {
+		case procexp.RecvValRec:
+			recieverBS := procbind.ProviderSide
+			if commChnlBR.ChnlBS == procbind.ProviderSide {
+				recieverBS = procbind.ClientSide
+			}
+			senderBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlBS: commChnlBR.ChnlBS,
+				ChnlPH: expSpec.CommChnlPH,
+				ChnlID: expRec.ContChnlID,
+				ExpID:  nextExpID,
+			}
+			execMod.Binds = append(execMod.Binds, senderBR)
+			recieverBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: serviceSR.ExecRef.ID,
+					RN: serviceSR.ExecRef.RN.Next(),
+				},
+				ChnlBS: recieverBS,
+				ChnlPH: expRec.CommChnlPH,
+				ChnlID: expRec.ContChnlID,
+				ExpID:  nextExpID,
+			}
+			execMod.Binds = append(execMod.Binds, recieverBR)
+			recieverVR := procstep.ValRec{
+				ExecRef: ExecRef{
+					ID: serviceSR.ExecRef.ID,
+					RN: serviceSR.ExecRef.RN.Next(),
+				},
+				BindVar: expRec.BindVar,
+				T:       dataType(typeER),
+				Val:     val,
+			}
+			execMod.Steps = append(execMod.Steps, recieverVR)
+			recieverSS := procstep.StepSpec{
+				ExecRef: serviceSR.ExecRef,
+				ProcES:  expRec.ContES,
+			}
+			execMod.Wakes = append(execMod.Wakes, recieverSS)
+			s.log.Debug("taking succeed", viaAttr)
+			return stepSpec, execMod, nil
+		default:
+			panic(procexp.ErrRecTypeUnexpected(serviceSR.ContER))
+		}
+	case procexp.RecvValSpec:
+		commChnlBR, ok := execSnap.ChnlBRs[expSpec.CommChnlPH]
+		if !ok {
+			err := procdef.ErrMissingInCfg(expSpec.CommChnlPH)
+			s.log.Error("taking failed")
+			return procstep.StepSpec{}, ExecMod{}, err
+		}
+		viaAttr := slog.Any("chnlID", commChnlBR.ChnlID)
+		execMod.Locks = append(execMod.Locks, execSnap.ExecRef)
+		senderSR := execSnap.ProcSRs[commChnlBR.ChnlID]
+		if senderSR == nil {
+			receiverSR := procstep.SvcRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlID: commChnlBR.ChnlID,
+				ContER: procexp.RecvValRec{
+					CommChnlPH: expSpec.CommChnlPH,
+					ContChnlID: identity.New(),
+					BindVar:    expSpec.BindVar,
+					ContES:     expSpec.ContES,
+				},
+			}
+			execMod.Steps = append(execMod.Steps, receiverSR)
+			s.log.Debug("taking half done", viaAttr)
+			return stepSpec, execMod, nil
+		}
+		messageSR, ok := senderSR.(procstep.MsgRec)
+		if !ok {
+			panic(procstep.ErrRecTypeUnexpected(senderSR))
+		}
+		switch expRec := messageSR.ValER.(type) {
+		case procexp.SendValRec:
+			typeER, ok := procEnv.TypeExps[commChnlBR.ExpID]
+			if !ok {
+				err := typedef.ErrMissingInEnv(commChnlBR.ExpID)
+				s.log.Error("taking failed", viaAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
+			}
+			typeER, err := procEnv.TypeEnv.Unfold(typeER)
+			if err != nil {
+				s.log.Error("taking failed", viaAttr)
+				return procstep.StepSpec{}, ExecMod{}, err
+			}
+			execMod.Exps = materialize(procEnv, typeER, execMod.Exps)
+			recieverBR := procbind.BindRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				ChnlBS: commChnlBR.ChnlBS,
+				ChnlPH: expSpec.CommChnlPH,
+				ChnlID: expRec.ContChnlID,
+				ExpID:  typeER.(typeexp.ProdRec).Next(),
+			}
+			execMod.Binds = append(execMod.Binds, recieverBR)
+			recieverVR := procstep.ValRec{
+				ExecRef: ExecRef{
+					ID: execSnap.ExecRef.ID,
+					RN: execSnap.ExecRef.RN.Next(),
+				},
+				BindVar: expSpec.BindVar,
+				T:       dataType(typeER),
+				Val:     expRec.Val,
+			}
+			execMod.Steps = append(execMod.Steps, recieverVR)
+			stepSpec = procstep.StepSpec{
+				ExecRef: execSnap.ExecRef,
+				ProcES:  expSpec.ContES,
+			}
+			s.log.Debug("taking succeed", viaAttr)
+			return stepSpec, execMod, nil
+		default:
+			panic(procexp.ErrRecTypeUnexpected(messageSR.ValER))
+		}
+	default:
+		panic(procexp.ErrExpTypeUnexpected(es))
+	}
+}
+
+// Strips ◯ on every tick; a patient type (□ for a client, ◇ for a provider)
+// stays as is. Unfolded states are stored along with the step.
+func advance(
+	procEnv Env,
+	typeER typeexp.ExpRec,
+	ticks int64,
+	patient func(typeexp.ExpRec) bool,
+	exps []typeexp.ExpRec,
+) (typeexp.ExpRec, []typeexp.ExpRec, error) {
+	for range ticks {
+		unfoldedER, err := procEnv.TypeEnv.Unfold(typeER)
+		if err != nil {
+			return nil, nil, err
+		}
+		if unfoldedER.Ident() != typeER.Ident() {
+			exps = materialize(procEnv, unfoldedER, exps)
+		}
+		if patient(unfoldedER) {
+			return unfoldedER, exps, nil
+		}
+		nextER, ok := unfoldedER.(typeexp.NextRec)
+		if !ok {
+			return nil, nil, typeexp.ErrRecTypeUnexpected(unfoldedER)
+		}
+		typeER = nextER.Z
+	}
+	return typeER, exps, nil
+}
+
+// Indexes and constraints are erased at run time: a step without
+// a partner only advances the channel type to its continuation.
+func (s *service) takeGhost(
+	procEnv Env,
+	execSnap ExecSnap,
+	commChnlPH symbol.ADT,
+	contES procexp.ExpSpec,
+	next func(typeexp.ExpRec) typeexp.ExpRec,
+) (
+	stepSpec procstep.StepSpec,
+	execMod ExecMod,
+	_ error,
+) {
+	commChnlBR, ok := execSnap.ChnlBRs[commChnlPH]
+	if !ok {
+		err := procdef.ErrMissingInCfg(commChnlPH)
+		s.log.Error("taking failed")
+		return procstep.StepSpec{}, ExecMod{}, err
+	}
+	viaAttr := slog.Any("chnlID", commChnlBR.ChnlID)
+	typeER, ok := procEnv.TypeExps[commChnlBR.ExpID]
+	if !ok {
+		err := typedef.ErrMissingInEnv(commChnlBR.ExpID)
+		s.log.Error("taking failed", viaAttr)
+		return procstep.StepSpec{}, ExecMod{}, err
+	}
+	typeER, err := procEnv.TypeEnv.Unfold(typeER)
+	if err != nil {
+		s.log.Error("taking failed", viaAttr)
+		return procstep.StepSpec{}, ExecMod{}, err
+	}
+	execMod.Exps = materialize(procEnv, typeER, execMod.Exps)
+	nextER := next(typeER)
+	if nextER == nil {
+		err := typeexp.ErrRecTypeUnexpected(typeER)
+		s.log.Error("taking failed", viaAttr)
+		return procstep.StepSpec{}, ExecMod{}, err
+	}
+	// the continuation of an existing exp is already stored with it
+	if !slices.Contains(typeexp.CollectIDs(typeER), nextER.Ident()) {
+		execMod.Exps = materialize(procEnv, nextER, execMod.Exps)
+	}
+	execMod.Locks = append(execMod.Locks, execSnap.ExecRef)
+	commBR := procbind.BindRec{
+		ExecRef: ExecRef{
+			ID: execSnap.ExecRef.ID,
+			RN: execSnap.ExecRef.RN.Next(),
+		},
+		ChnlBS: commChnlBR.ChnlBS,
+		ChnlPH: commChnlPH,
+		ChnlID: commChnlBR.ChnlID,
+		ExpID:  nextER.Ident(),
+	}
+	execMod.Binds = append(execMod.Binds, commBR)
+	stepSpec = procstep.StepSpec{
+		ExecRef: execSnap.ExecRef,
+		ProcES:  contES,
+	}
+	s.log.Debug("taking succeed", viaAttr)
+	return stepSpec, execMod, nil
+}
+
+func nextModal(typeER typeexp.ExpRec) typeexp.ExpRec {
+	switch rec := typeER.(type) {
+	case typeexp.BoxRec:
+		return rec.Z
+	case typeexp.DiamondRec:
+		return rec.Z
+	default:
+		return nil
 	}
 }
 
+func dataType(typeER typeexp.ExpRec) typeexp.DataType {
+	switch rec := typeER.(type) {
+	case typeexp.AndRec:
+		return rec.T
+	case typeexp.ImplRec:
+		return rec.T
+	default:
+		panic(typeexp.ErrRecTypeUnexpected(typeER))
+	}
+}
+
+func nextCond(typeER typeexp.ExpRec) typeexp.ExpRec {
+	switch rec := typeER.(type) {
+	case typeexp.AssertRec:
+		return rec.Z
+	case typeexp.AssumeRec:
+		return rec.Z
+	default:
+		return nil
+	}
+}
+
+// only the head call or spawn needs the callee body
+func collectDefs(es procexp.ExpSpec, synDecs map[uniqsym.ADT]syndec.DecRec) []identity.ADT {
+	var procQN uniqsym.ADT
+	switch expSpec := es.(type) {
+	case procexp.CallSpec:
+		procQN = expSpec.ProcQN
+	case procexp.SpawnSpec:
+		procQN = expSpec.ProcQN
+	default:
+		return []identity.ADT{}
+	}
+	procSD, ok := synDecs[procQN]
+	if !ok {
+		return []identity.ADT{}
+	}
+	return []identity.ADT{procSD.DecID}
+}
+
+func CollectCtx(chnls iter.Seq[procbind.BindRec]) []identity.ADT {
+	expIDs := []identity.ADT{}
+	for bind := range chnls {
+		expIDs = append(expIDs, bind.ExpID)
+	}
+	return expIDs
+}
+
+func convertToCtx(
+	chnlBinds iter.Seq[procbind.BindRec],
+	typeExps map[identity.ADT]typeexp.ExpRec,
+	valRecs iter.Seq[procstep.ValRec],
+) typedef.Context {
+	assets := make(map[symbol.ADT]typeexp.ExpRec, 1)
+	liabs := make(map[symbol.ADT]typeexp.ExpRec, 1)
+	for bind := range chnlBinds {
+		if bind.ChnlBS == procbind.ProviderSide {
+			liabs[bind.ChnlPH] = typeExps[bind.ExpID]
+		} else {
+			assets[bind.ChnlPH] = typeExps[bind.ExpID]
+		}
+	}
+	vals := make(map[symbol.ADT]typeexp.DataType, 1)
+	for val := range valRecs {
+		vals[val.BindVar] = val.T
+	}
+	// indexes are erased at run time; constraints and potential sufficiency
+	// are proved when the definition is created; contradictory facts and
+	// unbounded potential make their check trivial
+	return typedef.Context{
+		Assets: assets,
+		Liabs:  liabs,
+		Facts:  []arithexp.PropSpec{arithexp.FalseSpec{}},
+		Pot:    math.MaxInt64,
+		Vals:   vals,
+	}
+}
+
+var errConcurrentUpdate = errors.New("entity concurrent modification")
+
+// the scheduler of some node still writes the journal
+var errJournalBusy = errors.New("journal busy: stop the scheduler of every node before the replay")
+
 func errOptimisticUpdate(got revnum.ADT) error {
-	return fmt.Errorf("entity concurrent modification: got revision %v", got)
+	return fmt.Errorf("%w: got revision %v", errConcurrentUpdate, got)
+}
+
+func errMissingTicket(want TicketRef) error {
+	return fmt.Errorf("ticket missing in queue: %v", want)
+}
+
+func errMissingVal(want symbol.ADT) error {
+	return fmt.Errorf("value missing in snap: %v", want)
+}
+
+func errMissingProc(want uniqsym.ADT) error {
+	return fmt.Errorf("proc missing in env: %v", want)
+}
+
+func errMissingLease(want symbol.ADT) error {
+	return fmt.Errorf("lease missing in cfg: %v", want)
 }
 
 func errMissingPool(want uniqsym.ADT) error {
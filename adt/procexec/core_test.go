@@ -0,0 +1,617 @@
+package procexec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"orglang/go-runtime/lib/db"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/procdec"
+	"orglang/go-runtime/adt/procdef"
+	"orglang/go-runtime/adt/procexp"
+	"orglang/go-runtime/adt/procstep"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/syndec"
+	"orglang/go-runtime/adt/typedef"
+	"orglang/go-runtime/adt/typeexp"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+func TestTakeSpawnStartsChild(t *testing.T) {
+	s := &service{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	oneQN := uniqsym.New("one")
+	oneER := typeexp.OneRec{ExpID: identity.New()}
+	closerQN := uniqsym.New("closer")
+	closerID := identity.New()
+	procEnv := Env{
+		SynDecs: map[uniqsym.ADT]syndec.DecRec{
+			closerQN: {DecID: closerID, DecRN: revnum.New(), DecQN: closerQN},
+		},
+		ProcDecs: map[identity.ADT]procdec.DecRec{
+			closerID: {
+				DecRef:     procdec.DecRef{ID: closerID, RN: revnum.New()},
+				ProviderBS: procbind.BindSpec{ChnlPH: "z", TypeQN: oneQN},
+			},
+		},
+		ProcDefs: map[identity.ADT]procdef.DefRec{
+			closerID: {
+				DefRef: procdef.DefRef{ID: closerID, RN: revnum.New()},
+				ProcES: procexp.CloseSpec{CommChnlPH: "z"},
+			},
+		},
+		TypeDefs: map[uniqsym.ADT]typedef.DefRec{
+			oneQN: {ExpID: oneER.ExpID},
+		},
+		TypeExps: map[identity.ADT]typeexp.ExpRec{
+			oneER.ExpID: oneER,
+		},
+	}
+	parentSnap := ExecSnap{
+		ExecRef: ExecRef{ID: identity.New(), RN: revnum.New()},
+	}
+	spawnES := procexp.SpawnSpec{
+		CommChnlPH: "y",
+		ProcQN:     closerQN,
+		ContES:     procexp.WaitSpec{CommChnlPH: "y"},
+	}
+	nextSpec, spawnMod, err := s.takeWith(procEnv, parentSnap, spawnES)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if nextSpec.ExecRef != parentSnap.ExecRef {
+		t.Errorf("got parent %v, want %v", nextSpec.ExecRef, parentSnap.ExecRef)
+	}
+	if len(spawnMod.Execs) != 1 || len(spawnMod.Wakes) != 1 {
+		t.Fatalf("got %v execs and %v wakes, want 1 and 1", len(spawnMod.Execs), len(spawnMod.Wakes))
+	}
+	childRef := spawnMod.Execs[0].ExecRef
+	childSpec := spawnMod.Wakes[0]
+	if childSpec.ExecRef != childRef {
+		t.Fatalf("got child %v, want %v", childSpec.ExecRef, childRef)
+	}
+	// the child proceeds on its own binds
+	childSnap := ExecSnap{
+		ExecRef: childRef,
+		ChnlBRs: map[symbol.ADT]procbind.BindRec{},
+	}
+	for _, bind := range spawnMod.Binds {
+		if bind.ExecRef == childRef {
+			childSnap.ChnlBRs[bind.ChnlPH] = bind
+		}
+	}
+	_, childMod, err := s.takeWith(procEnv, childSnap, childSpec.ProcES)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if len(childMod.Steps) != 1 {
+		t.Fatalf("got %v steps, want 1", len(childMod.Steps))
+	}
+	msgSR, ok := childMod.Steps[0].(procstep.MsgRec)
+	if !ok {
+		t.Fatalf("got %T, want procstep.MsgRec", childMod.Steps[0])
+	}
+	if msgSR.ChnlID != childSnap.ChnlBRs["z"].ChnlID {
+		t.Errorf("got channel %v, want %v", msgSR.ChnlID, childSnap.ChnlBRs["z"].ChnlID)
+	}
+}
+
+func TestAnalyzeWaits(t *testing.T) {
+	a, b, c := identity.New(), identity.New(), identity.New()
+	aToB := WaitRec{ExecID: a, ChnlID: identity.New(), ChnlPH: "x", PeerID: b}
+	bToA := WaitRec{ExecID: b, ChnlID: identity.New(), ChnlPH: "y", PeerID: a}
+	aToA := WaitRec{ExecID: a, ChnlID: identity.New(), ChnlPH: "x", PeerID: a}
+	cToA := WaitRec{ExecID: c, ChnlID: identity.New(), ChnlPH: "z", PeerID: a}
+	aToNone := WaitRec{ExecID: a, ChnlID: identity.New(), ChnlPH: "x"}
+	tests := []struct {
+		name  string
+		waits []WaitRec
+		want  []FindingRec
+	}{
+		{"no waits", nil, nil},
+		{"no cycle", []WaitRec{aToB}, nil},
+		{"self-loop", []WaitRec{aToA}, []FindingRec{
+			{K: DeadlockFinding, Waits: []WaitRec{aToA}},
+		}},
+		{"2-cycle with tail", []WaitRec{cToA, aToB, bToA}, []FindingRec{
+			{K: DeadlockFinding, Waits: []WaitRec{aToB, bToA}},
+		}},
+		{"orphan", []WaitRec{aToNone}, []FindingRec{
+			{K: OrphanFinding, Waits: []WaitRec{aToNone}},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := AnalyzeWaits(test.waits)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v findings, want %v", len(got), len(test.want))
+			}
+			for i := range got {
+				if got[i].K != test.want[i].K || !sameWaits(got[i].Waits, test.want[i].Waits) {
+					t.Errorf("got %+v, want %+v", got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+// waits within a cycle come in no particular order
+func sameWaits(got, want []WaitRec) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for _, wait := range want {
+		if !slices.Contains(got, wait) {
+			return false
+		}
+	}
+	return true
+}
+
+// closer provides z: 1 and takes the given clients
+func closerEnv(closerQN uniqsym.ADT, clients ...procbind.BindSpec) Env {
+	oneQN := uniqsym.New("one")
+	oneER := typeexp.OneRec{ExpID: identity.New()}
+	closerID := identity.New()
+	return Env{
+		SynDecs: map[uniqsym.ADT]syndec.DecRec{
+			closerQN: {DecID: closerID, DecRN: revnum.New(), DecQN: closerQN},
+		},
+		ProcDecs: map[identity.ADT]procdec.DecRec{
+			closerID: {
+				DecRef:     procdec.DecRef{ID: closerID, RN: revnum.New()},
+				ProviderBS: procbind.BindSpec{ChnlPH: "z", TypeQN: oneQN},
+				ClientBSs:  clients,
+			},
+		},
+		ProcDefs: map[identity.ADT]procdef.DefRec{
+			closerID: {
+				DefRef: procdef.DefRef{ID: closerID, RN: revnum.New()},
+				ProcES: procexp.CloseSpec{CommChnlPH: "z"},
+			},
+		},
+		TypeDefs: map[uniqsym.ADT]typedef.DefRec{
+			oneQN: {ExpID: oneER.ExpID},
+		},
+		TypeExps: map[identity.ADT]typeexp.ExpRec{
+			oneER.ExpID: oneER,
+		},
+	}
+}
+
+func TestTakeCall(t *testing.T) {
+	s := &service{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	closerQN := uniqsym.New("closer")
+	procEnv := closerEnv(closerQN)
+	callerSnap := ExecSnap{
+		ExecRef: ExecRef{ID: identity.New(), RN: revnum.New()},
+	}
+	tests := []struct {
+		name string
+		es   procexp.CallSpec
+		want procexp.ExpSpec
+		err  error
+	}{
+		{"tail call runs the callee on the caller's channel",
+			procexp.CallSpec{BindChnlPH: "y", ProcQN: closerQN},
+			procexp.CloseSpec{CommChnlPH: "y"}, nil},
+		{"non-tail call",
+			procexp.CallSpec{BindChnlPH: "y", ProcQN: closerQN, ContES: procexp.WaitSpec{CommChnlPH: "y"}},
+			nil, procdef.ErrNonTailCall(closerQN)},
+		{"extra argument",
+			procexp.CallSpec{BindChnlPH: "y", ProcQN: closerQN, ValChnlPHs: []symbol.ADT{"x"}},
+			nil, procdef.ErrCtxMismatch(0, 1)},
+		{"unknown callee",
+			procexp.CallSpec{BindChnlPH: "y", ProcQN: uniqsym.New("other")},
+			nil, errMissingProc(uniqsym.New("other"))},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nextSpec, callMod, err := s.takeWith(procEnv, callerSnap, test.es)
+			if test.err != nil {
+				if err == nil || err.Error() != test.err.Error() {
+					t.Fatalf("got %v, want %v", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if nextSpec.ExecRef != callerSnap.ExecRef {
+				t.Errorf("got exec %v, want %v", nextSpec.ExecRef, callerSnap.ExecRef)
+			}
+			if nextSpec.ProcES != test.want {
+				t.Errorf("got %+v, want %+v", nextSpec.ProcES, test.want)
+			}
+			if len(callMod.Execs) != 0 {
+				t.Errorf("got %v execs, want none", len(callMod.Execs))
+			}
+		})
+	}
+}
+
+func TestTakeShared(t *testing.T) {
+	s := &service{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	oneER := typeexp.OneRec{ExpID: identity.New()}
+	// ↑1 and the linear 1 it is not
+	upER := typeexp.UpRec{ExpID: identity.New(), Z: oneER}
+	sharedID := identity.New()
+	client := ExecRef{ID: identity.New(), RN: revnum.New()}
+	provider := ExecRef{ID: identity.New(), RN: revnum.New()}
+	snapOf := func(self ExecRef, expID identity.ADT, queued procstep.StepRec) ExecSnap {
+		snap := ExecSnap{
+			ExecRef: self,
+			ChnlBRs: map[symbol.ADT]procbind.BindRec{
+				"s": {ExecRef: self, ChnlPH: "s", ChnlID: sharedID, ExpID: expID},
+			},
+			AcqSRs: map[identity.ADT]procstep.StepRec{},
+		}
+		if queued != nil {
+			snap.AcqSRs[sharedID] = queued
+		}
+		return snap
+	}
+	acceptSR := procstep.SvcRec{
+		ExecRef: provider,
+		ChnlID:  sharedID,
+		ContER:  procexp.AcceptRec{CommChnlPH: "s", ContES: procexp.CloseSpec{CommChnlPH: "s"}},
+	}
+	acquireSR := procstep.MsgRec{
+		ExecRef: client,
+		ChnlID:  sharedID,
+		ValER:   procexp.AcquireRec{CommChnlPH: "s", ContES: procexp.WaitSpec{CommChnlPH: "s"}},
+	}
+	tests := []struct {
+		name  string
+		snap  ExecSnap
+		es    procexp.ExpSpec
+		enqs  int
+		wakes []ExecRef
+		err   bool
+		// the type rather than the configuration is at fault
+		mismatch bool
+	}{
+		{"acquire queues without provider",
+			snapOf(client, upER.ExpID, nil), procexp.AcquireSpec{CommChnlPH: "s"}, 1, nil, false, false},
+		{"acquire pairs with queued accept",
+			snapOf(client, upER.ExpID, acceptSR), procexp.AcquireSpec{CommChnlPH: "s"}, 0, []ExecRef{provider}, false, false},
+		{"accept queues without client",
+			snapOf(provider, upER.ExpID, nil), procexp.AcceptSpec{CommChnlPH: "s"}, 1, nil, false, false},
+		{"accept pairs with queued acquire",
+			snapOf(provider, upER.ExpID, acquireSR), procexp.AcceptSpec{CommChnlPH: "s"}, 0, []ExecRef{client}, false, false},
+		{"acquire on linear channel",
+			snapOf(client, oneER.ExpID, acceptSR), procexp.AcquireSpec{CommChnlPH: "s"}, 0, nil, true, true},
+		{"accept on linear channel",
+			snapOf(provider, oneER.ExpID, acquireSR), procexp.AcceptSpec{CommChnlPH: "s"}, 0, nil, true, true},
+		{"acquire on missing channel",
+			snapOf(client, upER.ExpID, nil), procexp.AcquireSpec{CommChnlPH: "t"}, 0, nil, true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			procEnv := Env{TypeExps: map[identity.ADT]typeexp.ExpRec{
+				oneER.ExpID: oneER,
+				upER.ExpID:  upER,
+			}}
+			_, stepMod, err := s.takeWith(procEnv, test.snap, test.es)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got no error, want one")
+				}
+				if got := errors.As(err, new(typeexp.MismatchError)); got != test.mismatch {
+					t.Errorf("got mismatch %v for %q, want %v", got, err, test.mismatch)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if len(stepMod.Enqs) != test.enqs {
+				t.Errorf("got %v enqueued, want %v", len(stepMod.Enqs), test.enqs)
+			}
+			var wakes []ExecRef
+			for _, wake := range stepMod.Wakes {
+				wakes = append(wakes, wake.ExecRef)
+			}
+			if !slices.Equal(wakes, test.wakes) {
+				t.Errorf("got wakes %v, want %v", wakes, test.wakes)
+			}
+			if len(test.wakes) == 0 {
+				return
+			}
+			// both ends get a fresh linear channel of the shifted type and a lease
+			if len(stepMod.Binds) != 2 || len(stepMod.Leases) != 1 || len(stepMod.Deqs) != 1 {
+				t.Fatalf("got %v binds, %v leases, %v deqs, want 2, 1, 1",
+					len(stepMod.Binds), len(stepMod.Leases), len(stepMod.Deqs))
+			}
+			for _, bind := range stepMod.Binds {
+				if bind.ExpID != oneER.ExpID || bind.ChnlID == sharedID {
+					t.Errorf("got bind %+v, want fresh channel of %v", bind, oneER.ExpID)
+				}
+			}
+			lease := stepMod.Leases[0]
+			if lease.ClientID != client.ID || lease.ProviderID != provider.ID {
+				t.Errorf("got lease %+v, want client %v and provider %v", lease, client.ID, provider.ID)
+			}
+		})
+	}
+}
+
+// operations run without storage
+type stubOperator struct{}
+
+func (stubOperator) Explicit(_ context.Context, op func(db.Source) error) error { return op(nil) }
+
+func (stubOperator) Implicit(_ context.Context, op func(db.Source) error) error { return op(nil) }
+
+type stubRuns struct {
+	Repo
+	runs []RunRec
+	err  error
+}
+
+func (r *stubRuns) InsertRuns(_ db.Source, runs ...RunRec) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.runs = append(r.runs, runs...)
+	return nil
+}
+
+func TestPost(t *testing.T) {
+	spec := procstep.StepSpec{
+		ExecRef: ExecRef{ID: identity.New(), RN: revnum.New()},
+		ProcES:  procexp.CloseSpec{CommChnlPH: "z"},
+	}
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"queued", nil},
+		{"queue unavailable", errors.New("insertion failed")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			runs := &stubRuns{err: test.err}
+			s := &service{
+				procExecs: runs,
+				operator:  stubOperator{},
+				log:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}
+			ticketRef, err := s.Post(context.Background(), spec)
+			if test.err != nil {
+				if !errors.Is(err, test.err) || !ticketRef.IsEmpty() {
+					t.Errorf("got %v and ticket %v, want %v and none", err, ticketRef, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			// nothing is reduced until a worker polls the run
+			if len(runs.runs) != 1 {
+				t.Fatalf("got %v runs, want 1", len(runs.runs))
+			}
+			run := runs.runs[0]
+			if run.TicketRef != ticketRef || run.StepSpec.ExecRef != spec.ExecRef || run.StepSpec.ProcES != spec.ProcES {
+				t.Errorf("got run %+v, want %+v under %v", run, spec, ticketRef)
+			}
+		})
+	}
+}
+
+func TestTakeCancelled(t *testing.T) {
+	// no repos: a cancelled take must not reach the storage
+	s := &service{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	spec := procstep.StepSpec{
+		ExecRef: ExecRef{ID: identity.New(), RN: revnum.New()},
+		ProcES:  procexp.CloseSpec{CommChnlPH: "z"},
+	}
+	err := s.Take(ctx, spec)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestTakeWork(t *testing.T) {
+	s := &service{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	execSnap := ExecSnap{
+		ExecRef: ExecRef{ID: identity.New(), RN: revnum.New()},
+	}
+	contES := procexp.CloseSpec{CommChnlPH: "z"}
+	nextSpec, workMod, err := s.takeWith(Env{}, execSnap, procexp.WorkSpec{Work: 3, ContES: contES})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	// work needs no partner, so the exec goes on at once
+	if nextSpec.ExecRef != execSnap.ExecRef || nextSpec.ProcES != contES {
+		t.Errorf("got %+v, want %+v of %v", nextSpec.ProcES, contES, execSnap.ExecRef)
+	}
+	if len(workMod.Steps) != 1 {
+		t.Fatalf("got %v steps, want 1", len(workMod.Steps))
+	}
+	workSR, ok := workMod.Steps[0].(procstep.WorkRec)
+	if !ok {
+		t.Fatalf("got %T, want procstep.WorkRec", workMod.Steps[0])
+	}
+	if workSR.Work != 3 || workSR.ExecRef.ID != execSnap.ExecRef.ID {
+		t.Errorf("got %+v, want work 3 of %v", workSR, execSnap.ExecRef.ID)
+	}
+	if !slices.Equal(workMod.Locks, []ExecRef{execSnap.ExecRef}) {
+		t.Errorf("got locks %v, want %v", workMod.Locks, execSnap.ExecRef)
+	}
+}
+
+func TestTakeDelay(t *testing.T) {
+	s := &service{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	oneER := typeexp.OneRec{ExpID: identity.New()}
+	nextER := typeexp.NextRec{ExpID: identity.New(), Z: oneER}
+	diamondER := typeexp.DiamondRec{ExpID: identity.New(), Z: oneER}
+	tests := []struct {
+		name string
+		z    typeexp.ExpRec
+		// the type z is rebound to, if any
+		want typeexp.ExpRec
+		err  bool
+	}{
+		{"next advances", nextER, oneER, false},
+		{"patient stays", diamondER, nil, false},
+		{"impatient fails", oneER, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			procEnv := Env{TypeExps: map[identity.ADT]typeexp.ExpRec{
+				oneER.ExpID:     oneER,
+				nextER.ExpID:    nextER,
+				diamondER.ExpID: diamondER,
+			}}
+			execRef := ExecRef{ID: identity.New(), RN: revnum.New()}
+			execSnap := ExecSnap{
+				ExecRef: execRef,
+				ChnlBRs: map[symbol.ADT]procbind.BindRec{
+					"z": {ExecRef: execRef, ChnlBS: procbind.ProviderSide, ChnlPH: "z", ChnlID: identity.New(), ExpID: test.z.Ident()},
+				},
+			}
+			_, delayMod, err := s.takeWith(procEnv, execSnap, procexp.DelaySpec{Ticks: 1})
+			if test.err {
+				if err == nil {
+					t.Fatalf("got no error, want one")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			// the continuation waits for the tick
+			if delayMod.Delay != 1 {
+				t.Errorf("got delay %v, want 1", delayMod.Delay)
+			}
+			var got []identity.ADT
+			for _, bind := range delayMod.Binds {
+				got = append(got, bind.ExpID)
+			}
+			var want []identity.ADT
+			if test.want != nil {
+				want = append(want, test.want.Ident())
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("got binds to %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestTakeVals(t *testing.T) {
+	s := &service{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	oneER := typeexp.OneRec{ExpID: identity.New()}
+	andER := typeexp.AndRec{ExpID: identity.New(), T: typeexp.IntData, Z: oneER}
+	self := ExecRef{ID: identity.New(), RN: revnum.New()}
+	peer := ExecRef{ID: identity.New(), RN: revnum.New()}
+	chnlID := identity.New()
+	contChnlID := identity.New()
+	queuedRecv := procstep.SvcRec{
+		ExecRef: peer,
+		ChnlID:  chnlID,
+		ContER:  procexp.RecvValRec{CommChnlPH: "x", ContChnlID: contChnlID, BindVar: "v"},
+	}
+	queuedSend := procstep.MsgRec{
+		ExecRef: peer,
+		ChnlID:  chnlID,
+		ValER:   procexp.SendValRec{CommChnlPH: "x", ContChnlID: contChnlID, Val: json.RawMessage("1")},
+	}
+	sendLit := procexp.SendValSpec{CommChnlPH: "z", ValLit: json.RawMessage("1")}
+	tests := []struct {
+		name string
+		// z is provided rather than consumed
+		provider bool
+		queued   procstep.StepRec
+		es       procexp.ExpSpec
+		// the exec the value ends up with, and the value
+		owner ExecRef
+		want  string
+		wakes []ExecRef
+		err   bool
+	}{
+		{"send queues without receiver",
+			true, nil, sendLit, self, "1", nil, false},
+		{"send pairs with queued receive",
+			true, queuedRecv, sendLit, peer, "1", []ExecRef{peer}, false},
+		{"send received value",
+			true, nil, procexp.SendValSpec{CommChnlPH: "z", ValVar: "w"}, self, "7", nil, false},
+		{"send unknown value",
+			true, nil, procexp.SendValSpec{CommChnlPH: "z", ValVar: "u"}, ExecRef{}, "", nil, true},
+		{"receive queues without sender",
+			false, nil, procexp.RecvValSpec{CommChnlPH: "z", BindVar: "v"}, self, "", nil, false},
+		{"receive pairs with queued send",
+			false, queuedSend, procexp.RecvValSpec{CommChnlPH: "z", BindVar: "v"}, self, "1", nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			procEnv := Env{TypeExps: map[identity.ADT]typeexp.ExpRec{
+				oneER.ExpID: oneER,
+				andER.ExpID: andER,
+			}}
+			zBR := procbind.BindRec{ExecRef: self, ChnlBS: procbind.ClientSide, ChnlPH: "z", ChnlID: chnlID, ExpID: andER.ExpID}
+			if test.provider {
+				zBR.ChnlBS = procbind.ProviderSide
+			}
+			execSnap := ExecSnap{
+				ExecRef: self,
+				ChnlBRs: map[symbol.ADT]procbind.BindRec{"z": zBR},
+				ProcSRs: map[identity.ADT]procstep.StepRec{},
+				Vals: map[symbol.ADT]procstep.ValRec{
+					"w": {ExecRef: self, BindVar: "w", T: typeexp.IntData, Val: json.RawMessage("7")},
+				},
+			}
+			if test.queued != nil {
+				execSnap.ProcSRs[chnlID] = test.queued
+			}
+			_, valMod, err := s.takeWith(procEnv, execSnap, test.es)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got no error, want one")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if len(valMod.Steps) != 1 {
+				t.Fatalf("got %v steps, want 1", len(valMod.Steps))
+			}
+			var owner ExecRef
+			var got string
+			switch step := valMod.Steps[0].(type) {
+			case procstep.MsgRec:
+				owner, got = step.ExecRef, string(step.ValER.(procexp.SendValRec).Val)
+			case procstep.ValRec:
+				owner, got = step.ExecRef, string(step.Val)
+				if step.T != typeexp.IntData {
+					t.Errorf("got type %v, want %v", step.T, typeexp.IntData)
+				}
+			case procstep.SvcRec:
+				owner = step.ExecRef
+			default:
+				t.Fatalf("got %T, want a value step", step)
+			}
+			if owner.ID != test.owner.ID || got != test.want {
+				t.Errorf("got %q of %v, want %q of %v", got, owner.ID, test.want, test.owner.ID)
+			}
+			var wakes []ExecRef
+			for _, wake := range valMod.Wakes {
+				wakes = append(wakes, wake.ExecRef)
+			}
+			if !slices.Equal(wakes, test.wakes) {
+				t.Errorf("got wakes %v, want %v", wakes, test.wakes)
+			}
+		})
+	}
+}
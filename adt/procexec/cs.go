@@ -0,0 +1,33 @@
+package procexec
+
+import (
+	"time"
+
+	"orglang/go-runtime/lib/kv"
+)
+
+func newSchedulingCS(loader kv.Loader) (schedulingCS, error) {
+	dto := &schedulingCS{}
+	loadingErr := loader.Load("scheduling", dto)
+	if loadingErr != nil {
+		return schedulingCS{}, loadingErr
+	}
+	validationErr := dto.Validate()
+	if validationErr != nil {
+		return schedulingCS{}, validationErr
+	}
+	return *dto, nil
+}
+
+type schedulingCS struct {
+	// number of concurrent workers
+	Workers uint8 `mapstructure:"workers"`
+	// pause on an empty queue
+	Interval time.Duration `mapstructure:"interval"`
+	// claim duration of a step by a worker
+	Lease time.Duration `mapstructure:"lease"`
+	// tick duration of temporal types
+	Tick time.Duration `mapstructure:"tick"`
+	// period of the stuck execution scan
+	Check time.Duration `mapstructure:"check"`
+}
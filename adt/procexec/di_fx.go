@@ -7,13 +7,28 @@ import (
 var Module = fx.Module("adt/procexec",
 	fx.Provide(
 		fx.Annotate(newService, fx.As(new(API))),
+		newDAO,
 	),
 	fx.Provide(
 		fx.Private,
+		newSchedulingCS,
+		newSchedulerStdlib,
 		newEchoController,
-		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
 	),
 	fx.Invoke(
+		cfgSchedulerStdlib,
 		cfgEchoController,
 	),
 )
+
+// offline journal replay, started instead of Module
+var ReplayModule = fx.Module("adt/procexec/replay",
+	fx.Provide(
+		fx.Private,
+		newDAO,
+		newReplayerStdlib,
+	),
+	fx.Invoke(
+		cfgReplayerStdlib,
+	),
+)
@@ -1,9 +1,18 @@
 package procexec
 
 import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
 	"orglang/go-runtime/lib/db"
 
 	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/procexp"
 	"orglang/go-runtime/adt/procstep"
 	"orglang/go-runtime/adt/uniqref"
 )
@@ -11,18 +20,234 @@ import (
 type Repo interface {
 	SelectSnap(db.Source, ExecRef) (ExecSnap, error)
 	UpdateProc(db.Source, ExecMod) error
+	InsertRuns(db.Source, ...RunRec) error
+	UpdateRun(db.Source, RunRec) error
+	SelectNextRun(db.Source, time.Duration) (RunRec, error)
+	SelectTicket(db.Source, TicketRef) (TicketSnap, error)
+	SelectWaits(db.Source) ([]WaitRec, error)
+	InsertFindings(db.Source, ...FindingRec) error
+	// without apply it only verifies the tables against the journal
+	ReplayMods(db.Source, bool) (ReplayRec, error)
+}
+
+// storage adapter is chosen by the operator
+func newDAO(l *slog.Logger, o db.Operator) Repo {
+	switch o.(type) {
+	case *db.OperatorMem:
+		return newMemDAO(l)
+	case *db.OperatorSqlite:
+		return newSqliteDAO(l)
+	default:
+		return newPgxDAO(l)
+	}
 }
 
 type execModDS struct {
+	Execs []execRecDS
 	Locks []execRefDS
 	Binds []procbind.BindRecDS
 	Steps []procstep.StepRecDS
+	Enqs  []procstep.StepRecDS
+	Deqs  []procstep.StepRecDS
+	// shared channel leases
+	Leases  []leaseRecDS
+	Returns []leaseRecDS
 }
 
 type execRefDS = uniqref.Data
 
+type execRecDS struct {
+	ID    string `db:"exec_id"`
+	RN    int64  `db:"exec_rn"`
+	DecID string `db:"dec_id"`
+	DecRN int64  `db:"dec_rn"`
+}
+
+type leaseRecDS struct {
+	ChnlID     string `db:"chnl_id"`
+	ProviderID string `db:"provider_id"`
+	ProviderPH string `db:"provider_ph"`
+	ClientID   string `db:"client_id"`
+	ClientPH   string `db:"client_ph"`
+}
+
+type workDS struct {
+	Work int64 `db:"work"`
+	Pot  int64 `db:"pot"`
+}
+
 type liabDS struct {
 	PoolID string `db:"pool_id"`
 	ProcID string `db:"proc_id"`
 	PoolRN int64  `db:"rev"`
 }
+
+type runRecDS struct {
+	RunID    string            `db:"run_id"`
+	TicketID string            `db:"ticket_id"`
+	ExecID   string            `db:"exec_id"`
+	ExecRN   int64             `db:"exec_rn"`
+	ProcES   procexp.ExpSpecDS `db:"proc_es"`
+	Status   runStatusDS       `db:"status"`
+	Reason   sql.NullString    `db:"reason"`
+	WakeAt   sql.NullTime      `db:"wake_at"`
+}
+
+type ticketSnapDS struct {
+	TicketID string         `db:"ticket_id"`
+	Status   runStatusDS    `db:"status"`
+	Reason   sql.NullString `db:"reason"`
+}
+
+type runStatusDS int
+
+const (
+	nonRun = runStatusDS(iota)
+	pendingRun
+	runningRun
+	doneRun
+	failedRun
+)
+
+type waitRecDS struct {
+	ExecID string         `db:"exec_id" json:"exec_id"`
+	ChnlID string         `db:"chnl_id" json:"chnl_id"`
+	ChnlPH string         `db:"chnl_ph" json:"chnl_ph"`
+	PeerID sql.NullString `db:"peer_id" json:"peer_id"`
+}
+
+type findingRecDS struct {
+	// the same finding is recorded once
+	FindingKey string        `db:"finding_key"`
+	K          findingKindDS `db:"kind"`
+	Waits      []waitRecDS   `db:"waits"`
+}
+
+type findingKindDS int
+
+const (
+	nonFinding = findingKindDS(iota)
+	deadlockFinding
+	orphanFinding
+)
+
+// execution journal entry
+type modRecDS struct {
+	Seq int64     `db:"seq"`
+	Mod execModDS `db:"exec_mod"`
+}
+
+type replayDS struct {
+	LastSeq int64
+	// executions spawned after the journal appeared
+	ExecIDs []string
+	Binds   []procbind.BindRecDS
+	Steps   []procstep.StepRecDS
+	// executions with table rows but no spawn entry
+	Unverifiable []string
+	Drifts       []driftDS
+}
+
+type driftDS struct {
+	ExecID       string
+	MissingBinds int
+	MissingSteps int
+	ExtraBinds   int
+	ExtraSteps   int
+}
+
+// The journal is replayed in ascending order; binds and steps are
+// append-only, so the restored rows simply accumulate.
+func replayMods(mods []modRecDS, liveBinds []procbind.BindRecDS, liveSteps []procstep.StepRecDS) replayDS {
+	dto := replayDS{}
+	covered := map[string]bool{}
+	for _, mod := range mods {
+		dto.LastSeq = mod.Seq
+		for _, exec := range mod.Mod.Execs {
+			covered[exec.ID] = true
+		}
+	}
+	for _, mod := range mods {
+		for _, bind := range mod.Mod.Binds {
+			if covered[bind.ID] {
+				dto.Binds = append(dto.Binds, bind)
+			}
+		}
+		for _, step := range mod.Mod.Steps {
+			if covered[step.ExecID.String] {
+				dto.Steps = append(dto.Steps, step)
+			}
+		}
+	}
+	dto.ExecIDs = slices.Sorted(maps.Keys(covered))
+	unverifiable := map[string]bool{}
+	var binds []procbind.BindRecDS
+	for _, bind := range liveBinds {
+		if covered[bind.ID] {
+			binds = append(binds, bind)
+		} else {
+			unverifiable[bind.ID] = true
+		}
+	}
+	var steps []procstep.StepRecDS
+	for _, step := range liveSteps {
+		if covered[step.ExecID.String] {
+			steps = append(steps, step)
+		} else {
+			unverifiable[step.ExecID.String] = true
+		}
+	}
+	dto.Unverifiable = slices.Sorted(maps.Keys(unverifiable))
+	bindDrifts := countDrifts(dto.Binds, binds, func(dto procbind.BindRecDS) string { return dto.ID })
+	stepDrifts := countDrifts(dto.Steps, steps, func(dto procstep.StepRecDS) string { return dto.ExecID.String })
+	for _, execID := range dto.ExecIDs {
+		drift := driftDS{
+			ExecID:       execID,
+			MissingBinds: bindDrifts[execID][0],
+			ExtraBinds:   bindDrifts[execID][1],
+			MissingSteps: stepDrifts[execID][0],
+			ExtraSteps:   stepDrifts[execID][1],
+		}
+		if drift != (driftDS{ExecID: execID}) {
+			dto.Drifts = append(dto.Drifts, drift)
+		}
+	}
+	return dto
+}
+
+// Rows are compared as multisets of their json form,
+// counting missing and extra rows per execution.
+func countDrifts[R any](want, got []R, execID func(R) string) map[string][2]int {
+	counts := map[string]int{}
+	execIDs := map[string]string{}
+	for _, row := range want {
+		key := rowKeyDS(row)
+		counts[key]++
+		execIDs[key] = execID(row)
+	}
+	for _, row := range got {
+		key := rowKeyDS(row)
+		counts[key]--
+		execIDs[key] = execID(row)
+	}
+	drifts := map[string][2]int{}
+	for key, n := range counts {
+		drift := drifts[execIDs[key]]
+		switch {
+		case n > 0:
+			drift[0] += n
+		case n < 0:
+			drift[1] -= n
+		}
+		drifts[execIDs[key]] = drift
+	}
+	return drifts
+}
+
+func rowKeyDS(row any) string {
+	data, err := json.Marshal(row)
+	if err != nil {
+		panic(fmt.Sprintf("json row: %v", err))
+	}
+	return string(data)
+}
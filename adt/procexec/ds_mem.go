@@ -0,0 +1,398 @@
+package procexec
+
+import (
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/procdec"
+	"orglang/go-runtime/adt/procstep"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/uniqref"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(l *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{l.With(name)}
+}
+
+func (dao *memDAO) SelectSnap(source db.Source, execRef ExecRef) (ExecSnap, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("execRef", execRef)
+	execID := execRef.ID.String()
+	execDto, err := selectCurrent(ds, execID)
+	if err != nil {
+		dao.log.Error("selection failed", refAttr)
+		return ExecSnap{}, err
+	}
+	ref, err := uniqref.DataToADT(execDto)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	chnlDtos := selectLiveBinds(ds, func(dto procbind.BindRecDS) bool { return dto.ID == execID })
+	chnls, err := procbind.DataToBindRecs(chnlDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	chnlIDs := make([]string, 0, len(chnlDtos))
+	for _, dto := range chnlDtos {
+		chnlIDs = append(chnlIDs, dto.ChnlID)
+	}
+	stepDtos := []procstep.StepRecDS{}
+	workDto := workDS{}
+	valDtos := []procstep.StepRecDS{}
+	for _, dto := range db.SelectMem[procstep.StepRecDS](ds, procstep.StepsMem) {
+		if slices.Contains(chnlIDs, dto.ChnlID.String) {
+			stepDtos = append(stepDtos, dto)
+		}
+		if dto.ExecID.String != execID {
+			continue
+		}
+		// only work steps carry its amount
+		if dto.ProcER.Work != nil {
+			workDto.Work += dto.ProcER.Work.W
+		}
+		if dto.ProcER.Val != nil {
+			valDtos = append(valDtos, dto)
+		}
+	}
+	steps, err := procstep.DataToStepRecs(stepDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	// the channel queue is served in arrival order
+	acqDtos := []procstep.StepRecDS{}
+	for _, dto := range db.SelectMem[procstep.StepRecDS](ds, execAcqs) {
+		if !slices.Contains(chnlIDs, dto.ChnlID.String) {
+			continue
+		}
+		if !slices.ContainsFunc(acqDtos, func(head procstep.StepRecDS) bool { return head.ChnlID == dto.ChnlID }) {
+			acqDtos = append(acqDtos, dto)
+		}
+	}
+	acqs, err := procstep.DataToStepRecs(acqDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	leaseDtos := slices.DeleteFunc(slices.Clone(db.SelectMem[leaseRecDS](ds, execLeases)), func(dto leaseRecDS) bool {
+		return dto.ProviderID != execID && dto.ClientID != execID
+	})
+	leases, err := DataToLeaseRecs(leaseDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	for _, dto := range db.SelectMem[execRecDS](ds, execDecs) {
+		if dto.ID == execID {
+			workDto.Pot = procdec.SelectPotMem(ds, dto.DecID, dto.DecRN)
+		}
+	}
+	// a rebound name sees the latest received value
+	valDtos = db.LatestMem(valDtos,
+		func(dto procstep.StepRecDS) string { return dto.ProcER.Val.Y },
+		func(dto procstep.StepRecDS) int64 { return dto.ExecRN },
+	)
+	vals, err := procstep.DataToStepRecs(valDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	valRs := make(map[symbol.ADT]procstep.ValRec, len(vals))
+	for _, val := range vals {
+		valRec, ok := val.(procstep.ValRec)
+		if !ok {
+			return ExecSnap{}, procstep.ErrRecTypeUnexpected(val)
+		}
+		valRs[valRec.BindVar] = valRec
+	}
+	// lease is seen from both sides
+	leaseRs := make(map[symbol.ADT]LeaseRec, len(leases))
+	for _, lease := range leases {
+		if lease.ProviderID == execRef.ID {
+			leaseRs[lease.ProviderPH] = lease
+		} else {
+			leaseRs[lease.ClientPH] = lease
+		}
+	}
+	dao.log.Debug("selection succeed", refAttr)
+	return ExecSnap{
+		ExecRef: ref,
+		ChnlBRs: procbind.IndexBy(ChnlPH, chnls),
+		ProcSRs: procbind.IndexBy(procstep.ChnlID, steps),
+		AcqSRs:  procbind.IndexBy(procstep.ChnlID, acqs),
+		LeaseRs: leaseRs,
+		Work:    workDto.Work,
+		Pot:     workDto.Pot,
+		Vals:    valRs,
+	}, nil
+}
+
+func (dao *memDAO) UpdateProc(source db.Source, mod ExecMod) error {
+	// nothing to lock when spawning from scratch
+	if len(mod.Locks) == 0 && len(mod.Execs) == 0 {
+		panic("empty locks")
+	}
+	ds := db.MustConform[db.SourceMem](source)
+	dto, err := DataFromMod(mod)
+	if err != nil {
+		dao.log.Error("conversion failed")
+		return err
+	}
+	return dao.applyMod(ds, dto)
+}
+
+func (dao *memDAO) applyMod(ds db.SourceMem, dto execModDS) error {
+	// the journal is written in the same transaction as the change
+	db.InsertMem(ds, execMods, modRecDS{Seq: db.NextSeqMem(ds, execMods), Mod: dto})
+	// spawns
+	for _, dto := range dto.Execs {
+		db.InsertMem(ds, procbind.ExecsMem, uniqref.Data{ID: dto.ID, RN: dto.RN})
+	}
+	db.InsertMem(ds, execDecs, dto.Execs...)
+	db.InsertMem(ds, procbind.BindsMem, dto.Binds...)
+	db.InsertMem(ds, procstep.StepsMem, dto.Steps...)
+	// queues
+	db.InsertMem(ds, execAcqs, dto.Enqs...)
+	for _, deq := range dto.Deqs {
+		db.DeleteMem(ds, execAcqs, func(dto procstep.StepRecDS) bool {
+			return dto.ExecID == deq.ExecID && dto.ChnlID == deq.ChnlID
+		})
+	}
+	// leases
+	db.InsertMem(ds, execLeases, dto.Leases...)
+	for _, ret := range dto.Returns {
+		db.DeleteMem(ds, execLeases, func(dto leaseRecDS) bool {
+			return dto.ChnlID == ret.ChnlID && dto.ClientID == ret.ClientID
+		})
+	}
+	// execs
+	for _, lock := range dto.Locks {
+		n := db.UpdateMem(ds, procbind.ExecsMem,
+			func(dto uniqref.Data) bool { return dto == lock },
+			func(dto uniqref.Data) uniqref.Data { return uniqref.Data{ID: dto.ID, RN: dto.RN + 1} },
+		)
+		if n == 0 {
+			dao.log.Error("update failed")
+			return errOptimisticUpdate(revnum.ADT(lock.RN))
+		}
+	}
+	dao.log.Debug("update succeed")
+	return nil
+}
+
+func (dao *memDAO) InsertRuns(source db.Source, recs ...RunRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	for _, rec := range recs {
+		dto, err := DataFromRunRec(rec)
+		if err != nil {
+			dao.log.Error("conversion failed", slog.Any("runID", rec.RunID))
+			return err
+		}
+		dto.Status = pendingRun
+		db.InsertMem(ds, execRuns, runRowMem{Seq: db.NextSeqMem(ds, execRuns), Dto: dto})
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Int("runs", len(recs)))
+	return nil
+}
+
+func (dao *memDAO) UpdateRun(source db.Source, rec RunRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	idAttr := slog.Any("runID", rec.RunID)
+	dto, err := DataFromRunRec(rec)
+	if err != nil {
+		dao.log.Error("conversion failed", idAttr)
+		return err
+	}
+	db.UpdateMem(ds, execRuns,
+		func(row runRowMem) bool { return row.Dto.RunID == dto.RunID },
+		func(row runRowMem) runRowMem {
+			row.Dto.Status = dto.Status
+			row.Dto.Reason = dto.Reason
+			return row
+		},
+	)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "update succeed", idAttr, slog.Any("status", rec.Status))
+	return nil
+}
+
+// steps of one process run strictly in order,
+// a delayed step blocks the later ones until its tick
+func (dao *memDAO) SelectNextRun(source db.Source, lease time.Duration) (RunRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	now := time.Now()
+	rows := db.SelectMem[runRowMem](ds, execRuns)
+	blocked := make(map[string]bool)
+	for _, row := range rows {
+		active := row.Dto.Status == pendingRun || row.Dto.Status == runningRun
+		claimable := row.Dto.Status == pendingRun ||
+			row.Dto.Status == runningRun && row.ClaimedAt.Before(now.Add(-lease))
+		awake := !row.Dto.WakeAt.Valid || !row.Dto.WakeAt.Time.After(now)
+		if claimable && awake && !blocked[row.Dto.ExecID] {
+			db.UpdateMem(ds, execRuns,
+				func(r runRowMem) bool { return r.Seq == row.Seq },
+				func(r runRowMem) runRowMem {
+					r.Dto.Status = runningRun
+					r.ClaimedAt = now
+					return r
+				},
+			)
+			row.Dto.Status = runningRun
+			dao.log.Log(ds.Ctx, lf.LevelTrace, "selection succeed", slog.Any("dto", row.Dto))
+			return DataToRunRec(row.Dto)
+		}
+		if active {
+			blocked[row.Dto.ExecID] = true
+		}
+	}
+	return RunRec{}, nil
+}
+
+func (dao *memDAO) SelectTicket(source db.Source, ref TicketRef) (TicketSnap, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("ticketRef", ref)
+	dto := ticketSnapDS{TicketID: ref.String(), Status: doneRun}
+	found := false
+	reasons := []string{}
+	for _, row := range db.SelectMem[runRowMem](ds, execRuns) {
+		if row.Dto.TicketID != dto.TicketID {
+			continue
+		}
+		found = true
+		switch {
+		case row.Dto.Status == failedRun:
+			dto.Status = failedRun
+		case dto.Status != failedRun && (row.Dto.Status == pendingRun || row.Dto.Status == runningRun):
+			dto.Status = pendingRun
+		}
+		if row.Dto.Reason.Valid {
+			reasons = append(reasons, row.Dto.Reason.String)
+		}
+	}
+	if !found {
+		dao.log.Error("selection failed", refAttr)
+		return TicketSnap{}, errMissingTicket(ref)
+	}
+	dto.Reason.String = strings.Join(reasons, "; ")
+	dto.Reason.Valid = len(reasons) > 0
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "selection succeed", refAttr)
+	return DataToTicketSnap(dto)
+}
+
+// unmatched step halves and the opposite ends of their channels
+func (dao *memDAO) SelectWaits(source db.Source) ([]WaitRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	live := selectLiveBinds(ds, func(procbind.BindRecDS) bool { return true })
+	execs := db.SelectMem[uniqref.Data](ds, procbind.ExecsMem)
+	dtos := []waitRecDS{}
+	for _, step := range db.SelectMem[procstep.StepRecDS](ds, procstep.StepsMem) {
+		current := uniqref.Data{ID: step.ExecID.String, RN: step.ExecRN}
+		if !slices.Contains(execs, current) {
+			continue
+		}
+		for _, own := range live {
+			if own.ID != step.ExecID.String || own.ChnlID != step.ChnlID.String {
+				continue
+			}
+			dto := waitRecDS{ExecID: own.ID, ChnlID: own.ChnlID, ChnlPH: own.ChnlPH}
+			for _, peer := range live {
+				if peer.ChnlID == own.ChnlID && peer.ID != own.ID {
+					dto.PeerID.String, dto.PeerID.Valid = peer.ID, true
+				}
+			}
+			dtos = append(dtos, dto)
+		}
+	}
+	slices.SortFunc(dtos, func(a, b waitRecDS) int {
+		return strings.Compare(a.ExecID+a.ChnlID, b.ExecID+b.ChnlID)
+	})
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "selection succeed", slog.Int("waits", len(dtos)))
+	return DataToWaitRecs(dtos)
+}
+
+func (dao *memDAO) InsertFindings(source db.Source, recs ...FindingRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	for _, rec := range recs {
+		dto := DataFromFindingRec(rec)
+		// the same finding is recorded once
+		if slices.ContainsFunc(db.SelectMem[findingRecDS](ds, execFindings), func(f findingRecDS) bool {
+			return f.FindingKey == dto.FindingKey
+		}) {
+			continue
+		}
+		db.InsertMem(ds, execFindings, dto)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Int("findings", len(recs)))
+	return nil
+}
+
+func (dao *memDAO) ReplayMods(source db.Source, apply bool) (ReplayRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	dto := replayMods(
+		db.SelectMem[modRecDS](ds, execMods),
+		db.SelectMem[procbind.BindRecDS](ds, procbind.BindsMem),
+		db.SelectMem[procstep.StepRecDS](ds, procstep.StepsMem),
+	)
+	if apply {
+		db.DeleteMem(ds, procbind.BindsMem, func(bind procbind.BindRecDS) bool {
+			return slices.Contains(dto.ExecIDs, bind.ID)
+		})
+		db.DeleteMem(ds, procstep.StepsMem, func(step procstep.StepRecDS) bool {
+			return slices.Contains(dto.ExecIDs, step.ExecID.String)
+		})
+		db.InsertMem(ds, procbind.BindsMem, dto.Binds...)
+		db.InsertMem(ds, procstep.StepsMem, dto.Steps...)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "replay succeed", slog.Int64("seq", dto.LastSeq), slog.Bool("apply", apply))
+	return DataToReplayRec(dto)
+}
+
+func selectCurrent(ds db.SourceMem, execID string) (uniqref.Data, error) {
+	return db.SelectLatestMem(ds, procbind.ExecsMem,
+		func(dto uniqref.Data) bool { return dto.ID == execID },
+		func(dto uniqref.Data) int64 { return dto.RN },
+	)
+}
+
+// removed binds carry negative revision
+func selectLiveBinds(ds db.SourceMem, match func(procbind.BindRecDS) bool) []procbind.BindRecDS {
+	binds := db.LatestMem(db.SelectMem[procbind.BindRecDS](ds, procbind.BindsMem),
+		func(dto procbind.BindRecDS) [2]string { return [2]string{dto.ID, dto.ChnlPH} },
+		func(dto procbind.BindRecDS) int64 { return dto.RN },
+	)
+	return slices.DeleteFunc(binds, func(dto procbind.BindRecDS) bool {
+		return dto.RN <= 0 || !match(dto)
+	})
+}
+
+// queue item along with its sequence number and claim deadline
+type runRowMem struct {
+	Seq       int64
+	Dto       runRecDS
+	ClaimedAt time.Time
+}
+
+const (
+	execDecs     = "proc_exec_decs"
+	execAcqs     = "proc_acqs"
+	execLeases   = "proc_leases"
+	execRuns     = "proc_runs"
+	execFindings = "proc_findings"
+	// modRecDS rows
+	execMods = "proc_mods"
+)
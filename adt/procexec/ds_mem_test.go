@@ -0,0 +1,167 @@
+package procexec
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"go.uber.org/fx"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/procstep"
+	"orglang/go-runtime/adt/uniqref"
+	"orglang/go-runtime/lib/db"
+)
+
+func newOperatorMem(t *testing.T) db.Operator {
+	t.Helper()
+	var operator db.Operator
+	app := fx.New(db.MemModule, fx.NopLogger, fx.Populate(&operator))
+	if err := app.Err(); err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	return operator
+}
+
+func stepOf(execID string, execRN int64) procstep.StepRecDS {
+	return procstep.StepRecDS{
+		ExecID: sql.NullString{String: execID, Valid: true},
+		ExecRN: execRN,
+		ChnlID: sql.NullString{String: identity.ConvertToString(identity.New()), Valid: true},
+	}
+}
+
+// live binds and steps as sorted row keys
+func snapshotMem(t *testing.T, operator db.Operator) []string {
+	t.Helper()
+	var keys []string
+	err := operator.Implicit(context.Background(), func(source db.Source) error {
+		ds := db.MustConform[db.SourceMem](source)
+		for _, bind := range db.SelectMem[procbind.BindRecDS](ds, procbind.BindsMem) {
+			keys = append(keys, rowKeyDS(bind))
+		}
+		for _, step := range db.SelectMem[procstep.StepRecDS](ds, procstep.StepsMem) {
+			keys = append(keys, rowKeyDS(step))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func replayMem(t *testing.T, operator db.Operator, dao *memDAO, apply bool) ReplayRec {
+	t.Helper()
+	var rec ReplayRec
+	err := operator.Explicit(context.Background(), func(source db.Source) (err error) {
+		rec, err = dao.ReplayMods(source, apply)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	return rec
+}
+
+func TestReplayModsMem(t *testing.T) {
+	operator := newOperatorMem(t)
+	dao := newMemDAO(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	execID := identity.New()
+	exec := identity.ConvertToString(execID)
+	preID := identity.New()
+	pre := identity.ConvertToString(preID)
+	// spawn and one step of a journaled execution
+	spawn := execModDS{
+		Execs: []execRecDS{{ID: exec, RN: 1}},
+		Binds: []procbind.BindRecDS{{ID: exec, RN: 1, ChnlPH: "z", ChnlID: identity.ConvertToString(identity.New())}},
+		Steps: []procstep.StepRecDS{stepOf(exec, 1)},
+	}
+	step := execModDS{
+		Locks: []execRefDS{{ID: exec, RN: 1}},
+		Binds: []procbind.BindRecDS{{ID: exec, RN: 2, ChnlPH: "x", ChnlID: identity.ConvertToString(identity.New())}},
+		Steps: []procstep.StepRecDS{stepOf(exec, 2)},
+	}
+	err := operator.Explicit(context.Background(), func(source db.Source) error {
+		ds := db.MustConform[db.SourceMem](source)
+		// an execution spawned before the journal appeared
+		db.InsertMem(ds, procbind.ExecsMem, uniqref.Data{ID: pre, RN: 1})
+		db.InsertMem(ds, procbind.BindsMem, procbind.BindRecDS{ID: pre, RN: 1, ChnlPH: "z"})
+		db.InsertMem(ds, procstep.StepsMem, stepOf(pre, 1))
+		for _, mod := range []execModDS{spawn, step} {
+			err := dao.applyMod(ds, mod)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	recorded := snapshotMem(t, operator)
+	rec := replayMem(t, operator, dao, false)
+	if rec.LastSeq != 2 || rec.Binds != 2 || rec.Steps != 2 {
+		t.Errorf("got seq %v, binds %v, steps %v, want 2, 2, 2", rec.LastSeq, rec.Binds, rec.Steps)
+	}
+	if len(rec.Drifts) != 0 {
+		t.Errorf("got drifts %v, want none", rec.Drifts)
+	}
+	if !slices.Equal(rec.Unverifiable, []identity.ADT{preID}) {
+		t.Errorf("got unverifiable %v, want %v", rec.Unverifiable, []identity.ADT{preID})
+	}
+	if rec.Verified() {
+		t.Errorf("got verified with unverifiable executions")
+	}
+	// lose a bind and gain a step behind the journal's back
+	err = operator.Explicit(context.Background(), func(source db.Source) error {
+		ds := db.MustConform[db.SourceMem](source)
+		db.DeleteMem(ds, procbind.BindsMem, func(dto procbind.BindRecDS) bool { return dto.ID == exec && dto.RN == 2 })
+		db.InsertMem(ds, procstep.StepsMem, stepOf(exec, 3))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	rec = replayMem(t, operator, dao, true)
+	want := []DriftRec{{ExecID: execID, MissingBinds: 1, ExtraSteps: 1}}
+	if !slices.Equal(rec.Drifts, want) {
+		t.Errorf("got drifts %v, want %v", rec.Drifts, want)
+	}
+	replayed := snapshotMem(t, operator)
+	if !slices.Equal(replayed, recorded) {
+		t.Errorf("got snapshot %v, want %v", replayed, recorded)
+	}
+	rec = replayMem(t, operator, dao, false)
+	if len(rec.Drifts) != 0 {
+		t.Errorf("got drifts %v after replay, want none", rec.Drifts)
+	}
+	if !slices.Equal(rec.Unverifiable, []identity.ADT{preID}) {
+		t.Errorf("got unverifiable %v, want %v", rec.Unverifiable, []identity.ADT{preID})
+	}
+}
+
+func TestReplayModsVerified(t *testing.T) {
+	tests := []struct {
+		name string
+		rec  ReplayRec
+		want bool
+	}{
+		{"clean", ReplayRec{}, true},
+		{"drift", ReplayRec{Drifts: []DriftRec{{ExecID: identity.New(), MissingBinds: 1}}}, false},
+		{"unverifiable", ReplayRec{Unverifiable: []identity.ADT{identity.New()}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.rec.Verified()
+			if got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
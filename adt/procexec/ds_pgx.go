@@ -4,14 +4,18 @@ import (
 	"errors"
 	"log/slog"
 	"reflect"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 
 	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
 
 	"orglang/go-runtime/adt/procbind"
 	"orglang/go-runtime/adt/procstep"
 	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/uniqref"
 )
 
 // Adapter
@@ -32,6 +36,22 @@ func newPgxDAO(l *slog.Logger) *pgxDAO {
 func (dao *pgxDAO) SelectSnap(source db.Source, execRef ExecRef) (ExecSnap, error) {
 	ds := db.MustConform[db.SourcePgx](source)
 	refAttr := slog.Any("execRef", execRef)
+	execRows, err := ds.Conn.Query(ds.Ctx, selectExec, execRef.ID.String())
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecSnap{}, err
+	}
+	defer execRows.Close()
+	execDto, err := pgx.CollectExactlyOneRow(execRows, pgx.RowToStructByName[execRefDS])
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(execDto)))
+		return ExecSnap{}, err
+	}
+	ref, err := uniqref.DataToADT(execDto)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
 	chnlRows, err := ds.Conn.Query(ds.Ctx, selectChnls, execRef.ID.String())
 	if err != nil {
 		dao.log.Error("execution failed", refAttr)
@@ -48,7 +68,11 @@ func (dao *pgxDAO) SelectSnap(source db.Source, execRef ExecRef) (ExecSnap, erro
 		dao.log.Error("conversion failed", refAttr)
 		return ExecSnap{}, err
 	}
-	stepRows, err := ds.Conn.Query(ds.Ctx, selectSteps, execRef.ID.String())
+	chnlIDs := make([]string, 0, len(chnls))
+	for _, chnl := range chnls {
+		chnlIDs = append(chnlIDs, chnl.ChnlID.String())
+	}
+	stepRows, err := ds.Conn.Query(ds.Ctx, selectSteps, chnlIDs)
 	if err != nil {
 		dao.log.Error("execution failed", refAttr)
 		return ExecSnap{}, err
@@ -64,15 +88,98 @@ func (dao *pgxDAO) SelectSnap(source db.Source, execRef ExecRef) (ExecSnap, erro
 		dao.log.Error("conversion failed", refAttr)
 		return ExecSnap{}, err
 	}
+	acqRows, err := ds.Conn.Query(ds.Ctx, selectAcqs, chnlIDs)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecSnap{}, err
+	}
+	defer acqRows.Close()
+	acqDtos, err := pgx.CollectRows(acqRows, pgx.RowToStructByName[procstep.StepRecDS])
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(acqDtos)))
+		return ExecSnap{}, err
+	}
+	acqs, err := procstep.DataToStepRecs(acqDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	leaseRows, err := ds.Conn.Query(ds.Ctx, selectLeases, execRef.ID.String())
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecSnap{}, err
+	}
+	defer leaseRows.Close()
+	leaseDtos, err := pgx.CollectRows(leaseRows, pgx.RowToStructByName[leaseRecDS])
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(leaseDtos)))
+		return ExecSnap{}, err
+	}
+	leases, err := DataToLeaseRecs(leaseDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	workRows, err := ds.Conn.Query(ds.Ctx, selectWork, execRef.ID.String())
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecSnap{}, err
+	}
+	defer workRows.Close()
+	workDto, err := pgx.CollectExactlyOneRow(workRows, pgx.RowToStructByName[workDS])
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(workDto)))
+		return ExecSnap{}, err
+	}
+	valRows, err := ds.Conn.Query(ds.Ctx, selectVals, execRef.ID.String())
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecSnap{}, err
+	}
+	defer valRows.Close()
+	valDtos, err := pgx.CollectRows(valRows, pgx.RowToStructByName[procstep.StepRecDS])
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(valDtos)))
+		return ExecSnap{}, err
+	}
+	vals, err := procstep.DataToStepRecs(valDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	valRs := make(map[symbol.ADT]procstep.ValRec, len(vals))
+	for _, val := range vals {
+		valRec, ok := val.(procstep.ValRec)
+		if !ok {
+			return ExecSnap{}, procstep.ErrRecTypeUnexpected(val)
+		}
+		valRs[valRec.BindVar] = valRec
+	}
+	// lease is seen from both sides
+	leaseRs := make(map[symbol.ADT]LeaseRec, len(leases))
+	for _, lease := range leases {
+		if lease.ProviderID == execRef.ID {
+			leaseRs[lease.ProviderPH] = lease
+		} else {
+			leaseRs[lease.ClientPH] = lease
+		}
+	}
 	dao.log.Debug("selection succeed", refAttr)
 	return ExecSnap{
+		ExecRef: ref,
 		ChnlBRs: procbind.IndexBy(ChnlPH, chnls),
 		ProcSRs: procbind.IndexBy(procstep.ChnlID, steps),
+		AcqSRs:  procbind.IndexBy(procstep.ChnlID, acqs),
+		LeaseRs: leaseRs,
+		Work:    workDto.Work,
+		Pot:     workDto.Pot,
+		Vals:    valRs,
 	}, nil
 }
 
 func (dao *pgxDAO) UpdateProc(source db.Source, mod ExecMod) (err error) {
-	if len(mod.Locks) == 0 {
+	// nothing to lock when spawning from scratch
+	if len(mod.Locks) == 0 && len(mod.Execs) == 0 {
 		panic("empty locks")
 	}
 	ds := db.MustConform[db.SourcePgx](source)
@@ -81,12 +188,50 @@ func (dao *pgxDAO) UpdateProc(source db.Source, mod ExecMod) (err error) {
 		dao.log.Error("conversion failed")
 		return err
 	}
+	// journal; writers share the lock the replay takes alone
+	_, err = ds.Conn.Exec(ds.Ctx, shareJournal)
+	if err != nil {
+		dao.log.Error("execution failed", slog.String("q", shareJournal))
+		return err
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertMod, pgx.NamedArgs{"exec_mod": dto})
+	if err != nil {
+		dao.log.Error("execution failed", slog.String("q", insertMod))
+		return err
+	}
+	// spawns
+	spawnReq := pgx.Batch{}
+	for _, dto := range dto.Execs {
+		args := pgx.NamedArgs{
+			"exec_id": dto.ID,
+			"exec_rn": dto.RN,
+			"dec_id":  dto.DecID,
+			"dec_rn":  dto.DecRN,
+		}
+		spawnReq.Queue(insertExec, args)
+	}
+	if spawnReq.Len() > 0 {
+		spawnRes := ds.Conn.SendBatch(ds.Ctx, &spawnReq)
+		defer func() {
+			err = errors.Join(err, spawnRes.Close())
+		}()
+		for _, dto := range dto.Execs {
+			_, err = spawnRes.Exec()
+			if err != nil {
+				dao.log.Error("execution failed", slog.Any("dto", dto))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
 	// binds
 	bindReq := pgx.Batch{}
 	for _, dto := range dto.Binds {
 		args := pgx.NamedArgs{
 			"exec_id":  dto.ID,
 			"exec_rn":  dto.RN,
+			"chnl_bs":  dto.ChnlBS,
 			"chnl_ph":  dto.ChnlPH,
 			"chnl_id":  dto.ChnlID,
 			"state_id": dto.ExpID,
@@ -113,7 +258,8 @@ func (dao *pgxDAO) UpdateProc(source db.Source, mod ExecMod) (err error) {
 	for _, dto := range dto.Steps {
 		args := pgx.NamedArgs{
 			"kind":    dto.K,
-			"proc_id": dto.ExecID,
+			"exec_id": dto.ExecID,
+			"exec_rn": dto.ExecRN,
 			"chnl_id": dto.ChnlID,
 			"proc_er": dto.ProcER,
 		}
@@ -134,6 +280,74 @@ func (dao *pgxDAO) UpdateProc(source db.Source, mod ExecMod) (err error) {
 			return err
 		}
 	}
+	// queues
+	acqReq := pgx.Batch{}
+	for _, dto := range dto.Enqs {
+		args := pgx.NamedArgs{
+			"kind":    dto.K,
+			"exec_id": dto.ExecID,
+			"exec_rn": dto.ExecRN,
+			"chnl_id": dto.ChnlID,
+			"proc_er": dto.ProcER,
+		}
+		acqReq.Queue(insertAcq, args)
+	}
+	for _, dto := range dto.Deqs {
+		args := pgx.NamedArgs{
+			"exec_id": dto.ExecID,
+			"chnl_id": dto.ChnlID,
+		}
+		acqReq.Queue(deleteAcq, args)
+	}
+	if acqReq.Len() > 0 {
+		acqRes := ds.Conn.SendBatch(ds.Ctx, &acqReq)
+		defer func() {
+			err = errors.Join(err, acqRes.Close())
+		}()
+		for range acqReq.Len() {
+			_, err = acqRes.Exec()
+			if err != nil {
+				dao.log.Error("execution failed")
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// leases
+	leaseReq := pgx.Batch{}
+	for _, dto := range dto.Leases {
+		args := pgx.NamedArgs{
+			"chnl_id":     dto.ChnlID,
+			"provider_id": dto.ProviderID,
+			"provider_ph": dto.ProviderPH,
+			"client_id":   dto.ClientID,
+			"client_ph":   dto.ClientPH,
+		}
+		leaseReq.Queue(insertLease, args)
+	}
+	for _, dto := range dto.Returns {
+		args := pgx.NamedArgs{
+			"chnl_id":   dto.ChnlID,
+			"client_id": dto.ClientID,
+		}
+		leaseReq.Queue(deleteLease, args)
+	}
+	if leaseReq.Len() > 0 {
+		leaseRes := ds.Conn.SendBatch(ds.Ctx, &leaseReq)
+		defer func() {
+			err = errors.Join(err, leaseRes.Close())
+		}()
+		for range leaseReq.Len() {
+			_, err = leaseRes.Exec()
+			if err != nil {
+				dao.log.Error("execution failed")
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
 	// execs
 	execReq := pgx.Batch{}
 	for _, dto := range dto.Locks {
@@ -164,49 +378,519 @@ func (dao *pgxDAO) UpdateProc(source db.Source, mod ExecMod) (err error) {
 	return nil
 }
 
+func (dao *pgxDAO) InsertRuns(source db.Source, recs ...RunRec) (err error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	if len(recs) == 0 {
+		return nil
+	}
+	batch := pgx.Batch{}
+	for _, rec := range recs {
+		dto, err := DataFromRunRec(rec)
+		if err != nil {
+			dao.log.Error("conversion failed", slog.Any("runID", rec.RunID))
+			return err
+		}
+		args := pgx.NamedArgs{
+			"run_id":    dto.RunID,
+			"ticket_id": dto.TicketID,
+			"exec_id":   dto.ExecID,
+			"exec_rn":   dto.ExecRN,
+			"proc_es":   dto.ProcES,
+			"status":    pendingRun,
+			"wake_at":   dto.WakeAt,
+		}
+		batch.Queue(insertRun, args)
+	}
+	br := ds.Conn.SendBatch(ds.Ctx, &batch)
+	defer func() {
+		err = errors.Join(err, br.Close())
+	}()
+	for _, rec := range recs {
+		_, err = br.Exec()
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("runID", rec.RunID))
+			return err
+		}
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Int("runs", len(recs)))
+	return nil
+}
+
+func (dao *pgxDAO) UpdateRun(source db.Source, rec RunRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("runID", rec.RunID)
+	dto, err := DataFromRunRec(rec)
+	if err != nil {
+		dao.log.Error("conversion failed", idAttr)
+		return err
+	}
+	args := pgx.NamedArgs{
+		"run_id": dto.RunID,
+		"status": dto.Status,
+		"reason": dto.Reason,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, updateRun, args)
+	if err != nil {
+		dao.log.Error("execution failed", idAttr)
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "update succeed", idAttr, slog.Any("status", rec.Status))
+	return nil
+}
+
+// claims the next step, skipping those claimed by other workers
+func (dao *pgxDAO) SelectNextRun(source db.Source, lease time.Duration) (RunRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	args := pgx.NamedArgs{
+		"pending": pendingRun,
+		"running": runningRun,
+		"lease":   lease.Seconds(),
+	}
+	rows, err := ds.Conn.Query(ds.Ctx, selectNextRun, args)
+	if err != nil {
+		dao.log.Error("execution failed")
+		return RunRec{}, err
+	}
+	defer rows.Close()
+	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[runRecDS])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return RunRec{}, nil
+	}
+	if err != nil {
+		dao.log.Error("collection failed", slog.Any("t", reflect.TypeOf(dto)))
+		return RunRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "selection succeed", slog.Any("dto", dto))
+	return DataToRunRec(dto)
+}
+
+func (dao *pgxDAO) SelectTicket(source db.Source, ref TicketRef) (TicketSnap, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("ticketRef", ref)
+	args := pgx.NamedArgs{
+		"ticket_id": ref.String(),
+		"pending":   pendingRun,
+		"running":   runningRun,
+		"done":      doneRun,
+		"failed":    failedRun,
+	}
+	rows, err := ds.Conn.Query(ds.Ctx, selectTicket, args)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return TicketSnap{}, err
+	}
+	defer rows.Close()
+	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[ticketSnapDS])
+	if errors.Is(err, pgx.ErrNoRows) {
+		dao.log.Error("selection failed", refAttr)
+		return TicketSnap{}, errMissingTicket(ref)
+	}
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(dto)))
+		return TicketSnap{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "selection succeed", refAttr)
+	return DataToTicketSnap(dto)
+}
+
+func (dao *pgxDAO) SelectWaits(source db.Source) ([]WaitRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	rows, err := ds.Conn.Query(ds.Ctx, selectWaits)
+	if err != nil {
+		dao.log.Error("execution failed", slog.String("q", selectWaits))
+		return nil, err
+	}
+	defer rows.Close()
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[waitRecDS])
+	if err != nil {
+		dao.log.Error("collection failed", slog.Any("t", reflect.TypeOf(dtos)))
+		return nil, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "selection succeed", slog.Int("waits", len(dtos)))
+	return DataToWaitRecs(dtos)
+}
+
+func (dao *pgxDAO) InsertFindings(source db.Source, recs ...FindingRec) (err error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	if len(recs) == 0 {
+		return nil
+	}
+	batch := pgx.Batch{}
+	for _, rec := range recs {
+		dto := DataFromFindingRec(rec)
+		args := pgx.NamedArgs{
+			"finding_key": dto.FindingKey,
+			"kind":        dto.K,
+			"waits":       dto.Waits,
+		}
+		batch.Queue(insertFinding, args)
+	}
+	br := ds.Conn.SendBatch(ds.Ctx, &batch)
+	defer func() {
+		err = errors.Join(err, br.Close())
+	}()
+	for range recs {
+		_, err = br.Exec()
+		if err != nil {
+			dao.log.Error("execution failed", slog.String("q", insertFinding))
+			return err
+		}
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Int("findings", len(recs)))
+	return nil
+}
+
+func (dao *pgxDAO) ReplayMods(source db.Source, apply bool) (ReplayRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	// the journal and the tables are read from one snapshot, so writers
+	// go on; it must be the first statement of the transaction
+	snapshot := readSnapshot
+	if apply {
+		snapshot = writeSnapshot
+	}
+	_, err := ds.Conn.Exec(ds.Ctx, snapshot)
+	if err != nil {
+		dao.log.Error("execution failed", slog.String("q", snapshot))
+		return ReplayRec{}, err
+	}
+	if apply {
+		var alone bool
+		err = ds.Conn.QueryRow(ds.Ctx, lockJournal).Scan(&alone)
+		if err != nil {
+			dao.log.Error("execution failed", slog.String("q", lockJournal))
+			return ReplayRec{}, err
+		}
+		if !alone {
+			return ReplayRec{}, errJournalBusy
+		}
+	}
+	var mods []modRecDS
+	for {
+		var after int64
+		if len(mods) > 0 {
+			after = mods[len(mods)-1].Seq
+		}
+		rows, err := ds.Conn.Query(ds.Ctx, selectModsAfter, pgx.NamedArgs{"after": after, "limit": modPage})
+		if err != nil {
+			dao.log.Error("execution failed", slog.String("q", selectModsAfter))
+			return ReplayRec{}, err
+		}
+		page, err := pgx.CollectRows(rows, pgx.RowToStructByName[modRecDS])
+		if err != nil {
+			dao.log.Error("collection failed", slog.Int64("after", after))
+			return ReplayRec{}, err
+		}
+		mods = append(mods, page...)
+		if len(page) < modPage {
+			break
+		}
+	}
+	binds, err := selectAll[procbind.BindRecDS](ds, selectAllBinds)
+	if err != nil {
+		dao.log.Error("execution failed", slog.String("q", selectAllBinds))
+		return ReplayRec{}, err
+	}
+	steps, err := selectAll[procstep.StepRecDS](ds, selectAllSteps)
+	if err != nil {
+		dao.log.Error("execution failed", slog.String("q", selectAllSteps))
+		return ReplayRec{}, err
+	}
+	dto := replayMods(mods, binds, steps)
+	if apply {
+		for _, q := range []string{deleteReplayedBinds, deleteReplayedSteps} {
+			_, err = ds.Conn.Exec(ds.Ctx, q, pgx.NamedArgs{"exec_ids": dto.ExecIDs})
+			if err != nil {
+				dao.log.Error("execution failed", slog.String("q", q))
+				return ReplayRec{}, err
+			}
+		}
+		for _, bind := range dto.Binds {
+			args := pgx.NamedArgs{
+				"exec_id":  bind.ID,
+				"exec_rn":  bind.RN,
+				"chnl_bs":  bind.ChnlBS,
+				"chnl_ph":  bind.ChnlPH,
+				"chnl_id":  bind.ChnlID,
+				"state_id": bind.ExpID,
+			}
+			_, err = ds.Conn.Exec(ds.Ctx, insertBind, args)
+			if err != nil {
+				dao.log.Error("execution failed", slog.Any("dto", bind))
+				return ReplayRec{}, err
+			}
+		}
+		for _, step := range dto.Steps {
+			args := pgx.NamedArgs{
+				"kind":    step.K,
+				"exec_id": step.ExecID,
+				"exec_rn": step.ExecRN,
+				"chnl_id": step.ChnlID,
+				"proc_er": step.ProcER,
+			}
+			_, err = ds.Conn.Exec(ds.Ctx, insertStep, args)
+			if err != nil {
+				dao.log.Error("execution failed", slog.Any("dto", step))
+				return ReplayRec{}, err
+			}
+		}
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "replay succeed", slog.Int64("seq", dto.LastSeq), slog.Bool("apply", apply))
+	return DataToReplayRec(dto)
+}
+
+// journal entries read per query
+const modPage = 1000
+
+func selectAll[T any](ds db.SourcePgx, query string) ([]T, error) {
+	rows, err := ds.Conn.Query(ds.Ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return pgx.CollectRows(rows, pgx.RowToStructByName[T])
+}
+
 const (
+	insertExec = `
+		insert into proc_execs (
+			exec_id, exec_rn, dec_id, dec_rn
+		) values (
+			@exec_id, @exec_rn, @dec_id, @dec_rn
+		)`
+
+	insertMod = `
+		insert into proc_mods (exec_mod) values (@exec_mod)`
+
+	readSnapshot = `
+		set transaction isolation level repeatable read, read only`
+
+	writeSnapshot = `
+		set transaction isolation level repeatable read`
+
+	shareJournal = `
+		select pg_advisory_xact_lock_shared(hashtext('proc_mods'))`
+
+	lockJournal = `
+		select pg_try_advisory_xact_lock(hashtext('proc_mods'))`
+
+	selectModsAfter = `
+		select seq, exec_mod
+		from proc_mods
+		where seq > @after
+		order by seq
+		limit @limit`
+
+	selectAllBinds = `
+		select
+			exec_id, exec_rn, chnl_bs, chnl_ph, chnl_id, state_id as exp_id
+		from proc_binds`
+
+	selectAllSteps = `
+		select
+			exec_id, exec_rn, chnl_id, kind, proc_er
+		from proc_steps`
+
+	deleteReplayedBinds = `
+		delete from proc_binds
+		where exec_id = any(@exec_ids)`
+
+	deleteReplayedSteps = `
+		delete from proc_steps
+		where exec_id = any(@exec_ids)`
+
 	insertBind = `
 		insert into proc_binds (
-			exec_id, chnl_ph, chnl_id, state_id, exec_rn
+			exec_id, chnl_bs, chnl_ph, chnl_id, state_id, exec_rn
 		) values (
-			@exec_id, @chnl_ph, @chnl_id, @state_id, @exec_rn
+			@exec_id, @chnl_bs, @chnl_ph, @chnl_id, @state_id, @exec_rn
 		)`
 
 	insertStep = `
 		insert into proc_steps (
-			exec_id, chnl_id, kind, proc_er
+			exec_id, exec_rn, chnl_id, kind, proc_er
 		) values (
-			@exec_id, @chnl_id, @kind, @proc_er
+			@exec_id, @exec_rn, @chnl_id, @kind, @proc_er
 		)`
 
+	insertAcq = `
+		insert into proc_acqs (
+			exec_id, exec_rn, chnl_id, kind, proc_er
+		) values (
+			@exec_id, @exec_rn, @chnl_id, @kind, @proc_er
+		)`
+
+	deleteAcq = `
+		delete from proc_acqs
+		where exec_id = @exec_id
+			and chnl_id = @chnl_id`
+
+	insertLease = `
+		insert into proc_leases (
+			chnl_id, provider_id, provider_ph, client_id, client_ph
+		) values (
+			@chnl_id, @provider_id, @provider_ph, @client_id, @client_ph
+		)`
+
+	deleteLease = `
+		delete from proc_leases
+		where chnl_id = @chnl_id
+			and client_id = @client_id`
+
+	selectAcqs = `
+		select distinct on (chnl_id)
+			exec_id, exec_rn, chnl_id, kind, proc_er
+		from proc_acqs
+		where chnl_id = any($1)
+		order by chnl_id, acq_seq`
+
+	selectLeases = `
+		select
+			chnl_id, provider_id, provider_ph, client_id, client_ph
+		from proc_leases
+		where provider_id = $1
+			or client_id = $1`
+
 	updateExec = `
 		update proc_execs
 		set exec_rn = @exec_rn + 1
 		where exec_id = @exec_id
 			and exec_rn = @exec_rn`
 
+	selectExec = `
+		select
+			exec_id as id, exec_rn as rn
+		from proc_execs
+		where exec_id = $1`
+
+	// only work steps carry its amount, the sum skips the rest
+	selectWork = `
+		select
+			coalesce((
+				select sum((s.proc_er->'work'->>'w')::bigint)
+				from proc_steps s
+				where s.exec_id = e.exec_id
+			), 0) as work,
+			coalesce(d.pot, 0) as pot
+		from proc_execs e
+		left join proc_decs d
+			on d.dec_id = e.dec_id
+			and d.dec_rn = e.dec_rn
+		where e.exec_id = $1`
+
+	// a rebound name sees the latest received value
+	selectVals = `
+		select distinct on (proc_er->'val'->>'y')
+			exec_id, exec_rn, chnl_id, kind, proc_er
+		from proc_steps
+		where exec_id = $1
+			and proc_er->'val' is not null
+		order by proc_er->'val'->>'y', exec_rn desc`
+
+	// removed binds carry negative revision
 	selectChnls = `
 		with bnds as not materialized (
 			select distinct on (chnl_ph)
-				*
-			from proc_bnds
-			where proc_id = 'proc1'
-			order by chnl_ph, abs(rev) desc
-		), liabs as not materialized (
-			select distinct on (proc_id)
-				*
-			from pool_liabs
-			where proc_id = 'proc1'
-			order by proc_id, abs(rev) desc
+				exec_id, exec_rn, chnl_bs, chnl_ph, chnl_id, state_id as exp_id
+			from proc_binds
+			where exec_id = $1
+			order by chnl_ph, abs(exec_rn) desc
 		)
 		select
-			bnd.*,
-			prvd.pool_id
-		from bnds bnd
-		left join liabs liab
-			on liab.proc_id = bnd.proc_id
-		left join pool_execs prvd
-			on prvd.pool_id = liab.pool_id`
-
-	selectSteps = ``
+			*
+		from bnds
+		where exec_rn > 0`
+
+	// steps are keyed by channel, so pending ones are those posted
+	// to channels still bound in the execution
+	selectSteps = `
+		select
+			exec_id, exec_rn, chnl_id, kind, proc_er
+		from proc_steps
+		where chnl_id = any($1)`
+
+	insertRun = `
+		insert into proc_runs (
+			run_id, ticket_id, exec_id, exec_rn, proc_es, status, wake_at
+		) values (
+			@run_id, @ticket_id, @exec_id, @exec_rn, @proc_es, @status, @wake_at
+		)`
+
+	updateRun = `
+		update proc_runs
+		set status = @status,
+			reason = @reason
+		where run_id = @run_id`
+
+	// steps of one process run strictly in order,
+	// a delayed step blocks the later ones until its tick
+	selectNextRun = `
+		update proc_runs
+		set status = @running,
+			claimed_at = now()
+		where run_id = (
+			select r.run_id
+			from proc_runs r
+			where (r.status = @pending
+					or r.status = @running and r.claimed_at < now() - make_interval(secs => @lease))
+				and (r.wake_at is null or r.wake_at <= now())
+				and not exists (
+					select 1
+					from proc_runs p
+					where p.exec_id = r.exec_id
+						and p.run_seq < r.run_seq
+						and p.status in (@pending, @running)
+				)
+			order by r.run_seq
+			limit 1
+			for update skip locked
+		)
+		returning run_id, ticket_id, exec_id, exec_rn, proc_es, status, reason, wake_at`
+
+	selectTicket = `
+		select
+			ticket_id,
+			case
+				when bool_or(status = @failed) then @failed::smallint
+				when bool_or(status in (@pending, @running)) then @pending::smallint
+				else @done::smallint
+			end as status,
+			string_agg(reason, '; ') as reason
+		from proc_runs
+		where ticket_id = @ticket_id
+		group by ticket_id`
+
+	// unmatched step halves and the opposite ends of their channels
+	selectWaits = `
+		with binds as (
+			select distinct on (exec_id, chnl_ph)
+				exec_id, exec_rn, chnl_ph, chnl_id
+			from proc_binds
+			order by exec_id, chnl_ph, abs(exec_rn) desc
+		), live as (
+			select * from binds where exec_rn > 0
+		)
+		select
+			s.exec_id,
+			s.chnl_id,
+			w.chnl_ph,
+			p.exec_id as peer_id
+		from proc_steps s
+		join proc_execs e
+			on e.exec_id = s.exec_id
+			and e.exec_rn = s.exec_rn
+		join live w
+			on w.exec_id = s.exec_id
+			and w.chnl_id = s.chnl_id
+		left join live p
+			on p.chnl_id = s.chnl_id
+			and p.exec_id <> s.exec_id
+		order by s.exec_id, s.chnl_id`
+
+	insertFinding = `
+		insert into proc_findings (
+			finding_key, kind, waits
+		) values (
+			@finding_key, @kind, @waits
+		)
+		on conflict (finding_key) do nothing`
 )
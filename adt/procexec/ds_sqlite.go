@@ -0,0 +1,766 @@
+package procexec
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/procstep"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/uniqref"
+)
+
+// Adapter
+type sqliteDAO struct {
+	log *slog.Logger
+}
+
+func newSqliteDAO(l *slog.Logger) *sqliteDAO {
+	name := slog.String("name", reflect.TypeFor[sqliteDAO]().Name())
+	return &sqliteDAO{l.With(name)}
+}
+
+func (dao *sqliteDAO) SelectSnap(source db.Source, execRef ExecRef) (ExecSnap, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("execRef", execRef)
+	execArgs := db.NamedArgsSqlite{"exec_id": execRef.ID.String()}
+	execRows, err := ds.Conn.Query(ds.Ctx, selectExecSqlite, execArgs)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecSnap{}, err
+	}
+	execDto, err := db.CollectOneRowSqlite[execRefDS](execRows)
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(execDto)))
+		return ExecSnap{}, err
+	}
+	ref, err := uniqref.DataToADT(execDto)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	chnlRows, err := ds.Conn.Query(ds.Ctx, selectChnlsSqlite, execArgs)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecSnap{}, err
+	}
+	chnlDtos, err := db.CollectRowsSqlite[procbind.BindRecDS](chnlRows)
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(chnlDtos)))
+		return ExecSnap{}, err
+	}
+	chnls, err := procbind.DataToBindRecs(chnlDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	chnlIDs := make([]string, 0, len(chnls))
+	for _, chnl := range chnls {
+		chnlIDs = append(chnlIDs, chnl.ChnlID.String())
+	}
+	chnlArgs := db.NamedArgsSqlite{"chnl_ids": chnlIDs}
+	stepRows, err := ds.Conn.Query(ds.Ctx, selectStepsSqlite, chnlArgs)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecSnap{}, err
+	}
+	stepDtos, err := db.CollectRowsSqlite[procstep.StepRecDS](stepRows)
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(stepDtos)))
+		return ExecSnap{}, err
+	}
+	steps, err := procstep.DataToStepRecs(stepDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	acqRows, err := ds.Conn.Query(ds.Ctx, selectAcqsSqlite, chnlArgs)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecSnap{}, err
+	}
+	acqDtos, err := db.CollectRowsSqlite[procstep.StepRecDS](acqRows)
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(acqDtos)))
+		return ExecSnap{}, err
+	}
+	acqs, err := procstep.DataToStepRecs(acqDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	leaseRows, err := ds.Conn.Query(ds.Ctx, selectLeasesSqlite, execArgs)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecSnap{}, err
+	}
+	leaseDtos, err := db.CollectRowsSqlite[leaseRecDS](leaseRows)
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(leaseDtos)))
+		return ExecSnap{}, err
+	}
+	leases, err := DataToLeaseRecs(leaseDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	workRows, err := ds.Conn.Query(ds.Ctx, selectWorkSqlite, execArgs)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecSnap{}, err
+	}
+	workDto, err := db.CollectOneRowSqlite[workDS](workRows)
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(workDto)))
+		return ExecSnap{}, err
+	}
+	valRows, err := ds.Conn.Query(ds.Ctx, selectValsSqlite, execArgs)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return ExecSnap{}, err
+	}
+	valDtos, err := db.CollectRowsSqlite[procstep.StepRecDS](valRows)
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(valDtos)))
+		return ExecSnap{}, err
+	}
+	vals, err := procstep.DataToStepRecs(valDtos)
+	if err != nil {
+		dao.log.Error("conversion failed", refAttr)
+		return ExecSnap{}, err
+	}
+	valRs := make(map[symbol.ADT]procstep.ValRec, len(vals))
+	for _, val := range vals {
+		valRec, ok := val.(procstep.ValRec)
+		if !ok {
+			return ExecSnap{}, procstep.ErrRecTypeUnexpected(val)
+		}
+		valRs[valRec.BindVar] = valRec
+	}
+	// lease is seen from both sides
+	leaseRs := make(map[symbol.ADT]LeaseRec, len(leases))
+	for _, lease := range leases {
+		if lease.ProviderID == execRef.ID {
+			leaseRs[lease.ProviderPH] = lease
+		} else {
+			leaseRs[lease.ClientPH] = lease
+		}
+	}
+	dao.log.Debug("selection succeed", refAttr)
+	return ExecSnap{
+		ExecRef: ref,
+		ChnlBRs: procbind.IndexBy(ChnlPH, chnls),
+		ProcSRs: procbind.IndexBy(procstep.ChnlID, steps),
+		AcqSRs:  procbind.IndexBy(procstep.ChnlID, acqs),
+		LeaseRs: leaseRs,
+		Work:    workDto.Work,
+		Pot:     workDto.Pot,
+		Vals:    valRs,
+	}, nil
+}
+
+func (dao *sqliteDAO) UpdateProc(source db.Source, mod ExecMod) error {
+	// nothing to lock when spawning from scratch
+	if len(mod.Locks) == 0 && len(mod.Execs) == 0 {
+		panic("empty locks")
+	}
+	ds := db.MustConform[db.SourceSqlite](source)
+	dto, err := DataFromMod(mod)
+	if err != nil {
+		dao.log.Error("conversion failed")
+		return err
+	}
+	// journal
+	_, err = ds.Conn.Exec(ds.Ctx, insertModSqlite, db.NamedArgsSqlite{"exec_mod": dto})
+	if err != nil {
+		dao.log.Error("execution failed", slog.String("q", insertModSqlite))
+		return err
+	}
+	// spawns
+	for _, dto := range dto.Execs {
+		args := db.NamedArgsSqlite{
+			"exec_id": dto.ID,
+			"exec_rn": dto.RN,
+			"dec_id":  dto.DecID,
+			"dec_rn":  dto.DecRN,
+		}
+		_, err = ds.Conn.Exec(ds.Ctx, insertExecSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+			return err
+		}
+	}
+	// binds
+	for _, dto := range dto.Binds {
+		args := db.NamedArgsSqlite{
+			"exec_id":  dto.ID,
+			"exec_rn":  dto.RN,
+			"chnl_bs":  dto.ChnlBS,
+			"chnl_ph":  dto.ChnlPH,
+			"chnl_id":  dto.ChnlID,
+			"state_id": dto.ExpID,
+		}
+		_, err = ds.Conn.Exec(ds.Ctx, insertBindSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+			return err
+		}
+	}
+	// steps
+	for _, dto := range dto.Steps {
+		_, err = ds.Conn.Exec(ds.Ctx, insertStepSqlite, stepArgsSqlite(dto))
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+			return err
+		}
+	}
+	// queues
+	for _, dto := range dto.Enqs {
+		_, err = ds.Conn.Exec(ds.Ctx, insertAcqSqlite, stepArgsSqlite(dto))
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+			return err
+		}
+	}
+	for _, dto := range dto.Deqs {
+		args := db.NamedArgsSqlite{
+			"exec_id": dto.ExecID,
+			"chnl_id": dto.ChnlID,
+		}
+		_, err = ds.Conn.Exec(ds.Ctx, deleteAcqSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+			return err
+		}
+	}
+	// leases
+	for _, dto := range dto.Leases {
+		args := db.NamedArgsSqlite{
+			"chnl_id":     dto.ChnlID,
+			"provider_id": dto.ProviderID,
+			"provider_ph": dto.ProviderPH,
+			"client_id":   dto.ClientID,
+			"client_ph":   dto.ClientPH,
+		}
+		_, err = ds.Conn.Exec(ds.Ctx, insertLeaseSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+			return err
+		}
+	}
+	for _, dto := range dto.Returns {
+		args := db.NamedArgsSqlite{
+			"chnl_id":   dto.ChnlID,
+			"client_id": dto.ClientID,
+		}
+		_, err = ds.Conn.Exec(ds.Ctx, deleteLeaseSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+			return err
+		}
+	}
+	// execs
+	for _, dto := range dto.Locks {
+		args := db.NamedArgsSqlite{
+			"exec_id": dto.ID,
+			"exec_rn": dto.RN,
+		}
+		res, err := ds.Conn.Exec(ds.Ctx, updateExecSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("dto", dto))
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			dao.log.Error("update failed")
+			return errOptimisticUpdate(revnum.ADT(dto.RN))
+		}
+	}
+	dao.log.Debug("update succeed")
+	return nil
+}
+
+func (dao *sqliteDAO) InsertRuns(source db.Source, recs ...RunRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	for _, rec := range recs {
+		dto, err := DataFromRunRec(rec)
+		if err != nil {
+			dao.log.Error("conversion failed", slog.Any("runID", rec.RunID))
+			return err
+		}
+		args := db.NamedArgsSqlite{
+			"run_id":    dto.RunID,
+			"ticket_id": dto.TicketID,
+			"exec_id":   dto.ExecID,
+			"exec_rn":   dto.ExecRN,
+			"proc_es":   dto.ProcES,
+			"status":    pendingRun,
+			"wake_at":   dto.WakeAt,
+		}
+		_, err = ds.Conn.Exec(ds.Ctx, insertRunSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.Any("runID", rec.RunID))
+			return err
+		}
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Int("runs", len(recs)))
+	return nil
+}
+
+func (dao *sqliteDAO) UpdateRun(source db.Source, rec RunRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	idAttr := slog.Any("runID", rec.RunID)
+	dto, err := DataFromRunRec(rec)
+	if err != nil {
+		dao.log.Error("conversion failed", idAttr)
+		return err
+	}
+	args := db.NamedArgsSqlite{
+		"run_id": dto.RunID,
+		"status": dto.Status,
+		"reason": dto.Reason,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, updateRunSqlite, args)
+	if err != nil {
+		dao.log.Error("execution failed", idAttr)
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "update succeed", idAttr, slog.Any("status", rec.Status))
+	return nil
+}
+
+// sqlite transactions run one at a time, so no one can claim
+// the selected step between the select and the update
+func (dao *sqliteDAO) SelectNextRun(source db.Source, lease time.Duration) (RunRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	now := time.Now()
+	args := db.NamedArgsSqlite{
+		"pending": pendingRun,
+		"running": runningRun,
+		"now":     now,
+		"stale":   now.Add(-lease),
+	}
+	rows, err := ds.Conn.Query(ds.Ctx, selectNextRunSqlite, args)
+	if err != nil {
+		dao.log.Error("execution failed")
+		return RunRec{}, err
+	}
+	dto, err := db.CollectOneRowSqlite[runRecDS](rows)
+	if errors.Is(err, db.ErrNoRows) {
+		return RunRec{}, nil
+	}
+	if err != nil {
+		dao.log.Error("collection failed", slog.Any("t", reflect.TypeOf(dto)))
+		return RunRec{}, err
+	}
+	claimArgs := db.NamedArgsSqlite{
+		"run_id":  dto.RunID,
+		"running": runningRun,
+		"now":     now,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, claimRunSqlite, claimArgs)
+	if err != nil {
+		dao.log.Error("execution failed", slog.Any("runID", dto.RunID))
+		return RunRec{}, err
+	}
+	dto.Status = runningRun
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "selection succeed", slog.Any("dto", dto))
+	return DataToRunRec(dto)
+}
+
+func (dao *sqliteDAO) SelectTicket(source db.Source, ref TicketRef) (TicketSnap, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("ticketRef", ref)
+	args := db.NamedArgsSqlite{
+		"ticket_id": ref.String(),
+		"pending":   pendingRun,
+		"running":   runningRun,
+		"done":      doneRun,
+		"failed":    failedRun,
+	}
+	rows, err := ds.Conn.Query(ds.Ctx, selectTicketSqlite, args)
+	if err != nil {
+		dao.log.Error("execution failed", refAttr)
+		return TicketSnap{}, err
+	}
+	dto, err := db.CollectOneRowSqlite[ticketSnapDS](rows)
+	if errors.Is(err, db.ErrNoRows) {
+		dao.log.Error("selection failed", refAttr)
+		return TicketSnap{}, errMissingTicket(ref)
+	}
+	if err != nil {
+		dao.log.Error("collection failed", refAttr, slog.Any("t", reflect.TypeOf(dto)))
+		return TicketSnap{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "selection succeed", refAttr)
+	return DataToTicketSnap(dto)
+}
+
+func (dao *sqliteDAO) SelectWaits(source db.Source) ([]WaitRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	rows, err := ds.Conn.Query(ds.Ctx, selectWaitsSqlite, nil)
+	if err != nil {
+		dao.log.Error("execution failed", slog.String("q", selectWaitsSqlite))
+		return nil, err
+	}
+	dtos, err := db.CollectRowsSqlite[waitRecDS](rows)
+	if err != nil {
+		dao.log.Error("collection failed", slog.Any("t", reflect.TypeOf(dtos)))
+		return nil, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "selection succeed", slog.Int("waits", len(dtos)))
+	return DataToWaitRecs(dtos)
+}
+
+func (dao *sqliteDAO) InsertFindings(source db.Source, recs ...FindingRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	for _, rec := range recs {
+		dto := DataFromFindingRec(rec)
+		args := db.NamedArgsSqlite{
+			"finding_key": dto.FindingKey,
+			"kind":        dto.K,
+			"waits":       dto.Waits,
+		}
+		_, err := ds.Conn.Exec(ds.Ctx, insertFindingSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.String("q", insertFindingSqlite))
+			return err
+		}
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "insertion succeed", slog.Int("findings", len(recs)))
+	return nil
+}
+
+func (dao *sqliteDAO) ReplayMods(source db.Source, apply bool) (ReplayRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	mods, err := selectAllSqlite[modRecDS](ds, selectModsSqlite)
+	if err != nil {
+		dao.log.Error("execution failed", slog.String("q", selectModsSqlite))
+		return ReplayRec{}, err
+	}
+	binds, err := selectAllSqlite[procbind.BindRecDS](ds, selectAllBindsSqlite)
+	if err != nil {
+		dao.log.Error("execution failed", slog.String("q", selectAllBindsSqlite))
+		return ReplayRec{}, err
+	}
+	steps, err := selectAllSqlite[procstep.StepRecDS](ds, selectAllStepsSqlite)
+	if err != nil {
+		dao.log.Error("execution failed", slog.String("q", selectAllStepsSqlite))
+		return ReplayRec{}, err
+	}
+	dto := replayMods(mods, binds, steps)
+	if apply {
+		for _, q := range []string{deleteReplayedBindsSqlite, deleteReplayedStepsSqlite} {
+			_, err = ds.Conn.Exec(ds.Ctx, q, db.NamedArgsSqlite{"exec_ids": dto.ExecIDs})
+			if err != nil {
+				dao.log.Error("execution failed", slog.String("q", q))
+				return ReplayRec{}, err
+			}
+		}
+		for _, bind := range dto.Binds {
+			args := db.NamedArgsSqlite{
+				"exec_id":  bind.ID,
+				"exec_rn":  bind.RN,
+				"chnl_bs":  bind.ChnlBS,
+				"chnl_ph":  bind.ChnlPH,
+				"chnl_id":  bind.ChnlID,
+				"state_id": bind.ExpID,
+			}
+			_, err = ds.Conn.Exec(ds.Ctx, insertBindSqlite, args)
+			if err != nil {
+				dao.log.Error("execution failed", slog.Any("dto", bind))
+				return ReplayRec{}, err
+			}
+		}
+		for _, step := range dto.Steps {
+			_, err = ds.Conn.Exec(ds.Ctx, insertStepSqlite, stepArgsSqlite(step))
+			if err != nil {
+				dao.log.Error("execution failed", slog.Any("dto", step))
+				return ReplayRec{}, err
+			}
+		}
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "replay succeed", slog.Int64("seq", dto.LastSeq), slog.Bool("apply", apply))
+	return DataToReplayRec(dto)
+}
+
+func selectAllSqlite[T any](ds db.SourceSqlite, query string) ([]T, error) {
+	rows, err := ds.Conn.Query(ds.Ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return db.CollectRowsSqlite[T](rows)
+}
+
+func stepArgsSqlite(dto procstep.StepRecDS) db.NamedArgsSqlite {
+	return db.NamedArgsSqlite{
+		"kind":    dto.K,
+		"exec_id": dto.ExecID,
+		"exec_rn": dto.ExecRN,
+		"chnl_id": dto.ChnlID,
+		"proc_er": dto.ProcER,
+	}
+}
+
+const (
+	insertExecSqlite = `
+		insert into proc_execs (
+			exec_id, exec_rn, dec_id, dec_rn
+		) values (
+			:exec_id, :exec_rn, :dec_id, :dec_rn
+		)`
+
+	insertModSqlite = `
+		insert into proc_mods (exec_mod) values (:exec_mod)`
+
+	selectModsSqlite = `
+		select seq, exec_mod
+		from proc_mods
+		order by seq`
+
+	selectAllBindsSqlite = `
+		select
+			exec_id, exec_rn, chnl_bs, chnl_ph, chnl_id, state_id as exp_id
+		from proc_binds`
+
+	selectAllStepsSqlite = `
+		select
+			exec_id, exec_rn, chnl_id, kind, proc_er
+		from proc_steps`
+
+	deleteReplayedBindsSqlite = `
+		delete from proc_binds
+		where exec_id in (select value from json_each(:exec_ids))`
+
+	deleteReplayedStepsSqlite = `
+		delete from proc_steps
+		where exec_id in (select value from json_each(:exec_ids))`
+
+	insertBindSqlite = `
+		insert into proc_binds (
+			exec_id, chnl_bs, chnl_ph, chnl_id, state_id, exec_rn
+		) values (
+			:exec_id, :chnl_bs, :chnl_ph, :chnl_id, :state_id, :exec_rn
+		)`
+
+	insertStepSqlite = `
+		insert into proc_steps (
+			exec_id, exec_rn, chnl_id, kind, proc_er
+		) values (
+			:exec_id, :exec_rn, :chnl_id, :kind, :proc_er
+		)`
+
+	insertAcqSqlite = `
+		insert into proc_acqs (
+			exec_id, exec_rn, chnl_id, kind, proc_er
+		) values (
+			:exec_id, :exec_rn, :chnl_id, :kind, :proc_er
+		)`
+
+	deleteAcqSqlite = `
+		delete from proc_acqs
+		where exec_id = :exec_id
+			and chnl_id = :chnl_id`
+
+	insertLeaseSqlite = `
+		insert into proc_leases (
+			chnl_id, provider_id, provider_ph, client_id, client_ph
+		) values (
+			:chnl_id, :provider_id, :provider_ph, :client_id, :client_ph
+		)`
+
+	deleteLeaseSqlite = `
+		delete from proc_leases
+		where chnl_id = :chnl_id
+			and client_id = :client_id`
+
+	// the channel queue is served in arrival order
+	selectAcqsSqlite = `
+		select
+			exec_id, exec_rn, chnl_id, kind, proc_er
+		from (
+			select
+				*,
+				row_number() over (
+					partition by chnl_id
+					order by acq_seq
+				) as pos
+			from proc_acqs
+			where chnl_id in (select value from json_each(:chnl_ids))
+		)
+		where pos = 1`
+
+	selectLeasesSqlite = `
+		select
+			chnl_id, provider_id, provider_ph, client_id, client_ph
+		from proc_leases
+		where provider_id = :exec_id
+			or client_id = :exec_id`
+
+	updateExecSqlite = `
+		update proc_execs
+		set exec_rn = :exec_rn + 1
+		where exec_id = :exec_id
+			and exec_rn = :exec_rn`
+
+	selectExecSqlite = `
+		select
+			exec_id as id, exec_rn as rn
+		from proc_execs
+		where exec_id = :exec_id`
+
+	// only work steps carry its amount, the sum skips the rest
+	selectWorkSqlite = `
+		select
+			coalesce((
+				select sum(json_extract(s.proc_er, '$.work.w'))
+				from proc_steps s
+				where s.exec_id = e.exec_id
+			), 0) as work,
+			coalesce(d.pot, 0) as pot
+		from proc_execs e
+		left join proc_decs d
+			on d.dec_id = e.dec_id
+			and d.dec_rn = e.dec_rn
+		where e.exec_id = :exec_id`
+
+	// a rebound name sees the latest received value
+	selectValsSqlite = `
+		select
+			exec_id, exec_rn, chnl_id, kind, proc_er
+		from (
+			select
+				*,
+				row_number() over (
+					partition by json_extract(proc_er, '$.val.y')
+					order by exec_rn desc
+				) as pos
+			from proc_steps
+			where exec_id = :exec_id
+				and json_extract(proc_er, '$.val') is not null
+		)
+		where pos = 1`
+
+	// removed binds carry negative revision
+	selectChnlsSqlite = `
+		select
+			exec_id, exec_rn, chnl_bs, chnl_ph, chnl_id, exp_id
+		from (
+			select
+				exec_id, exec_rn, chnl_bs, chnl_ph, chnl_id, state_id as exp_id,
+				row_number() over (
+					partition by chnl_ph
+					order by abs(exec_rn) desc
+				) as pos
+			from proc_binds
+			where exec_id = :exec_id
+		)
+		where pos = 1
+			and exec_rn > 0`
+
+	// steps are keyed by channel, so pending ones are those posted
+	// to channels still bound in the execution
+	selectStepsSqlite = `
+		select
+			exec_id, exec_rn, chnl_id, kind, proc_er
+		from proc_steps
+		where chnl_id in (select value from json_each(:chnl_ids))`
+
+	insertRunSqlite = `
+		insert into proc_runs (
+			run_id, ticket_id, exec_id, exec_rn, proc_es, status, wake_at
+		) values (
+			:run_id, :ticket_id, :exec_id, :exec_rn, :proc_es, :status, :wake_at
+		)`
+
+	updateRunSqlite = `
+		update proc_runs
+		set status = :status,
+			reason = :reason
+		where run_id = :run_id`
+
+	// steps of one process run strictly in order,
+	// a delayed step blocks the later ones until its tick
+	selectNextRunSqlite = `
+		select
+			r.run_id, r.ticket_id, r.exec_id, r.exec_rn, r.proc_es, r.status, r.reason, r.wake_at
+		from proc_runs r
+		where (r.status = :pending
+				or r.status = :running and r.claimed_at < :stale)
+			and (r.wake_at is null or r.wake_at <= :now)
+			and not exists (
+				select 1
+				from proc_runs p
+				where p.exec_id = r.exec_id
+					and p.run_seq < r.run_seq
+					and p.status in (:pending, :running)
+			)
+		order by r.run_seq
+		limit 1`
+
+	claimRunSqlite = `
+		update proc_runs
+		set status = :running,
+			claimed_at = :now
+		where run_id = :run_id`
+
+	selectTicketSqlite = `
+		select
+			ticket_id,
+			case
+				when max(status = :failed) then :failed
+				when max(status in (:pending, :running)) then :pending
+				else :done
+			end as status,
+			group_concat(reason, '; ') as reason
+		from proc_runs
+		where ticket_id = :ticket_id
+		group by ticket_id`
+
+	// unmatched step halves and the opposite ends of their channels
+	selectWaitsSqlite = `
+		with binds as (
+			select
+				exec_id, exec_rn, chnl_ph, chnl_id,
+				row_number() over (
+					partition by exec_id, chnl_ph
+					order by abs(exec_rn) desc
+				) as pos
+			from proc_binds
+		), live as (
+			select * from binds where pos = 1 and exec_rn > 0
+		)
+		select
+			s.exec_id,
+			s.chnl_id,
+			w.chnl_ph,
+			p.exec_id as peer_id
+		from proc_steps s
+		join proc_execs e
+			on e.exec_id = s.exec_id
+			and e.exec_rn = s.exec_rn
+		join live w
+			on w.exec_id = s.exec_id
+			and w.chnl_id = s.chnl_id
+		left join live p
+			on p.chnl_id = s.chnl_id
+			and p.exec_id <> s.exec_id
+		order by s.exec_id, s.chnl_id`
+
+	insertFindingSqlite = `
+		insert into proc_findings (
+			finding_key, kind, waits
+		) values (
+			:finding_key, :kind, :waits
+		)
+		on conflict (finding_key) do nothing`
+)
@@ -0,0 +1,23 @@
+package procexec
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func (dto schedulingCS) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.Workers, validation.Required, validation.Max(uint8(64))),
+		validation.Field(&dto.Interval, validation.Required, validation.Min(time.Millisecond)),
+		validation.Field(&dto.Lease, validation.Required, validation.Min(time.Second)),
+		validation.Field(&dto.Tick, validation.Required, validation.Min(time.Millisecond)),
+		validation.Field(&dto.Check, validation.Required, validation.Min(time.Second)),
+	)
+}
+
+func (dto TicketRefVP) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.TicketRef, validation.Required),
+	)
+}
@@ -12,6 +12,7 @@ import (
 
 	"orglang/go-runtime/lib/lf"
 
+	"orglang/go-runtime/adt/identity"
 	"orglang/go-runtime/adt/procstep"
 	"orglang/go-runtime/adt/uniqref"
 )
@@ -27,8 +28,11 @@ func newEchoController(a API, l *slog.Logger) *echoController {
 }
 
 func cfgEchoController(e *echo.Echo, h *echoController) error {
+	e.GET("/api/v1/procs/findings", h.GetFindings)
+	e.GET("/api/v1/procs/journal", h.GetJournal)
 	e.GET("/api/v1/procs/:id", h.GetSnap)
 	e.POST("/api/v1/procs/:id/steps", h.PostStep)
+	e.GET("/api/v1/tickets/:id", h.GetTicket)
 	return nil
 }
 
@@ -44,13 +48,14 @@ func (h *echoController) GetSnap(c echo.Context) error {
 		h.log.Error("conversion failed", slog.Any("dto", dto))
 		return conversionErr
 	}
-	snap, retrievalErr := h.api.RetrieveSnap(ref)
+	snap, retrievalErr := h.api.RetrieveSnap(c.Request().Context(), ref)
 	if retrievalErr != nil {
 		return retrievalErr
 	}
-	return c.JSON(http.StatusOK, MsgFromExecSnap(snap))
+	return c.JSON(http.StatusOK, ViewFromExecSnap(snap))
 }
 
+// the step is queued, its outcome is available by the ticket
 func (h *echoController) PostStep(c echo.Context) error {
 	var dto sdk.StepSpec
 	bindingErr := c.Bind(&dto)
@@ -70,9 +75,51 @@ func (h *echoController) PostStep(c echo.Context) error {
 		h.log.Error("conversion failed", slog.Any("dto", dto))
 		return conversionErr
 	}
-	takingErr := h.api.Take(spec)
-	if takingErr != nil {
-		return takingErr
+	ticketRef, postingErr := h.api.Post(ctx, spec)
+	if postingErr != nil {
+		return postingErr
 	}
-	return c.NoContent(http.StatusOK)
+	h.log.Log(ctx, lf.LevelTrace, "posting succeed", slog.Any("ticketRef", ticketRef))
+	return c.JSON(http.StatusAccepted, ViewFromTicketSnap(TicketSnap{TicketRef: ticketRef, Status: PendingRun}))
+}
+
+func (h *echoController) GetTicket(c echo.Context) error {
+	var dto TicketRefVP
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	validationErr := dto.Validate()
+	if validationErr != nil {
+		h.log.Error("validation failed", slog.Any("dto", dto))
+		return validationErr
+	}
+	ref, conversionErr := identity.ConvertFromString(dto.TicketRef)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	snap, retrievalErr := h.api.RetrieveTicket(c.Request().Context(), ref)
+	if retrievalErr != nil {
+		return retrievalErr
+	}
+	return c.JSON(http.StatusOK, ViewFromTicketSnap(snap))
+}
+
+func (h *echoController) GetFindings(c echo.Context) error {
+	findings, analysisErr := h.api.Analyze(c.Request().Context())
+	if analysisErr != nil {
+		return analysisErr
+	}
+	return c.JSON(http.StatusOK, ViewFromFindingRecs(findings))
+}
+
+// verification without changes; verified only if every execution is verified
+func (h *echoController) GetJournal(c echo.Context) error {
+	rec, verificationErr := h.api.Verify(c.Request().Context())
+	if verificationErr != nil {
+		return verificationErr
+	}
+	return c.JSON(http.StatusOK, ViewFromReplayRec(rec))
 }
@@ -0,0 +1,113 @@
+package procexec
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// Background primary adapter
+type schedulerStdlib struct {
+	api API
+	cs  schedulingCS
+	log *slog.Logger
+}
+
+func newSchedulerStdlib(a API, cs schedulingCS, l *slog.Logger) *schedulerStdlib {
+	name := slog.String("name", reflect.TypeFor[schedulerStdlib]().Name())
+	return &schedulerStdlib{a, cs, l.With(name)}
+}
+
+func cfgSchedulerStdlib(lc fx.Lifecycle, s *schedulerStdlib) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	lc.Append(
+		fx.Hook{
+			OnStart: func(context.Context) error {
+				for i := range int(s.cs.Workers) {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						s.drain(ctx, slog.Int("worker", i))
+					}()
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					s.check(ctx)
+				}()
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				cancel()
+				done := make(chan struct{})
+				go func() {
+					wg.Wait()
+					close(done)
+				}()
+				select {
+				case <-done:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			},
+		},
+	)
+}
+
+func (s *schedulerStdlib) drain(ctx context.Context, workerAttr slog.Attr) {
+	s.log.Debug("draining started", workerAttr)
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Debug("draining stopped", workerAttr)
+			return
+		default:
+		}
+		ticketRef, err := s.api.Poll(ctx)
+		if ctx.Err() != nil {
+			s.log.Debug("draining stopped", workerAttr)
+			return
+		}
+		if err != nil {
+			s.log.Error("polling failed", workerAttr, slog.Any("ticketRef", ticketRef), slog.Any("reason", err))
+		}
+		// the queue is not empty, take the next step without a pause
+		if err == nil && !ticketRef.IsEmpty() {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			s.log.Debug("draining stopped", workerAttr)
+			return
+		case <-time.After(s.cs.Interval):
+		}
+	}
+}
+
+func (s *schedulerStdlib) check(ctx context.Context) {
+	s.log.Debug("checking started")
+	ticker := time.NewTicker(s.cs.Check)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Debug("checking stopped")
+			return
+		case <-ticker.C:
+		}
+		_, err := s.api.Check(ctx)
+		if ctx.Err() != nil {
+			s.log.Debug("checking stopped")
+			return
+		}
+		if err != nil {
+			s.log.Error("checking failed", slog.Any("reason", err))
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package procexec
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/procexp"
+	"orglang/go-runtime/adt/procstep"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/typeexp"
+	"orglang/go-runtime/adt/uniqref"
+)
+
+func ViewFromExecSnap(snap ExecSnap) ExecSnapVP {
+	binds := make([]BindRecVP, 0, len(snap.ChnlBRs))
+	for _, chnlPH := range slices.Sorted(maps.Keys(snap.ChnlBRs)) {
+		bind := snap.ChnlBRs[chnlPH]
+		binds = append(binds, BindRecVP{
+			ChnlPH: symbol.ConvertToString(bind.ChnlPH),
+			ChnlID: identity.ConvertToString(bind.ChnlID),
+			ChnlBS: viewFromBindSide(bind),
+			TypeES: typeexp.MsgFromExpSpec(snap.ChnlESs[chnlPH]),
+		})
+	}
+	steps := make([]StepRecVP, 0, len(snap.ProcSRs))
+	for _, chnlID := range slices.SortedFunc(maps.Keys(snap.ProcSRs), compareIDs) {
+		steps = append(steps, viewFromStepRec(snap.ProcSRs[chnlID]))
+	}
+	var vals map[string]json.RawMessage
+	if len(snap.Vals) > 0 {
+		vals = make(map[string]json.RawMessage, len(snap.Vals))
+		for bindVar, val := range snap.Vals {
+			vals[symbol.ConvertToString(bindVar)] = val.Val
+		}
+	}
+	return ExecSnapVP{
+		ExecRef: uniqref.MsgFromADT(snap.ExecRef),
+		ChnlBRs: binds,
+		ProcSRs: steps,
+		Work:    snap.Work,
+		Pot:     snap.Pot,
+		Vals:    vals,
+	}
+}
+
+func viewFromBindSide(bind procbind.BindRec) string {
+	if bind.ChnlBS == procbind.ProviderSide {
+		return "provider"
+	}
+	return "client"
+}
+
+func viewFromStepRec(stepRec procstep.StepRec) StepRecVP {
+	switch rec := stepRec.(type) {
+	case procstep.MsgRec:
+		return StepRecVP{
+			K:       "msg",
+			ExecRef: uniqref.MsgFromADT(rec.ExecRef),
+			ChnlID:  identity.ConvertToString(rec.ChnlID),
+			ChnlPH:  symbol.ConvertToString(rec.ValER.Via()),
+		}
+	case procstep.SvcRec:
+		return StepRecVP{
+			K:       "svc",
+			ExecRef: uniqref.MsgFromADT(rec.ExecRef),
+			ChnlID:  identity.ConvertToString(rec.ChnlID),
+			ChnlPH:  symbol.ConvertToString(rec.ContER.Via()),
+		}
+	default:
+		panic(procstep.ErrRecTypeUnexpected(stepRec))
+	}
+}
+
+func compareIDs(a, b identity.ADT) int {
+	return strings.Compare(a.String(), b.String())
+}
+
+func DataFromRunRec(rec RunRec) (runRecDS, error) {
+	procES, err := procexp.DataFromExpSpec(rec.StepSpec.ProcES)
+	if err != nil {
+		return runRecDS{}, err
+	}
+	return runRecDS{
+		RunID:    identity.ConvertToString(rec.RunID),
+		TicketID: identity.ConvertToString(rec.TicketRef),
+		ExecID:   identity.ConvertToString(rec.StepSpec.ExecRef.ID),
+		ExecRN:   revnum.ConvertToInt(rec.StepSpec.ExecRef.RN),
+		ProcES:   procES,
+		Status:   runStatusDS(rec.Status),
+		Reason:   sql.NullString{String: rec.Reason, Valid: len(rec.Reason) > 0},
+		WakeAt:   sql.NullTime{Time: rec.WakeAt, Valid: !rec.WakeAt.IsZero()},
+	}, nil
+}
+
+func DataToRunRec(dto runRecDS) (RunRec, error) {
+	runID, err := identity.ConvertFromString(dto.RunID)
+	if err != nil {
+		return RunRec{}, err
+	}
+	ticketRef, err := identity.ConvertFromString(dto.TicketID)
+	if err != nil {
+		return RunRec{}, err
+	}
+	execID, err := identity.ConvertFromString(dto.ExecID)
+	if err != nil {
+		return RunRec{}, err
+	}
+	procES, err := procexp.DataToExpSpec(dto.ProcES)
+	if err != nil {
+		return RunRec{}, err
+	}
+	return RunRec{
+		RunID:     runID,
+		TicketRef: ticketRef,
+		StepSpec: procstep.StepSpec{
+			ExecRef: uniqref.ADT{ID: execID, RN: revnum.ConvertFromInt(dto.ExecRN)},
+			ProcES:  procES,
+		},
+		Status: RunStatus(dto.Status),
+		Reason: dto.Reason.String,
+		WakeAt: dto.WakeAt.Time,
+	}, nil
+}
+
+func DataToTicketSnap(dto ticketSnapDS) (TicketSnap, error) {
+	ticketRef, err := identity.ConvertFromString(dto.TicketID)
+	if err != nil {
+		return TicketSnap{}, err
+	}
+	return TicketSnap{
+		TicketRef: ticketRef,
+		Status:    RunStatus(dto.Status),
+		Reason:    dto.Reason.String,
+	}, nil
+}
+
+func ViewFromTicketSnap(snap TicketSnap) TicketSnapVP {
+	return TicketSnapVP{
+		TicketRef: identity.ConvertToString(snap.TicketRef),
+		Status:    viewFromRunStatus(snap.Status),
+		Reason:    snap.Reason,
+	}
+}
+
+func viewFromRunStatus(status RunStatus) string {
+	switch status {
+	case PendingRun, RunningRun:
+		return "pending"
+	case DoneRun:
+		return "done"
+	case FailedRun:
+		return "failed"
+	default:
+		panic(fmt.Errorf("run status unexpected: %v", status))
+	}
+}
+
+func DataToWaitRecs(dtos []waitRecDS) ([]WaitRec, error) {
+	recs := make([]WaitRec, 0, len(dtos))
+	for _, dto := range dtos {
+		rec, err := dataToWaitRec(dto)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+func dataToWaitRec(dto waitRecDS) (WaitRec, error) {
+	execID, err := identity.ConvertFromString(dto.ExecID)
+	if err != nil {
+		return WaitRec{}, err
+	}
+	chnlID, err := identity.ConvertFromString(dto.ChnlID)
+	if err != nil {
+		return WaitRec{}, err
+	}
+	chnlPH, err := symbol.ConvertFromString(dto.ChnlPH)
+	if err != nil {
+		return WaitRec{}, err
+	}
+	rec := WaitRec{ExecID: execID, ChnlID: chnlID, ChnlPH: chnlPH}
+	if !dto.PeerID.Valid {
+		return rec, nil
+	}
+	rec.PeerID, err = identity.ConvertFromString(dto.PeerID.String)
+	if err != nil {
+		return WaitRec{}, err
+	}
+	return rec, nil
+}
+
+func DataFromFindingRec(rec FindingRec) findingRecDS {
+	waits := make([]waitRecDS, 0, len(rec.Waits))
+	keys := make([]string, 0, len(rec.Waits))
+	for _, wait := range rec.Waits {
+		dto := waitRecDS{
+			ExecID: identity.ConvertToString(wait.ExecID),
+			ChnlID: identity.ConvertToString(wait.ChnlID),
+			ChnlPH: symbol.ConvertToString(wait.ChnlPH),
+		}
+		if !wait.PeerID.IsEmpty() {
+			dto.PeerID = sql.NullString{String: identity.ConvertToString(wait.PeerID), Valid: true}
+		}
+		waits = append(waits, dto)
+		keys = append(keys, dto.ExecID+"/"+dto.ChnlID)
+	}
+	slices.Sort(keys)
+	return findingRecDS{
+		FindingKey: fmt.Sprintf("%v:%v", rec.K, strings.Join(keys, ",")),
+		K:          findingKindDS(rec.K),
+		Waits:      waits,
+	}
+}
+
+func ViewFromFindingRecs(recs []FindingRec) []FindingVP {
+	views := make([]FindingVP, 0, len(recs))
+	for _, rec := range recs {
+		waits := make([]WaitVP, 0, len(rec.Waits))
+		for _, wait := range rec.Waits {
+			view := WaitVP{
+				ExecID: identity.ConvertToString(wait.ExecID),
+				ChnlID: identity.ConvertToString(wait.ChnlID),
+				ChnlPH: symbol.ConvertToString(wait.ChnlPH),
+			}
+			if !wait.PeerID.IsEmpty() {
+				view.PeerID = identity.ConvertToString(wait.PeerID)
+			}
+			waits = append(waits, view)
+		}
+		views = append(views, FindingVP{K: viewFromFindingKind(rec.K), Waits: waits})
+	}
+	return views
+}
+
+func viewFromFindingKind(kind FindingKind) string {
+	switch kind {
+	case DeadlockFinding:
+		return "deadlock"
+	case OrphanFinding:
+		return "orphan"
+	default:
+		panic(fmt.Errorf("finding kind unexpected: %v", kind))
+	}
+}
+
+func DataToReplayRec(dto replayDS) (ReplayRec, error) {
+	drifts := make([]DriftRec, 0, len(dto.Drifts))
+	for _, drift := range dto.Drifts {
+		execID, err := identity.ConvertFromString(drift.ExecID)
+		if err != nil {
+			return ReplayRec{}, err
+		}
+		drifts = append(drifts, DriftRec{
+			ExecID:       execID,
+			MissingBinds: drift.MissingBinds,
+			MissingSteps: drift.MissingSteps,
+			ExtraBinds:   drift.ExtraBinds,
+			ExtraSteps:   drift.ExtraSteps,
+		})
+	}
+	unverifiable := make([]identity.ADT, 0, len(dto.Unverifiable))
+	for _, dto := range dto.Unverifiable {
+		execID, err := identity.ConvertFromString(dto)
+		if err != nil {
+			return ReplayRec{}, err
+		}
+		unverifiable = append(unverifiable, execID)
+	}
+	return ReplayRec{
+		LastSeq:      dto.LastSeq,
+		Binds:        len(dto.Binds),
+		Steps:        len(dto.Steps),
+		Unverifiable: unverifiable,
+		Drifts:       drifts,
+	}, nil
+}
+
+func ViewFromReplayRec(rec ReplayRec) ReplayVP {
+	drifts := make([]DriftVP, 0, len(rec.Drifts))
+	for _, drift := range rec.Drifts {
+		drifts = append(drifts, DriftVP{
+			ExecID:       identity.ConvertToString(drift.ExecID),
+			MissingBinds: drift.MissingBinds,
+			MissingSteps: drift.MissingSteps,
+			ExtraBinds:   drift.ExtraBinds,
+			ExtraSteps:   drift.ExtraSteps,
+		})
+	}
+	unverifiable := make([]string, 0, len(rec.Unverifiable))
+	for _, execID := range rec.Unverifiable {
+		unverifiable = append(unverifiable, identity.ConvertToString(execID))
+	}
+	return ReplayVP{
+		LastSeq:      rec.LastSeq,
+		Binds:        rec.Binds,
+		Steps:        rec.Steps,
+		Verified:     rec.Verified(),
+		Unverifiable: unverifiable,
+		Drifts:       drifts,
+	}
+}
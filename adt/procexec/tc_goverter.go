@@ -8,16 +8,25 @@ import "github.com/orglang/go-sdk/adt/procexec"
 var (
 	MsgToExecRef   func(procexec.ExecRef) (ExecRef, error)
 	MsgFromExecRef func(ExecRef) procexec.ExecRef
-	// goverter:ignore ChnlBRs ProcSRs
+	// goverter:ignore ChnlBRs ProcSRs AcqSRs LeaseRs ChnlESs
 	MsgToExecSnap   func(procexec.ExecSnap) (ExecSnap, error)
 	MsgFromExecSnap func(ExecSnap) procexec.ExecSnap
 )
 
 // goverter:variables
 // goverter:output:format assign-variable
+// goverter:extend orglang/go-runtime/adt/identity:Convert.*
+// goverter:extend orglang/go-runtime/adt/revnum:Convert.*
+// goverter:extend orglang/go-runtime/adt/symbol:Convert.*
 // goverter:extend orglang/go-runtime/adt/uniqref:Data.*
 // goverter:extend orglang/go-runtime/adt/procbind:Data.*
 // goverter:extend orglang/go-runtime/adt/procstep:Data.*
 var (
 	DataFromMod func(ExecMod) (execModDS, error)
+	// goverter:autoMap ExecRef
+	// goverter:map DecRef.ID DecID
+	// goverter:map DecRef.RN DecRN
+	DataFromExecRec  func(ExecRec) (execRecDS, error)
+	DataToLeaseRecs  func([]leaseRecDS) ([]LeaseRec, error)
+	DataFromLeaseRec func(LeaseRec) leaseRecDS
 )
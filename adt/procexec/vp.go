@@ -0,0 +1,78 @@
+package procexec
+
+import (
+	"encoding/json"
+
+	"github.com/orglang/go-sdk/adt/typeexp"
+	"github.com/orglang/go-sdk/adt/uniqref"
+)
+
+type ExecRefVP = uniqref.Msg
+
+type ExecSnapVP struct {
+	ExecRef ExecRefVP   `json:"ref"`
+	ChnlBRs []BindRecVP `json:"binds"`
+	ProcSRs []StepRecVP `json:"steps"`
+	// work performed so far and its static bound
+	Work int64 `json:"work"`
+	Pot  int64 `json:"pot"`
+	// values received so far, keyed by name
+	Vals map[string]json.RawMessage `json:"vals,omitempty"`
+}
+
+type BindRecVP struct {
+	ChnlPH string          `json:"chnl_ph"`
+	ChnlID string          `json:"chnl_id"`
+	ChnlBS string          `json:"chnl_bs"`
+	TypeES typeexp.ExpSpec `json:"type_es"`
+}
+
+// pending step awaiting its counterpart
+type StepRecVP struct {
+	K       string    `json:"kind"`
+	ExecRef ExecRefVP `json:"exec_ref"`
+	ChnlID  string    `json:"chnl_id"`
+	ChnlPH  string    `json:"chnl_ph"`
+}
+
+type TicketRefVP struct {
+	TicketRef string `param:"id" json:"id"`
+}
+
+type TicketSnapVP struct {
+	TicketRef string `json:"id"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type FindingVP struct {
+	K     string   `json:"kind"`
+	Waits []WaitVP `json:"waits"`
+}
+
+// half-step blocked on its channel
+type WaitVP struct {
+	ExecID string `json:"exec_id"`
+	ChnlID string `json:"chnl_id"`
+	ChnlPH string `json:"chnl_ph"`
+	PeerID string `json:"peer_id,omitempty"`
+}
+
+type ReplayVP struct {
+	LastSeq int64 `json:"last_seq"`
+	Binds   int   `json:"binds"`
+	Steps   int   `json:"steps"`
+	// false when anything drifted or could not be checked
+	Verified     bool      `json:"verified"`
+	Unverifiable []string  `json:"unverifiable"`
+	Drifts       []DriftVP `json:"drifts"`
+}
+
+// rows the journal and the live tables disagree on
+type DriftVP struct {
+	ExecID       string `json:"exec_id"`
+	MissingBinds int    `json:"missing_binds,omitempty"`
+	MissingSteps int    `json:"missing_steps,omitempty"`
+	ExtraBinds   int    `json:"extra_binds,omitempty"`
+	ExtraSteps   int    `json:"extra_steps,omitempty"`
+}
@@ -1,10 +1,14 @@
 package procexp
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
 
+	"orglang/go-runtime/adt/arithexp"
 	"orglang/go-runtime/adt/identity"
 	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/typeexp"
 	"orglang/go-runtime/adt/uniqsym"
 )
 
@@ -71,15 +75,17 @@ type FwdSpec struct {
 
 func (s FwdSpec) Via() symbol.ADT { return s.CommChnlPH }
 
+// tail call: the callee body replaces the current one
 type CallSpec struct {
-	CommChnlPH symbol.ADT // TODO удалить
 	BindChnlPH symbol.ADT
 	ProcQN     uniqsym.ADT
 	ValChnlPHs []symbol.ADT // channel bulk
-	ContES     ExpSpec
+	// index arguments of the callee
+	IdxESs []arithexp.ExpSpec
+	ContES ExpSpec
 }
 
-func (s CallSpec) Via() symbol.ADT { return s.CommChnlPH }
+func (s CallSpec) Via() symbol.ADT { return s.BindChnlPH }
 
 // аналог RecvSpec, но значения принимаются балком
 type SpawnSpecOld struct {
@@ -96,17 +102,107 @@ type SpawnSpec struct {
 	CommChnlPH  symbol.ADT
 	ProcQN      uniqsym.ADT
 	BindChnlPHs []symbol.ADT
-	ContES      ExpSpec
+	// index arguments of the spawned process
+	IdxESs []arithexp.ExpSpec
+	ContES ExpSpec
 }
 
 func (s SpawnSpec) Via() symbol.ADT { return s.CommChnlPH }
 
-type AcqureSpec struct {
+// aka SendNat: sends a natural (∃ for a provider, ∀ for a client)
+type SendIdxSpec struct {
+	CommChnlPH symbol.ADT
+	IdxES      arithexp.ExpSpec
+	ContES     ExpSpec
+}
+
+func (s SendIdxSpec) Via() symbol.ADT { return s.CommChnlPH }
+
+// aka RecvNat: receives a natural (∀ for a provider, ∃ for a client)
+type RecvIdxSpec struct {
+	CommChnlPH symbol.ADT
+	IdxVar     symbol.ADT
+	ContES     ExpSpec
+}
+
+func (s RecvIdxSpec) Via() symbol.ADT { return s.CommChnlPH }
+
+// aka Assert: guarantees a constraint (proved statically)
+type AssertSpec struct {
+	CommChnlPH symbol.ADT
+	Prop       arithexp.PropSpec
+	ContES     ExpSpec
+}
+
+func (s AssertSpec) Via() symbol.ADT { return s.CommChnlPH }
+
+// aka Assume: the constraint becomes a known fact
+type AssumeSpec struct {
+	CommChnlPH symbol.ADT
+	Prop       arithexp.PropSpec
+	ContES     ExpSpec
+}
+
+func (s AssumeSpec) Via() symbol.ADT { return s.CommChnlPH }
+
+// aka Work: spends process potential, touches no channels
+type WorkSpec struct {
+	Work   int64
+	ContES ExpSpec
+}
+
+func (s WorkSpec) Via() symbol.ADT { return "" }
+
+// aka Delay: the continuation comes after ticks ticks
+type DelaySpec struct {
+	Ticks  int64
+	ContES ExpSpec
+}
+
+func (s DelaySpec) Via() symbol.ADT { return "" }
+
+// aka Now: resolves a modality (◇ for a provider, □ for a client)
+type NowSpec struct {
+	CommChnlPH symbol.ADT
+	ContES     ExpSpec
+}
+
+func (s NowSpec) Via() symbol.ADT { return s.CommChnlPH }
+
+// aka When: waits for the moment chosen by the other side
+type WhenSpec struct {
 	CommChnlPH symbol.ADT
 	ContES     ExpSpec
 }
 
-func (s AcqureSpec) Via() symbol.ADT { return s.CommChnlPH }
+func (s WhenSpec) Via() symbol.ADT { return s.CommChnlPH }
+
+// aka SendVal: sends a value (∧ for a provider, ⊃ for a client)
+type SendValSpec struct {
+	CommChnlPH symbol.ADT
+	// a literal or a previously received value
+	ValLit json.RawMessage
+	ValVar symbol.ADT
+	ContES ExpSpec
+}
+
+func (s SendValSpec) Via() symbol.ADT { return s.CommChnlPH }
+
+// aka RecvVal: receives a value (⊃ for a provider, ∧ for a client)
+type RecvValSpec struct {
+	CommChnlPH symbol.ADT
+	BindVar    symbol.ADT
+	ContES     ExpSpec
+}
+
+func (s RecvValSpec) Via() symbol.ADT { return s.CommChnlPH }
+
+type AcquireSpec struct {
+	CommChnlPH symbol.ADT
+	ContES     ExpSpec
+}
+
+func (s AcquireSpec) Via() symbol.ADT { return s.CommChnlPH }
 
 type AcceptSpec struct {
 	CommChnlPH symbol.ADT
@@ -200,12 +296,89 @@ func (r FwdRec) Via() symbol.ADT { return r.CommChnlPH }
 
 func (FwdRec) impl() {}
 
-func CollectEnv(spec ExpSpec) []identity.ADT {
-	return collectEnvRec(spec, []identity.ADT{})
+type AcquireRec struct {
+	CommChnlPH symbol.ADT
+	ContES     ExpSpec
+}
+
+func (r AcquireRec) Via() symbol.ADT { return r.CommChnlPH }
+
+func (AcquireRec) impl() {}
+
+type AcceptRec struct {
+	CommChnlPH symbol.ADT
+	ContES     ExpSpec
+}
+
+func (r AcceptRec) Via() symbol.ADT { return r.CommChnlPH }
+
+func (AcceptRec) impl() {}
+
+// work done, recorded by an execution step
+type WorkRec struct {
+	Work int64
+}
+
+func (r WorkRec) Via() symbol.ADT { return "" }
+
+func (WorkRec) impl() {}
+
+type SendValRec struct {
+	CommChnlPH symbol.ADT
+	ContChnlID identity.ADT
+	Val        json.RawMessage
+}
+
+func (r SendValRec) Via() symbol.ADT { return r.CommChnlPH }
+
+func (SendValRec) impl() {}
+
+type RecvValRec struct {
+	CommChnlPH symbol.ADT
+	ContChnlID identity.ADT
+	BindVar    symbol.ADT
+	ContES     ExpSpec
+}
+
+func (r RecvValRec) Via() symbol.ADT { return r.CommChnlPH }
+
+func (RecvValRec) impl() {}
+
+// received value, recorded by an execution step
+type ValRec struct {
+	BindVar symbol.ADT
+	T       typeexp.DataType
+	Val     json.RawMessage
+}
+
+func (r ValRec) Via() symbol.ADT { return "" }
+
+func (ValRec) impl() {}
+
+type DetachRec struct {
+	CommChnlPH symbol.ADT
+}
+
+func (r DetachRec) Via() symbol.ADT { return r.CommChnlPH }
+
+func (DetachRec) impl() {}
+
+type ReleaseRec struct {
+	CommChnlPH symbol.ADT
+}
+
+func (r ReleaseRec) Via() symbol.ADT { return r.CommChnlPH }
+
+func (ReleaseRec) impl() {}
+
+func CollectEnv(spec ExpSpec) []uniqsym.ADT {
+	return collectEnvRec(spec, []uniqsym.ADT{})
 }
 
-func collectEnvRec(es ExpSpec, env []identity.ADT) []identity.ADT {
+func collectEnvRec(es ExpSpec, env []uniqsym.ADT) []uniqsym.ADT {
 	switch spec := es.(type) {
+	case WaitSpec:
+		return collectEnvRec(spec.ContES, env)
 	case RecvSpec:
 		return collectEnvRec(spec.ContES, env)
 	case CaseSpec:
@@ -213,13 +386,201 @@ func collectEnvRec(es ExpSpec, env []identity.ADT) []identity.ADT {
 			env = collectEnvRec(cont, env)
 		}
 		return env
+	case AcquireSpec:
+		return collectEnvRec(spec.ContES, env)
+	case AcceptSpec:
+		return collectEnvRec(spec.ContES, env)
 	case SpawnSpec:
+		return collectEnvRec(spec.ContES, append(env, spec.ProcQN))
+	case CallSpec:
+		return collectEnvRec(spec.ContES, append(env, spec.ProcQN))
+	case SendIdxSpec:
+		return collectEnvRec(spec.ContES, env)
+	case RecvIdxSpec:
+		return collectEnvRec(spec.ContES, env)
+	case AssertSpec:
+		return collectEnvRec(spec.ContES, env)
+	case AssumeSpec:
+		return collectEnvRec(spec.ContES, env)
+	case WorkSpec:
+		return collectEnvRec(spec.ContES, env)
+	case DelaySpec:
+		return collectEnvRec(spec.ContES, env)
+	case NowSpec:
+		return collectEnvRec(spec.ContES, env)
+	case WhenSpec:
+		return collectEnvRec(spec.ContES, env)
+	case SendValSpec:
+		return collectEnvRec(spec.ContES, env)
+	case RecvValSpec:
 		return collectEnvRec(spec.ContES, env)
 	default:
 		return env
 	}
 }
 
+// RenameSpec substitutes free channel placeholders according to phs
+func RenameSpec(es ExpSpec, phs map[symbol.ADT]symbol.ADT) ExpSpec {
+	rename := func(ph symbol.ADT) symbol.ADT {
+		newPH, ok := phs[ph]
+		if !ok {
+			return ph
+		}
+		return newPH
+	}
+	switch spec := es.(type) {
+	case nil:
+		return nil
+	case CloseSpec:
+		return CloseSpec{CommChnlPH: rename(spec.CommChnlPH)}
+	case WaitSpec:
+		return WaitSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			ContES:     RenameSpec(spec.ContES, phs),
+		}
+	case SendSpec:
+		return SendSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			ValChnlPH:  rename(spec.ValChnlPH),
+		}
+	case RecvSpec:
+		return RecvSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			BindChnlPH: spec.BindChnlPH,
+			ContES:     RenameSpec(spec.ContES, shadow(phs, spec.BindChnlPH)),
+		}
+	case LabSpec:
+		return LabSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			LabelQN:    spec.LabelQN,
+		}
+	case CaseSpec:
+		conts := make(map[uniqsym.ADT]ExpSpec, len(spec.ContESs))
+		for label, cont := range spec.ContESs {
+			conts[label] = RenameSpec(cont, phs)
+		}
+		return CaseSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			ContESs:    conts,
+		}
+	case FwdSpec:
+		return FwdSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			ContChnlPH: rename(spec.ContChnlPH),
+		}
+	case CallSpec:
+		valPHs := make([]symbol.ADT, len(spec.ValChnlPHs))
+		for i, valPH := range spec.ValChnlPHs {
+			valPHs[i] = rename(valPH)
+		}
+		// in tail position the call provides the caller's channel,
+		// while a continuation would see that name bound afresh
+		return CallSpec{
+			BindChnlPH: rename(spec.BindChnlPH),
+			ProcQN:     spec.ProcQN,
+			ValChnlPHs: valPHs,
+			IdxESs:     spec.IdxESs,
+			ContES:     RenameSpec(spec.ContES, shadow(phs, spec.BindChnlPH)),
+		}
+	case AcquireSpec:
+		return AcquireSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			ContES:     RenameSpec(spec.ContES, phs),
+		}
+	case AcceptSpec:
+		return AcceptSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			ContES:     RenameSpec(spec.ContES, phs),
+		}
+	case DetachSpec:
+		return DetachSpec{CommChnlPH: rename(spec.CommChnlPH)}
+	case ReleaseSpec:
+		return ReleaseSpec{CommChnlPH: rename(spec.CommChnlPH)}
+	case SpawnSpec:
+		bindPHs := make([]symbol.ADT, len(spec.BindChnlPHs))
+		for i, bindPH := range spec.BindChnlPHs {
+			bindPHs[i] = rename(bindPH)
+		}
+		return SpawnSpec{
+			CommChnlPH:  spec.CommChnlPH,
+			ProcQN:      spec.ProcQN,
+			BindChnlPHs: bindPHs,
+			IdxESs:      spec.IdxESs,
+			ContES:      RenameSpec(spec.ContES, shadow(phs, spec.CommChnlPH)),
+		}
+	case SendIdxSpec:
+		return SendIdxSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			IdxES:      spec.IdxES,
+			ContES:     RenameSpec(spec.ContES, phs),
+		}
+	case RecvIdxSpec:
+		return RecvIdxSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			IdxVar:     spec.IdxVar,
+			ContES:     RenameSpec(spec.ContES, phs),
+		}
+	case AssertSpec:
+		return AssertSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			Prop:       spec.Prop,
+			ContES:     RenameSpec(spec.ContES, phs),
+		}
+	case AssumeSpec:
+		return AssumeSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			Prop:       spec.Prop,
+			ContES:     RenameSpec(spec.ContES, phs),
+		}
+	case WorkSpec:
+		return WorkSpec{
+			Work:   spec.Work,
+			ContES: RenameSpec(spec.ContES, phs),
+		}
+	case DelaySpec:
+		return DelaySpec{
+			Ticks:  spec.Ticks,
+			ContES: RenameSpec(spec.ContES, phs),
+		}
+	case NowSpec:
+		return NowSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			ContES:     RenameSpec(spec.ContES, phs),
+		}
+	case WhenSpec:
+		return WhenSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			ContES:     RenameSpec(spec.ContES, phs),
+		}
+	case SendValSpec:
+		return SendValSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			ValLit:     spec.ValLit,
+			ValVar:     spec.ValVar,
+			ContES:     RenameSpec(spec.ContES, phs),
+		}
+	case RecvValSpec:
+		return RecvValSpec{
+			CommChnlPH: rename(spec.CommChnlPH),
+			BindVar:    spec.BindVar,
+			ContES:     RenameSpec(spec.ContES, phs),
+		}
+	default:
+		panic(ErrExpTypeUnexpected(es))
+	}
+}
+
+// bound placeholder hides the outer one
+func shadow(phs map[symbol.ADT]symbol.ADT, bindPH symbol.ADT) map[symbol.ADT]symbol.ADT {
+	_, ok := phs[bindPH]
+	if !ok {
+		return phs
+	}
+	inner := maps.Clone(phs)
+	delete(inner, bindPH)
+	return inner
+}
+
 func ErrExpTypeUnexpected(got ExpSpec) error {
 	return fmt.Errorf("term spec unexpected: %T", got)
 }
@@ -0,0 +1,40 @@
+package procexp
+
+import (
+	"reflect"
+	"testing"
+
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+func TestRenameSpec(t *testing.T) {
+	procQN := uniqsym.New("proc")
+	phs := map[symbol.ADT]symbol.ADT{"x": "y", "v": "w"}
+	tests := []struct {
+		name string
+		es   ExpSpec
+		want ExpSpec
+	}{
+		{"free", CloseSpec{CommChnlPH: "x"}, CloseSpec{CommChnlPH: "y"}},
+		{"unmapped", CloseSpec{CommChnlPH: "z"}, CloseSpec{CommChnlPH: "z"}},
+		{"tail call",
+			CallSpec{BindChnlPH: "x", ProcQN: procQN, ValChnlPHs: []symbol.ADT{"v"}},
+			CallSpec{BindChnlPH: "y", ProcQN: procQN, ValChnlPHs: []symbol.ADT{"w"}}},
+		// the continuation sees the binder, not the caller's channel
+		{"call binder shadows",
+			CallSpec{BindChnlPH: "x", ProcQN: procQN, ValChnlPHs: []symbol.ADT{}, ContES: WaitSpec{CommChnlPH: "x", ContES: CloseSpec{CommChnlPH: "v"}}},
+			CallSpec{BindChnlPH: "y", ProcQN: procQN, ValChnlPHs: []symbol.ADT{}, ContES: WaitSpec{CommChnlPH: "x", ContES: CloseSpec{CommChnlPH: "w"}}}},
+		{"recv binder shadows",
+			RecvSpec{CommChnlPH: "x", BindChnlPH: "v", ContES: SendSpec{CommChnlPH: "x", ValChnlPH: "v"}},
+			RecvSpec{CommChnlPH: "y", BindChnlPH: "v", ContES: SendSpec{CommChnlPH: "y", ValChnlPH: "v"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := RenameSpec(test.es, phs)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
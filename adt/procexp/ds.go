@@ -1,6 +1,9 @@
 package procexp
 
 import (
+	"encoding/json"
+	"log/slog"
+
 	"orglang/go-runtime/lib/db"
 )
 
@@ -8,26 +11,61 @@ type Repo interface {
 	Insert(db.Source, ExpRec) error
 }
 
+// storage adapter is chosen by the operator
+func newDAO(l *slog.Logger, o db.Operator) Repo {
+	switch o.(type) {
+	case *db.OperatorMem:
+		return newMemDAO(l)
+	case *db.OperatorSqlite:
+		return newSqliteDAO(l)
+	default:
+		return newPgxDAO(l)
+	}
+}
+
 type ExpSpecDS struct {
-	K     expKindDS    `json:"k"`
-	Close *closeSpecDS `json:"close,omitempty"`
-	Wait  *waitSpecDS  `json:"wait,omitempty"`
-	Send  *sendSpecDS  `json:"send,omitempty"`
-	Recv  *recvSpecDS  `json:"recv,omitempty"`
-	Lab   *labSpecDS   `json:"lab,omitempty"`
-	Case  *caseSpecDS  `json:"case,omitempty"`
-	Fwd   *fwdSpecDS   `json:"fwd,omitempty"`
+	K       expKindDS      `json:"k"`
+	Close   *closeSpecDS   `json:"close,omitempty"`
+	Wait    *waitSpecDS    `json:"wait,omitempty"`
+	Send    *sendSpecDS    `json:"send,omitempty"`
+	Recv    *recvSpecDS    `json:"recv,omitempty"`
+	Lab     *labSpecDS     `json:"lab,omitempty"`
+	Case    *caseSpecDS    `json:"case,omitempty"`
+	Fwd     *fwdSpecDS     `json:"fwd,omitempty"`
+	Call    *callSpecDS    `json:"call,omitempty"`
+	Acquire *shiftSpecDS   `json:"acquire,omitempty"`
+	Accept  *shiftSpecDS   `json:"accept,omitempty"`
+	Detach  *shiftSpecDS   `json:"detach,omitempty"`
+	Release *shiftSpecDS   `json:"release,omitempty"`
+	SendIdx *sendIdxDS     `json:"send_idx,omitempty"`
+	RecvIdx *recvIdxDS     `json:"recv_idx,omitempty"`
+	Assert  *condSpecDS    `json:"assert,omitempty"`
+	Assume  *condSpecDS    `json:"assume,omitempty"`
+	Work    *workSpecDS    `json:"work,omitempty"`
+	Delay   *delaySpecDS   `json:"delay,omitempty"`
+	Now     *shiftSpecDS   `json:"now,omitempty"`
+	When    *shiftSpecDS   `json:"when,omitempty"`
+	SendVal *sendValSpecDS `json:"send_val,omitempty"`
+	RecvVal *recvValSpecDS `json:"recv_val,omitempty"`
 }
 
 type ExpRecDS struct {
-	K     expKindDS   `json:"k"`
-	Close *closeRecDS `json:"close,omitempty"`
-	Wait  *waitRecDS  `json:"wait,omitempty"`
-	Send  *sendRecDS  `json:"send,omitempty"`
-	Recv  *recvRecDS  `json:"recv,omitempty"`
-	Lab   *labRecDS   `json:"lab,omitempty"`
-	Case  *caseRecDS  `json:"case,omitempty"`
-	Fwd   *fwdRecDS   `json:"fwd,omitempty"`
+	K       expKindDS     `json:"k"`
+	Close   *closeRecDS   `json:"close,omitempty"`
+	Wait    *waitRecDS    `json:"wait,omitempty"`
+	Send    *sendRecDS    `json:"send,omitempty"`
+	Recv    *recvRecDS    `json:"recv,omitempty"`
+	Lab     *labRecDS     `json:"lab,omitempty"`
+	Case    *caseRecDS    `json:"case,omitempty"`
+	Fwd     *fwdRecDS     `json:"fwd,omitempty"`
+	Acquire *shiftRecDS   `json:"acquire,omitempty"`
+	Accept  *shiftRecDS   `json:"accept,omitempty"`
+	Detach  *shiftRecDS   `json:"detach,omitempty"`
+	Release *shiftRecDS   `json:"release,omitempty"`
+	Work    *workRecDS    `json:"work,omitempty"`
+	SendVal *sendValRecDS `json:"send_val,omitempty"`
+	RecvVal *recvValRecDS `json:"recv_val,omitempty"`
+	Val     *valRecDS     `json:"val,omitempty"`
 }
 
 type expKindDS int
@@ -43,6 +81,22 @@ const (
 	linkExp
 	spawnExp
 	fwdExp
+	callExp
+	acquireExp
+	acceptExp
+	detachExp
+	releaseExp
+	sendIdxExp
+	recvIdxExp
+	assertExp
+	assumeExp
+	workExp
+	delayExp
+	nowExp
+	whenExp
+	sendValExp
+	recvValExp
+	valExp
 )
 
 type closeSpecDS struct {
@@ -126,3 +180,85 @@ type fwdRecDS struct {
 	X string `json:"x"`
 	B string `json:"b"`
 }
+
+type shiftSpecDS struct {
+	X      string     `json:"x"`
+	ContES *ExpSpecDS `json:"cont,omitempty"`
+}
+
+type shiftRecDS struct {
+	X      string     `json:"x"`
+	ContES *ExpSpecDS `json:"cont,omitempty"`
+}
+
+type callSpecDS struct {
+	X      string     `json:"x"`
+	ProcQN string     `json:"proc"`
+	Ys     []string   `json:"ys"`
+	Idxs   []string   `json:"idxs,omitempty"`
+	ContES *ExpSpecDS `json:"cont,omitempty"`
+}
+
+type sendIdxDS struct {
+	X      string    `json:"x"`
+	IdxES  string    `json:"idx"`
+	ContES ExpSpecDS `json:"cont"`
+}
+
+type recvIdxDS struct {
+	X      string    `json:"x"`
+	IdxVar string    `json:"var"`
+	ContES ExpSpecDS `json:"cont"`
+}
+
+type condSpecDS struct {
+	X      string    `json:"x"`
+	Prop   string    `json:"prop"`
+	ContES ExpSpecDS `json:"cont"`
+}
+
+type workSpecDS struct {
+	W      int64     `json:"w"`
+	ContES ExpSpecDS `json:"cont"`
+}
+
+type workRecDS struct {
+	W int64 `json:"w"`
+}
+
+type delaySpecDS struct {
+	T      int64     `json:"t"`
+	ContES ExpSpecDS `json:"cont"`
+}
+
+type sendValSpecDS struct {
+	X      string          `json:"x"`
+	Lit    json.RawMessage `json:"lit,omitempty"`
+	V      string          `json:"v,omitempty"`
+	ContES ExpSpecDS       `json:"cont"`
+}
+
+type sendValRecDS struct {
+	X   string          `json:"x"`
+	A   string          `json:"a"`
+	Lit json.RawMessage `json:"lit"`
+}
+
+type recvValSpecDS struct {
+	X      string    `json:"x"`
+	Y      string    `json:"y"`
+	ContES ExpSpecDS `json:"cont"`
+}
+
+type recvValRecDS struct {
+	X      string    `json:"x"`
+	A      string    `json:"a"`
+	Y      string    `json:"y"`
+	ContES ExpSpecDS `json:"cont"`
+}
+
+type valRecDS struct {
+	Y   string          `json:"y"`
+	T   string          `json:"t"`
+	Lit json.RawMessage `json:"lit"`
+}
@@ -0,0 +1,22 @@
+package procexp
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(l *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{l.With(name)}
+}
+
+func (dao *memDAO) Insert(source db.Source, rec ExpRec) error {
+	return nil
+}
@@ -0,0 +1,22 @@
+package procexp
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+)
+
+// Adapter
+type sqliteDAO struct {
+	log *slog.Logger
+}
+
+func newSqliteDAO(l *slog.Logger) *sqliteDAO {
+	name := slog.String("name", reflect.TypeFor[sqliteDAO]().Name())
+	return &sqliteDAO{l.With(name)}
+}
+
+func (dao *sqliteDAO) Insert(source db.Source, rec ExpRec) error {
+	return nil
+}
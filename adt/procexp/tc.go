@@ -3,8 +3,10 @@ package procexp
 import (
 	"fmt"
 
+	"orglang/go-runtime/adt/arithexp"
 	"orglang/go-runtime/adt/identity"
 	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/typeexp"
 	"orglang/go-runtime/adt/uniqsym"
 
 	"github.com/orglang/go-sdk/adt/procexp"
@@ -80,6 +82,103 @@ func MsgFromExpSpec(s ExpSpec) procexp.ExpSpec {
 				ContPH: symbol.ConvertToString(spec.ContChnlPH),
 			},
 		}
+	case CallSpec:
+		return procexp.ExpSpec{
+			K: procexp.Call,
+			Call: &procexp.CallSpec{
+				BindPH: symbol.ConvertToString(spec.BindChnlPH),
+				ProcQN: uniqsym.ConvertToString(spec.ProcQN),
+				ValPHs: symbol.ConvertToStrings(spec.ValChnlPHs),
+				IdxESs: arithexp.ConvertExpsToStrings(spec.IdxESs),
+			},
+		}
+	case SendIdxSpec:
+		return procexp.ExpSpec{
+			K: procexp.SendIdx,
+			SendIdx: &procexp.SendIdxSpec{
+				CommPH: symbol.ConvertToString(spec.CommChnlPH),
+				IdxES:  arithexp.ConvertExpToString(spec.IdxES),
+				ContES: MsgFromExpSpec(spec.ContES),
+			},
+		}
+	case RecvIdxSpec:
+		return procexp.ExpSpec{
+			K: procexp.RecvIdx,
+			RecvIdx: &procexp.RecvIdxSpec{
+				CommPH: symbol.ConvertToString(spec.CommChnlPH),
+				IdxVar: symbol.ConvertToString(spec.IdxVar),
+				ContES: MsgFromExpSpec(spec.ContES),
+			},
+		}
+	case AssertSpec:
+		return procexp.ExpSpec{
+			K: procexp.Assert,
+			Assert: &procexp.CondSpec{
+				CommPH: symbol.ConvertToString(spec.CommChnlPH),
+				Prop:   arithexp.ConvertPropToString(spec.Prop),
+				ContES: MsgFromExpSpec(spec.ContES),
+			},
+		}
+	case AssumeSpec:
+		return procexp.ExpSpec{
+			K: procexp.Assume,
+			Assume: &procexp.CondSpec{
+				CommPH: symbol.ConvertToString(spec.CommChnlPH),
+				Prop:   arithexp.ConvertPropToString(spec.Prop),
+				ContES: MsgFromExpSpec(spec.ContES),
+			},
+		}
+	case WorkSpec:
+		return procexp.ExpSpec{
+			K: procexp.Work,
+			Work: &procexp.WorkSpec{
+				Work:   spec.Work,
+				ContES: MsgFromExpSpec(spec.ContES),
+			},
+		}
+	case DelaySpec:
+		return procexp.ExpSpec{
+			K: procexp.Delay,
+			Delay: &procexp.DelaySpec{
+				Ticks:  spec.Ticks,
+				ContES: MsgFromExpSpec(spec.ContES),
+			},
+		}
+	case NowSpec:
+		return procexp.ExpSpec{
+			K: procexp.Now,
+			Now: &procexp.TimeSpec{
+				CommPH: symbol.ConvertToString(spec.CommChnlPH),
+				ContES: MsgFromExpSpec(spec.ContES),
+			},
+		}
+	case WhenSpec:
+		return procexp.ExpSpec{
+			K: procexp.When,
+			When: &procexp.TimeSpec{
+				CommPH: symbol.ConvertToString(spec.CommChnlPH),
+				ContES: MsgFromExpSpec(spec.ContES),
+			},
+		}
+	case SendValSpec:
+		return procexp.ExpSpec{
+			K: procexp.SendVal,
+			SendVal: &procexp.SendValSpec{
+				CommPH: symbol.ConvertToString(spec.CommChnlPH),
+				ValLit: spec.ValLit,
+				ValVar: symbol.ConvertToString(spec.ValVar),
+				ContES: MsgFromExpSpec(spec.ContES),
+			},
+		}
+	case RecvValSpec:
+		return procexp.ExpSpec{
+			K: procexp.RecvVal,
+			RecvVal: &procexp.RecvValSpec{
+				CommPH:  symbol.ConvertToString(spec.CommChnlPH),
+				BindVar: symbol.ConvertToString(spec.BindVar),
+				ContES:  MsgFromExpSpec(spec.ContES),
+			},
+		}
 	default:
 		panic(ErrExpTypeUnexpected(s))
 	}
@@ -175,7 +274,11 @@ func MsgToExpSpec(dto procexp.ExpSpec) (ExpSpec, error) {
 		if err != nil {
 			return nil, err
 		}
-		return CallSpec{BindChnlPH: bindPH, ProcQN: procQN, ValChnlPHs: valPHs}, nil
+		idxESs, err := arithexp.ConvertExpsFromStrings(dto.Call.IdxESs)
+		if err != nil {
+			return nil, err
+		}
+		return CallSpec{BindChnlPH: bindPH, ProcQN: procQN, ValChnlPHs: valPHs, IdxESs: idxESs}, nil
 	case procexp.Fwd:
 		x, err := symbol.ConvertFromString(dto.Fwd.CommPH)
 		if err != nil {
@@ -186,11 +289,139 @@ func MsgToExpSpec(dto procexp.ExpSpec) (ExpSpec, error) {
 			return nil, err
 		}
 		return FwdSpec{CommChnlPH: x, ContChnlPH: y}, nil
+	case procexp.SendIdx:
+		x, err := symbol.ConvertFromString(dto.SendIdx.CommPH)
+		if err != nil {
+			return nil, err
+		}
+		idxES, err := arithexp.ConvertExpFromString(dto.SendIdx.IdxES)
+		if err != nil {
+			return nil, err
+		}
+		cont, err := MsgToExpSpec(dto.SendIdx.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return SendIdxSpec{CommChnlPH: x, IdxES: idxES, ContES: cont}, nil
+	case procexp.RecvIdx:
+		x, err := symbol.ConvertFromString(dto.RecvIdx.CommPH)
+		if err != nil {
+			return nil, err
+		}
+		idxVar, err := symbol.ConvertFromString(dto.RecvIdx.IdxVar)
+		if err != nil {
+			return nil, err
+		}
+		cont, err := MsgToExpSpec(dto.RecvIdx.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return RecvIdxSpec{CommChnlPH: x, IdxVar: idxVar, ContES: cont}, nil
+	case procexp.Assert:
+		x, prop, cont, err := msgToCondSpec(dto.Assert)
+		if err != nil {
+			return nil, err
+		}
+		return AssertSpec{CommChnlPH: x, Prop: prop, ContES: cont}, nil
+	case procexp.Assume:
+		x, prop, cont, err := msgToCondSpec(dto.Assume)
+		if err != nil {
+			return nil, err
+		}
+		return AssumeSpec{CommChnlPH: x, Prop: prop, ContES: cont}, nil
+	case procexp.Work:
+		cont, err := MsgToExpSpec(dto.Work.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return WorkSpec{Work: dto.Work.Work, ContES: cont}, nil
+	case procexp.Delay:
+		cont, err := MsgToExpSpec(dto.Delay.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return DelaySpec{Ticks: dto.Delay.Ticks, ContES: cont}, nil
+	case procexp.Now:
+		x, cont, err := msgToTimeSpec(dto.Now)
+		if err != nil {
+			return nil, err
+		}
+		return NowSpec{CommChnlPH: x, ContES: cont}, nil
+	case procexp.When:
+		x, cont, err := msgToTimeSpec(dto.When)
+		if err != nil {
+			return nil, err
+		}
+		return WhenSpec{CommChnlPH: x, ContES: cont}, nil
+	case procexp.SendVal:
+		x, err := symbol.ConvertFromString(dto.SendVal.CommPH)
+		if err != nil {
+			return nil, err
+		}
+		valVar, err := convertValVar(dto.SendVal.ValVar)
+		if err != nil {
+			return nil, err
+		}
+		cont, err := MsgToExpSpec(dto.SendVal.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return SendValSpec{CommChnlPH: x, ValLit: dto.SendVal.ValLit, ValVar: valVar, ContES: cont}, nil
+	case procexp.RecvVal:
+		x, err := symbol.ConvertFromString(dto.RecvVal.CommPH)
+		if err != nil {
+			return nil, err
+		}
+		bindVar, err := symbol.ConvertFromString(dto.RecvVal.BindVar)
+		if err != nil {
+			return nil, err
+		}
+		cont, err := MsgToExpSpec(dto.RecvVal.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return RecvValSpec{CommChnlPH: x, BindVar: bindVar, ContES: cont}, nil
 	default:
 		panic(procexp.ErrUnexpectedExpKind(dto.K))
 	}
 }
 
+// no variable when a literal is sent
+func convertValVar(str string) (symbol.ADT, error) {
+	if len(str) == 0 {
+		return "", nil
+	}
+	return symbol.ConvertFromString(str)
+}
+
+func msgToTimeSpec(dto *procexp.TimeSpec) (symbol.ADT, ExpSpec, error) {
+	x, err := symbol.ConvertFromString(dto.CommPH)
+	if err != nil {
+		return "", nil, err
+	}
+	cont, err := MsgToExpSpec(dto.ContES)
+	if err != nil {
+		return "", nil, err
+	}
+	return x, cont, nil
+}
+
+func msgToCondSpec(dto *procexp.CondSpec) (symbol.ADT, arithexp.PropSpec, ExpSpec, error) {
+	x, err := symbol.ConvertFromString(dto.CommPH)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	prop, err := arithexp.ConvertPropFromString(dto.Prop)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	cont, err := MsgToExpSpec(dto.ContES)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return x, prop, cont, nil
+}
+
 func DataFromExpRec(r ExpRec) (ExpRecDS, error) {
 	switch rec := r.(type) {
 	case CloseRec:
@@ -199,7 +430,7 @@ func DataFromExpRec(r ExpRec) (ExpRecDS, error) {
 			Close: &closeRecDS{symbol.ConvertToString(rec.CommChnlPH)},
 		}, nil
 	case WaitRec:
-		dto, err := dataFromExpSpec(rec.ContES)
+		dto, err := DataFromExpSpec(rec.ContES)
 		if err != nil {
 			return ExpRecDS{}, err
 		}
@@ -220,7 +451,7 @@ func DataFromExpRec(r ExpRec) (ExpRecDS, error) {
 			},
 		}, nil
 	case RecvRec:
-		dto, err := dataFromExpSpec(rec.ContES)
+		dto, err := DataFromExpSpec(rec.ContES)
 		if err != nil {
 			return ExpRecDS{}, err
 		}
@@ -240,7 +471,7 @@ func DataFromExpRec(r ExpRec) (ExpRecDS, error) {
 	case CaseRec:
 		brs := []branchRecDS{}
 		for l, cont := range rec.ContESs {
-			dto, err := dataFromExpSpec(cont)
+			dto, err := DataFromExpSpec(cont)
 			if err != nil {
 				return ExpRecDS{}, err
 			}
@@ -261,11 +492,77 @@ func DataFromExpRec(r ExpRec) (ExpRecDS, error) {
 				B: identity.ConvertToString(rec.ContChnlID),
 			},
 		}, nil
+	case AcquireRec:
+		dto, err := dataFromShiftRec(rec.CommChnlPH, rec.ContES)
+		if err != nil {
+			return ExpRecDS{}, err
+		}
+		return ExpRecDS{K: acquireExp, Acquire: dto}, nil
+	case AcceptRec:
+		dto, err := dataFromShiftRec(rec.CommChnlPH, rec.ContES)
+		if err != nil {
+			return ExpRecDS{}, err
+		}
+		return ExpRecDS{K: acceptExp, Accept: dto}, nil
+	case DetachRec:
+		dto, err := dataFromShiftRec(rec.CommChnlPH, nil)
+		if err != nil {
+			return ExpRecDS{}, err
+		}
+		return ExpRecDS{K: detachExp, Detach: dto}, nil
+	case ReleaseRec:
+		dto, err := dataFromShiftRec(rec.CommChnlPH, nil)
+		if err != nil {
+			return ExpRecDS{}, err
+		}
+		return ExpRecDS{K: releaseExp, Release: dto}, nil
+	case WorkRec:
+		return ExpRecDS{K: workExp, Work: &workRecDS{W: rec.Work}}, nil
+	case SendValRec:
+		return ExpRecDS{
+			K: sendValExp,
+			SendVal: &sendValRecDS{
+				X:   symbol.ConvertToString(rec.CommChnlPH),
+				A:   identity.ConvertToString(rec.ContChnlID),
+				Lit: rec.Val,
+			},
+		}, nil
+	case RecvValRec:
+		dto, err := DataFromExpSpec(rec.ContES)
+		if err != nil {
+			return ExpRecDS{}, err
+		}
+		return ExpRecDS{
+			K: recvValExp,
+			RecvVal: &recvValRecDS{
+				X:      symbol.ConvertToString(rec.CommChnlPH),
+				A:      identity.ConvertToString(rec.ContChnlID),
+				Y:      symbol.ConvertToString(rec.BindVar),
+				ContES: dto,
+			},
+		}, nil
+	case ValRec:
+		return ExpRecDS{
+			K: valExp,
+			Val: &valRecDS{
+				Y:   symbol.ConvertToString(rec.BindVar),
+				T:   typeexp.ConvertDataToString(rec.T),
+				Lit: rec.Val,
+			},
+		}, nil
 	default:
 		panic(ErrExpTypeUnexpected(rec))
 	}
 }
 
+func dataFromShiftRec(x symbol.ADT, contES ExpSpec) (*shiftRecDS, error) {
+	dto, err := dataFromShiftSpec(x, contES)
+	if err != nil {
+		return nil, err
+	}
+	return &shiftRecDS{X: dto.X, ContES: dto.ContES}, nil
+}
+
 func DataToExpRec(dto ExpRecDS) (ExpRec, error) {
 	switch dto.K {
 	case closeExp:
@@ -279,7 +576,7 @@ func DataToExpRec(dto ExpRecDS) (ExpRec, error) {
 		if err != nil {
 			return nil, err
 		}
-		cont, err := dataToExpSpec(dto.Wait.ContES)
+		cont, err := DataToExpSpec(dto.Wait.ContES)
 		if err != nil {
 			return nil, err
 		}
@@ -303,7 +600,7 @@ func DataToExpRec(dto ExpRecDS) (ExpRec, error) {
 		if err != nil {
 			return nil, err
 		}
-		cont, err := dataToExpSpec(dto.Recv.ContES)
+		cont, err := DataToExpSpec(dto.Recv.ContES)
 		if err != nil {
 			return nil, err
 		}
@@ -325,7 +622,7 @@ func DataToExpRec(dto ExpRecDS) (ExpRec, error) {
 		}
 		conts := make(map[uniqsym.ADT]ExpSpec, len(dto.Case.Branches))
 		for _, branch := range dto.Case.Branches {
-			cont, err := dataToExpSpec(branch.ContES)
+			cont, err := DataToExpSpec(branch.ContES)
 			if err != nil {
 				return nil, err
 			}
@@ -346,12 +643,76 @@ func DataToExpRec(dto ExpRecDS) (ExpRec, error) {
 			return nil, err
 		}
 		return FwdRec{CommChnlPH: x, ContChnlID: b}, nil
+	case acquireExp:
+		x, cont, err := dataToShiftSpec((*shiftSpecDS)(dto.Acquire))
+		if err != nil {
+			return nil, err
+		}
+		return AcquireRec{CommChnlPH: x, ContES: cont}, nil
+	case acceptExp:
+		x, cont, err := dataToShiftSpec((*shiftSpecDS)(dto.Accept))
+		if err != nil {
+			return nil, err
+		}
+		return AcceptRec{CommChnlPH: x, ContES: cont}, nil
+	case detachExp:
+		x, _, err := dataToShiftSpec((*shiftSpecDS)(dto.Detach))
+		if err != nil {
+			return nil, err
+		}
+		return DetachRec{CommChnlPH: x}, nil
+	case releaseExp:
+		x, _, err := dataToShiftSpec((*shiftSpecDS)(dto.Release))
+		if err != nil {
+			return nil, err
+		}
+		return ReleaseRec{CommChnlPH: x}, nil
+	case workExp:
+		return WorkRec{Work: dto.Work.W}, nil
+	case sendValExp:
+		x, err := symbol.ConvertFromString(dto.SendVal.X)
+		if err != nil {
+			return nil, err
+		}
+		a, err := identity.ConvertFromString(dto.SendVal.A)
+		if err != nil {
+			return nil, err
+		}
+		return SendValRec{CommChnlPH: x, ContChnlID: a, Val: dto.SendVal.Lit}, nil
+	case recvValExp:
+		x, err := symbol.ConvertFromString(dto.RecvVal.X)
+		if err != nil {
+			return nil, err
+		}
+		a, err := identity.ConvertFromString(dto.RecvVal.A)
+		if err != nil {
+			return nil, err
+		}
+		y, err := symbol.ConvertFromString(dto.RecvVal.Y)
+		if err != nil {
+			return nil, err
+		}
+		cont, err := DataToExpSpec(dto.RecvVal.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return RecvValRec{CommChnlPH: x, ContChnlID: a, BindVar: y, ContES: cont}, nil
+	case valExp:
+		y, err := symbol.ConvertFromString(dto.Val.Y)
+		if err != nil {
+			return nil, err
+		}
+		t, err := typeexp.ConvertDataFromString(dto.Val.T)
+		if err != nil {
+			return nil, err
+		}
+		return ValRec{BindVar: y, T: t, Val: dto.Val.Lit}, nil
 	default:
 		panic(errUnexpectedExpKind(dto.K))
 	}
 }
 
-func dataFromExpSpec(s ExpSpec) (ExpSpecDS, error) {
+func DataFromExpSpec(s ExpSpec) (ExpSpecDS, error) {
 	switch spec := s.(type) {
 	case CloseSpec:
 		return ExpSpecDS{
@@ -359,7 +720,7 @@ func dataFromExpSpec(s ExpSpec) (ExpSpecDS, error) {
 			Close: &closeSpecDS{symbol.ConvertToString(spec.CommChnlPH)},
 		}, nil
 	case WaitSpec:
-		dto, err := dataFromExpSpec(spec.ContES)
+		dto, err := DataFromExpSpec(spec.ContES)
 		if err != nil {
 			return ExpSpecDS{}, err
 		}
@@ -379,7 +740,7 @@ func dataFromExpSpec(s ExpSpec) (ExpSpecDS, error) {
 			},
 		}, nil
 	case RecvSpec:
-		dto, err := dataFromExpSpec(spec.ContES)
+		dto, err := DataFromExpSpec(spec.ContES)
 		if err != nil {
 			return ExpSpecDS{}, err
 		}
@@ -399,7 +760,7 @@ func dataFromExpSpec(s ExpSpec) (ExpSpecDS, error) {
 	case CaseSpec:
 		brs := []branchSpecDS{}
 		for l, cont := range spec.ContESs {
-			dto, err := dataFromExpSpec(cont)
+			dto, err := DataFromExpSpec(cont)
 			if err != nil {
 				return ExpSpecDS{}, err
 			}
@@ -420,12 +781,199 @@ func dataFromExpSpec(s ExpSpec) (ExpSpecDS, error) {
 				Y: symbol.ConvertToString(spec.ContChnlPH),
 			},
 		}, nil
+	case CallSpec:
+		var cont *ExpSpecDS
+		if spec.ContES != nil {
+			dto, err := DataFromExpSpec(spec.ContES)
+			if err != nil {
+				return ExpSpecDS{}, err
+			}
+			cont = &dto
+		}
+		return ExpSpecDS{
+			K: callExp,
+			Call: &callSpecDS{
+				X:      symbol.ConvertToString(spec.BindChnlPH),
+				ProcQN: uniqsym.ConvertToString(spec.ProcQN),
+				Ys:     symbol.ConvertToStrings(spec.ValChnlPHs),
+				Idxs:   arithexp.ConvertExpsToStrings(spec.IdxESs),
+				ContES: cont,
+			},
+		}, nil
+	case AcquireSpec:
+		dto, err := dataFromShiftSpec(spec.CommChnlPH, spec.ContES)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: acquireExp, Acquire: dto}, nil
+	case AcceptSpec:
+		dto, err := dataFromShiftSpec(spec.CommChnlPH, spec.ContES)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: acceptExp, Accept: dto}, nil
+	case DetachSpec:
+		dto, err := dataFromShiftSpec(spec.CommChnlPH, nil)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: detachExp, Detach: dto}, nil
+	case ReleaseSpec:
+		dto, err := dataFromShiftSpec(spec.CommChnlPH, nil)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: releaseExp, Release: dto}, nil
+	case SendIdxSpec:
+		dto, err := DataFromExpSpec(spec.ContES)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{
+			K: sendIdxExp,
+			SendIdx: &sendIdxDS{
+				X:      symbol.ConvertToString(spec.CommChnlPH),
+				IdxES:  arithexp.ConvertExpToString(spec.IdxES),
+				ContES: dto,
+			},
+		}, nil
+	case RecvIdxSpec:
+		dto, err := DataFromExpSpec(spec.ContES)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{
+			K: recvIdxExp,
+			RecvIdx: &recvIdxDS{
+				X:      symbol.ConvertToString(spec.CommChnlPH),
+				IdxVar: symbol.ConvertToString(spec.IdxVar),
+				ContES: dto,
+			},
+		}, nil
+	case AssertSpec:
+		dto, err := dataFromCondSpec(spec.CommChnlPH, spec.Prop, spec.ContES)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: assertExp, Assert: dto}, nil
+	case AssumeSpec:
+		dto, err := dataFromCondSpec(spec.CommChnlPH, spec.Prop, spec.ContES)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: assumeExp, Assume: dto}, nil
+	case WorkSpec:
+		dto, err := DataFromExpSpec(spec.ContES)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: workExp, Work: &workSpecDS{W: spec.Work, ContES: dto}}, nil
+	case DelaySpec:
+		dto, err := DataFromExpSpec(spec.ContES)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: delayExp, Delay: &delaySpecDS{T: spec.Ticks, ContES: dto}}, nil
+	case NowSpec:
+		dto, err := dataFromShiftSpec(spec.CommChnlPH, spec.ContES)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: nowExp, Now: dto}, nil
+	case WhenSpec:
+		dto, err := dataFromShiftSpec(spec.CommChnlPH, spec.ContES)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{K: whenExp, When: dto}, nil
+	case SendValSpec:
+		dto, err := DataFromExpSpec(spec.ContES)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{
+			K: sendValExp,
+			SendVal: &sendValSpecDS{
+				X:      symbol.ConvertToString(spec.CommChnlPH),
+				Lit:    spec.ValLit,
+				V:      symbol.ConvertToString(spec.ValVar),
+				ContES: dto,
+			},
+		}, nil
+	case RecvValSpec:
+		dto, err := DataFromExpSpec(spec.ContES)
+		if err != nil {
+			return ExpSpecDS{}, err
+		}
+		return ExpSpecDS{
+			K: recvValExp,
+			RecvVal: &recvValSpecDS{
+				X:      symbol.ConvertToString(spec.CommChnlPH),
+				Y:      symbol.ConvertToString(spec.BindVar),
+				ContES: dto,
+			},
+		}, nil
 	default:
 		panic(ErrExpTypeUnexpected(spec))
 	}
 }
 
-func dataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
+func dataFromShiftSpec(x symbol.ADT, contES ExpSpec) (*shiftSpecDS, error) {
+	dto := shiftSpecDS{X: symbol.ConvertToString(x)}
+	if contES != nil {
+		cont, err := DataFromExpSpec(contES)
+		if err != nil {
+			return nil, err
+		}
+		dto.ContES = &cont
+	}
+	return &dto, nil
+}
+
+func dataFromCondSpec(x symbol.ADT, prop arithexp.PropSpec, contES ExpSpec) (*condSpecDS, error) {
+	cont, err := DataFromExpSpec(contES)
+	if err != nil {
+		return nil, err
+	}
+	return &condSpecDS{
+		X:      symbol.ConvertToString(x),
+		Prop:   arithexp.ConvertPropToString(prop),
+		ContES: cont,
+	}, nil
+}
+
+func dataToCondSpec(dto *condSpecDS) (symbol.ADT, arithexp.PropSpec, ExpSpec, error) {
+	x, err := symbol.ConvertFromString(dto.X)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	prop, err := arithexp.ConvertPropFromString(dto.Prop)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	cont, err := DataToExpSpec(dto.ContES)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return x, prop, cont, nil
+}
+
+func dataToShiftSpec(dto *shiftSpecDS) (symbol.ADT, ExpSpec, error) {
+	x, err := symbol.ConvertFromString(dto.X)
+	if err != nil {
+		return "", nil, err
+	}
+	if dto.ContES == nil {
+		return x, nil, nil
+	}
+	cont, err := DataToExpSpec(*dto.ContES)
+	if err != nil {
+		return "", nil, err
+	}
+	return x, cont, nil
+}
+
+func DataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
 	switch dto.K {
 	case closeExp:
 		a, err := symbol.ConvertFromString(dto.Close.X)
@@ -438,7 +986,7 @@ func dataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
 		if err != nil {
 			return nil, err
 		}
-		cont, err := dataToExpSpec(dto.Wait.ContES)
+		cont, err := DataToExpSpec(dto.Wait.ContES)
 		if err != nil {
 			return nil, err
 		}
@@ -462,7 +1010,7 @@ func dataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
 		if err != nil {
 			return nil, err
 		}
-		cont, err := dataToExpSpec(dto.Recv.ContES)
+		cont, err := DataToExpSpec(dto.Recv.ContES)
 		if err != nil {
 			return nil, err
 		}
@@ -484,7 +1032,7 @@ func dataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
 		}
 		conts := make(map[uniqsym.ADT]ExpSpec, len(dto.Case.Branches))
 		for _, b := range dto.Case.Branches {
-			cont, err := dataToExpSpec(b.ContES)
+			cont, err := DataToExpSpec(b.ContES)
 			if err != nil {
 				return nil, err
 			}
@@ -505,6 +1053,147 @@ func dataToExpSpec(dto ExpSpecDS) (ExpSpec, error) {
 			return nil, err
 		}
 		return FwdSpec{CommChnlPH: x, ContChnlPH: y}, nil
+	case callExp:
+		x, err := symbol.ConvertFromString(dto.Call.X)
+		if err != nil {
+			return nil, err
+		}
+		procQN, err := uniqsym.ConvertFromString(dto.Call.ProcQN)
+		if err != nil {
+			return nil, err
+		}
+		ys, err := symbol.ConvertFromStrings(dto.Call.Ys)
+		if err != nil {
+			return nil, err
+		}
+		idxESs, err := arithexp.ConvertExpsFromStrings(dto.Call.Idxs)
+		if err != nil {
+			return nil, err
+		}
+		var cont ExpSpec
+		if dto.Call.ContES != nil {
+			cont, err = DataToExpSpec(*dto.Call.ContES)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return CallSpec{BindChnlPH: x, ProcQN: procQN, ValChnlPHs: ys, IdxESs: idxESs, ContES: cont}, nil
+	case acquireExp:
+		x, cont, err := dataToShiftSpec(dto.Acquire)
+		if err != nil {
+			return nil, err
+		}
+		return AcquireSpec{CommChnlPH: x, ContES: cont}, nil
+	case acceptExp:
+		x, cont, err := dataToShiftSpec(dto.Accept)
+		if err != nil {
+			return nil, err
+		}
+		return AcceptSpec{CommChnlPH: x, ContES: cont}, nil
+	case detachExp:
+		x, _, err := dataToShiftSpec(dto.Detach)
+		if err != nil {
+			return nil, err
+		}
+		return DetachSpec{CommChnlPH: x}, nil
+	case releaseExp:
+		x, _, err := dataToShiftSpec(dto.Release)
+		if err != nil {
+			return nil, err
+		}
+		return ReleaseSpec{CommChnlPH: x}, nil
+	case sendIdxExp:
+		x, err := symbol.ConvertFromString(dto.SendIdx.X)
+		if err != nil {
+			return nil, err
+		}
+		idxES, err := arithexp.ConvertExpFromString(dto.SendIdx.IdxES)
+		if err != nil {
+			return nil, err
+		}
+		cont, err := DataToExpSpec(dto.SendIdx.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return SendIdxSpec{CommChnlPH: x, IdxES: idxES, ContES: cont}, nil
+	case recvIdxExp:
+		x, err := symbol.ConvertFromString(dto.RecvIdx.X)
+		if err != nil {
+			return nil, err
+		}
+		idxVar, err := symbol.ConvertFromString(dto.RecvIdx.IdxVar)
+		if err != nil {
+			return nil, err
+		}
+		cont, err := DataToExpSpec(dto.RecvIdx.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return RecvIdxSpec{CommChnlPH: x, IdxVar: idxVar, ContES: cont}, nil
+	case assertExp:
+		x, prop, cont, err := dataToCondSpec(dto.Assert)
+		if err != nil {
+			return nil, err
+		}
+		return AssertSpec{CommChnlPH: x, Prop: prop, ContES: cont}, nil
+	case assumeExp:
+		x, prop, cont, err := dataToCondSpec(dto.Assume)
+		if err != nil {
+			return nil, err
+		}
+		return AssumeSpec{CommChnlPH: x, Prop: prop, ContES: cont}, nil
+	case workExp:
+		cont, err := DataToExpSpec(dto.Work.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return WorkSpec{Work: dto.Work.W, ContES: cont}, nil
+	case delayExp:
+		cont, err := DataToExpSpec(dto.Delay.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return DelaySpec{Ticks: dto.Delay.T, ContES: cont}, nil
+	case nowExp:
+		x, cont, err := dataToShiftSpec(dto.Now)
+		if err != nil {
+			return nil, err
+		}
+		return NowSpec{CommChnlPH: x, ContES: cont}, nil
+	case whenExp:
+		x, cont, err := dataToShiftSpec(dto.When)
+		if err != nil {
+			return nil, err
+		}
+		return WhenSpec{CommChnlPH: x, ContES: cont}, nil
+	case sendValExp:
+		x, err := symbol.ConvertFromString(dto.SendVal.X)
+		if err != nil {
+			return nil, err
+		}
+		valVar, err := convertValVar(dto.SendVal.V)
+		if err != nil {
+			return nil, err
+		}
+		cont, err := DataToExpSpec(dto.SendVal.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return SendValSpec{CommChnlPH: x, ValLit: dto.SendVal.Lit, ValVar: valVar, ContES: cont}, nil
+	case recvValExp:
+		x, err := symbol.ConvertFromString(dto.RecvVal.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := symbol.ConvertFromString(dto.RecvVal.Y)
+		if err != nil {
+			return nil, err
+		}
+		cont, err := DataToExpSpec(dto.RecvVal.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return RecvValSpec{CommChnlPH: x, BindVar: y, ContES: cont}, nil
 	default:
 		panic(errUnexpectedExpKind(dto.K))
 	}
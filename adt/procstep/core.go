@@ -1,11 +1,14 @@
 package procstep
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"orglang/go-runtime/adt/identity"
 	"orglang/go-runtime/adt/procexp"
 	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/typeexp"
 	"orglang/go-runtime/adt/uniqref"
 )
 
@@ -39,6 +42,24 @@ type SvcRec struct {
 
 func (r SvcRec) step() identity.ADT { return r.ChnlID }
 
+// work done by an execution; waits for no partner
+type WorkRec struct {
+	ExecRef uniqref.ADT
+	Work    int64
+}
+
+func (r WorkRec) step() identity.ADT { return identity.Empty() }
+
+// value received by an execution; waits for no partner
+type ValRec struct {
+	ExecRef uniqref.ADT
+	BindVar symbol.ADT
+	T       typeexp.DataType
+	Val     json.RawMessage
+}
+
+func (r ValRec) step() identity.ADT { return identity.Empty() }
+
 func ErrRecTypeUnexpected(got StepRec) error {
 	return fmt.Errorf("step rec unexpected: %T", got)
 }
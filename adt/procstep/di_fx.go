@@ -6,6 +6,6 @@ import (
 
 var Module = fx.Module("adt/procstep",
 	fx.Provide(
-		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+		newDAO,
 	),
 )
@@ -2,6 +2,7 @@ package procstep
 
 import (
 	"database/sql"
+	"log/slog"
 
 	"orglang/go-runtime/lib/db"
 
@@ -12,9 +13,22 @@ type Repo interface {
 	InsertRecs(db.Source, ...StepRec) error
 }
 
+// storage adapter is chosen by the operator
+func newDAO(l *slog.Logger, o db.Operator) Repo {
+	switch o.(type) {
+	case *db.OperatorMem:
+		return newMemDAO(l)
+	case *db.OperatorSqlite:
+		return newSqliteDAO(l)
+	default:
+		return newPgxDAO(l)
+	}
+}
+
 type StepRecDS struct {
 	K      stepKindDS       `db:"kind"`
 	ExecID sql.NullString   `db:"exec_id"`
+	ExecRN int64            `db:"exec_rn"`
 	ChnlID sql.NullString   `db:"chnl_id"`
 	ProcER procexp.ExpRecDS `db:"proc_er"`
 }
@@ -25,4 +39,11 @@ const (
 	nonStep = stepKindDS(iota)
 	msgStep
 	svcStep
+	workStep
+	valStep
+)
+
+// in-memory table of StepRecDS rows
+const (
+	StepsMem = "proc_steps"
 )
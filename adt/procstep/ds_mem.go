@@ -0,0 +1,28 @@
+package procstep
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+)
+
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(l *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{l.With(name)}
+}
+
+func (dao *memDAO) InsertRecs(source db.Source, recs ...StepRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	dtos, err := DataFromStepRecs(recs)
+	if err != nil {
+		dao.log.Error("conversion failed")
+		return err
+	}
+	db.InsertMem(ds, StepsMem, dtos...)
+	return nil
+}
@@ -8,9 +8,6 @@ import (
 	"github.com/jackc/pgx/v5"
 
 	"orglang/go-runtime/lib/db"
-	"orglang/go-runtime/lib/lf"
-
-	"orglang/go-runtime/adt/identity"
 )
 
 type pgxDAO struct {
@@ -39,6 +36,7 @@ func (dao *pgxDAO) InsertRecs(source db.Source, recs ...StepRec) error {
 		args := pgx.NamedArgs{
 			"kind":    dto.K,
 			"exec_id": dto.ExecID,
+			"exec_rn": dto.ExecRN,
 			"chnl_id": dto.ChnlID,
 			"proc_er": dto.ProcER,
 		}
@@ -60,45 +58,11 @@ func (dao *pgxDAO) InsertRecs(source db.Source, recs ...StepRec) error {
 	return nil
 }
 
-func (dao *pgxDAO) SelectRecs(source db.Source, rid identity.ADT) (StepRec, error) {
-	query := `
-		SELECT
-			id, kind, pid, vid, spec
-		FROM steps
-		WHERE id = $1`
-	return dao.execute(source, query, rid.String())
-}
-
-func (dao *pgxDAO) execute(source db.Source, query string, arg string) (StepRec, error) {
-	ds := db.MustConform[db.SourcePgx](source)
-	rows, err := ds.Conn.Query(ds.Ctx, query, arg)
-	if err != nil {
-		dao.log.Error("query execution failed", slog.String("q", query))
-		return nil, err
-	}
-	defer rows.Close()
-	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[StepRecDS])
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	}
-	if err != nil {
-		dao.log.Error("row collection failed")
-		return nil, err
-	}
-	root, err := dataToStepRec(dto)
-	if err != nil {
-		dao.log.Error("model conversion failed")
-		return nil, err
-	}
-	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", slog.Any("root", root))
-	return root, nil
-}
-
 const (
 	insertStep = `
-		insert into pool_steps (
-			id, kind, pid, vid, spec
+		insert into proc_steps (
+			exec_id, exec_rn, chnl_id, kind, proc_er
 		) values (
-			@id, @kind, @pid, @vid, @spec
+			@exec_id, @exec_rn, @chnl_id, @kind, @proc_er
 		)`
 )
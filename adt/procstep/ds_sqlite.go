@@ -0,0 +1,50 @@
+package procstep
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+)
+
+type sqliteDAO struct {
+	log *slog.Logger
+}
+
+func newSqliteDAO(l *slog.Logger) *sqliteDAO {
+	name := slog.String("name", reflect.TypeFor[sqliteDAO]().Name())
+	return &sqliteDAO{l.With(name)}
+}
+
+func (dao *sqliteDAO) InsertRecs(source db.Source, recs ...StepRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	dtos, err := DataFromStepRecs(recs)
+	if err != nil {
+		dao.log.Error("conversion failed")
+		return err
+	}
+	for _, dto := range dtos {
+		args := db.NamedArgsSqlite{
+			"kind":    dto.K,
+			"exec_id": dto.ExecID,
+			"exec_rn": dto.ExecRN,
+			"chnl_id": dto.ChnlID,
+			"proc_er": dto.ProcER,
+		}
+		_, err = ds.Conn.Exec(ds.Ctx, insertStepSqlite, args)
+		if err != nil {
+			dao.log.Error("execution failed", slog.String("q", insertStepSqlite), slog.Any("dto", dto))
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	insertStepSqlite = `
+		insert into proc_steps (
+			exec_id, exec_rn, chnl_id, kind, proc_er
+		) values (
+			:exec_id, :exec_rn, :chnl_id, :kind, :proc_er
+		)`
+)
@@ -3,7 +3,10 @@ package procstep
 import (
 	"fmt"
 
+	"orglang/go-runtime/adt/identity"
 	"orglang/go-runtime/adt/procexp"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/uniqref"
 )
 
 func dataFromStepRec(r StepRec) (StepRecDS, error) {
@@ -18,6 +21,9 @@ func dataFromStepRec(r StepRec) (StepRecDS, error) {
 		}
 		return StepRecDS{
 			K:      msgStep,
+			ExecID: identity.ConvertToNullString(rec.ExecRef.ID),
+			ExecRN: revnum.ConvertToInt(rec.ExecRef.RN),
+			ChnlID: identity.ConvertToNullString(rec.ChnlID),
 			ProcER: msgVal,
 		}, nil
 	case SvcRec:
@@ -27,8 +33,33 @@ func dataFromStepRec(r StepRec) (StepRecDS, error) {
 		}
 		return StepRecDS{
 			K:      svcStep,
+			ExecID: identity.ConvertToNullString(rec.ExecRef.ID),
+			ExecRN: revnum.ConvertToInt(rec.ExecRef.RN),
+			ChnlID: identity.ConvertToNullString(rec.ChnlID),
 			ProcER: svcCont,
 		}, nil
+	case WorkRec:
+		workVal, err := procexp.DataFromExpRec(procexp.WorkRec{Work: rec.Work})
+		if err != nil {
+			return StepRecDS{}, err
+		}
+		return StepRecDS{
+			K:      workStep,
+			ExecID: identity.ConvertToNullString(rec.ExecRef.ID),
+			ExecRN: revnum.ConvertToInt(rec.ExecRef.RN),
+			ProcER: workVal,
+		}, nil
+	case ValRec:
+		val, err := procexp.DataFromExpRec(procexp.ValRec{BindVar: rec.BindVar, T: rec.T, Val: rec.Val})
+		if err != nil {
+			return StepRecDS{}, err
+		}
+		return StepRecDS{
+			K:      valStep,
+			ExecID: identity.ConvertToNullString(rec.ExecRef.ID),
+			ExecRN: revnum.ConvertToInt(rec.ExecRef.RN),
+			ProcER: val,
+		}, nil
 	default:
 		panic(ErrRecTypeUnexpected(rec))
 	}
@@ -39,19 +70,48 @@ func dataToStepRec(dto StepRecDS) (StepRec, error) {
 	if dto == nilData {
 		return nil, nil
 	}
+	execID, err := identity.ConvertFromNullString(dto.ExecID)
+	if err != nil {
+		return nil, err
+	}
+	execRef := uniqref.ADT{ID: execID, RN: revnum.ConvertFromInt(dto.ExecRN)}
+	chnlID, err := identity.ConvertFromNullString(dto.ChnlID)
+	if err != nil {
+		return nil, err
+	}
 	switch dto.K {
 	case msgStep:
 		val, err := procexp.DataToExpRec(dto.ProcER)
 		if err != nil {
 			return nil, err
 		}
-		return MsgRec{ValER: val}, nil
+		return MsgRec{ExecRef: execRef, ChnlID: chnlID, ValER: val}, nil
 	case svcStep:
 		cont, err := procexp.DataToExpRec(dto.ProcER)
 		if err != nil {
 			return nil, err
 		}
-		return SvcRec{ContER: cont}, nil
+		return SvcRec{ExecRef: execRef, ChnlID: chnlID, ContER: cont}, nil
+	case workStep:
+		val, err := procexp.DataToExpRec(dto.ProcER)
+		if err != nil {
+			return nil, err
+		}
+		workVal, ok := val.(procexp.WorkRec)
+		if !ok {
+			return nil, procexp.ErrRecTypeUnexpected(val)
+		}
+		return WorkRec{ExecRef: execRef, Work: workVal.Work}, nil
+	case valStep:
+		val, err := procexp.DataToExpRec(dto.ProcER)
+		if err != nil {
+			return nil, err
+		}
+		valRec, ok := val.(procexp.ValRec)
+		if !ok {
+			return nil, procexp.ErrRecTypeUnexpected(val)
+		}
+		return ValRec{ExecRef: execRef, BindVar: valRec.BindVar, T: valRec.T, Val: valRec.Val}, nil
 	default:
 		panic(errUnexpectedStepKind(dto.K))
 	}
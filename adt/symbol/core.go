@@ -1,5 +1,7 @@
 package symbol
 
+import "slices"
+
 type ADT string
 
 func New(str string) ADT {
@@ -8,3 +10,18 @@ func New(str string) ADT {
 	}
 	return ADT(str)
 }
+
+// symbols that appeared in to and symbols that disappeared from from
+func Diff(from, to []ADT) (added, removed []ADT) {
+	for _, sym := range to {
+		if !slices.Contains(from, sym) {
+			added = append(added, sym)
+		}
+	}
+	for _, sym := range from {
+		if !slices.Contains(to, sym) {
+			removed = append(removed, sym)
+		}
+	}
+	return added, removed
+}
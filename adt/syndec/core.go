@@ -1,6 +1,10 @@
 package syndec
 
 import (
+	"errors"
+	"fmt"
+	"iter"
+
 	"orglang/go-runtime/adt/identity"
 	"orglang/go-runtime/adt/revnum"
 	"orglang/go-runtime/adt/uniqsym"
@@ -11,3 +15,17 @@ type DecRec struct {
 	DecRN revnum.ADT
 	DecQN uniqsym.ADT
 }
+
+func CollectEnv(recs iter.Seq[DecRec]) []identity.ADT {
+	decIDs := []identity.ADT{}
+	for rec := range recs {
+		decIDs = append(decIDs, rec.DecID)
+	}
+	return decIDs
+}
+
+var ErrDecMissing = errors.New("dec missing in env")
+
+func ErrMissingInEnv(want uniqsym.ADT) error {
+	return fmt.Errorf("%w: %v", ErrDecMissing, want)
+}
@@ -1,15 +1,33 @@
 package syndec
 
 import (
+	"log/slog"
+
 	"orglang/go-runtime/lib/db"
+
+	"orglang/go-runtime/adt/uniqsym"
 )
 
 type Repo interface {
 	Insert(db.Source, DecRec) error
+	SelectRecByQN(db.Source, uniqsym.ADT) (DecRec, error)
+	SelectEnv(db.Source, []uniqsym.ADT) (map[uniqsym.ADT]DecRec, error)
+}
+
+// storage adapter is chosen by the operator
+func newDAO(l *slog.Logger, o db.Operator) Repo {
+	switch o.(type) {
+	case *db.OperatorMem:
+		return newMemDAO(l)
+	case *db.OperatorSqlite:
+		return newSqliteDAO(l)
+	default:
+		return newPgxDAO(l)
+	}
 }
 
 type decRecDS struct {
-	DecID string
-	DecRN int64
-	DecQN string
+	DecID string `db:"dec_id"`
+	DecRN int64  `db:"dec_rn"`
+	DecQN string `db:"dec_qn"`
 }
@@ -0,0 +1,83 @@
+package syndec
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(l *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{l.With(name)}
+}
+
+func (dao *memDAO) Insert(source db.Source, root DecRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	dto, err := DataFromDecRec(root)
+	if err != nil {
+		dao.log.Error("model conversion failed", slog.Any("id", root.DecID))
+		return err
+	}
+	db.InsertMem(ds, Aliases, dto)
+	return nil
+}
+
+func (dao *memDAO) SelectRecByQN(source db.Source, decQN uniqsym.ADT) (DecRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	qnAttr := slog.Any("decQN", decQN)
+	dto, err := selectLatest(ds, uniqsym.ConvertToString(decQN))
+	if err != nil {
+		dao.log.Error("entity selection failed", qnAttr)
+		return DecRec{}, ErrMissingInEnv(decQN)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", qnAttr)
+	return DataToDecRec(dto)
+}
+
+func (dao *memDAO) SelectEnv(source db.Source, decQNs []uniqsym.ADT) (map[uniqsym.ADT]DecRec, error) {
+	env := make(map[uniqsym.ADT]DecRec, len(decQNs))
+	for _, decQN := range decQNs {
+		rec, err := dao.SelectRecByQN(source, decQN)
+		if err != nil {
+			return nil, err
+		}
+		env[decQN] = rec
+	}
+	return env, nil
+}
+
+func selectLatest(ds db.SourceMem, decQN string) (decRecDS, error) {
+	return db.SelectLatestMem(ds, Aliases,
+		func(dto decRecDS) bool { return dto.DecQN == decQN },
+		func(dto decRecDS) int64 { return dto.DecRN },
+	)
+}
+
+// other in-memory stores need the aliases too
+func SelectIDByQN(ds db.SourceMem, decQN string) (string, error) {
+	dto, err := selectLatest(ds, decQN)
+	return dto.DecID, err
+}
+
+// all names the entity has been known by
+func SelectQNsByID(ds db.SourceMem, decID string) []string {
+	decQNs := []string{}
+	for _, dto := range db.SelectMem[decRecDS](ds, Aliases) {
+		if dto.DecID == decID {
+			decQNs = append(decQNs, dto.DecQN)
+		}
+	}
+	return decQNs
+}
+
+const (
+	Aliases = "aliases"
+)
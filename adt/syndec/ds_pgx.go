@@ -1,6 +1,7 @@
 package syndec
 
 import (
+	"errors"
 	"log/slog"
 	"math"
 	"reflect"
@@ -8,6 +9,9 @@ import (
 	"github.com/jackc/pgx/v5"
 
 	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/uniqsym"
 )
 
 type pgxDAO struct {
@@ -33,16 +37,16 @@ func (dao *pgxDAO) Insert(source db.Source, root DecRec) error {
 		return err
 	}
 	query := `
-		insert into aliases (
-			id, from_rn, to_rn, sym
+		insert into syn_decs (
+			dec_id, dec_qn, from_rn, to_rn
 		) values (
-			@id, @from_rn, @to_rn, @sym
+			@dec_id, @dec_qn, @from_rn, @to_rn
 		)`
 	args := pgx.NamedArgs{
-		"id":      dto.DecID,
+		"dec_id":  dto.DecID,
+		"dec_qn":  dto.DecQN,
 		"from_rn": dto.DecRN,
 		"to_rn":   math.MaxInt64,
-		"sym":     dto.DecQN,
 	}
 	_, err = ds.Conn.Exec(ds.Ctx, query, args)
 	if err != nil {
@@ -51,3 +55,78 @@ func (dao *pgxDAO) Insert(source db.Source, root DecRec) error {
 	}
 	return nil
 }
+
+func (dao *pgxDAO) SelectRecByQN(source db.Source, decQN uniqsym.ADT) (DecRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	qnAttr := slog.Any("decQN", decQN)
+	rows, err := ds.Conn.Query(ds.Ctx, selectByQN, uniqsym.ConvertToString(decQN))
+	if err != nil {
+		dao.log.Error("query execution failed", qnAttr, slog.String("q", selectByQN))
+		return DecRec{}, err
+	}
+	defer rows.Close()
+	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[decRecDS])
+	if errors.Is(err, pgx.ErrNoRows) {
+		dao.log.Error("entity selection failed", qnAttr)
+		return DecRec{}, ErrMissingInEnv(decQN)
+	}
+	if err != nil {
+		dao.log.Error("row collection failed", qnAttr)
+		return DecRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", qnAttr)
+	return DataToDecRec(dto)
+}
+
+func (dao *pgxDAO) SelectEnv(source db.Source, decQNs []uniqsym.ADT) (_ map[uniqsym.ADT]DecRec, err error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	if len(decQNs) == 0 {
+		return map[uniqsym.ADT]DecRec{}, nil
+	}
+	batch := pgx.Batch{}
+	for _, decQN := range decQNs {
+		batch.Queue(selectByQN, uniqsym.ConvertToString(decQN))
+	}
+	br := ds.Conn.SendBatch(ds.Ctx, &batch)
+	defer func() {
+		err = errors.Join(err, br.Close())
+	}()
+	env := make(map[uniqsym.ADT]DecRec, len(decQNs))
+	for _, decQN := range decQNs {
+		qnAttr := slog.Any("decQN", decQN)
+		rows, err := br.Query()
+		if err != nil {
+			dao.log.Error("query execution failed", qnAttr, slog.String("q", selectByQN))
+			return nil, err
+		}
+		dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[decRecDS])
+		if errors.Is(err, pgx.ErrNoRows) {
+			dao.log.Error("entity selection failed", qnAttr)
+			return nil, ErrMissingInEnv(decQN)
+		}
+		if err != nil {
+			dao.log.Error("row collection failed", qnAttr)
+			return nil, err
+		}
+		rec, err := DataToDecRec(dto)
+		if err != nil {
+			dao.log.Error("model conversion failed", qnAttr)
+			return nil, err
+		}
+		env[decQN] = rec
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("env", env))
+	return env, nil
+}
+
+const (
+	selectByQN = `
+		select
+			dec_id,
+			from_rn as dec_rn,
+			dec_qn::text
+		from syn_decs
+		where dec_qn = $1
+		order by from_rn desc
+		limit 1`
+)
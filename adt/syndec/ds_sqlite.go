@@ -0,0 +1,98 @@
+package syndec
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+// Adapter
+type sqliteDAO struct {
+	log *slog.Logger
+}
+
+func newSqliteDAO(l *slog.Logger) *sqliteDAO {
+	name := slog.String("name", reflect.TypeFor[sqliteDAO]().Name())
+	return &sqliteDAO{l.With(name)}
+}
+
+func (dao *sqliteDAO) Insert(source db.Source, root DecRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	idAttr := slog.Any("id", root.DecID)
+	dto, err := DataFromDecRec(root)
+	if err != nil {
+		dao.log.Error("model conversion failed", idAttr)
+		return err
+	}
+	args := db.NamedArgsSqlite{
+		"dec_id":  dto.DecID,
+		"dec_qn":  dto.DecQN,
+		"from_rn": dto.DecRN,
+		"to_rn":   math.MaxInt64,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertSqlite, args)
+	if err != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("q", insertSqlite))
+		return err
+	}
+	return nil
+}
+
+func (dao *sqliteDAO) SelectRecByQN(source db.Source, decQN uniqsym.ADT) (DecRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	qnAttr := slog.Any("decQN", decQN)
+	args := db.NamedArgsSqlite{"dec_qn": uniqsym.ConvertToString(decQN)}
+	rows, err := ds.Conn.Query(ds.Ctx, selectByQNSqlite, args)
+	if err != nil {
+		dao.log.Error("query execution failed", qnAttr, slog.String("q", selectByQNSqlite))
+		return DecRec{}, err
+	}
+	dto, err := db.CollectOneRowSqlite[decRecDS](rows)
+	if errors.Is(err, db.ErrNoRows) {
+		dao.log.Error("entity selection failed", qnAttr)
+		return DecRec{}, ErrMissingInEnv(decQN)
+	}
+	if err != nil {
+		dao.log.Error("row collection failed", qnAttr)
+		return DecRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", qnAttr)
+	return DataToDecRec(dto)
+}
+
+func (dao *sqliteDAO) SelectEnv(source db.Source, decQNs []uniqsym.ADT) (map[uniqsym.ADT]DecRec, error) {
+	env := make(map[uniqsym.ADT]DecRec, len(decQNs))
+	for _, decQN := range decQNs {
+		rec, err := dao.SelectRecByQN(source, decQN)
+		if err != nil {
+			return nil, err
+		}
+		env[decQN] = rec
+	}
+	return env, nil
+}
+
+const (
+	insertSqlite = `
+		insert into syn_decs (
+			dec_id, dec_qn, from_rn, to_rn
+		) values (
+			:dec_id, :dec_qn, :from_rn, :to_rn
+		)`
+
+	selectByQNSqlite = `
+		select
+			dec_id,
+			from_rn as dec_rn,
+			dec_qn
+		from syn_decs
+		where dec_qn = :dec_qn
+		order by from_rn desc
+		limit 1`
+)
@@ -2,12 +2,17 @@ package typedef
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	"log/slog"
+	"maps"
+	"slices"
+	"time"
 
 	"orglang/go-runtime/lib/db"
 
+	"orglang/go-runtime/adt/arithexp"
 	"orglang/go-runtime/adt/identity"
 	"orglang/go-runtime/adt/revnum"
 	"orglang/go-runtime/adt/symbol"
@@ -18,38 +23,139 @@ import (
 )
 
 type API interface {
-	Incept(uniqsym.ADT) (DefRef, error)
-	Create(DefSpec) (DefSnap, error)
-	Modify(DefSnap) (DefSnap, error)
-	RetrieveSnap(DefRef) (DefSnap, error)
-	retrieveSnap(DefRec) (DefSnap, error)
-	RetreiveRefs() ([]DefRef, error)
+	Incept(context.Context, uniqsym.ADT) (DefRef, error)
+	Create(context.Context, DefSpec) (DefSnap, error)
+	Modify(context.Context, DefSnap) (DefSnap, error)
+	Assess(context.Context, DefSnap) (ImpactRec, error)
+	RetrieveSnap(context.Context, DefRef) (DefSnap, error)
+	// the revision given in the ref rather than the latest
+	RetrieveSnapByRN(context.Context, DefRef) (DefSnap, error)
+	// the latest revision as of the given time
+	RetrieveSnapAsOf(context.Context, identity.ADT, time.Time) (DefSnap, error)
+	retrieveSnap(context.Context, DefRec) (DefSnap, error)
+	RetreiveRefs(context.Context) ([]DefRef, error)
+	RetrieveRevs(context.Context, identity.ADT) ([]RevRec, error)
+	Compare(context.Context, identity.ADT, revnum.ADT, revnum.ADT) (DefDiff, error)
 }
 
 type DefRef = uniqref.ADT
 
 type DefSpec struct {
 	TypeQN uniqsym.ADT
-	TypeES typeexp.ExpSpec
+	// type parameters (aka type variables)
+	TypeVars []symbol.ADT
+	// index parameters (naturals)
+	IdxVars []symbol.ADT
+	TypeES  typeexp.ExpSpec
 }
 
 // aka TpDef
 type DefRec struct {
-	DefRef DefRef
-	Title  string
-	ExpID  identity.ADT
+	DefRef   DefRef
+	Title    string
+	TypeVars []symbol.ADT
+	IdxVars  []symbol.ADT
+	ExpID    identity.ADT
 }
 
 type DefSnap struct {
+	DefRef   DefRef
+	Title    string
+	TypeQN   uniqsym.ADT
+	TypeVars []symbol.ADT
+	IdxVars  []symbol.ADT
+	TypeES   typeexp.ExpSpec
+}
+
+// revision in the definition history
+type RevRec struct {
 	DefRef DefRef
-	Title  string
-	TypeQN uniqsym.ADT
-	TypeES typeexp.ExpSpec
+	RevAt  time.Time
+}
+
+// differences between two revisions of a definition
+type DefDiff struct {
+	FromRef         DefRef
+	ToRef           DefRef
+	TypeVarsAdded   []symbol.ADT
+	TypeVarsRemoved []symbol.ADT
+	IdxVarsAdded    []symbol.ADT
+	IdxVarsRemoved  []symbol.ADT
+	// expressions are set only if they differ
+	FromES typeexp.ExpSpec
+	ToES   typeexp.ExpSpec
+}
+
+// impact of a definition change
+type ImpactRec struct {
+	// declarations referring to the type
+	DecRefs []uniqref.ADT
+	// definitions of those declarations
+	DefRefs []uniqref.ADT
+	// live executions bound to the current expression
+	ExecRefs []uniqref.ADT
+}
+
+// the change would break running processes
+type ImpactError struct {
+	Impact ImpactRec
+}
+
+func (e ImpactError) Error() string {
+	return fmt.Sprintf("type modification breaks running executions: %v", len(e.Impact.ExecRefs))
 }
 
 type Context struct {
 	Assets map[symbol.ADT]typeexp.ExpRec
 	Liabs  map[symbol.ADT]typeexp.ExpRec
+	// known constraints on index variables
+	Facts []arithexp.PropSpec
+	// remaining potential of the process
+	Pot int64
+	// types of received values
+	Vals map[symbol.ADT]typeexp.DataType
+}
+
+type DefErrorKind uint8
+
+const (
+	NotContractive DefErrorKind = iota + 1
+	DanglingLink
+	UnboundVar
+	DuplicateVar
+	UnboundIdx
+	DuplicateIdx
+	NegativePot
+)
+
+// violation of type definition rules
+type DefError struct {
+	K       DefErrorKind
+	TypeQN  uniqsym.ADT
+	LinkQN  uniqsym.ADT
+	TypeVar symbol.ADT
+	IdxVar  symbol.ADT
+}
+
+func (e DefError) Error() string {
+	switch e.K {
+	case NotContractive:
+		return fmt.Sprintf("type not contractive: %v", e.TypeQN)
+	case DanglingLink:
+		return fmt.Sprintf("type link dangling: %v refers to undeclared %v", e.TypeQN, e.LinkQN)
+	case UnboundVar:
+		return fmt.Sprintf("type var unbound: %v refers to undeclared %v", e.TypeQN, e.TypeVar)
+	case DuplicateVar:
+		return fmt.Sprintf("type var duplicate: %v declares %v twice", e.TypeQN, e.TypeVar)
+	case UnboundIdx:
+		return fmt.Sprintf("index var unbound: %v refers to undeclared %v", e.TypeQN, e.IdxVar)
+	case DuplicateIdx:
+		return fmt.Sprintf("index var duplicate: %v declares %v twice", e.TypeQN, e.IdxVar)
+	case NegativePot:
+		return fmt.Sprintf("potential negative: %v", e.TypeQN)
+	default:
+		return fmt.Sprintf("type definition invalid: %v", e.TypeQN)
+	}
 }
 
 type service struct {
@@ -57,6 +163,7 @@ type service struct {
 	typeExps typeexp.Repo
 	synDecs  syndec.Repo
 	operator db.Operator
+	cs       modificationCS
 	log      *slog.Logger
 }
 
@@ -70,13 +177,13 @@ func newService(
 	typeExps typeexp.Repo,
 	synDecs syndec.Repo,
 	operator db.Operator,
+	cs modificationCS,
 	l *slog.Logger,
 ) *service {
-	return &service{typeDefs, typeExps, synDecs, operator, l}
+	return &service{typeDefs, typeExps, synDecs, operator, cs, l}
 }
 
-func (s *service) Incept(typeQN uniqsym.ADT) (_ DefRef, err error) {
-	ctx := context.Background()
+func (s *service) Incept(ctx context.Context, typeQN uniqsym.ADT) (_ DefRef, err error) {
 	qnAttr := slog.Any("typeQN", typeQN)
 	s.log.Debug("inception started", qnAttr)
 	newSyn := syndec.DecRec{DecQN: typeQN, DecID: identity.New(), DecRN: revnum.New()}
@@ -100,16 +207,22 @@ func (s *service) Incept(typeQN uniqsym.ADT) (_ DefRef, err error) {
 	return ConvertRecToRef(newType), nil
 }
 
-func (s *service) Create(spec DefSpec) (_ DefSnap, err error) {
-	ctx := context.Background()
+func (s *service) Create(ctx context.Context, spec DefSpec) (_ DefSnap, err error) {
 	qnAttr := slog.Any("typeQN", spec.TypeQN)
 	s.log.Debug("creation started", qnAttr, slog.Any("spec", spec))
 	newSyn := syndec.DecRec{DecQN: spec.TypeQN, DecID: identity.New(), DecRN: revnum.New()}
 	newExp := typeexp.ConvertSpecToRec(spec.TypeES)
 	newType := DefRec{
-		DefRef: DefRef{ID: newSyn.DecID, RN: newSyn.DecRN},
-		Title:  symbol.ConvertToString(newSyn.DecQN.Sym()),
-		ExpID:  newExp.Ident(),
+		DefRef:   DefRef{ID: newSyn.DecID, RN: newSyn.DecRN},
+		Title:    symbol.ConvertToString(newSyn.DecQN.Sym()),
+		TypeVars: spec.TypeVars,
+		IdxVars:  spec.IdxVars,
+		ExpID:    newExp.Ident(),
+	}
+	err = s.checkDef(ctx, spec.TypeQN, spec.TypeVars, spec.IdxVars, newExp)
+	if err != nil {
+		s.log.Error("creation failed", qnAttr)
+		return DefSnap{}, err
 	}
 	s.operator.Explicit(ctx, func(ds db.Source) error {
 		err = s.synDecs.Insert(ds, newSyn)
@@ -132,54 +245,63 @@ func (s *service) Create(spec DefSpec) (_ DefSnap, err error) {
 	}
 	s.log.Debug("creation succeed", qnAttr, slog.Any("defRef", newType.DefRef))
 	return DefSnap{
-		DefRef: newType.DefRef,
-		Title:  newType.Title,
-		TypeQN: newSyn.DecQN,
-		TypeES: typeexp.ConvertRecToSpec(newExp),
+		DefRef:   newType.DefRef,
+		Title:    newType.Title,
+		TypeQN:   newSyn.DecQN,
+		TypeVars: newType.TypeVars,
+		IdxVars:  newType.IdxVars,
+		TypeES:   typeexp.ConvertRecToSpec(newExp),
 	}, nil
 }
 
-func (s *service) Modify(snap DefSnap) (_ DefSnap, err error) {
-	ctx := context.Background()
+func (s *service) Modify(ctx context.Context, snap DefSnap) (_ DefSnap, err error) {
 	refAttr := slog.Any("defRef", snap.DefRef)
 	s.log.Debug("modification started", refAttr)
-	var rec DefRec
-	s.operator.Implicit(ctx, func(ds db.Source) error {
-		rec, err = s.typeDefs.SelectRecByRef(ds, snap.DefRef)
-		return err
-	})
+	newExp := typeexp.ConvertSpecToRec(snap.TypeES)
+	err = s.checkDef(ctx, snap.TypeQN, snap.TypeVars, snap.IdxVars, newExp)
 	if err != nil {
 		s.log.Error("modification failed", refAttr)
 		return DefSnap{}, err
 	}
-	if snap.DefRef.RN != rec.DefRef.RN {
-		s.log.Error("modification failed", refAttr)
-		return DefSnap{}, errConcurrentModification(snap.DefRef.RN, rec.DefRef.RN)
-	} else {
+	// the impact is assessed against the very state being updated
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		rec, err := s.typeDefs.SelectRecByRef(ds, snap.DefRef)
+		if err != nil {
+			return err
+		}
+		if snap.DefRef.RN != rec.DefRef.RN {
+			return errConcurrentModification(snap.DefRef.RN, rec.DefRef.RN)
+		}
 		snap.DefRef.RN = revnum.Next(snap.DefRef.RN)
-	}
-	curSnap, err := s.retrieveSnap(rec)
-	if err != nil {
-		s.log.Error("modification failed", refAttr)
-		return DefSnap{}, err
-	}
-	s.operator.Explicit(ctx, func(ds db.Source) error {
-		if typeexp.CheckSpec(snap.TypeES, curSnap.TypeES) != nil {
-			newTerm := typeexp.ConvertSpecToRec(snap.TypeES)
-			err = s.typeExps.InsertRec(ds, newTerm)
-			if err != nil {
-				return err
-			}
-			rec.ExpID = newTerm.Ident()
-			rec.DefRef.RN = snap.DefRef.RN
+		curExp, err := s.typeExps.SelectRecByID(ds, rec.ExpID)
+		if err != nil {
+			return err
 		}
-		if rec.DefRef.RN == snap.DefRef.RN {
-			err = s.typeDefs.Update(ds, rec)
+		changed := !slices.Equal(snap.TypeVars, rec.TypeVars) ||
+			!slices.Equal(snap.IdxVars, rec.IdxVars) ||
+			typeexp.CheckSpec(snap.TypeES, typeexp.ConvertRecToSpec(curExp)) != nil
+		if !changed {
+			return nil
+		}
+		if s.cs.Policy == refusePolicy {
+			impact, err := s.assessWith(ds, rec, curExp)
 			if err != nil {
 				return err
 			}
+			if len(impact.ExecRefs) > 0 {
+				s.log.Error("modification refused", refAttr, slog.Any("execs", impact.ExecRefs))
+				return ImpactError{impact}
+			}
 		}
-		return nil
+		err = s.typeExps.InsertRec(ds, newExp)
+		if err != nil {
+			return err
+		}
+		rec.TypeVars = snap.TypeVars
+		rec.IdxVars = snap.IdxVars
+		rec.ExpID = newExp.Ident()
+		rec.DefRef.RN = snap.DefRef.RN
+		return s.typeDefs.Update(ds, rec)
 	})
 	if err != nil {
 		s.log.Error("modification failed", refAttr)
@@ -189,8 +311,35 @@ func (s *service) Modify(snap DefSnap) (_ DefSnap, err error) {
 	return snap, nil
 }
 
-func (s *service) RetrieveSnap(defID DefRef) (_ DefSnap, err error) {
-	ctx := context.Background()
+func (s *service) Assess(ctx context.Context, snap DefSnap) (_ ImpactRec, err error) {
+	refAttr := slog.Any("defRef", snap.DefRef)
+	s.log.Debug("assessment started", refAttr)
+	var impact ImpactRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		rec, err := s.typeDefs.SelectRecByRef(ds, snap.DefRef)
+		if err != nil {
+			return err
+		}
+		curExp, err := s.typeExps.SelectRecByID(ds, rec.ExpID)
+		if err != nil {
+			return err
+		}
+		impact, err = s.assessWith(ds, rec, curExp)
+		return err
+	})
+	if err != nil {
+		s.log.Error("assessment failed", refAttr)
+		return ImpactRec{}, err
+	}
+	s.log.Debug("assessment succeed", refAttr, slog.Int("execs", len(impact.ExecRefs)))
+	return impact, nil
+}
+
+func (s *service) assessWith(ds db.Source, rec DefRec, curExp typeexp.ExpRec) (ImpactRec, error) {
+	return s.typeDefs.SelectImpact(ds, rec.DefRef, typeexp.CollectIDs(curExp))
+}
+
+func (s *service) RetrieveSnap(ctx context.Context, defID DefRef) (_ DefSnap, err error) {
 	var root DefRec
 	s.operator.Implicit(ctx, func(ds db.Source) error {
 		root, err = s.typeDefs.SelectRecByRef(ds, defID)
@@ -200,11 +349,44 @@ func (s *service) RetrieveSnap(defID DefRef) (_ DefSnap, err error) {
 		s.log.Error("retrieval failed", slog.Any("defID", defID))
 		return DefSnap{}, err
 	}
-	return s.retrieveSnap(root)
+	return s.retrieveSnap(ctx, root)
 }
 
-func (s *service) retrieveSnap(rec DefRec) (_ DefSnap, err error) {
-	ctx := context.Background()
+func (s *service) RetrieveSnapByRN(ctx context.Context, ref DefRef) (_ DefSnap, err error) {
+	var rec DefRec
+	s.operator.Implicit(ctx, func(ds db.Source) error {
+		rec, err = s.typeDefs.SelectRecByRN(ds, ref)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", slog.Any("defRef", ref))
+		return DefSnap{}, err
+	}
+	return s.retrieveSnap(ctx, rec)
+}
+
+func (s *service) RetrieveSnapAsOf(ctx context.Context, defID identity.ADT, at time.Time) (_ DefSnap, err error) {
+	var rec DefRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		revs, err := s.typeDefs.SelectRevs(ds, defID)
+		if err != nil {
+			return err
+		}
+		rev, ok := revAsOf(revs, at)
+		if !ok {
+			return errRevMissingAt(defID, at)
+		}
+		rec, err = s.typeDefs.SelectRecByRN(ds, rev.DefRef)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", slog.Any("defID", defID), slog.Time("at", at))
+		return DefSnap{}, err
+	}
+	return s.retrieveSnap(ctx, rec)
+}
+
+func (s *service) retrieveSnap(ctx context.Context, rec DefRec) (_ DefSnap, err error) {
 	var termRec typeexp.ExpRec
 	s.operator.Implicit(ctx, func(ds db.Source) error {
 		termRec, err = s.typeExps.SelectRecByID(ds, rec.ExpID)
@@ -215,14 +397,15 @@ func (s *service) retrieveSnap(rec DefRec) (_ DefSnap, err error) {
 		return DefSnap{}, err
 	}
 	return DefSnap{
-		DefRef: rec.DefRef,
-		Title:  rec.Title,
-		TypeES: typeexp.ConvertRecToSpec(termRec),
+		DefRef:   rec.DefRef,
+		Title:    rec.Title,
+		TypeVars: rec.TypeVars,
+		IdxVars:  rec.IdxVars,
+		TypeES:   typeexp.ConvertRecToSpec(termRec),
 	}, nil
 }
 
-func (s *service) RetreiveRefs() (refs []DefRef, err error) {
-	ctx := context.Background()
+func (s *service) RetreiveRefs(ctx context.Context) (refs []DefRef, err error) {
 	s.operator.Implicit(ctx, func(ds db.Source) error {
 		refs, err = s.typeDefs.SelectRefs(ds)
 		return err
@@ -234,6 +417,112 @@ func (s *service) RetreiveRefs() (refs []DefRef, err error) {
 	return refs, nil
 }
 
+func (s *service) RetrieveRevs(ctx context.Context, defID identity.ADT) (revs []RevRec, err error) {
+	s.operator.Implicit(ctx, func(ds db.Source) error {
+		revs, err = s.typeDefs.SelectRevs(ds, defID)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", slog.Any("defID", defID))
+		return nil, err
+	}
+	if len(revs) == 0 {
+		return nil, ErrDoesNotExist(defID)
+	}
+	return revs, nil
+}
+
+func (s *service) Compare(ctx context.Context, defID identity.ADT, fromRN, toRN revnum.ADT) (DefDiff, error) {
+	idAttr := slog.Any("defID", defID)
+	fromSnap, err := s.RetrieveSnapByRN(ctx, DefRef{ID: defID, RN: fromRN})
+	if err != nil {
+		s.log.Error("comparison failed", idAttr)
+		return DefDiff{}, err
+	}
+	toSnap, err := s.RetrieveSnapByRN(ctx, DefRef{ID: defID, RN: toRN})
+	if err != nil {
+		s.log.Error("comparison failed", idAttr)
+		return DefDiff{}, err
+	}
+	return diffSnaps(fromSnap, toSnap), nil
+}
+
+func diffSnaps(from, to DefSnap) DefDiff {
+	diff := DefDiff{FromRef: from.DefRef, ToRef: to.DefRef}
+	diff.TypeVarsAdded, diff.TypeVarsRemoved = symbol.Diff(from.TypeVars, to.TypeVars)
+	diff.IdxVarsAdded, diff.IdxVarsRemoved = symbol.Diff(from.IdxVars, to.IdxVars)
+	if typeexp.CheckSpec(to.TypeES, from.TypeES) != nil {
+		diff.FromES = from.TypeES
+		diff.ToES = to.TypeES
+	}
+	return diff
+}
+
+// revisions are ordered by ascending number
+func revAsOf(revs []RevRec, at time.Time) (RevRec, bool) {
+	for i := len(revs) - 1; i >= 0; i-- {
+		if !revs[i].RevAt.After(at) {
+			return revs[i], true
+		}
+	}
+	return RevRec{}, false
+}
+
+// aka Contractive
+func (s *service) checkDef(
+	ctx context.Context,
+	typeQN uniqsym.ADT,
+	typeVars []symbol.ADT,
+	idxVars []symbol.ADT,
+	rec typeexp.ExpRec,
+) error {
+	var errs []error
+	if _, ok := rec.(typeexp.LinkRec); ok {
+		errs = append(errs, DefError{K: NotContractive, TypeQN: typeQN})
+	}
+	for i, typeVar := range typeVars {
+		if slices.Contains(typeVars[:i], typeVar) {
+			errs = append(errs, DefError{K: DuplicateVar, TypeQN: typeQN, TypeVar: typeVar})
+		}
+	}
+	for _, typeVar := range typeexp.CollectVars(rec) {
+		if !slices.Contains(typeVars, typeVar) {
+			errs = append(errs, DefError{K: UnboundVar, TypeQN: typeQN, TypeVar: typeVar})
+		}
+	}
+	for i, idxVar := range idxVars {
+		if slices.Contains(idxVars[:i], idxVar) {
+			errs = append(errs, DefError{K: DuplicateIdx, TypeQN: typeQN, IdxVar: idxVar})
+		}
+	}
+	for _, idxVar := range typeexp.CollectIdxVars(rec) {
+		if !slices.Contains(idxVars, idxVar) {
+			errs = append(errs, DefError{K: UnboundIdx, TypeQN: typeQN, IdxVar: idxVar})
+		}
+	}
+	if slices.ContainsFunc(typeexp.CollectPots(rec), func(pot int64) bool { return pot < 0 }) {
+		errs = append(errs, DefError{K: NegativePot, TypeQN: typeQN})
+	}
+	for _, linkQN := range typeexp.CollectLinks(slices.Values([]typeexp.ExpRec{rec})) {
+		// recursive reference to the definition itself
+		if linkQN.Equal(typeQN) {
+			continue
+		}
+		err := s.operator.Implicit(ctx, func(ds db.Source) error {
+			_, err := s.synDecs.SelectRecByQN(ds, linkQN)
+			return err
+		})
+		if errors.Is(err, syndec.ErrDecMissing) {
+			errs = append(errs, DefError{K: DanglingLink, TypeQN: typeQN, LinkQN: linkQN})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func CollectEnv(recs iter.Seq[DefRec]) []identity.ADT {
 	termIDs := []identity.ADT{}
 	for r := range recs {
@@ -242,6 +531,35 @@ func CollectEnv(recs iter.Seq[DefRec]) []identity.ADT {
 	return termIDs
 }
 
+// type names not loaded into the environment yet
+func MissingLinks(recs iter.Seq[typeexp.ExpRec], defs map[uniqsym.ADT]DefRec) []uniqsym.ADT {
+	var linkQNs []uniqsym.ADT
+	for _, linkQN := range typeexp.CollectLinks(recs) {
+		known := func(typeQN uniqsym.ADT) bool { return typeQN.Equal(linkQN) }
+		if slices.ContainsFunc(linkQNs, known) {
+			continue
+		}
+		if slices.ContainsFunc(slices.Collect(maps.Keys(defs)), known) {
+			continue
+		}
+		linkQNs = append(linkQNs, linkQN)
+	}
+	return linkQNs
+}
+
+// environment for unfolding type references
+func ConvertToEnv(defs map[uniqsym.ADT]DefRec, exps map[identity.ADT]typeexp.ExpRec) typeexp.Env {
+	typeIDs := make(map[uniqsym.ADT]identity.ADT, len(defs))
+	typeVars := make(map[uniqsym.ADT][]symbol.ADT, len(defs))
+	idxVars := make(map[uniqsym.ADT][]symbol.ADT, len(defs))
+	for typeQN, def := range defs {
+		typeIDs[typeQN] = def.ExpID
+		typeVars[typeQN] = def.TypeVars
+		idxVars[typeQN] = def.IdxVars
+	}
+	return typeexp.Env{TypeIDs: typeIDs, TypeExps: exps, TypeVars: typeVars, IdxVars: idxVars}
+}
+
 func ErrSymMissingInEnv(want uniqsym.ADT) error {
 	return fmt.Errorf("root missing in env: %v", want)
 }
@@ -254,6 +572,10 @@ func errOptimisticUpdate(got revnum.ADT) error {
 	return fmt.Errorf("entity concurrent modification: got revision %v", got)
 }
 
+func errRevMissingAt(defID identity.ADT, at time.Time) error {
+	return fmt.Errorf("revision missing: %v has none as of %v", defID, at)
+}
+
 func ErrDoesNotExist(want identity.ADT) error {
 	return fmt.Errorf("root doesn't exist: %v", want)
 }
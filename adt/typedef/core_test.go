@@ -0,0 +1,515 @@
+package typedef
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"orglang/go-runtime/lib/db"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/syndec"
+	"orglang/go-runtime/adt/typeexp"
+	"orglang/go-runtime/adt/uniqref"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+// operations run without storage; an explicit one fails on commit if told to
+type stubOperator struct {
+	commitErr error
+}
+
+func (o stubOperator) Explicit(_ context.Context, op func(db.Source) error) error {
+	err := op(nil)
+	if err != nil {
+		return err
+	}
+	return o.commitErr
+}
+
+func (stubOperator) Implicit(_ context.Context, op func(db.Source) error) error { return op(nil) }
+
+type stubDefs struct {
+	Repo
+	recs    map[identity.ADT][]DefRec
+	revs    map[identity.ADT][]RevRec
+	impact  ImpactRec
+	updates []DefRec
+}
+
+func (r *stubDefs) SelectRecByRef(_ db.Source, ref DefRef) (DefRec, error) {
+	recs := r.recs[ref.ID]
+	if len(recs) == 0 {
+		return DefRec{}, ErrDoesNotExist(ref.ID)
+	}
+	return recs[len(recs)-1], nil
+}
+
+func (r *stubDefs) SelectRecByRN(_ db.Source, ref DefRef) (DefRec, error) {
+	for _, rec := range r.recs[ref.ID] {
+		if rec.DefRef.RN == ref.RN {
+			return rec, nil
+		}
+	}
+	return DefRec{}, ErrDoesNotExist(ref.ID)
+}
+
+func (r *stubDefs) SelectRevs(_ db.Source, defID identity.ADT) ([]RevRec, error) {
+	return r.revs[defID], nil
+}
+
+func (r *stubDefs) SelectImpact(db.Source, DefRef, []identity.ADT) (ImpactRec, error) {
+	return r.impact, nil
+}
+
+func (r *stubDefs) Update(_ db.Source, rec DefRec) error {
+	r.updates = append(r.updates, rec)
+	return nil
+}
+
+type stubExps struct {
+	typeexp.Repo
+	recs map[identity.ADT]typeexp.ExpRec
+}
+
+func (r stubExps) SelectRecByID(_ db.Source, expID identity.ADT) (typeexp.ExpRec, error) {
+	rec, ok := r.recs[expID]
+	if !ok {
+		return nil, typeexp.ErrDoesNotExist(expID)
+	}
+	return rec, nil
+}
+
+func (r stubExps) InsertRec(_ db.Source, rec typeexp.ExpRec) error {
+	r.recs[rec.Ident()] = rec
+	return nil
+}
+
+type stubSyns struct {
+	syndec.Repo
+	decQNs []uniqsym.ADT
+}
+
+func (r stubSyns) SelectRecByQN(_ db.Source, decQN uniqsym.ADT) (syndec.DecRec, error) {
+	for _, known := range r.decQNs {
+		if known.Equal(decQN) {
+			return syndec.DecRec{DecID: identity.New(), DecRN: revnum.New(), DecQN: decQN}, nil
+		}
+	}
+	return syndec.DecRec{}, syndec.ErrDecMissing
+}
+
+func newStubService(policy policyCS, defs *stubDefs, exps stubExps, syns stubSyns) *service {
+	return &service{
+		typeDefs: defs,
+		typeExps: exps,
+		synDecs:  syns,
+		operator: stubOperator{},
+		cs:       modificationCS{Policy: policy},
+		log:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+// stored definition of the given expression
+func storedDef(exp typeexp.ExpRec) (DefRec, *stubDefs, stubExps) {
+	rec := DefRec{DefRef: uniqref.New(), Title: "t", ExpID: exp.Ident()}
+	defs := &stubDefs{recs: map[identity.ADT][]DefRec{rec.DefRef.ID: {rec}}}
+	exps := stubExps{recs: map[identity.ADT]typeexp.ExpRec{exp.Ident(): exp}}
+	return rec, defs, exps
+}
+
+func TestModify(t *testing.T) {
+	typeQN := uniqsym.New("t")
+	live := ImpactRec{ExecRefs: []uniqref.ADT{uniqref.New()}}
+	commitErr := errors.New("commit failed")
+	tests := []struct {
+		name      string
+		policy    policyCS
+		impact    ImpactRec
+		newES     typeexp.ExpSpec
+		staleRN   bool
+		commitErr error
+		err       error
+		updated   bool
+	}{
+		{"allow breaking change", allowPolicy, live, typeexp.WithSpec{}, false, nil, nil, true},
+		{"refuse without live execs", refusePolicy, ImpactRec{}, typeexp.WithSpec{}, false, nil, nil, true},
+		{"refuse breaking change", refusePolicy, live, typeexp.WithSpec{}, false, nil, ImpactError{live}, false},
+		{"refuse ignores no-op", refusePolicy, live, typeexp.OneSpec{}, false, nil, nil, false},
+		{"stale revision", allowPolicy, ImpactRec{}, typeexp.WithSpec{}, true, nil, errors.New("concurrent"), false},
+		{"failed commit", allowPolicy, ImpactRec{}, typeexp.WithSpec{}, false, commitErr, commitErr, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec, defs, exps := storedDef(typeexp.OneRec{ExpID: identity.New()})
+			defs.impact = test.impact
+			s := newStubService(test.policy, defs, exps, stubSyns{})
+			s.operator = stubOperator{commitErr: test.commitErr}
+			snap := DefSnap{DefRef: rec.DefRef, TypeQN: typeQN, TypeES: test.newES}
+			if test.staleRN {
+				snap.DefRef.RN = revnum.Next(snap.DefRef.RN)
+			}
+			got, err := s.Modify(context.Background(), snap)
+			if (len(defs.updates) > 0) != test.updated {
+				t.Errorf("got %v updates, want updated %v", len(defs.updates), test.updated)
+			}
+			var impactErr ImpactError
+			switch {
+			case test.err == nil:
+				if err != nil {
+					t.Fatalf("unexpected error %q", err)
+				}
+				if got.DefRef.RN != revnum.Next(rec.DefRef.RN) {
+					t.Errorf("got revision %v, want %v", got.DefRef.RN, revnum.Next(rec.DefRef.RN))
+				}
+			case errors.As(test.err, &impactErr):
+				if !errors.As(err, &impactErr) || len(impactErr.Impact.ExecRefs) != len(live.ExecRefs) {
+					t.Errorf("got %v, want %v", err, test.err)
+				}
+			case errors.Is(test.err, commitErr):
+				if !errors.Is(err, commitErr) {
+					t.Errorf("got %v, want %v", err, commitErr)
+				}
+			default:
+				if err == nil {
+					t.Errorf("got no error, want %v", test.err)
+				}
+			}
+		})
+	}
+}
+
+// the dry run reports the impact and changes nothing
+func TestAssess(t *testing.T) {
+	tests := []struct {
+		name   string
+		impact ImpactRec
+	}{
+		{"no impact", ImpactRec{}},
+		{"live execs", ImpactRec{
+			DecRefs:  []uniqref.ADT{uniqref.New()},
+			ExecRefs: []uniqref.ADT{uniqref.New(), uniqref.New()},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec, defs, exps := storedDef(typeexp.OneRec{ExpID: identity.New()})
+			defs.impact = test.impact
+			s := newStubService(refusePolicy, defs, exps, stubSyns{})
+			got, err := s.Assess(context.Background(), DefSnap{DefRef: rec.DefRef, TypeES: typeexp.WithSpec{}})
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if len(got.DecRefs) != len(test.impact.DecRefs) || len(got.ExecRefs) != len(test.impact.ExecRefs) {
+				t.Errorf("got %+v, want %+v", got, test.impact)
+			}
+			if len(defs.updates) != 0 {
+				t.Errorf("got %v updates, want none", len(defs.updates))
+			}
+		})
+	}
+	t.Run("missing definition", func(t *testing.T) {
+		_, defs, exps := storedDef(typeexp.OneRec{ExpID: identity.New()})
+		s := newStubService(refusePolicy, defs, exps, stubSyns{})
+		_, err := s.Assess(context.Background(), DefSnap{DefRef: uniqref.New()})
+		if err == nil {
+			t.Errorf("got no error, want one")
+		}
+	})
+}
+
+func TestCheckDef(t *testing.T) {
+	typeQN := uniqsym.New("t")
+	known := uniqsym.New("known")
+	link := func(linkQN uniqsym.ADT) typeexp.ExpRec {
+		return typeexp.LinkRec{ExpID: identity.New(), TypeQN: linkQN}
+	}
+	// +{next: body}
+	guarded := func(body typeexp.ExpRec) typeexp.ExpRec {
+		return typeexp.PlusRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]typeexp.ExpRec{uniqsym.New("next"): body}}
+	}
+	tests := []struct {
+		name     string
+		typeVars []symbol.ADT
+		rec      typeexp.ExpRec
+		want     []DefErrorKind
+	}{
+		{"guarded recursion", nil, guarded(link(typeQN)), nil},
+		{"guarded declared link", nil, guarded(link(known)), nil},
+		{"bare self link", nil, link(typeQN), []DefErrorKind{NotContractive}},
+		// A = B is rejected whatever B is
+		{"bare declared link", nil, link(known), []DefErrorKind{NotContractive}},
+		{"dangling link", nil, guarded(link(uniqsym.New("unknown"))), []DefErrorKind{DanglingLink}},
+		{"bare dangling link", nil, link(uniqsym.New("unknown")), []DefErrorKind{NotContractive, DanglingLink}},
+		{"bound var", []symbol.ADT{"a"}, guarded(typeexp.VarRec{ExpID: identity.New(), TypeVar: "a"}), nil},
+		{"unbound var", nil, guarded(typeexp.VarRec{ExpID: identity.New(), TypeVar: "a"}), []DefErrorKind{UnboundVar}},
+		{"duplicate var", []symbol.ADT{"a", "a"}, guarded(typeexp.OneRec{ExpID: identity.New()}), []DefErrorKind{DuplicateVar}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newStubService(allowPolicy, &stubDefs{}, stubExps{}, stubSyns{decQNs: []uniqsym.ADT{known}})
+			err := s.checkDef(context.Background(), typeQN, test.typeVars, nil, test.rec)
+			var got []DefErrorKind
+			for _, err := range unjoin(err) {
+				var defErr DefError
+				if !errors.As(err, &defErr) {
+					t.Fatalf("got %v, want DefError", err)
+				}
+				got = append(got, defErr.K)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func unjoin(err error) []error {
+	if err == nil {
+		return nil
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	return joined.Unwrap()
+}
+
+type recordingDefs struct {
+	stubDefs
+	inserts []DefRec
+}
+
+func (r *recordingDefs) Insert(_ db.Source, rec DefRec) error {
+	r.inserts = append(r.inserts, rec)
+	return nil
+}
+
+type recordingSyns struct {
+	stubSyns
+	inserts *[]syndec.DecRec
+}
+
+func (r recordingSyns) Insert(_ db.Source, rec syndec.DecRec) error {
+	*r.inserts = append(*r.inserts, rec)
+	return nil
+}
+
+// only definitions that check are stored
+func TestCreate(t *testing.T) {
+	typeQN := uniqsym.New("t")
+	tests := []struct {
+		name   string
+		typeES typeexp.ExpSpec
+		err    bool
+	}{
+		{"contractive", typeexp.PlusSpec{Zs: map[uniqsym.ADT]typeexp.ExpSpec{uniqsym.New("next"): typeexp.LinkSpec{TypeQN: typeQN}}}, false},
+		{"not contractive", typeexp.LinkSpec{TypeQN: typeQN}, true},
+		{"dangling", typeexp.PlusSpec{Zs: map[uniqsym.ADT]typeexp.ExpSpec{uniqsym.New("next"): typeexp.LinkSpec{TypeQN: uniqsym.New("unknown")}}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defs := &recordingDefs{}
+			var syns []syndec.DecRec
+			s := newStubService(allowPolicy, &defs.stubDefs, stubExps{recs: map[identity.ADT]typeexp.ExpRec{}}, stubSyns{})
+			s.typeDefs = defs
+			s.synDecs = recordingSyns{inserts: &syns}
+			_, err := s.Create(context.Background(), DefSpec{TypeQN: typeQN, TypeES: test.typeES})
+			if test.err {
+				var defErr DefError
+				if !errors.As(err, &defErr) {
+					t.Errorf("got %v, want DefError", err)
+				}
+				if len(defs.inserts) != 0 || len(syns) != 0 {
+					t.Errorf("got %v defs and %v decs stored, want none", len(defs.inserts), len(syns))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if len(defs.inserts) != 1 || len(syns) != 1 {
+				t.Errorf("got %v defs and %v decs stored, want 1 and 1", len(defs.inserts), len(syns))
+			}
+		})
+	}
+}
+
+// three revisions an hour apart: a type var added, then an expression replaced
+func storedHistory(start time.Time) (identity.ADT, []revnum.ADT, *stubDefs, stubExps) {
+	defID := identity.New()
+	rn1 := revnum.New()
+	rn2 := revnum.Next(rn1)
+	rn3 := revnum.Next(rn2)
+	one := typeexp.OneRec{ExpID: identity.New()}
+	with := typeexp.WithRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]typeexp.ExpRec{}}
+	recs := []DefRec{
+		{DefRef: DefRef{ID: defID, RN: rn1}, ExpID: one.ExpID, TypeVars: []symbol.ADT{"a"}},
+		{DefRef: DefRef{ID: defID, RN: rn2}, ExpID: one.ExpID, TypeVars: []symbol.ADT{"a", "b"}},
+		{DefRef: DefRef{ID: defID, RN: rn3}, ExpID: with.ExpID, TypeVars: []symbol.ADT{"b"}},
+	}
+	revs := make([]RevRec, 0, len(recs))
+	for i, rec := range recs {
+		revs = append(revs, RevRec{DefRef: rec.DefRef, RevAt: start.Add(time.Duration(i) * time.Hour)})
+	}
+	defs := &stubDefs{
+		recs: map[identity.ADT][]DefRec{defID: recs},
+		revs: map[identity.ADT][]RevRec{defID: revs},
+	}
+	exps := stubExps{recs: map[identity.ADT]typeexp.ExpRec{one.ExpID: one, with.ExpID: with}}
+	return defID, []revnum.ADT{rn1, rn2, rn3}, defs, exps
+}
+
+func TestRetrieveSnapByRN(t *testing.T) {
+	defID, rns, defs, exps := storedHistory(time.Now())
+	s := newStubService(allowPolicy, defs, exps, stubSyns{})
+	tests := []struct {
+		name string
+		ref  DefRef
+		want []symbol.ADT
+		err  bool
+	}{
+		{"first revision", DefRef{ID: defID, RN: rns[0]}, []symbol.ADT{"a"}, false},
+		{"last revision", DefRef{ID: defID, RN: rns[2]}, []symbol.ADT{"b"}, false},
+		{"unknown revision", DefRef{ID: defID, RN: revnum.Next(rns[2])}, nil, true},
+		{"unknown definition", DefRef{ID: identity.New(), RN: rns[0]}, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := s.RetrieveSnapByRN(context.Background(), test.ref)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if got.DefRef != test.ref || !slices.Equal(got.TypeVars, test.want) {
+				t.Errorf("got %v with %v, want %v with %v", got.DefRef, got.TypeVars, test.ref, test.want)
+			}
+		})
+	}
+}
+
+func TestRetrieveSnapAsOf(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	defID, rns, defs, exps := storedHistory(start)
+	s := newStubService(allowPolicy, defs, exps, stubSyns{})
+	tests := []struct {
+		name string
+		id   identity.ADT
+		at   time.Time
+		want revnum.ADT
+		err  bool
+	}{
+		{"before the first revision", defID, start.Add(-time.Second), 0, true},
+		{"at the first revision", defID, start, rns[0], false},
+		{"between revisions", defID, start.Add(90 * time.Minute), rns[1], false},
+		{"at the second revision", defID, start.Add(time.Hour), rns[1], false},
+		{"after the last revision", defID, start.Add(24 * time.Hour), rns[2], false},
+		{"unknown definition", identity.New(), start.Add(time.Hour), 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := s.RetrieveSnapAsOf(context.Background(), test.id, test.at)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got %v, want error", got.DefRef)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if got.DefRef.RN != test.want {
+				t.Errorf("got revision %v, want %v", got.DefRef.RN, test.want)
+			}
+		})
+	}
+}
+
+func TestRetrieveRevs(t *testing.T) {
+	defID, rns, defs, exps := storedHistory(time.Now())
+	s := newStubService(allowPolicy, defs, exps, stubSyns{})
+	tests := []struct {
+		name string
+		id   identity.ADT
+		want []revnum.ADT
+		err  bool
+	}{
+		{"all revisions", defID, rns, false},
+		{"unknown definition", identity.New(), nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := s.RetrieveRevs(context.Background(), test.id)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			gotRNs := make([]revnum.ADT, 0, len(got))
+			for _, rev := range got {
+				gotRNs = append(gotRNs, rev.DefRef.RN)
+			}
+			if !slices.Equal(gotRNs, test.want) {
+				t.Errorf("got %v, want %v", gotRNs, test.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	defID, rns, defs, exps := storedHistory(time.Now())
+	s := newStubService(allowPolicy, defs, exps, stubSyns{})
+	tests := []struct {
+		name    string
+		from    revnum.ADT
+		to      revnum.ADT
+		added   []symbol.ADT
+		removed []symbol.ADT
+		changed bool
+		err     bool
+	}{
+		{"var added", rns[0], rns[1], []symbol.ADT{"b"}, nil, false, false},
+		{"var removed and expression replaced", rns[1], rns[2], nil, []symbol.ADT{"a"}, true, false},
+		{"same revision", rns[1], rns[1], nil, nil, false, false},
+		{"unknown from", revnum.Next(rns[2]), rns[2], nil, nil, false, true},
+		{"unknown to", rns[0], revnum.Next(rns[2]), nil, nil, false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := s.Compare(context.Background(), defID, test.from, test.to)
+			if test.err {
+				if err == nil {
+					t.Fatalf("got %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if got.FromRef.RN != test.from || got.ToRef.RN != test.to {
+				t.Errorf("got %v..%v, want %v..%v", got.FromRef.RN, got.ToRef.RN, test.from, test.to)
+			}
+			if !slices.Equal(got.TypeVarsAdded, test.added) || !slices.Equal(got.TypeVarsRemoved, test.removed) {
+				t.Errorf("got +%v -%v, want +%v -%v", got.TypeVarsAdded, got.TypeVarsRemoved, test.added, test.removed)
+			}
+			if (got.ToES != nil) != test.changed {
+				t.Errorf("got expression %v, want changed %v", got.ToES, test.changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package typedef
+
+import (
+	"orglang/go-runtime/lib/kv"
+)
+
+func newModificationCS(loader kv.Loader) (modificationCS, error) {
+	dto := &modificationCS{}
+	loadingErr := loader.Load("modification", dto)
+	if loadingErr != nil {
+		return modificationCS{}, loadingErr
+	}
+	validationErr := dto.Validate()
+	if validationErr != nil {
+		return modificationCS{}, validationErr
+	}
+	return *dto, nil
+}
+
+type modificationCS struct {
+	// how to treat changes that break running processes
+	Policy policyCS `mapstructure:"policy"`
+}
+
+type policyCS string
+
+const (
+	allowPolicy  = policyCS("allow")
+	refusePolicy = policyCS("refuse")
+)
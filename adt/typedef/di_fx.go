@@ -9,10 +9,11 @@ import (
 var Module = fx.Module("adt/typedef",
 	fx.Provide(
 		fx.Annotate(newService, fx.As(new(API))),
-		fx.Annotate(newPgxDAO, fx.As(new(Repo))),
+		newDAO,
 	),
 	fx.Provide(
 		fx.Private,
+		newModificationCS,
 		newEchoController,
 		newEchoPresenter,
 		fx.Annotate(newRendererStdlib, fx.As(new(te.Renderer))),
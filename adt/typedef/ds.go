@@ -1,8 +1,12 @@
 package typedef
 
 import (
+	"log/slog"
+	"time"
+
 	"orglang/go-runtime/lib/db"
 
+	"orglang/go-runtime/adt/identity"
 	"orglang/go-runtime/adt/uniqsym"
 )
 
@@ -11,10 +15,25 @@ type Repo interface {
 	Update(db.Source, DefRec) error
 	SelectRefs(db.Source) ([]DefRef, error)
 	SelectRecByRef(db.Source, DefRef) (DefRec, error)
+	SelectRecByRN(db.Source, DefRef) (DefRec, error)
+	SelectRevs(db.Source, identity.ADT) ([]RevRec, error)
 	SelectRecsByRefs(db.Source, []DefRef) ([]DefRec, error)
 	SelectRecByQN(db.Source, uniqsym.ADT) (DefRec, error)
 	SelectRecsByQNs(db.Source, []uniqsym.ADT) ([]DefRec, error)
 	SelectEnv(db.Source, []uniqsym.ADT) (map[uniqsym.ADT]DefRec, error)
+	SelectImpact(db.Source, DefRef, []identity.ADT) (ImpactRec, error)
+}
+
+// storage adapter is chosen by the operator
+func newDAO(l *slog.Logger, o db.Operator) Repo {
+	switch o.(type) {
+	case *db.OperatorMem:
+		return newMemDAO(l)
+	case *db.OperatorSqlite:
+		return newSqliteDAO(l)
+	default:
+		return newPgxDAO(l)
+	}
 }
 
 type defRefDS struct {
@@ -23,8 +42,16 @@ type defRefDS struct {
 }
 
 type defRecDS struct {
-	ID    string `db:"def_id"`
-	RN    int64  `db:"def_rn"`
-	ExpID string `db:"exp_id"`
-	Title string `db:"title"`
+	ID       string   `db:"def_id"`
+	RN       int64    `db:"def_rn"`
+	ExpID    string   `db:"exp_id"`
+	Title    string   `db:"title"`
+	TypeVars []string `db:"type_vars"`
+	IdxVars  []string `db:"idx_vars"`
+}
+
+type revRecDS struct {
+	ID    string    `db:"def_id"`
+	RN    int64     `db:"def_rn"`
+	RevAt time.Time `db:"rev_at"`
 }
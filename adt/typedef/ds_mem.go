@@ -0,0 +1,257 @@
+package typedef
+
+import (
+	"cmp"
+	"log/slog"
+	"reflect"
+	"slices"
+	"time"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/procbind"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/syndec"
+	"orglang/go-runtime/adt/uniqref"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(l *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{l.With(name)}
+}
+
+func (dao *memDAO) Insert(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("defRef", rec.DefRef)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	db.InsertMem(ds, typeDefs, dto)
+	db.InsertMem(ds, defRevs, revRecDS{ID: dto.ID, RN: dto.RN, RevAt: time.Now()})
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", refAttr)
+	return nil
+}
+
+// each revision is stored as a separate row
+func (dao *memDAO) Update(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("defRef", rec.DefRef)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	prev, err := selectLatest(ds, dto.ID)
+	if err != nil || prev.RN != dto.RN-1 {
+		dao.log.Error("entity update failed", refAttr)
+		return errOptimisticUpdate(rec.DefRef.RN - 1)
+	}
+	db.InsertMem(ds, typeDefs, dto)
+	db.InsertMem(ds, defRevs, revRecDS{ID: dto.ID, RN: dto.RN, RevAt: time.Now()})
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity update succeed", refAttr)
+	return nil
+}
+
+func (dao *memDAO) SelectRefs(source db.Source) ([]DefRef, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	latest := db.LatestMem(db.SelectMem[defRecDS](ds, typeDefs),
+		func(dto defRecDS) string { return dto.ID },
+		func(dto defRecDS) int64 { return dto.RN },
+	)
+	dtos := make([]defRefDS, 0, len(latest))
+	for _, dto := range latest {
+		dtos = append(dtos, defRefDS{ID: dto.ID, RN: dto.RN})
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToDefRefs(dtos)
+}
+
+func (dao *memDAO) SelectRecByRef(source db.Source, defRef DefRef) (DefRec, error) {
+	recs, err := dao.SelectRecsByRefs(source, []DefRef{defRef})
+	if err != nil {
+		return DefRec{}, err
+	}
+	return recs[0], nil
+}
+
+func (dao *memDAO) SelectRecsByRefs(source db.Source, defRefs []DefRef) ([]DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	dtos := make([]defRecDS, 0, len(defRefs))
+	for _, defRef := range defRefs {
+		if defRef.ID.IsEmpty() {
+			return nil, identity.ErrEmpty
+		}
+		dto, err := selectLatest(ds, defRef.ID.String())
+		if err != nil {
+			dao.log.Error("entity selection failed", slog.Any("defRef", defRef))
+			return nil, err
+		}
+		dtos = append(dtos, dto)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToDefRecs(dtos)
+}
+
+func (dao *memDAO) SelectRecByRN(source db.Source, defRef DefRef) (DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("defRef", defRef)
+	if defRef.ID.IsEmpty() {
+		return DefRec{}, identity.ErrEmpty
+	}
+	defID, defRN := defRef.ID.String(), revnum.ConvertToInt(defRef.RN)
+	dto, err := db.SelectLatestMem(ds, typeDefs,
+		func(dto defRecDS) bool { return dto.ID == defID && dto.RN == defRN },
+		func(dto defRecDS) int64 { return dto.RN },
+	)
+	if err != nil {
+		dao.log.Error("entity selection failed", refAttr)
+		return DefRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", refAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *memDAO) SelectRevs(source db.Source, defID identity.ADT) ([]RevRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	dtos := []revRecDS{}
+	for _, dto := range db.SelectMem[revRecDS](ds, defRevs) {
+		if dto.ID == defID.String() {
+			dtos = append(dtos, dto)
+		}
+	}
+	slices.SortFunc(dtos, func(a, b revRecDS) int { return cmp.Compare(a.RN, b.RN) })
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToRevRecs(dtos)
+}
+
+func (dao *memDAO) SelectRecByQN(source db.Source, typeQN uniqsym.ADT) (DefRec, error) {
+	recs, err := dao.SelectRecsByQNs(source, []uniqsym.ADT{typeQN})
+	if err != nil {
+		return DefRec{}, err
+	}
+	return recs[0], nil
+}
+
+func (dao *memDAO) SelectRecsByQNs(source db.Source, typeQNs []uniqsym.ADT) ([]DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	dtos := make([]defRecDS, 0, len(typeQNs))
+	for _, typeQN := range typeQNs {
+		qnAttr := slog.Any("typeQN", typeQN)
+		defID, err := syndec.SelectIDByQN(ds, uniqsym.ConvertToString(typeQN))
+		if err != nil {
+			dao.log.Error("entity selection failed", qnAttr)
+			return nil, err
+		}
+		dto, err := selectLatest(ds, defID)
+		if err != nil {
+			dao.log.Error("entity selection failed", qnAttr)
+			return nil, err
+		}
+		dtos = append(dtos, dto)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToDefRecs(dtos)
+}
+
+func (dao *memDAO) SelectEnv(source db.Source, typeQNs []uniqsym.ADT) (map[uniqsym.ADT]DefRec, error) {
+	recs, err := dao.SelectRecsByQNs(source, typeQNs)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[uniqsym.ADT]DefRec, len(recs))
+	for i, rec := range recs {
+		env[typeQNs[i]] = rec
+	}
+	return env, nil
+}
+
+func (dao *memDAO) SelectImpact(source db.Source, ref DefRef, expIDs []identity.ADT) (ImpactRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	typeQNs := syndec.SelectQNsByID(ds, ref.ID.String())
+	// declarations whose current binds refer to the type name
+	decUses := db.SelectMem[UseMem](ds, DecUsesMem)
+	latestDecs := db.LatestMem(decUses,
+		func(dto UseMem) string { return dto.Ref.ID },
+		func(dto UseMem) int64 { return dto.Ref.RN },
+	)
+	decDtos := []uniqref.Data{}
+	for _, dec := range latestDecs {
+		for _, use := range decUses {
+			if use.Ref == dec.Ref && slices.Contains(typeQNs, use.TypeQN) {
+				decDtos = append(decDtos, use.Ref)
+				break
+			}
+		}
+	}
+	// the definition shares its identity with the declaration
+	defDtos := []uniqref.Data{}
+	for _, def := range db.SelectMem[uniqref.Data](ds, DefRefsMem) {
+		if slices.ContainsFunc(decUses, func(use UseMem) bool {
+			return use.Ref.ID == def.ID && slices.Contains(typeQNs, use.TypeQN)
+		}) {
+			defDtos = append(defDtos, def)
+		}
+	}
+	// live executions bound to the current expression
+	stateIDs := make([]string, 0, len(expIDs))
+	for _, expID := range expIDs {
+		stateIDs = append(stateIDs, expID.String())
+	}
+	binds := db.LatestMem(db.SelectMem[procbind.BindRecDS](ds, procbind.BindsMem),
+		func(dto procbind.BindRecDS) [2]string { return [2]string{dto.ID, dto.ChnlPH} },
+		func(dto procbind.BindRecDS) int64 { return dto.RN },
+	)
+	execDtos := []uniqref.Data{}
+	for _, exec := range db.SelectMem[uniqref.Data](ds, procbind.ExecsMem) {
+		if slices.ContainsFunc(binds, func(bind procbind.BindRecDS) bool {
+			return bind.ID == exec.ID && bind.RN > 0 && slices.Contains(stateIDs, bind.ExpID)
+		}) {
+			execDtos = append(execDtos, exec)
+		}
+	}
+	refs := make([][]uniqref.ADT, 0, 3)
+	for _, dtos := range [][]uniqref.Data{decDtos, defDtos, execDtos} {
+		adts, err := uniqref.DataToADTs(dtos)
+		if err != nil {
+			dao.log.Error("model conversion failed", slog.Any("defRef", ref))
+			return ImpactRec{}, err
+		}
+		refs = append(refs, adts)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("defRef", ref))
+	return ImpactRec{DecRefs: refs[0], DefRefs: refs[1], ExecRefs: refs[2]}, nil
+}
+
+func selectLatest(ds db.SourceMem, defID string) (defRecDS, error) {
+	return db.SelectLatestMem(ds, typeDefs,
+		func(dto defRecDS) bool { return dto.ID == defID },
+		func(dto defRecDS) int64 { return dto.RN },
+	)
+}
+
+// Reference from an entity to a type name, kept by in-memory
+// process declaration and definition stores for impact assessment.
+type UseMem struct {
+	Ref    uniqref.Data
+	TypeQN string
+}
+
+const (
+	typeDefs = "type_defs"
+	// revRecDS rows
+	defRevs = "type_def_revs"
+	// UseMem rows
+	DecUsesMem = "dec_type_uses"
+	// uniqref.Data rows
+	DefRefsMem = "proc_def_refs"
+)
@@ -3,7 +3,6 @@ package typedef
 import (
 	"errors"
 	"log/slog"
-	"math"
 	"reflect"
 
 	"github.com/jackc/pgx/v5"
@@ -12,6 +11,8 @@ import (
 	"orglang/go-runtime/lib/lf"
 
 	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/uniqref"
 	"orglang/go-runtime/adt/uniqsym"
 )
 
@@ -38,37 +39,17 @@ func (dao *pgxDAO) Insert(source db.Source, rec DefRec) error {
 		dao.log.Error("model conversion failed", refAttr)
 		return err
 	}
-	insertRoot := `
-		insert into type_def_roots (
-			def_id, def_rn, title
-		) values (
-			@def_id, @def_rn, @title
-		)`
-	rootArgs := pgx.NamedArgs{
-		"def_id": dto.ID,
-		"def_rn": dto.RN,
-		"title":  dto.Title,
-	}
-	_, err = ds.Conn.Exec(ds.Ctx, insertRoot, rootArgs)
-	if err != nil {
-		dao.log.Error("query execution failed", refAttr, slog.String("q", insertRoot))
-		return err
-	}
-	insertState := `
-		insert into type_term_states (
-			def_id, exp_id, from_rn, to_rn
-		) values (
-			@def_id, @exp_id, @from_rn, @to_rn
-		)`
-	stateArgs := pgx.NamedArgs{
-		"def_id":  dto.ID,
-		"from_rn": dto.RN,
-		"to_rn":   math.MaxInt64,
-		"exp_id":  dto.ExpID,
+	args := pgx.NamedArgs{
+		"def_id":    dto.ID,
+		"def_rn":    dto.RN,
+		"title":     dto.Title,
+		"exp_id":    dto.ExpID,
+		"type_vars": dto.TypeVars,
+		"idx_vars":  dto.IdxVars,
 	}
-	_, err = ds.Conn.Exec(ds.Ctx, insertState, stateArgs)
+	_, err = ds.Conn.Exec(ds.Ctx, insertRec, args)
 	if err != nil {
-		dao.log.Error("query execution failed", refAttr, slog.String("q", insertState))
+		dao.log.Error("query execution failed", refAttr, slog.String("q", insertRec))
 		return err
 	}
 	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", refAttr)
@@ -84,51 +65,32 @@ func (dao *pgxDAO) Update(source db.Source, rec DefRec) error {
 		dao.log.Error("model conversion failed", refAttr)
 		return err
 	}
-	updateRoot := `
-		update type_def_roots
-		set def_rn = @def_rn,
-			exp_id = @exp_id
-		where def_id = @def_id
-			and def_rn = @def_rn - 1`
-	insertSnap := `
-		insert into role_snaps (
-			def_id, def_rn, title, exp_id
-		) values (
-			@def_id, @def_rn, @title, @exp_id
-		)`
 	args := pgx.NamedArgs{
-		"def_id": dto.ID,
-		"def_rn": dto.RN,
-		"title":  dto.Title,
-		"exp_id": dto.ExpID,
+		"def_id":    dto.ID,
+		"def_rn":    dto.RN,
+		"title":     dto.Title,
+		"exp_id":    dto.ExpID,
+		"type_vars": dto.TypeVars,
+		"idx_vars":  dto.IdxVars,
 	}
-	ct, err := ds.Conn.Exec(ds.Ctx, updateRoot, args)
+	ct, err := ds.Conn.Exec(ds.Ctx, updateRec, args)
 	if err != nil {
-		dao.log.Error("query execution failed", refAttr, slog.String("q", updateRoot))
+		dao.log.Error("query execution failed", refAttr, slog.String("q", updateRec))
 		return err
 	}
 	if ct.RowsAffected() == 0 {
 		dao.log.Error("entity update failed", refAttr)
 		return errOptimisticUpdate(rec.DefRef.RN - 1)
 	}
-	_, err = ds.Conn.Exec(ds.Ctx, insertSnap, args)
-	if err != nil {
-		dao.log.Error("query execution failed", refAttr, slog.String("q", insertSnap))
-		return err
-	}
 	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity update succeed", refAttr)
 	return nil
 }
 
 func (dao *pgxDAO) SelectRefs(source db.Source) ([]DefRef, error) {
 	ds := db.MustConform[db.SourcePgx](source)
-	query := `
-		SELECT
-			def_id, def_rn, title
-		FROM type_defs`
-	rows, err := ds.Conn.Query(ds.Ctx, query)
+	rows, err := ds.Conn.Query(ds.Ctx, selectRefs)
 	if err != nil {
-		dao.log.Error("query execution failed", slog.String("q", query))
+		dao.log.Error("query execution failed", slog.String("q", selectRefs))
 		return nil, err
 	}
 	defer rows.Close()
@@ -159,6 +121,42 @@ func (dao *pgxDAO) SelectRecByRef(source db.Source, defRef DefRef) (DefRec, erro
 	return DataToDefRec(dto)
 }
 
+func (dao *pgxDAO) SelectRecByRN(source db.Source, defRef DefRef) (DefRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("defRef", defRef)
+	rows, err := ds.Conn.Query(ds.Ctx, selectByRN, defRef.ID.String(), revnum.ConvertToInt(defRef.RN))
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", selectByRN))
+		return DefRec{}, err
+	}
+	defer rows.Close()
+	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[defRecDS])
+	if err != nil {
+		dao.log.Error("row collection failed", refAttr)
+		return DefRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", refAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *pgxDAO) SelectRevs(source db.Source, defID identity.ADT) ([]RevRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("defID", defID)
+	rows, err := ds.Conn.Query(ds.Ctx, selectRevs, defID.String())
+	if err != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("q", selectRevs))
+		return nil, err
+	}
+	defer rows.Close()
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[revRecDS])
+	if err != nil {
+		dao.log.Error("rows collection failed", idAttr)
+		return nil, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToRevRecs(dtos)
+}
+
 func (dao *pgxDAO) SelectRecByQN(source db.Source, typeQN uniqsym.ADT) (DefRec, error) {
 	ds := db.MustConform[db.SourcePgx](source)
 	fqnAttr := slog.Any("typeQN", typeQN)
@@ -182,17 +180,12 @@ func (dao *pgxDAO) SelectRecsByRefs(source db.Source, defRefs []DefRef) (_ []Def
 	if len(defRefs) == 0 {
 		return []DefRec{}, nil
 	}
-	query := `
-		select
-			def_id, def_rn, title, exp_id, whole_id
-		from type_def_roots
-		where def_id = $1`
 	batch := pgx.Batch{}
 	for _, defRef := range defRefs {
 		if defRef.ID.IsEmpty() {
 			return nil, identity.ErrEmpty
 		}
-		batch.Queue(query, defRef.ID.String())
+		batch.Queue(selectById, defRef.ID.String())
 	}
 	br := ds.Conn.SendBatch(ds.Ctx, &batch)
 	defer func() {
@@ -202,7 +195,7 @@ func (dao *pgxDAO) SelectRecsByRefs(source db.Source, defRefs []DefRef) (_ []Def
 	for _, defID := range defRefs {
 		rows, err := br.Query()
 		if err != nil {
-			dao.log.Error("query execution failed", slog.Any("defID", defID), slog.String("q", query))
+			dao.log.Error("query execution failed", slog.Any("defID", defID), slog.String("q", selectById))
 		}
 		defer rows.Close()
 		dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[defRecDS])
@@ -263,36 +256,174 @@ func (dao *pgxDAO) SelectRecsByQNs(source db.Source, typeQNs []uniqsym.ADT) (_ [
 	return DataToDefRecs(dtos)
 }
 
+func (dao *pgxDAO) SelectImpact(source db.Source, ref DefRef, expIDs []identity.ADT) (_ ImpactRec, err error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("defRef", ref)
+	ids := make([]string, 0, len(expIDs))
+	for _, expID := range expIDs {
+		ids = append(ids, expID.String())
+	}
+	args := pgx.NamedArgs{
+		"def_id":  ref.ID.String(),
+		"exp_ids": ids,
+	}
+	queries := []string{selectImpactDecs, selectImpactDefs, selectImpactExecs}
+	batch := pgx.Batch{}
+	for _, query := range queries {
+		batch.Queue(query, args)
+	}
+	br := ds.Conn.SendBatch(ds.Ctx, &batch)
+	defer func() {
+		err = errors.Join(err, br.Close())
+	}()
+	refs := make([][]uniqref.ADT, 0, len(queries))
+	for _, query := range queries {
+		rows, err := br.Query()
+		if err != nil {
+			dao.log.Error("query execution failed", refAttr, slog.String("q", query))
+			return ImpactRec{}, err
+		}
+		dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[uniqref.Data])
+		if err != nil {
+			dao.log.Error("rows collection failed", refAttr)
+			return ImpactRec{}, err
+		}
+		adts, err := uniqref.DataToADTs(dtos)
+		if err != nil {
+			dao.log.Error("model conversion failed", refAttr)
+			return ImpactRec{}, err
+		}
+		refs = append(refs, adts)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", refAttr)
+	return ImpactRec{DecRefs: refs[0], DefRefs: refs[1], ExecRefs: refs[2]}, nil
+}
+
 const (
+	insertRec = `
+		insert into type_defs (
+			def_id, def_rn, title, exp_id, type_vars, idx_vars
+		) values (
+			@def_id, @def_rn, @title, @exp_id, @type_vars, @idx_vars
+		)`
+
+	// each revision is stored as a separate row,
+	// a new one is added only if the previous one is the latest
+	updateRec = `
+		insert into type_defs (
+			def_id, def_rn, title, exp_id, type_vars, idx_vars
+		)
+		select
+			@def_id, @def_rn, @title, @exp_id, @type_vars, @idx_vars
+		where (
+			select max(def_rn)
+			from type_defs
+			where def_id = @def_id
+		) = @def_rn - 1
+		on conflict (def_id, def_rn) do nothing`
+
+	selectRefs = `
+		select
+			def_id, max(def_rn) as def_rn
+		from type_defs
+		group by def_id`
+
 	selectByFQN = `
 		select
-			rr.def_id,
-			rr.def_rn,
-			rr.title,
-			rs.exp_id,
-			null as whole_id
-		from type_def_roots rr
-		left join aliases a
-			on a.id = rr.def_id
-			and a.from_rn >= rr.def_rn
-			and a.to_rn > rr.def_rn
-		left join type_term_states rs
-			on rs.def_id = rr.def_id
-			and rs.from_rn >= rr.def_rn
-			and rs.to_rn > rr.def_rn
-		where a.sym = $1`
+			td.def_id,
+			td.def_rn,
+			td.title,
+			td.exp_id,
+			td.type_vars,
+			td.idx_vars
+		from syn_decs sd
+		join type_defs td
+			on td.def_id = sd.dec_id
+		where sd.dec_qn = $1
+		order by td.def_rn desc
+		limit 1`
 
 	selectById = `
 		select
-			rr.def_id,
-			rr.def_rn,
-			rr.title,
-			rs.exp_id,
-			null as whole_id
-		from type_def_roots rr
-		left join type_term_states rs
-			on rs.def_id = rr.def_id
-			and rs.from_rn >= rr.def_rn
-			and rs.to_rn > rr.def_rn
-		where rr.def_id = $1`
+			def_id,
+			def_rn,
+			title,
+			exp_id,
+			type_vars,
+			idx_vars
+		from type_defs
+		where def_id = $1
+		order by def_rn desc
+		limit 1`
+
+	selectByRN = `
+		select
+			def_id,
+			def_rn,
+			title,
+			exp_id,
+			type_vars,
+			idx_vars
+		from type_defs
+		where def_id = $1
+			and def_rn = $2`
+
+	selectRevs = `
+		select
+			def_id, def_rn, rev_at
+		from type_defs
+		where def_id = $1
+		order by def_rn`
+
+	// declarations whose current binds refer to the type name
+	selectImpactDecs = `
+		select distinct
+			d.dec_id as id,
+			d.dec_rn as rn
+		from proc_decs d
+		join (
+			select dec_id, type_qn, from_rn, to_rn from dec_pes
+			union all
+			select dec_id, type_qn, from_rn, to_rn from dec_ces
+		) b
+			on b.dec_id = d.dec_id
+			and b.from_rn <= d.dec_rn
+			and b.to_rn > d.dec_rn
+		join syn_decs sd
+			on sd.dec_qn = b.type_qn
+		where sd.dec_id = @def_id`
+
+	// the definition shares its identity with the declaration
+	selectImpactDefs = `
+		select
+			pd.def_id as id,
+			pd.def_rn as rn
+		from proc_defs pd
+		where pd.def_id in (
+			select b.dec_id
+			from (
+				select dec_id, type_qn from dec_pes
+				union all
+				select dec_id, type_qn from dec_ces
+			) b
+			join syn_decs sd
+				on sd.dec_qn = b.type_qn
+			where sd.dec_id = @def_id
+		)`
+
+	selectImpactExecs = `
+		with binds as (
+			select distinct on (exec_id, chnl_ph)
+				exec_id, exec_rn, state_id
+			from proc_binds
+			order by exec_id, chnl_ph, abs(exec_rn) desc
+		)
+		select distinct
+			e.exec_id as id,
+			e.exec_rn as rn
+		from binds b
+		join proc_execs e
+			on e.exec_id = b.exec_id
+		where b.exec_rn > 0
+			and b.state_id = any(@exp_ids)`
 )
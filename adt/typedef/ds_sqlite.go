@@ -0,0 +1,367 @@
+package typedef
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/uniqref"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+// Adapter
+type sqliteDAO struct {
+	log *slog.Logger
+}
+
+func newSqliteDAO(l *slog.Logger) *sqliteDAO {
+	name := slog.String("name", reflect.TypeFor[sqliteDAO]().Name())
+	return &sqliteDAO{l.With(name)}
+}
+
+func (dao *sqliteDAO) Insert(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("defRef", rec.DefRef)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion started", refAttr)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertRecSqlite, recArgsSqlite(dto))
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", insertRecSqlite))
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", refAttr)
+	return nil
+}
+
+// each revision is stored as a separate row
+func (dao *sqliteDAO) Update(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("defRef", rec.DefRef)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity update started", refAttr)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	res, err := ds.Conn.Exec(ds.Ctx, updateRecSqlite, recArgsSqlite(dto))
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", updateRecSqlite))
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		dao.log.Error("entity update failed", refAttr)
+		return errOptimisticUpdate(rec.DefRef.RN - 1)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity update succeed", refAttr)
+	return nil
+}
+
+func (dao *sqliteDAO) SelectRefs(source db.Source) ([]DefRef, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	rows, err := ds.Conn.Query(ds.Ctx, selectRefsSqlite, nil)
+	if err != nil {
+		dao.log.Error("query execution failed", slog.String("q", selectRefsSqlite))
+		return nil, err
+	}
+	dtos, err := db.CollectRowsSqlite[defRefDS](rows)
+	if err != nil {
+		dao.log.Error("rows collection failed")
+		return nil, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToDefRefs(dtos)
+}
+
+func (dao *sqliteDAO) SelectRecByRef(source db.Source, defRef DefRef) (DefRec, error) {
+	recs, err := dao.SelectRecsByRefs(source, []DefRef{defRef})
+	if err != nil {
+		return DefRec{}, err
+	}
+	return recs[0], nil
+}
+
+func (dao *sqliteDAO) SelectRecsByRefs(source db.Source, defRefs []DefRef) ([]DefRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	dtos := make([]defRecDS, 0, len(defRefs))
+	for _, defRef := range defRefs {
+		if defRef.ID.IsEmpty() {
+			return nil, identity.ErrEmpty
+		}
+		refAttr := slog.Any("defRef", defRef)
+		rows, err := ds.Conn.Query(ds.Ctx, selectByIDSqlite, db.NamedArgsSqlite{"def_id": defRef.ID.String()})
+		if err != nil {
+			dao.log.Error("query execution failed", refAttr, slog.String("q", selectByIDSqlite))
+			return nil, err
+		}
+		dto, err := db.CollectOneRowSqlite[defRecDS](rows)
+		if err != nil {
+			dao.log.Error("row collection failed", refAttr)
+			return nil, err
+		}
+		dtos = append(dtos, dto)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToDefRecs(dtos)
+}
+
+func (dao *sqliteDAO) SelectRecByRN(source db.Source, defRef DefRef) (DefRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("defRef", defRef)
+	args := db.NamedArgsSqlite{"def_id": defRef.ID.String(), "def_rn": revnum.ConvertToInt(defRef.RN)}
+	rows, err := ds.Conn.Query(ds.Ctx, selectByRNSqlite, args)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", selectByRNSqlite))
+		return DefRec{}, err
+	}
+	dto, err := db.CollectOneRowSqlite[defRecDS](rows)
+	if err != nil {
+		dao.log.Error("row collection failed", refAttr)
+		return DefRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", refAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *sqliteDAO) SelectRevs(source db.Source, defID identity.ADT) ([]RevRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	idAttr := slog.Any("defID", defID)
+	rows, err := ds.Conn.Query(ds.Ctx, selectRevsSqlite, db.NamedArgsSqlite{"def_id": defID.String()})
+	if err != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("q", selectRevsSqlite))
+		return nil, err
+	}
+	dtos, err := db.CollectRowsSqlite[revRecDS](rows)
+	if err != nil {
+		dao.log.Error("rows collection failed", idAttr)
+		return nil, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToRevRecs(dtos)
+}
+
+func (dao *sqliteDAO) SelectRecByQN(source db.Source, typeQN uniqsym.ADT) (DefRec, error) {
+	recs, err := dao.SelectRecsByQNs(source, []uniqsym.ADT{typeQN})
+	if err != nil {
+		return DefRec{}, err
+	}
+	return recs[0], nil
+}
+
+func (dao *sqliteDAO) SelectRecsByQNs(source db.Source, typeQNs []uniqsym.ADT) ([]DefRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	dtos := make([]defRecDS, 0, len(typeQNs))
+	for _, typeQN := range typeQNs {
+		qnAttr := slog.Any("typeQN", typeQN)
+		args := db.NamedArgsSqlite{"type_qn": uniqsym.ConvertToString(typeQN)}
+		rows, err := ds.Conn.Query(ds.Ctx, selectByQNSqlite, args)
+		if err != nil {
+			dao.log.Error("query execution failed", qnAttr, slog.String("q", selectByQNSqlite))
+			return nil, err
+		}
+		dto, err := db.CollectOneRowSqlite[defRecDS](rows)
+		if err != nil {
+			dao.log.Error("row collection failed", qnAttr)
+			return nil, err
+		}
+		dtos = append(dtos, dto)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToDefRecs(dtos)
+}
+
+func (dao *sqliteDAO) SelectEnv(source db.Source, typeQNs []uniqsym.ADT) (map[uniqsym.ADT]DefRec, error) {
+	recs, err := dao.SelectRecsByQNs(source, typeQNs)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[uniqsym.ADT]DefRec, len(recs))
+	for i, rec := range recs {
+		env[typeQNs[i]] = rec
+	}
+	return env, nil
+}
+
+func (dao *sqliteDAO) SelectImpact(source db.Source, ref DefRef, expIDs []identity.ADT) (ImpactRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("defRef", ref)
+	ids := make([]string, 0, len(expIDs))
+	for _, expID := range expIDs {
+		ids = append(ids, expID.String())
+	}
+	refs := make([][]uniqref.ADT, 0, 3)
+	queries := []string{selectImpactDecsSqlite, selectImpactDefsSqlite, selectImpactExecsSqlite}
+	argss := []db.NamedArgsSqlite{{"def_id": ref.ID.String()}, {"def_id": ref.ID.String()}, {"exp_ids": ids}}
+	for i, query := range queries {
+		rows, err := ds.Conn.Query(ds.Ctx, query, argss[i])
+		if err != nil {
+			dao.log.Error("query execution failed", refAttr, slog.String("q", query))
+			return ImpactRec{}, err
+		}
+		dtos, err := db.CollectRowsSqlite[uniqref.Data](rows)
+		if err != nil {
+			dao.log.Error("rows collection failed", refAttr)
+			return ImpactRec{}, err
+		}
+		adts, err := uniqref.DataToADTs(dtos)
+		if err != nil {
+			dao.log.Error("model conversion failed", refAttr)
+			return ImpactRec{}, err
+		}
+		refs = append(refs, adts)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", refAttr)
+	return ImpactRec{DecRefs: refs[0], DefRefs: refs[1], ExecRefs: refs[2]}, nil
+}
+
+func recArgsSqlite(dto defRecDS) db.NamedArgsSqlite {
+	return db.NamedArgsSqlite{
+		"def_id":    dto.ID,
+		"def_rn":    dto.RN,
+		"title":     dto.Title,
+		"exp_id":    dto.ExpID,
+		"type_vars": dto.TypeVars,
+		"idx_vars":  dto.IdxVars,
+	}
+}
+
+const (
+	// the revision time is set here, see 0002_revisions.sql
+	insertRecSqlite = `
+		insert into type_defs (
+			def_id, def_rn, title, exp_id, type_vars, idx_vars, rev_at
+		) values (
+			:def_id, :def_rn, :title, :exp_id, :type_vars, :idx_vars,
+			strftime('%Y-%m-%d %H:%M:%f000', 'now')
+		)`
+
+	// a new revision is added only if the previous one is the latest
+	updateRecSqlite = `
+		insert into type_defs (
+			def_id, def_rn, title, exp_id, type_vars, idx_vars, rev_at
+		)
+		select
+			:def_id, :def_rn, :title, :exp_id, :type_vars, :idx_vars,
+			strftime('%Y-%m-%d %H:%M:%f000', 'now')
+		where (
+			select max(def_rn)
+			from type_defs
+			where def_id = :def_id
+		) = :def_rn - 1`
+
+	selectRefsSqlite = `
+		select
+			def_id, max(def_rn) as def_rn
+		from type_defs
+		group by def_id
+		order by def_id`
+
+	selectByIDSqlite = `
+		select
+			def_id, def_rn, exp_id, title, type_vars, idx_vars
+		from type_defs
+		where def_id = :def_id
+		order by def_rn desc
+		limit 1`
+
+	selectByRNSqlite = `
+		select
+			def_id, def_rn, exp_id, title, type_vars, idx_vars
+		from type_defs
+		where def_id = :def_id
+			and def_rn = :def_rn`
+
+	selectRevsSqlite = `
+		select
+			def_id, def_rn, rev_at
+		from type_defs
+		where def_id = :def_id
+		order by def_rn`
+
+	selectByQNSqlite = `
+		select
+			td.def_id, td.def_rn, td.exp_id, td.title, td.type_vars, td.idx_vars
+		from (
+			select dec_id
+			from syn_decs
+			where dec_qn = :type_qn
+			order by from_rn desc
+			limit 1
+		) sd
+		join type_defs td
+			on td.def_id = sd.dec_id
+		order by td.def_rn desc
+		limit 1`
+
+	// declarations whose current binds refer to the type name
+	selectImpactDecsSqlite = `
+		select distinct
+			d.dec_id as id,
+			d.dec_rn as rn
+		from (
+			select dec_id, max(dec_rn) as dec_rn
+			from proc_decs
+			group by dec_id
+		) d
+		join (
+			select dec_id, type_qn, from_rn, to_rn from dec_pes
+			union all
+			select dec_id, type_qn, from_rn, to_rn from dec_ces
+		) b
+			on b.dec_id = d.dec_id
+			and b.from_rn <= d.dec_rn
+			and b.to_rn > d.dec_rn
+		join syn_decs sd
+			on sd.dec_qn = b.type_qn
+		where sd.dec_id = :def_id`
+
+	// the definition shares its identity with the declaration
+	selectImpactDefsSqlite = `
+		select
+			pd.def_id as id,
+			pd.def_rn as rn
+		from proc_defs pd
+		where pd.def_id in (
+			select b.dec_id
+			from (
+				select dec_id, type_qn from dec_pes
+				union all
+				select dec_id, type_qn from dec_ces
+			) b
+			join syn_decs sd
+				on sd.dec_qn = b.type_qn
+			where sd.dec_id = :def_id
+		)`
+
+	// removed binds carry negative revision
+	selectImpactExecsSqlite = `
+		with binds as (
+			select
+				exec_id, exec_rn, state_id,
+				row_number() over (
+					partition by exec_id, chnl_ph
+					order by abs(exec_rn) desc
+				) as pos
+			from proc_binds
+		)
+		select distinct
+			e.exec_id as id,
+			e.exec_rn as rn
+		from binds b
+		join proc_execs e
+			on e.exec_id = b.exec_id
+		where b.pos = 1
+			and b.exec_rn > 0
+			and b.state_id in (select value from json_each(:exp_ids))`
+)
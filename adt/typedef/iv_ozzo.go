@@ -12,3 +12,9 @@ func (dto DefSpecVP) Validate() error {
 		validation.Field(&dto.TypeSN, uniqsym.Required...),
 	)
 }
+
+func (dto modificationCS) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.Policy, validation.Required, validation.In(allowPolicy, refusePolicy)),
+	)
+}
@@ -1,9 +1,11 @@
 package typedef
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"reflect"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
@@ -11,6 +13,7 @@ import (
 
 	"orglang/go-runtime/lib/lf"
 
+	"orglang/go-runtime/adt/revnum"
 	"orglang/go-runtime/adt/uniqref"
 )
 
@@ -29,6 +32,8 @@ func cfgEchoController(e *echo.Echo, h *echoController) error {
 	e.POST("/api/v1/types", h.PostSpec)
 	e.GET("/api/v1/types/:id", h.GetSnap)
 	e.PATCH("/api/v1/types/:id", h.PatchOne)
+	e.GET("/api/v1/types/:id/revisions", h.GetRevs)
+	e.GET("/api/v1/types/:id/diff", h.GetDiff)
 	return nil
 }
 
@@ -51,7 +56,10 @@ func (h *echoController) PostSpec(c echo.Context) error {
 		h.log.Error("conversion failed", slog.Any("dto", dto))
 		return conversionErr
 	}
-	snap, creationErr := h.api.Create(spec)
+	snap, creationErr := h.api.Create(ctx, spec)
+	if errors.As(creationErr, new(DefError)) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, ViewFromDefErrors(creationErr)).SetInternal(creationErr)
+	}
 	if creationErr != nil {
 		return creationErr
 	}
@@ -76,13 +84,91 @@ func (h *echoController) GetSnap(c echo.Context) error {
 		h.log.Error("conversion failed", slog.Any("dto", dto))
 		return conversionErr
 	}
-	snap, retrievalErr := h.api.RetrieveSnap(ref)
+	// the latest revision is returned without parameters
+	var rn int64
+	var at time.Time
+	paramsErr := echo.QueryParamsBinder(c).
+		Int64("rn", &rn).
+		Time("at", &at, time.RFC3339).
+		BindError()
+	if paramsErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", dto))
+		return paramsErr
+	}
+	ctx := c.Request().Context()
+	var snap DefSnap
+	var retrievalErr error
+	switch {
+	case rn != 0:
+		snap, retrievalErr = h.api.RetrieveSnapByRN(ctx, DefRef{ID: ref.ID, RN: revnum.ConvertFromInt(rn)})
+	case !at.IsZero():
+		snap, retrievalErr = h.api.RetrieveSnapAsOf(ctx, ref.ID, at)
+	default:
+		snap, retrievalErr = h.api.RetrieveSnap(ctx, ref)
+	}
 	if retrievalErr != nil {
 		return retrievalErr
 	}
 	return c.JSON(http.StatusOK, MsgFromDefSnap(snap))
 }
 
+func (h *echoController) GetRevs(c echo.Context) error {
+	var dto typedef.DefRef
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	validationErr := dto.Validate()
+	if validationErr != nil {
+		h.log.Error("validation failed", slog.Any("dto", dto))
+		return validationErr
+	}
+	ref, conversionErr := uniqref.MsgToADT(dto)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	revs, retrievalErr := h.api.RetrieveRevs(c.Request().Context(), ref.ID)
+	if retrievalErr != nil {
+		return retrievalErr
+	}
+	return c.JSON(http.StatusOK, ViewFromRevRecs(revs))
+}
+
+func (h *echoController) GetDiff(c echo.Context) error {
+	var dto typedef.DefRef
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	validationErr := dto.Validate()
+	if validationErr != nil {
+		h.log.Error("validation failed", slog.Any("dto", dto))
+		return validationErr
+	}
+	ref, conversionErr := uniqref.MsgToADT(dto)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	var fromRN, toRN int64
+	paramsErr := echo.QueryParamsBinder(c).
+		MustInt64("from", &fromRN).
+		MustInt64("to", &toRN).
+		BindError()
+	if paramsErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", dto))
+		return paramsErr
+	}
+	diff, comparisonErr := h.api.Compare(c.Request().Context(), ref.ID, revnum.ConvertFromInt(fromRN), revnum.ConvertFromInt(toRN))
+	if comparisonErr != nil {
+		return comparisonErr
+	}
+	return c.JSON(http.StatusOK, ViewFromDefDiff(diff))
+}
+
 func (h *echoController) PatchOne(c echo.Context) error {
 	var dto typedef.DefSnap
 	bindingErr := c.Bind(&dto)
@@ -102,7 +188,22 @@ func (h *echoController) PatchOne(c echo.Context) error {
 		h.log.Error("conversion failed", slog.Any("dto", dto))
 		return conversionErr
 	}
-	resSnap, modificationErr := h.api.Modify(reqSnap)
+	// dry run: only the impact report
+	if c.QueryParam("dry_run") == "true" {
+		impact, assessmentErr := h.api.Assess(ctx, reqSnap)
+		if assessmentErr != nil {
+			return assessmentErr
+		}
+		return c.JSON(http.StatusOK, ViewFromImpactRec(impact))
+	}
+	resSnap, modificationErr := h.api.Modify(ctx, reqSnap)
+	var impactErr ImpactError
+	if errors.As(modificationErr, &impactErr) {
+		return echo.NewHTTPError(http.StatusConflict, ViewFromImpactRec(impactErr.Impact)).SetInternal(modificationErr)
+	}
+	if errors.As(modificationErr, new(DefError)) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, ViewFromDefErrors(modificationErr)).SetInternal(modificationErr)
+	}
 	if modificationErr != nil {
 		return modificationErr
 	}
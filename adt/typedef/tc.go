@@ -0,0 +1,93 @@
+package typedef
+
+import (
+	"errors"
+	"fmt"
+
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/typeexp"
+	"orglang/go-runtime/adt/uniqref"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+// unwraps violations joined with errors.Join
+func ViewFromDefErrors(err error) []DefErrorVP {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if ok {
+		var views []DefErrorVP
+		for _, e := range joined.Unwrap() {
+			views = append(views, ViewFromDefErrors(e)...)
+		}
+		return views
+	}
+	var defErr DefError
+	if errors.As(err, &defErr) {
+		return []DefErrorVP{viewFromDefError(defErr)}
+	}
+	return nil
+}
+
+func viewFromDefError(err DefError) DefErrorVP {
+	view := DefErrorVP{
+		K:      viewFromDefErrorKind(err.K),
+		Msg:    err.Error(),
+		TypeQN: uniqsym.ConvertToString(err.TypeQN),
+	}
+	switch err.K {
+	case DanglingLink:
+		view.LinkQN = uniqsym.ConvertToString(err.LinkQN)
+	case UnboundVar, DuplicateVar:
+		view.TypeVar = symbol.ConvertToString(err.TypeVar)
+	case UnboundIdx, DuplicateIdx:
+		view.IdxVar = symbol.ConvertToString(err.IdxVar)
+	}
+	return view
+}
+
+func viewFromDefErrorKind(kind DefErrorKind) string {
+	switch kind {
+	case NotContractive:
+		return "not_contractive"
+	case DanglingLink:
+		return "dangling_link"
+	case UnboundVar:
+		return "unbound_var"
+	case DuplicateVar:
+		return "duplicate_var"
+	case UnboundIdx:
+		return "unbound_idx"
+	case DuplicateIdx:
+		return "duplicate_idx"
+	case NegativePot:
+		return "negative_pot"
+	default:
+		panic(fmt.Errorf("def error kind unexpected: %v", kind))
+	}
+}
+
+func ViewFromImpactRec(rec ImpactRec) ImpactVP {
+	return ImpactVP{
+		DecRefs:  uniqref.MsgFromADTs(rec.DecRefs),
+		DefRefs:  uniqref.MsgFromADTs(rec.DefRefs),
+		ExecRefs: uniqref.MsgFromADTs(rec.ExecRefs),
+	}
+}
+
+// the revision expression is in the view only if it changed
+func ViewFromDefDiff(diff DefDiff) DefDiffVP {
+	view := DefDiffVP{
+		FromRef:         uniqref.MsgFromADT(diff.FromRef),
+		ToRef:           uniqref.MsgFromADT(diff.ToRef),
+		TypeVarsAdded:   symbol.ConvertToStrings(diff.TypeVarsAdded),
+		TypeVarsRemoved: symbol.ConvertToStrings(diff.TypeVarsRemoved),
+		IdxVarsAdded:    symbol.ConvertToStrings(diff.IdxVarsAdded),
+		IdxVarsRemoved:  symbol.ConvertToStrings(diff.IdxVarsRemoved),
+	}
+	if diff.FromES != nil && diff.ToES != nil {
+		fromES := typeexp.MsgFromExpSpec(diff.FromES)
+		toES := typeexp.MsgFromExpSpec(diff.ToES)
+		view.FromES = &fromES
+		view.ToES = &toES
+	}
+	return view
+}
@@ -15,6 +15,7 @@ var (
 
 // goverter:variables
 // goverter:output:format assign-variable
+// goverter:extend orglang/go-runtime/adt/symbol:Convert.*
 // goverter:extend orglang/go-runtime/adt/uniqref:Msg.*
 // goverter:extend orglang/go-runtime/adt/typeexp:Msg.*
 // goverter:extend Msg.*
@@ -33,6 +34,7 @@ var (
 
 // goverter:variables
 // goverter:output:format assign-variable
+// goverter:extend orglang/go-runtime/adt/symbol:Convert.*
 // goverter:extend orglang/go-runtime/adt/uniqref:Msg.*
 // goverter:extend orglang/go-runtime/adt/typeexp:Msg.*
 var (
@@ -41,11 +43,14 @@ var (
 	ViewFromDefRefs func([]DefRef) []DefRefVP
 	ViewToDefRefs   func([]DefRefVP) ([]DefRef, error)
 	ViewFromDefSnap func(DefSnap) DefSnapVP
+	ViewFromRevRec  func(RevRec) RevVP
+	ViewFromRevRecs func([]RevRec) []RevVP
 )
 
 // goverter:variables
 // goverter:output:format assign-variable
 // goverter:extend orglang/go-runtime/adt/identity:Convert.*
+// goverter:extend orglang/go-runtime/adt/symbol:Convert.*
 // goverter:extend orglang/go-runtime/adt/uniqref:Data.*
 // goverter:extend orglang/go-runtime/adt/typeexp:Data.*
 var (
@@ -59,4 +64,7 @@ var (
 	DataFromDefRec  func(DefRec) (defRecDS, error)
 	DataToDefRecs   func([]defRecDS) ([]DefRec, error)
 	DataFromDefRecs func([]DefRec) ([]defRecDS, error)
+	// goverter:map . DefRef
+	DataToRevRec  func(revRecDS) (RevRec, error)
+	DataToRevRecs func([]revRecDS) ([]RevRec, error)
 )
@@ -1,6 +1,8 @@
 package typedef
 
 import (
+	"time"
+
 	"github.com/orglang/go-sdk/adt/typeexp"
 	"github.com/orglang/go-sdk/adt/uniqref"
 )
@@ -13,7 +15,41 @@ type DefSpecVP struct {
 type DefRefVP = uniqref.Msg
 
 type DefSnapVP struct {
-	DefRef DefRefVP        `json:"ref"`
-	Title  string          `json:"title"`
-	TypeES typeexp.ExpSpec `json:"type_es"`
+	DefRef   DefRefVP        `json:"ref"`
+	Title    string          `json:"title"`
+	TypeVars []string        `json:"type_vars,omitempty"`
+	IdxVars  []string        `json:"idx_vars,omitempty"`
+	TypeES   typeexp.ExpSpec `json:"type_es"`
+}
+
+type DefErrorVP struct {
+	K       string `json:"kind"`
+	Msg     string `json:"message"`
+	TypeQN  string `json:"type_qn"`
+	LinkQN  string `json:"link_qn,omitempty"`
+	TypeVar string `json:"type_var,omitempty"`
+	IdxVar  string `json:"idx_var,omitempty"`
+}
+
+type ImpactVP struct {
+	DecRefs  []DefRefVP `json:"decs"`
+	DefRefs  []DefRefVP `json:"defs"`
+	ExecRefs []DefRefVP `json:"execs"`
+}
+
+type RevVP struct {
+	DefRef DefRefVP  `json:"ref"`
+	RevAt  time.Time `json:"rev_at"`
+}
+
+// matching parts of revisions are omitted
+type DefDiffVP struct {
+	FromRef         DefRefVP         `json:"from"`
+	ToRef           DefRefVP         `json:"to"`
+	TypeVarsAdded   []string         `json:"type_vars_added,omitempty"`
+	TypeVarsRemoved []string         `json:"type_vars_removed,omitempty"`
+	IdxVarsAdded    []string         `json:"idx_vars_added,omitempty"`
+	IdxVarsRemoved  []string         `json:"idx_vars_removed,omitempty"`
+	FromES          *typeexp.ExpSpec `json:"from_es,omitempty"`
+	ToES            *typeexp.ExpSpec `json:"to_es,omitempty"`
 }
@@ -1,6 +1,7 @@
 package typedef
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"reflect"
@@ -56,7 +57,18 @@ func (p *echoPresenter) PostOne(c echo.Context) error {
 		p.log.Error("conversion failed", slog.Any("dto", dto))
 		return conversionErr
 	}
-	snap, creationErr := p.api.Create(DefSpec{TypeQN: ns.New(symbol.New(dto.TypeSN)), TypeES: typeexp.OneSpec{}})
+	snap, creationErr := p.api.Create(ctx, DefSpec{TypeQN: ns.New(symbol.New(dto.TypeSN)), TypeES: typeexp.OneSpec{}})
+	if errors.As(creationErr, new(DefError)) {
+		html, renderingErr := p.ssr.Render("problems", ViewFromDefErrors(creationErr))
+		if renderingErr != nil {
+			p.log.Error("rendering failed", slog.Any("reason", creationErr))
+			return renderingErr
+		}
+		// the problems replace the slot above the list, not the list itself
+		c.Response().Header().Set("HX-Retarget", "#problems")
+		c.Response().Header().Set("HX-Reswap", "outerHTML")
+		return c.HTMLBlob(http.StatusUnprocessableEntity, html)
+	}
 	if creationErr != nil {
 		return creationErr
 	}
@@ -70,7 +82,7 @@ func (p *echoPresenter) PostOne(c echo.Context) error {
 }
 
 func (p *echoPresenter) GetMany(c echo.Context) error {
-	refs, retrievalErr := p.api.RetreiveRefs()
+	refs, retrievalErr := p.api.RetreiveRefs(c.Request().Context())
 	if retrievalErr != nil {
 		return retrievalErr
 	}
@@ -101,7 +113,7 @@ func (p *echoPresenter) GetOne(c echo.Context) error {
 		p.log.Error("conversion failed", slog.Any("dto", dto))
 		return conversionErr
 	}
-	snap, retrievalErr := p.api.RetrieveSnap(ref)
+	snap, retrievalErr := p.api.RetrieveSnap(ctx, ref)
 	if retrievalErr != nil {
 		return retrievalErr
 	}
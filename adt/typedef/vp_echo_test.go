@@ -0,0 +1,55 @@
+package typedef
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+type stubAPI struct {
+	API
+	err error
+}
+
+func (a stubAPI) Create(context.Context, DefSpec) (DefSnap, error) {
+	return DefSnap{}, a.err
+}
+
+func TestPostOneProblems(t *testing.T) {
+	l := slog.New(slog.NewTextHandler(io.Discard, nil))
+	r, err := newRendererStdlib(l)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	rejection := DefError{K: NotContractive, TypeQN: uniqsym.New("loop")}
+	p := newEchoPresenter(stubAPI{err: rejection}, r, l)
+	form := url.Values{"ns": {"app"}, "name": {"loop"}}
+	req := httptest.NewRequest(http.MethodPost, "/ssr/types", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	err = p.PostOne(echo.New().NewContext(req, rec))
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("got status %v, want %v", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if got := rec.Header().Get("HX-Retarget"); got != "#problems" {
+		t.Errorf("got retarget %q, want %q", got, "#problems")
+	}
+	if got := rec.Header().Get("HX-Reswap"); got != "outerHTML" {
+		t.Errorf("got reswap %q, want %q", got, "outerHTML")
+	}
+	if !strings.Contains(rec.Body.String(), `id="problems"`) {
+		t.Errorf("got body %q, want problems slot", rec.Body.String())
+	}
+}
@@ -1,11 +1,21 @@
 package typeexp
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 
+	"orglang/go-runtime/adt/arithexp"
 	"orglang/go-runtime/adt/identity"
 	"orglang/go-runtime/adt/polarity"
 	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
 	"orglang/go-runtime/adt/uniqsym"
 )
 
@@ -20,34 +30,49 @@ func (OneSpec) spec() {}
 // aka TpName
 type LinkSpec struct {
 	TypeQN uniqsym.ADT
+	// type arguments
+	TypeESs []ExpSpec
+	// index arguments
+	IdxESs []arithexp.ExpSpec
 }
 
 func (LinkSpec) spec() {}
 
+// aka TpVar
+type VarSpec struct {
+	TypeVar symbol.ADT
+}
+
+func (VarSpec) spec() {}
+
 type TensorSpec struct {
-	Y ExpSpec // val to send
-	Z ExpSpec // cont
+	Y   ExpSpec // val to send
+	Z   ExpSpec // cont
+	Pot int64   // potential to send
 }
 
 func (TensorSpec) spec() {}
 
 type LolliSpec struct {
-	Y ExpSpec // val to receive
-	Z ExpSpec // cont
+	Y   ExpSpec // val to receive
+	Z   ExpSpec // cont
+	Pot int64   // potential to receive
 }
 
 func (LolliSpec) spec() {}
 
 // aka Internal Choice
 type PlusSpec struct {
-	Zs map[uniqsym.ADT]ExpSpec // conts
+	Zs  map[uniqsym.ADT]ExpSpec // conts
+	Pot int64                   // potential to send
 }
 
 func (PlusSpec) spec() {}
 
 // aka External Choice
 type WithSpec struct {
-	Zs map[uniqsym.ADT]ExpSpec // conts
+	Zs  map[uniqsym.ADT]ExpSpec // conts
+	Pot int64                   // potential to receive
 }
 
 func (WithSpec) spec() {}
@@ -64,6 +89,86 @@ type DownSpec struct {
 
 func (DownSpec) spec() {}
 
+// aka TpExists: the provider sends a natural
+type ExistsSpec struct {
+	IdxVar symbol.ADT
+	Z      ExpSpec // cont
+}
+
+func (ExistsSpec) spec() {}
+
+// aka TpForall: the provider receives a natural
+type ForallSpec struct {
+	IdxVar symbol.ADT
+	Z      ExpSpec // cont
+}
+
+func (ForallSpec) spec() {}
+
+// aka TpAssert: the provider guarantees a constraint
+type AssertSpec struct {
+	Prop arithexp.PropSpec
+	Z    ExpSpec // cont
+}
+
+func (AssertSpec) spec() {}
+
+// aka TpAssume: the provider relies on a constraint
+type AssumeSpec struct {
+	Prop arithexp.PropSpec
+	Z    ExpSpec // cont
+}
+
+func (AssumeSpec) spec() {}
+
+// aka TpNext: the continuation comes after one tick
+type NextSpec struct {
+	Z ExpSpec // cont
+}
+
+func (NextSpec) spec() {}
+
+// aka TpBox: the provider is ready at any time, the client picks the moment
+type BoxSpec struct {
+	Z ExpSpec // cont
+}
+
+func (BoxSpec) spec() {}
+
+// aka TpDiamond: the provider picks the moment, the client waits
+type DiamondSpec struct {
+	Z ExpSpec // cont
+}
+
+func (DiamondSpec) spec() {}
+
+// aka TpAnd: the provider sends a value
+type AndSpec struct {
+	T DataType
+	Z ExpSpec // cont
+}
+
+func (AndSpec) spec() {}
+
+// aka TpImp: the provider receives a value
+type ImplSpec struct {
+	T DataType
+	Z ExpSpec // cont
+}
+
+func (ImplSpec) spec() {}
+
+// functional type of values passed in messages
+type DataType uint8
+
+const (
+	NonData DataType = iota
+	IntData
+	StrData
+	BoolData
+	JSONData
+)
+
 type ExpRef interface {
 	identity.Identifiable
 }
@@ -80,6 +185,12 @@ type LinkRef struct {
 
 func (r LinkRef) Ident() identity.ADT { return r.ExpID }
 
+type VarRef struct {
+	ExpID identity.ADT
+}
+
+func (r VarRef) Ident() identity.ADT { return r.ExpID }
+
 type PlusRef struct {
 	ExpID identity.ADT
 }
@@ -116,6 +227,60 @@ type DownRef struct {
 
 func (r DownRef) Ident() identity.ADT { return r.ExpID }
 
+type ExistsRef struct {
+	ExpID identity.ADT
+}
+
+func (r ExistsRef) Ident() identity.ADT { return r.ExpID }
+
+type ForallRef struct {
+	ExpID identity.ADT
+}
+
+func (r ForallRef) Ident() identity.ADT { return r.ExpID }
+
+type AssertRef struct {
+	ExpID identity.ADT
+}
+
+func (r AssertRef) Ident() identity.ADT { return r.ExpID }
+
+type AssumeRef struct {
+	ExpID identity.ADT
+}
+
+func (r AssumeRef) Ident() identity.ADT { return r.ExpID }
+
+type NextRef struct {
+	ExpID identity.ADT
+}
+
+func (r NextRef) Ident() identity.ADT { return r.ExpID }
+
+type BoxRef struct {
+	ExpID identity.ADT
+}
+
+func (r BoxRef) Ident() identity.ADT { return r.ExpID }
+
+type DiamondRef struct {
+	ExpID identity.ADT
+}
+
+func (r DiamondRef) Ident() identity.ADT { return r.ExpID }
+
+type AndRef struct {
+	ExpID identity.ADT
+}
+
+func (r AndRef) Ident() identity.ADT { return r.ExpID }
+
+type ImplRef struct {
+	ExpID identity.ADT
+}
+
+func (r ImplRef) Ident() identity.ADT { return r.ExpID }
+
 // aka Stype
 type ExpRec interface {
 	identity.Identifiable
@@ -142,8 +307,10 @@ func (OneRec) Pol() polarity.ADT { return polarity.Pos }
 
 // aka TpName
 type LinkRec struct {
-	ExpID  identity.ADT
-	TypeQN uniqsym.ADT
+	ExpID   identity.ADT
+	TypeQN  uniqsym.ADT
+	TypeERs []ExpRec
+	IdxESs  []arithexp.ExpSpec
 }
 
 func (LinkRec) spec() {}
@@ -152,10 +319,23 @@ func (r LinkRec) Ident() identity.ADT { return r.ExpID }
 
 func (LinkRec) Pol() polarity.ADT { return polarity.Zero }
 
+// aka TpVar
+type VarRec struct {
+	ExpID   identity.ADT
+	TypeVar symbol.ADT
+}
+
+func (VarRec) spec() {}
+
+func (r VarRec) Ident() identity.ADT { return r.ExpID }
+
+func (VarRec) Pol() polarity.ADT { return polarity.Zero }
+
 // aka Internal Choice
 type PlusRec struct {
 	ExpID identity.ADT
 	Zs    map[uniqsym.ADT]ExpRec
+	Pot   int64
 }
 
 func (PlusRec) spec() {}
@@ -170,6 +350,7 @@ func (PlusRec) Pol() polarity.ADT { return polarity.Pos }
 type WithRec struct {
 	ExpID identity.ADT
 	Zs    map[uniqsym.ADT]ExpRec
+	Pot   int64
 }
 
 func (WithRec) spec() {}
@@ -184,6 +365,7 @@ type TensorRec struct {
 	ExpID identity.ADT
 	Y     ExpRec
 	Z     ExpRec
+	Pot   int64
 }
 
 func (TensorRec) spec() {}
@@ -198,6 +380,7 @@ type LolliRec struct {
 	ExpID identity.ADT
 	Y     ExpRec
 	Z     ExpRec
+	Pot   int64
 }
 
 func (LolliRec) spec() {}
@@ -230,6 +413,134 @@ func (r DownRec) Ident() identity.ADT { return r.ExpID }
 
 func (DownRec) Pol() polarity.ADT { return polarity.Zero }
 
+type ExistsRec struct {
+	ExpID  identity.ADT
+	IdxVar symbol.ADT
+	Z      ExpRec
+}
+
+func (ExistsRec) spec() {}
+
+func (r ExistsRec) Ident() identity.ADT { return r.ExpID }
+
+func (r ExistsRec) Next() identity.ADT { return r.Z.Ident() }
+
+func (ExistsRec) Pol() polarity.ADT { return polarity.Pos }
+
+type ForallRec struct {
+	ExpID  identity.ADT
+	IdxVar symbol.ADT
+	Z      ExpRec
+}
+
+func (ForallRec) spec() {}
+
+func (r ForallRec) Ident() identity.ADT { return r.ExpID }
+
+func (r ForallRec) Next() identity.ADT { return r.Z.Ident() }
+
+func (ForallRec) Pol() polarity.ADT { return polarity.Neg }
+
+type AssertRec struct {
+	ExpID identity.ADT
+	Prop  arithexp.PropSpec
+	Z     ExpRec
+}
+
+func (AssertRec) spec() {}
+
+func (r AssertRec) Ident() identity.ADT { return r.ExpID }
+
+func (r AssertRec) Next() identity.ADT { return r.Z.Ident() }
+
+func (AssertRec) Pol() polarity.ADT { return polarity.Pos }
+
+type AssumeRec struct {
+	ExpID identity.ADT
+	Prop  arithexp.PropSpec
+	Z     ExpRec
+}
+
+func (AssumeRec) spec() {}
+
+func (r AssumeRec) Ident() identity.ADT { return r.ExpID }
+
+func (r AssumeRec) Next() identity.ADT { return r.Z.Ident() }
+
+func (AssumeRec) Pol() polarity.ADT { return polarity.Neg }
+
+// aka TpNext
+type NextRec struct {
+	ExpID identity.ADT
+	Z     ExpRec
+}
+
+func (NextRec) spec() {}
+
+func (r NextRec) Ident() identity.ADT { return r.ExpID }
+
+func (r NextRec) Next() identity.ADT { return r.Z.Ident() }
+
+func (NextRec) Pol() polarity.ADT { return polarity.Zero }
+
+// aka TpBox
+type BoxRec struct {
+	ExpID identity.ADT
+	Z     ExpRec
+}
+
+func (BoxRec) spec() {}
+
+func (r BoxRec) Ident() identity.ADT { return r.ExpID }
+
+func (r BoxRec) Next() identity.ADT { return r.Z.Ident() }
+
+func (BoxRec) Pol() polarity.ADT { return polarity.Neg }
+
+// aka TpDiamond
+type DiamondRec struct {
+	ExpID identity.ADT
+	Z     ExpRec
+}
+
+func (DiamondRec) spec() {}
+
+func (r DiamondRec) Ident() identity.ADT { return r.ExpID }
+
+func (r DiamondRec) Next() identity.ADT { return r.Z.Ident() }
+
+func (DiamondRec) Pol() polarity.ADT { return polarity.Pos }
+
+// aka TpAnd
+type AndRec struct {
+	ExpID identity.ADT
+	T     DataType
+	Z     ExpRec
+}
+
+func (AndRec) spec() {}
+
+func (r AndRec) Ident() identity.ADT { return r.ExpID }
+
+func (r AndRec) Next() identity.ADT { return r.Z.Ident() }
+
+func (AndRec) Pol() polarity.ADT { return polarity.Pos }
+
+// aka TpImp
+type ImplRec struct {
+	ExpID identity.ADT
+	T     DataType
+	Z     ExpRec
+}
+
+func (ImplRec) spec() {}
+
+func (r ImplRec) Ident() identity.ADT { return r.ExpID }
+
+func (r ImplRec) Next() identity.ADT { return r.Z.Ident() }
+
+func (ImplRec) Pol() polarity.ADT { return polarity.Neg }
+
 type Context struct {
 	Assets map[uniqsym.ADT]ExpRec
 	Liabs  map[uniqsym.ADT]ExpRec
@@ -263,11 +574,41 @@ func CheckSpec(got, want ExpSpec) error {
 			return ErrSpecTypeMismatch(got, want)
 		}
 		return nil
+	case LinkSpec:
+		gotSt, ok := got.(LinkSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		if !gotSt.TypeQN.Equal(wantSt.TypeQN) {
+			return fmt.Errorf("link mismatch: want %v, got %v", wantSt.TypeQN, gotSt.TypeQN)
+		}
+		if len(gotSt.TypeESs) != len(wantSt.TypeESs) {
+			return ErrArityMismatch(wantSt.TypeQN, len(wantSt.TypeESs), len(gotSt.TypeESs))
+		}
+		for i := range wantSt.TypeESs {
+			err := CheckSpec(gotSt.TypeESs[i], wantSt.TypeESs[i])
+			if err != nil {
+				return err
+			}
+		}
+		return checkIdxs(gotSt.IdxESs, wantSt.IdxESs)
+	case VarSpec:
+		gotSt, ok := got.(VarSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		if gotSt.TypeVar != wantSt.TypeVar {
+			return fmt.Errorf("var mismatch: want %v, got %v", wantSt.TypeVar, gotSt.TypeVar)
+		}
+		return nil
 	case TensorSpec:
 		gotSt, ok := got.(TensorSpec)
 		if !ok {
 			return ErrSpecTypeMismatch(got, want)
 		}
+		if gotSt.Pot != wantSt.Pot {
+			return errPotMismatch(gotSt.Pot, wantSt.Pot)
+		}
 		err := CheckSpec(gotSt.Y, wantSt.Y)
 		if err != nil {
 			return err
@@ -278,6 +619,9 @@ func CheckSpec(got, want ExpSpec) error {
 		if !ok {
 			return ErrSpecTypeMismatch(got, want)
 		}
+		if gotSt.Pot != wantSt.Pot {
+			return errPotMismatch(gotSt.Pot, wantSt.Pot)
+		}
 		err := CheckSpec(gotSt.Y, wantSt.Y)
 		if err != nil {
 			return err
@@ -288,44 +632,160 @@ func CheckSpec(got, want ExpSpec) error {
 		if !ok {
 			return ErrSpecTypeMismatch(got, want)
 		}
+		if gotSt.Pot != wantSt.Pot {
+			return errPotMismatch(gotSt.Pot, wantSt.Pot)
+		}
 		if len(gotSt.Zs) != len(wantSt.Zs) {
 			return fmt.Errorf("choices mismatch: want %v items, got %v items", len(wantSt.Zs), len(gotSt.Zs))
 		}
-		for wantLab, wantChoice := range wantSt.Zs {
+		var errs []error
+		for _, wantLab := range sortedLabels(wantSt.Zs) {
 			gotChoice, ok := gotSt.Zs[wantLab]
 			if !ok {
-				return fmt.Errorf("label mismatch: want %q, got nothing", wantLab)
+				errs = append(errs, errLabelMissing(wantLab))
+				continue
 			}
-			err := CheckSpec(gotChoice, wantChoice)
+			err := CheckSpec(gotChoice, wantSt.Zs[wantLab])
 			if err != nil {
-				return err
+				errs = append(errs, err)
 			}
 		}
-		return nil
+		return errors.Join(errs...)
 	case WithSpec:
 		gotSt, ok := got.(WithSpec)
 		if !ok {
 			return ErrSpecTypeMismatch(got, want)
 		}
+		if gotSt.Pot != wantSt.Pot {
+			return errPotMismatch(gotSt.Pot, wantSt.Pot)
+		}
 		if len(gotSt.Zs) != len(wantSt.Zs) {
 			return fmt.Errorf("choices mismatch: want %v items, got %v items", len(wantSt.Zs), len(gotSt.Zs))
 		}
-		for wantLab, wantChoice := range wantSt.Zs {
+		var errs []error
+		for _, wantLab := range sortedLabels(wantSt.Zs) {
 			gotChoice, ok := gotSt.Zs[wantLab]
 			if !ok {
-				return fmt.Errorf("label mismatch: want %q, got nothing", wantLab)
+				errs = append(errs, errLabelMissing(wantLab))
+				continue
 			}
-			err := CheckSpec(gotChoice, wantChoice)
+			err := CheckSpec(gotChoice, wantSt.Zs[wantLab])
 			if err != nil {
-				return err
+				errs = append(errs, err)
 			}
 		}
-		return nil
+		return errors.Join(errs...)
+	case UpSpec:
+		gotSt, ok := got.(UpSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		return CheckSpec(gotSt.Z, wantSt.Z)
+	case DownSpec:
+		gotSt, ok := got.(DownSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		return CheckSpec(gotSt.Z, wantSt.Z)
+	case ExistsSpec:
+		gotSt, ok := got.(ExistsSpec)
+		if !ok || gotSt.IdxVar != wantSt.IdxVar {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		return CheckSpec(gotSt.Z, wantSt.Z)
+	case ForallSpec:
+		gotSt, ok := got.(ForallSpec)
+		if !ok || gotSt.IdxVar != wantSt.IdxVar {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		return CheckSpec(gotSt.Z, wantSt.Z)
+	case AssertSpec:
+		gotSt, ok := got.(AssertSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		err := checkProps(gotSt.Prop, wantSt.Prop)
+		if err != nil {
+			return err
+		}
+		return CheckSpec(gotSt.Z, wantSt.Z)
+	case AssumeSpec:
+		gotSt, ok := got.(AssumeSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		err := checkProps(gotSt.Prop, wantSt.Prop)
+		if err != nil {
+			return err
+		}
+		return CheckSpec(gotSt.Z, wantSt.Z)
+	case NextSpec:
+		gotSt, ok := got.(NextSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		return CheckSpec(gotSt.Z, wantSt.Z)
+	case BoxSpec:
+		gotSt, ok := got.(BoxSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		return CheckSpec(gotSt.Z, wantSt.Z)
+	case DiamondSpec:
+		gotSt, ok := got.(DiamondSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		return CheckSpec(gotSt.Z, wantSt.Z)
+	case AndSpec:
+		gotSt, ok := got.(AndSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		err := CheckDataType(gotSt.T, wantSt.T)
+		if err != nil {
+			return err
+		}
+		return CheckSpec(gotSt.Z, wantSt.Z)
+	case ImplSpec:
+		gotSt, ok := got.(ImplSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		err := CheckDataType(gotSt.T, wantSt.T)
+		if err != nil {
+			return err
+		}
+		return CheckSpec(gotSt.Z, wantSt.Z)
 	default:
 		panic(ErrSpecTypeUnexpected(want))
 	}
 }
 
+// syntactic comparison of indices
+func checkIdxs(got, want []arithexp.ExpSpec) error {
+	if len(got) != len(want) {
+		return fmt.Errorf("index args mismatch: want %v items, got %v items", len(want), len(got))
+	}
+	for i := range want {
+		gotIdx := arithexp.ConvertExpToString(got[i])
+		wantIdx := arithexp.ConvertExpToString(want[i])
+		if gotIdx != wantIdx {
+			return fmt.Errorf("index mismatch: want %v, got %v", wantIdx, gotIdx)
+		}
+	}
+	return nil
+}
+
+func checkProps(got, want arithexp.PropSpec) error {
+	gotProp := arithexp.ConvertPropToString(got)
+	wantProp := arithexp.ConvertPropToString(want)
+	if gotProp != wantProp {
+		return fmt.Errorf("constraint mismatch: want %v, got %v", wantProp, gotProp)
+	}
+	return nil
+}
+
 // aka eqtp
 func CheckRec(got, want ExpRec) error {
 	switch wantSt := want.(type) {
@@ -335,21 +795,54 @@ func CheckRec(got, want ExpRec) error {
 			return ErrSnapTypeMismatch(got, want)
 		}
 		return nil
-	case TensorRec:
-		gotSt, ok := got.(TensorRec)
+	case LinkRec:
+		gotSt, ok := got.(LinkRec)
 		if !ok {
 			return ErrSnapTypeMismatch(got, want)
 		}
-		err := CheckRec(gotSt.Y, wantSt.Y)
-		if err != nil {
-			return err
+		if !gotSt.TypeQN.Equal(wantSt.TypeQN) {
+			return fmt.Errorf("link mismatch: want %v, got %v", wantSt.TypeQN, gotSt.TypeQN)
 		}
-		return CheckRec(gotSt.Z, wantSt.Z)
-	case LolliRec:
-		gotSt, ok := got.(LolliRec)
+		if len(gotSt.TypeERs) != len(wantSt.TypeERs) {
+			return ErrArityMismatch(wantSt.TypeQN, len(wantSt.TypeERs), len(gotSt.TypeERs))
+		}
+		for i := range wantSt.TypeERs {
+			err := CheckRec(gotSt.TypeERs[i], wantSt.TypeERs[i])
+			if err != nil {
+				return err
+			}
+		}
+		return checkIdxs(gotSt.IdxESs, wantSt.IdxESs)
+	case VarRec:
+		gotSt, ok := got.(VarRec)
 		if !ok {
 			return ErrSnapTypeMismatch(got, want)
 		}
+		if gotSt.TypeVar != wantSt.TypeVar {
+			return fmt.Errorf("var mismatch: want %v, got %v", wantSt.TypeVar, gotSt.TypeVar)
+		}
+		return nil
+	case TensorRec:
+		gotSt, ok := got.(TensorRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		if gotSt.Pot != wantSt.Pot {
+			return errPotMismatch(gotSt.Pot, wantSt.Pot)
+		}
+		err := CheckRec(gotSt.Y, wantSt.Y)
+		if err != nil {
+			return err
+		}
+		return CheckRec(gotSt.Z, wantSt.Z)
+	case LolliRec:
+		gotSt, ok := got.(LolliRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		if gotSt.Pot != wantSt.Pot {
+			return errPotMismatch(gotSt.Pot, wantSt.Pot)
+		}
 		err := CheckRec(gotSt.Y, wantSt.Y)
 		if err != nil {
 			return err
@@ -360,44 +853,885 @@ func CheckRec(got, want ExpRec) error {
 		if !ok {
 			return ErrSnapTypeMismatch(got, want)
 		}
+		if gotSt.Pot != wantSt.Pot {
+			return errPotMismatch(gotSt.Pot, wantSt.Pot)
+		}
 		if len(gotSt.Zs) != len(wantSt.Zs) {
 			return fmt.Errorf("choices mismatch: want %v items, got %v items", len(wantSt.Zs), len(gotSt.Zs))
 		}
-		for wantLab, wantChoice := range wantSt.Zs {
+		var errs []error
+		for _, wantLab := range sortedLabels(wantSt.Zs) {
 			gotChoice, ok := gotSt.Zs[wantLab]
 			if !ok {
-				return fmt.Errorf("label mismatch: want %q, got nothing", wantLab)
+				errs = append(errs, errLabelMissing(wantLab))
+				continue
 			}
-			err := CheckRec(gotChoice, wantChoice)
+			err := CheckRec(gotChoice, wantSt.Zs[wantLab])
 			if err != nil {
-				return err
+				errs = append(errs, err)
 			}
 		}
-		return nil
+		return errors.Join(errs...)
 	case WithRec:
 		gotSt, ok := got.(WithRec)
 		if !ok {
 			return ErrSnapTypeMismatch(got, want)
 		}
+		if gotSt.Pot != wantSt.Pot {
+			return errPotMismatch(gotSt.Pot, wantSt.Pot)
+		}
 		if len(gotSt.Zs) != len(wantSt.Zs) {
 			return fmt.Errorf("choices mismatch: want %v items, got %v items", len(wantSt.Zs), len(gotSt.Zs))
 		}
-		for wantLab, wantChoice := range wantSt.Zs {
+		var errs []error
+		for _, wantLab := range sortedLabels(wantSt.Zs) {
 			gotChoice, ok := gotSt.Zs[wantLab]
 			if !ok {
-				return fmt.Errorf("label mismatch: want %q, got nothing", wantLab)
+				errs = append(errs, errLabelMissing(wantLab))
+				continue
 			}
-			err := CheckRec(gotChoice, wantChoice)
+			err := CheckRec(gotChoice, wantSt.Zs[wantLab])
 			if err != nil {
-				return err
+				errs = append(errs, err)
 			}
 		}
+		return errors.Join(errs...)
+	case UpRec:
+		gotSt, ok := got.(UpRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return CheckRec(gotSt.Z, wantSt.Z)
+	case DownRec:
+		gotSt, ok := got.(DownRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return CheckRec(gotSt.Z, wantSt.Z)
+	case ExistsRec:
+		gotSt, ok := got.(ExistsRec)
+		if !ok || gotSt.IdxVar != wantSt.IdxVar {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return CheckRec(gotSt.Z, wantSt.Z)
+	case ForallRec:
+		gotSt, ok := got.(ForallRec)
+		if !ok || gotSt.IdxVar != wantSt.IdxVar {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return CheckRec(gotSt.Z, wantSt.Z)
+	case AssertRec:
+		gotSt, ok := got.(AssertRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		err := checkProps(gotSt.Prop, wantSt.Prop)
+		if err != nil {
+			return err
+		}
+		return CheckRec(gotSt.Z, wantSt.Z)
+	case AssumeRec:
+		gotSt, ok := got.(AssumeRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		err := checkProps(gotSt.Prop, wantSt.Prop)
+		if err != nil {
+			return err
+		}
+		return CheckRec(gotSt.Z, wantSt.Z)
+	case NextRec:
+		gotSt, ok := got.(NextRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return CheckRec(gotSt.Z, wantSt.Z)
+	case BoxRec:
+		gotSt, ok := got.(BoxRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return CheckRec(gotSt.Z, wantSt.Z)
+	case DiamondRec:
+		gotSt, ok := got.(DiamondRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return CheckRec(gotSt.Z, wantSt.Z)
+	case AndRec:
+		gotSt, ok := got.(AndRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		err := CheckDataType(gotSt.T, wantSt.T)
+		if err != nil {
+			return err
+		}
+		return CheckRec(gotSt.Z, wantSt.Z)
+	case ImplRec:
+		gotSt, ok := got.(ImplRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		err := CheckDataType(gotSt.T, wantSt.T)
+		if err != nil {
+			return err
+		}
+		return CheckRec(gotSt.Z, wantSt.Z)
+	default:
+		panic(ErrRecTypeUnexpected(want))
+	}
+}
+
+// aka Environment
+type Env struct {
+	// type name to the expression of its definition
+	TypeIDs  map[uniqsym.ADT]identity.ADT
+	TypeExps map[identity.ADT]ExpRec
+	// type name to the parameters of its definition
+	TypeVars map[uniqsym.ADT][]symbol.ADT
+	// type name to the index parameters of its definition
+	IdxVars map[uniqsym.ADT][]symbol.ADT
+}
+
+// aka ExpdTp
+//
+// Link arguments replace definition parameters; the result ids
+// depend only on the definition and the arguments.
+func (env Env) Unfold(rec ExpRec) (ExpRec, error) {
+	seen := make(map[string]bool)
+	for {
+		link, ok := rec.(LinkRec)
+		if !ok {
+			return rec, nil
+		}
+		key := Key(link)
+		if seen[key] {
+			return nil, ErrNotContractive(link.TypeQN)
+		}
+		seen[key] = true
+		expID, ok := env.lookup(link.TypeQN)
+		if !ok {
+			return nil, ErrSymMissingInEnv(link.TypeQN)
+		}
+		rec, ok = env.TypeExps[expID]
+		if !ok {
+			return nil, ErrMissingInEnv(expID)
+		}
+		typeVars := lookupVars(env.TypeVars, link.TypeQN)
+		if len(typeVars) != len(link.TypeERs) {
+			return nil, ErrArityMismatch(link.TypeQN, len(typeVars), len(link.TypeERs))
+		}
+		idxVars := lookupVars(env.IdxVars, link.TypeQN)
+		if len(idxVars) != len(link.IdxESs) {
+			return nil, ErrIdxArityMismatch(link.TypeQN, len(idxVars), len(link.IdxESs))
+		}
+		if len(typeVars) == 0 && len(idxVars) == 0 {
+			continue
+		}
+		typeArgs := make(map[symbol.ADT]ExpRec, len(typeVars))
+		for i, typeVar := range typeVars {
+			typeArgs[typeVar] = link.TypeERs[i]
+		}
+		idxArgs := make(map[symbol.ADT]arithexp.ExpSpec, len(idxVars))
+		for i, idxVar := range idxVars {
+			idxArgs[idxVar] = link.IdxESs[i]
+		}
+		rec = Subst(rec, typeArgs, idxArgs)
+	}
+}
+
+// aka definition instance
+func (env Env) Instance(typeQN uniqsym.ADT, typeArgs []ExpRec, idxArgs []arithexp.ExpSpec) (ExpRec, error) {
+	return env.Unfold(LinkRec{ExpID: identity.New(), TypeQN: typeQN, TypeERs: typeArgs, IdxESs: idxArgs})
+}
+
+func (env Env) lookup(typeQN uniqsym.ADT) (identity.ADT, bool) {
+	expID, ok := env.TypeIDs[typeQN]
+	if ok {
+		return expID, true
+	}
+	for qn, expID := range env.TypeIDs {
+		if qn.Equal(typeQN) {
+			return expID, true
+		}
+	}
+	return identity.ADT{}, false
+}
+
+func lookupVars(vars map[uniqsym.ADT][]symbol.ADT, typeQN uniqsym.ADT) []symbol.ADT {
+	found, ok := vars[typeQN]
+	if ok {
+		return found
+	}
+	for qn, found := range vars {
+		if qn.Equal(typeQN) {
+			return found
+		}
+	}
+	return nil
+}
+
+// aka subst
+//
+// Substitutes arguments for type vars and index vars. Result states get
+// ids derived from the substituted exp and the arguments, so repeated
+// unfolds of the same instance yield the same exp and store it once.
+func Subst(r ExpRec, typeArgs map[symbol.ADT]ExpRec, idxArgs map[symbol.ADT]arithexp.ExpSpec) ExpRec {
+	m := &minter{seed: substSeed(r.Ident(), typeArgs, idxArgs)}
+	return m.subst(r, typeArgs, idxArgs)
+}
+
+// states are numbered in traversal order, unique within one result
+type minter struct {
+	seed string
+	n    int
+}
+
+func (m *minter) next() identity.ADT {
+	m.n++
+	return identity.Derive(fmt.Sprintf("%v/%v", m.seed, m.n))
+}
+
+func substSeed(expID identity.ADT, typeArgs map[symbol.ADT]ExpRec, idxArgs map[symbol.ADT]arithexp.ExpSpec) string {
+	var b strings.Builder
+	b.WriteString(expID.String())
+	for _, typeVar := range slices.Sorted(maps.Keys(typeArgs)) {
+		b.WriteString(";'")
+		b.WriteString(symbol.ConvertToString(typeVar))
+		b.WriteString("=")
+		writeKey(&b, typeArgs[typeVar])
+	}
+	for _, idxVar := range slices.Sorted(maps.Keys(idxArgs)) {
+		b.WriteString(";")
+		b.WriteString(symbol.ConvertToString(idxVar))
+		b.WriteString("=")
+		b.WriteString(arithexp.ConvertExpToString(idxArgs[idxVar]))
+	}
+	return b.String()
+}
+
+func (m *minter) subst(r ExpRec, typeArgs map[symbol.ADT]ExpRec, idxArgs map[symbol.ADT]arithexp.ExpSpec) ExpRec {
+	switch rec := r.(type) {
+	case OneRec:
+		return OneRec{ExpID: m.next()}
+	case VarRec:
+		arg, ok := typeArgs[rec.TypeVar]
+		if !ok {
+			return VarRec{ExpID: m.next(), TypeVar: rec.TypeVar}
+		}
+		return m.subst(arg, nil, nil)
+	case LinkRec:
+		expID := m.next()
+		typeERs := make([]ExpRec, len(rec.TypeERs))
+		for i, typeER := range rec.TypeERs {
+			typeERs[i] = m.subst(typeER, typeArgs, idxArgs)
+		}
+		var idxESs []arithexp.ExpSpec
+		for _, idxES := range rec.IdxESs {
+			idxESs = append(idxESs, arithexp.SubstExp(idxES, idxArgs))
+		}
+		return LinkRec{ExpID: expID, TypeQN: rec.TypeQN, TypeERs: typeERs, IdxESs: idxESs}
+	case TensorRec:
+		expID := m.next()
+		y := m.subst(rec.Y, typeArgs, idxArgs)
+		return TensorRec{ExpID: expID, Y: y, Z: m.subst(rec.Z, typeArgs, idxArgs), Pot: rec.Pot}
+	case LolliRec:
+		expID := m.next()
+		y := m.subst(rec.Y, typeArgs, idxArgs)
+		return LolliRec{ExpID: expID, Y: y, Z: m.subst(rec.Z, typeArgs, idxArgs), Pot: rec.Pot}
+	case PlusRec:
+		expID := m.next()
+		return PlusRec{ExpID: expID, Zs: m.substChoices(rec.Zs, typeArgs, idxArgs), Pot: rec.Pot}
+	case WithRec:
+		expID := m.next()
+		return WithRec{ExpID: expID, Zs: m.substChoices(rec.Zs, typeArgs, idxArgs), Pot: rec.Pot}
+	case UpRec:
+		expID := m.next()
+		return UpRec{ExpID: expID, Z: m.subst(rec.Z, typeArgs, idxArgs)}
+	case DownRec:
+		expID := m.next()
+		return DownRec{ExpID: expID, Z: m.subst(rec.Z, typeArgs, idxArgs)}
+	case ExistsRec:
+		expID := m.next()
+		idxVar, z := m.substBinder(rec.IdxVar, rec.Z, typeArgs, idxArgs)
+		return ExistsRec{ExpID: expID, IdxVar: idxVar, Z: z}
+	case ForallRec:
+		expID := m.next()
+		idxVar, z := m.substBinder(rec.IdxVar, rec.Z, typeArgs, idxArgs)
+		return ForallRec{ExpID: expID, IdxVar: idxVar, Z: z}
+	case AssertRec:
+		expID := m.next()
+		return AssertRec{ExpID: expID, Prop: arithexp.SubstProp(rec.Prop, idxArgs), Z: m.subst(rec.Z, typeArgs, idxArgs)}
+	case AssumeRec:
+		expID := m.next()
+		return AssumeRec{ExpID: expID, Prop: arithexp.SubstProp(rec.Prop, idxArgs), Z: m.subst(rec.Z, typeArgs, idxArgs)}
+	case NextRec:
+		expID := m.next()
+		return NextRec{ExpID: expID, Z: m.subst(rec.Z, typeArgs, idxArgs)}
+	case BoxRec:
+		expID := m.next()
+		return BoxRec{ExpID: expID, Z: m.subst(rec.Z, typeArgs, idxArgs)}
+	case DiamondRec:
+		expID := m.next()
+		return DiamondRec{ExpID: expID, Z: m.subst(rec.Z, typeArgs, idxArgs)}
+	case AndRec:
+		expID := m.next()
+		return AndRec{ExpID: expID, T: rec.T, Z: m.subst(rec.Z, typeArgs, idxArgs)}
+	case ImplRec:
+		expID := m.next()
+		return ImplRec{ExpID: expID, T: rec.T, Z: m.subst(rec.Z, typeArgs, idxArgs)}
+	default:
+		panic(ErrRecTypeUnexpected(r))
+	}
+}
+
+// labels are visited in order, so that numbering does not depend on map order
+func (m *minter) substChoices(
+	choices map[uniqsym.ADT]ExpRec,
+	typeArgs map[symbol.ADT]ExpRec,
+	idxArgs map[symbol.ADT]arithexp.ExpSpec,
+) map[uniqsym.ADT]ExpRec {
+	substituted := make(map[uniqsym.ADT]ExpRec, len(choices))
+	for _, lab := range sortedLabels(choices) {
+		substituted[lab] = m.subst(choices[lab], typeArgs, idxArgs)
+	}
+	return substituted
+}
+
+// a bound variable shadows the argument of the same name and is renamed
+// if it occurs in the substituted expressions (else it would capture them)
+func (m *minter) substBinder(
+	idxVar symbol.ADT,
+	z ExpRec,
+	typeArgs map[symbol.ADT]ExpRec,
+	idxArgs map[symbol.ADT]arithexp.ExpSpec,
+) (symbol.ADT, ExpRec) {
+	inner := make(map[symbol.ADT]arithexp.ExpSpec, len(idxArgs)+1)
+	var used []symbol.ADT
+	for v, arg := range idxArgs {
+		if v == idxVar {
+			continue
+		}
+		inner[v] = arg
+		used = append(used, arithexp.CollectVars(arg)...)
+	}
+	for _, arg := range typeArgs {
+		used = append(used, CollectIdxVars(arg)...)
+	}
+	if !slices.Contains(used, idxVar) {
+		return idxVar, m.subst(z, typeArgs, inner)
+	}
+	fresh := freshVar(idxVar, append(used, CollectIdxVars(z)...))
+	inner[idxVar] = arithexp.VarSpec{IdxVar: fresh}
+	return fresh, m.subst(z, typeArgs, inner)
+}
+
+// first name of the form idxVar_N that is not in used
+func freshVar(idxVar symbol.ADT, used []symbol.ADT) symbol.ADT {
+	for i := 1; ; i++ {
+		fresh := symbol.New(fmt.Sprintf("%v_%v", symbol.ConvertToString(idxVar), i))
+		if !slices.Contains(used, fresh) {
+			return fresh
+		}
+	}
+}
+
+// structural key of the expression, independent of state IDs
+func Key(r ExpRec) string {
+	var b strings.Builder
+	writeKey(&b, r)
+	return b.String()
+}
+
+func writeKey(b *strings.Builder, r ExpRec) {
+	switch rec := r.(type) {
+	case nil:
+		// unset continuation of the pattern
+		b.WriteString("_")
+	case OneRec:
+		b.WriteString("1")
+	case VarRec:
+		b.WriteString("'")
+		b.WriteString(symbol.ConvertToString(rec.TypeVar))
+	case LinkRec:
+		b.WriteString(uniqsym.ConvertToString(rec.TypeQN))
+		if len(rec.TypeERs) > 0 {
+			b.WriteString("[")
+			for i, typeER := range rec.TypeERs {
+				if i > 0 {
+					b.WriteString(",")
+				}
+				writeKey(b, typeER)
+			}
+			b.WriteString("]")
+		}
+		if len(rec.IdxESs) > 0 {
+			b.WriteString("{")
+			b.WriteString(strings.Join(arithexp.ConvertExpsToStrings(rec.IdxESs), ","))
+			b.WriteString("}")
+		}
+	case TensorRec:
+		b.WriteString("(")
+		writeKey(b, rec.Y)
+		b.WriteString("*")
+		writePotKey(b, rec.Pot)
+		writeKey(b, rec.Z)
+		b.WriteString(")")
+	case LolliRec:
+		b.WriteString("(")
+		writeKey(b, rec.Y)
+		b.WriteString("-o")
+		writePotKey(b, rec.Pot)
+		writeKey(b, rec.Z)
+		b.WriteString(")")
+	case PlusRec:
+		b.WriteString("+")
+		writePotKey(b, rec.Pot)
+		writeChoicesKey(b, rec.Zs)
+	case WithRec:
+		b.WriteString("&")
+		writePotKey(b, rec.Pot)
+		writeChoicesKey(b, rec.Zs)
+	case UpRec:
+		b.WriteString("^")
+		writeKey(b, rec.Z)
+	case DownRec:
+		b.WriteString("v")
+		writeKey(b, rec.Z)
+	case ExistsRec:
+		b.WriteString("?")
+		b.WriteString(symbol.ConvertToString(rec.IdxVar))
+		b.WriteString(".")
+		writeKey(b, rec.Z)
+	case ForallRec:
+		b.WriteString("!")
+		b.WriteString(symbol.ConvertToString(rec.IdxVar))
+		b.WriteString(".")
+		writeKey(b, rec.Z)
+	case AssertRec:
+		b.WriteString("?{")
+		b.WriteString(arithexp.ConvertPropToString(rec.Prop))
+		b.WriteString("}.")
+		writeKey(b, rec.Z)
+	case AssumeRec:
+		b.WriteString("!{")
+		b.WriteString(arithexp.ConvertPropToString(rec.Prop))
+		b.WriteString("}.")
+		writeKey(b, rec.Z)
+	case NextRec:
+		b.WriteString("()")
+		writeKey(b, rec.Z)
+	case BoxRec:
+		b.WriteString("[]")
+		writeKey(b, rec.Z)
+	case DiamondRec:
+		b.WriteString("<>")
+		writeKey(b, rec.Z)
+	case AndRec:
+		b.WriteString("(")
+		writeDataKey(b, rec.T)
+		b.WriteString("/\\")
+		writeKey(b, rec.Z)
+		b.WriteString(")")
+	case ImplRec:
+		b.WriteString("(")
+		writeDataKey(b, rec.T)
+		b.WriteString("=>")
+		writeKey(b, rec.Z)
+		b.WriteString(")")
+	default:
+		panic(ErrRecTypeUnexpected(r))
+	}
+}
+
+func writeDataKey(b *strings.Builder, t DataType) {
+	if t == NonData {
+		b.WriteString("_")
+		return
+	}
+	b.WriteString(ConvertDataToString(t))
+}
+
+// potential is given only if non-zero
+func writePotKey(b *strings.Builder, pot int64) {
+	if pot == 0 {
+		return
+	}
+	b.WriteString("<")
+	b.WriteString(strconv.FormatInt(pot, 10))
+	b.WriteString(">")
+}
+
+func writeChoicesKey(b *strings.Builder, choices map[uniqsym.ADT]ExpRec) {
+	labels := make([]string, 0, len(choices))
+	byLabel := make(map[string]ExpRec, len(choices))
+	for lab, choice := range choices {
+		label := uniqsym.ConvertToString(lab)
+		labels = append(labels, label)
+		byLabel[label] = choice
+	}
+	slices.Sort(labels)
+	b.WriteString("{")
+	for i, label := range labels {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(label)
+		b.WriteString(":")
+		writeKey(b, byLabel[label])
+	}
+	b.WriteString("}")
+}
+
+// aka eqtp
+//
+// Equality up to unfolding of names: a pair of expressions
+// met again is considered equal (coinduction). Index
+// expressions are compared by the solver under facts.
+func CheckEqual(env Env, facts []arithexp.PropSpec, got, want ExpRec) error {
+	c := checker{env, false, facts, make(map[[2]string]bool)}
+	return errMismatch(got, want, c.check(got, want))
+}
+
+// Session subtyping: got may be provided where want is expected.
+func CheckSub(env Env, facts []arithexp.PropSpec, got, want ExpRec) error {
+	c := checker{env, true, facts, make(map[[2]string]bool)}
+	return errMismatch(got, want, c.check(got, want))
+}
+
+// limit of unfoldings per comparison (nested types may grow without bound)
+const maxUnfolds = 1 << 12
+
+type checker struct {
+	env   Env
+	sub   bool
+	facts []arithexp.PropSpec
+	seen  map[[2]string]bool
+}
+
+func (c checker) assume(prop arithexp.PropSpec) checker {
+	return checker{c.env, c.sub, append(slices.Clip(c.facts), prop), c.seen}
+}
+
+func (c checker) check(got, want ExpRec) error {
+	gotLink, gotIsLink := got.(LinkRec)
+	wantLink, wantIsLink := want.(LinkRec)
+	if gotIsLink && wantIsLink && c.sameInstance(gotLink, wantLink) {
+		return nil
+	}
+	if gotIsLink || wantIsLink {
+		// substitution yields fresh IDs, so pairs are compared by structure
+		pair := [2]string{Key(got), Key(want)}
+		if c.seen[pair] {
+			return nil
+		}
+		if len(c.seen) >= maxUnfolds {
+			return fmt.Errorf("type expansion limit exceeded: %v", maxUnfolds)
+		}
+		c.seen[pair] = true
+		gotSt, err := c.env.Unfold(got)
+		if err != nil {
+			return err
+		}
+		wantSt, err := c.env.Unfold(want)
+		if err != nil {
+			return err
+		}
+		return c.check(gotSt, wantSt)
+	}
+	switch wantSt := want.(type) {
+	case OneRec:
+		_, ok := got.(OneRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return nil
+	case TensorRec:
+		gotSt, ok := got.(TensorRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		if gotSt.Pot != wantSt.Pot {
+			return errPotMismatch(gotSt.Pot, wantSt.Pot)
+		}
+		err := c.check(gotSt.Y, wantSt.Y)
+		if err != nil {
+			return err
+		}
+		return c.check(gotSt.Z, wantSt.Z)
+	case VarRec:
+		gotSt, ok := got.(VarRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		if gotSt.TypeVar != wantSt.TypeVar {
+			return fmt.Errorf("var mismatch: want %v, got %v", wantSt.TypeVar, gotSt.TypeVar)
+		}
 		return nil
+	case LolliRec:
+		gotSt, ok := got.(LolliRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		if gotSt.Pot != wantSt.Pot {
+			return errPotMismatch(gotSt.Pot, wantSt.Pot)
+		}
+		// the received value is contravariant
+		err := c.check(wantSt.Y, gotSt.Y)
+		if err != nil {
+			return err
+		}
+		return c.check(gotSt.Z, wantSt.Z)
+	case PlusRec:
+		gotSt, ok := got.(PlusRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		if gotSt.Pot != wantSt.Pot {
+			return errPotMismatch(gotSt.Pot, wantSt.Pot)
+		}
+		// the sender may choose from fewer labels
+		return c.checkChoices(gotSt.Zs, gotSt.Zs, wantSt.Zs)
+	case WithRec:
+		gotSt, ok := got.(WithRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		if gotSt.Pot != wantSt.Pot {
+			return errPotMismatch(gotSt.Pot, wantSt.Pot)
+		}
+		// the receiver may accept more labels
+		return c.checkChoices(wantSt.Zs, gotSt.Zs, wantSt.Zs)
+	case UpRec:
+		gotSt, ok := got.(UpRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return c.check(gotSt.Z, wantSt.Z)
+	case DownRec:
+		gotSt, ok := got.(DownRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return c.check(gotSt.Z, wantSt.Z)
+	case ExistsRec:
+		gotSt, ok := got.(ExistsRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return c.checkBinders(gotSt.IdxVar, gotSt.Z, wantSt.IdxVar, wantSt.Z)
+	case ForallRec:
+		gotSt, ok := got.(ForallRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return c.checkBinders(gotSt.IdxVar, gotSt.Z, wantSt.IdxVar, wantSt.Z)
+	case AssertRec:
+		gotSt, ok := got.(AssertRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		// what the sender proves must entail what is expected
+		err := c.checkImplies(gotSt.Prop, wantSt.Prop)
+		if err != nil {
+			return err
+		}
+		return c.assume(gotSt.Prop).check(gotSt.Z, wantSt.Z)
+	case AssumeRec:
+		gotSt, ok := got.(AssumeRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		// the receiver may require less
+		err := c.checkImplies(wantSt.Prop, gotSt.Prop)
+		if err != nil {
+			return err
+		}
+		return c.assume(wantSt.Prop).check(gotSt.Z, wantSt.Z)
+	case NextRec:
+		gotSt, ok := got.(NextRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return c.check(gotSt.Z, wantSt.Z)
+	case BoxRec:
+		gotSt, ok := got.(BoxRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return c.check(gotSt.Z, wantSt.Z)
+	case DiamondRec:
+		gotSt, ok := got.(DiamondRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		return c.check(gotSt.Z, wantSt.Z)
+	case AndRec:
+		gotSt, ok := got.(AndRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		err := CheckDataType(gotSt.T, wantSt.T)
+		if err != nil {
+			return err
+		}
+		return c.check(gotSt.Z, wantSt.Z)
+	case ImplRec:
+		gotSt, ok := got.(ImplRec)
+		if !ok {
+			return ErrSnapTypeMismatch(got, want)
+		}
+		err := CheckDataType(gotSt.T, wantSt.T)
+		if err != nil {
+			return err
+		}
+		return c.check(gotSt.Z, wantSt.Z)
 	default:
 		panic(ErrRecTypeUnexpected(want))
 	}
 }
 
+// instances of one definition with equal arguments are equal without unfolding
+func (c checker) sameInstance(got, want LinkRec) bool {
+	if !got.TypeQN.Equal(want.TypeQN) {
+		return false
+	}
+	if len(got.TypeERs) != len(want.TypeERs) || len(got.IdxESs) != len(want.IdxESs) {
+		return false
+	}
+	for i := range got.IdxESs {
+		if arithexp.CheckEqual(c.facts, got.IdxESs[i], want.IdxESs[i]) != nil {
+			return false
+		}
+	}
+	// a failed attempt must leave no assumptions
+	inv := checker{c.env, false, c.facts, maps.Clone(c.seen)}
+	for i := range got.TypeERs {
+		if inv.check(got.TypeERs[i], want.TypeERs[i]) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// bound variables are replaced with a common fresh one
+func (c checker) checkBinders(gotVar symbol.ADT, gotZ ExpRec, wantVar symbol.ADT, wantZ ExpRec) error {
+	used := append(CollectIdxVars(gotZ), CollectIdxVars(wantZ)...)
+	for _, fact := range c.facts {
+		used = append(used, arithexp.CollectPropVars(fact)...)
+	}
+	fresh := arithexp.VarSpec{IdxVar: freshVar(wantVar, used)}
+	gotZ = Subst(gotZ, nil, map[symbol.ADT]arithexp.ExpSpec{gotVar: fresh})
+	wantZ = Subst(wantZ, nil, map[symbol.ADT]arithexp.ExpSpec{wantVar: fresh})
+	return c.check(gotZ, wantZ)
+}
+
+// in equality mode the constraints must be equivalent
+func (c checker) checkImplies(got, want arithexp.PropSpec) error {
+	err := arithexp.CheckProp(append(slices.Clip(c.facts), got), want)
+	if err != nil || c.sub {
+		return err
+	}
+	return arithexp.CheckProp(append(slices.Clip(c.facts), want), got)
+}
+
+// labels must be present in both choices
+func (c checker) checkChoices(labels, got, want map[uniqsym.ADT]ExpRec) error {
+	if !c.sub && len(got) != len(want) {
+		return fmt.Errorf("choices mismatch: want %v items, got %v items", len(want), len(got))
+	}
+	// mismatches across all labels are reported together
+	var errs []error
+	for _, label := range sortedLabels(labels) {
+		gotChoice, ok := got[label]
+		if !ok {
+			errs = append(errs, errLabelMissing(label))
+			continue
+		}
+		wantChoice, ok := want[label]
+		if !ok {
+			errs = append(errs, errLabelExtra(label))
+			continue
+		}
+		err := c.check(gotChoice, wantChoice)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sortedLabels[T any](choices map[uniqsym.ADT]T) []uniqsym.ADT {
+	return slices.SortedFunc(maps.Keys(choices), func(a, b uniqsym.ADT) int {
+		return strings.Compare(uniqsym.ConvertToString(a), uniqsym.ConvertToString(b))
+	})
+}
+
+// shared channels are exempt from linearity
+func IsShared(rec ExpRec) bool {
+	_, ok := rec.(UpRec)
+	return ok
+}
+
+// a □ client picks the moment itself, so it may wait
+func IsPatientAsset(rec ExpRec) bool {
+	_, ok := rec.(BoxRec)
+	return ok
+}
+
+// a ◇ provider picks the moment itself, so it may wait
+func IsPatientLiab(rec ExpRec) bool {
+	_, ok := rec.(DiamondRec)
+	return ok
+}
+
+func CheckDataType(got, want DataType) error {
+	if got != want {
+		return fmt.Errorf("data type mismatch: want %v, got %v", ConvertDataToString(want), ConvertDataToString(got))
+	}
+	return nil
+}
+
+// the value must be a JSON form of the type
+func CheckData(t DataType, val json.RawMessage) error {
+	if !json.Valid(val) {
+		return fmt.Errorf("value malformed: %s", val)
+	}
+	// null is allowed only as a document
+	if t != JSONData && bytes.Equal(bytes.TrimSpace(val), []byte("null")) {
+		return errDataMismatch(t, val)
+	}
+	var err error
+	switch t {
+	case IntData:
+		var v int64
+		err = json.Unmarshal(val, &v)
+	case StrData:
+		var v string
+		err = json.Unmarshal(val, &v)
+	case BoolData:
+		var v bool
+		err = json.Unmarshal(val, &v)
+	case JSONData:
+		return nil
+	default:
+		panic(ErrDataTypeUnexpected(t))
+	}
+	if err != nil {
+		return errDataMismatch(t, val)
+	}
+	return nil
+}
+
+func errDataMismatch(t DataType, val json.RawMessage) error {
+	return fmt.Errorf("value mismatch: want %v, got %s", ConvertDataToString(t), val)
+}
+
+func ErrDataTypeUnexpected(got DataType) error {
+	return fmt.Errorf("data type unexpected: %v", got)
+}
+
 func ErrSpecTypeUnexpected(got ExpSpec) error {
 	return fmt.Errorf("spec type unexpected: %T", got)
 }
@@ -422,6 +1756,22 @@ func ErrMissingInCtx(want uniqsym.ADT) error {
 	return fmt.Errorf("root missing in ctx: %v", want)
 }
 
+func ErrNotContractive(got uniqsym.ADT) error {
+	return fmt.Errorf("type not contractive: %v", got)
+}
+
+func ErrArityMismatch(typeQN uniqsym.ADT, want, got int) error {
+	return fmt.Errorf("type arity mismatch: %v wants %v args, got %v", typeQN, want, got)
+}
+
+func ErrIdxArityMismatch(typeQN uniqsym.ADT, want, got int) error {
+	return fmt.Errorf("index arity mismatch: %v wants %v args, got %v", typeQN, want, got)
+}
+
+func errPotMismatch(got, want int64) error {
+	return fmt.Errorf("potential mismatch: want %v, got %v", want, got)
+}
+
 func ErrRecTypeUnexpected(got ExpRec) error {
 	return fmt.Errorf("rec type unexpected: %T", got)
 }
@@ -431,7 +1781,41 @@ func ErrSpecTypeMismatch(got, want ExpSpec) error {
 }
 
 func ErrSnapTypeMismatch(got, want ExpRec) error {
-	return fmt.Errorf("root type mismatch: want %T, got %T", want, got)
+	return MismatchError{Got: got, Want: want, Err: fmt.Errorf("root type mismatch: want %T, got %T", want, got)}
+}
+
+// Type mismatch along with both sides of the comparison.
+// The want pattern may have unset continuations.
+type MismatchError struct {
+	Got  ExpRec
+	Want ExpRec
+	Err  error
+}
+
+func (e MismatchError) Error() string { return e.Err.Error() }
+
+func (e MismatchError) Unwrap() error { return e.Err }
+
+var ErrLabelMismatch = errors.New("label mismatch")
+
+func errLabelMissing(want uniqsym.ADT) error {
+	return fmt.Errorf("%w: want %q, got nothing", ErrLabelMismatch, uniqsym.ConvertToString(want))
+}
+
+func errLabelExtra(got uniqsym.ADT) error {
+	return fmt.Errorf("%w: want nothing, got %q", ErrLabelMismatch, uniqsym.ConvertToString(got))
+}
+
+// the outer pair takes precedence over the nested one
+func errMismatch(got, want ExpRec, err error) error {
+	if err == nil {
+		return nil
+	}
+	mismatchErr, ok := err.(MismatchError)
+	if ok {
+		err = mismatchErr.Err
+	}
+	return MismatchError{Got: got, Want: want, Err: err}
 }
 
 func ErrPolarityUnexpected(got ExpRec) error {
@@ -441,3 +1825,286 @@ func ErrPolarityUnexpected(got ExpRec) error {
 func ErrPolarityMismatch(a, b ExpRec) error {
 	return fmt.Errorf("root polarity mismatch: %v != %v", a.Pol(), b.Pol())
 }
+
+// type names referenced by the expressions
+func CollectLinks(recs iter.Seq[ExpRec]) []uniqsym.ADT {
+	typeQNs := []uniqsym.ADT{}
+	for r := range recs {
+		typeQNs = collectLinks(r, typeQNs)
+	}
+	return typeQNs
+}
+
+func collectLinks(r ExpRec, typeQNs []uniqsym.ADT) []uniqsym.ADT {
+	switch rec := r.(type) {
+	case LinkRec:
+		typeQNs = append(typeQNs, rec.TypeQN)
+		for _, typeER := range rec.TypeERs {
+			typeQNs = collectLinks(typeER, typeQNs)
+		}
+		return typeQNs
+	case TensorRec:
+		return collectLinks(rec.Z, collectLinks(rec.Y, typeQNs))
+	case LolliRec:
+		return collectLinks(rec.Z, collectLinks(rec.Y, typeQNs))
+	case PlusRec:
+		for _, choice := range rec.Zs {
+			typeQNs = collectLinks(choice, typeQNs)
+		}
+		return typeQNs
+	case WithRec:
+		for _, choice := range rec.Zs {
+			typeQNs = collectLinks(choice, typeQNs)
+		}
+		return typeQNs
+	case UpRec:
+		return collectLinks(rec.Z, typeQNs)
+	case DownRec:
+		return collectLinks(rec.Z, typeQNs)
+	case ExistsRec:
+		return collectLinks(rec.Z, typeQNs)
+	case ForallRec:
+		return collectLinks(rec.Z, typeQNs)
+	case AssertRec:
+		return collectLinks(rec.Z, typeQNs)
+	case AssumeRec:
+		return collectLinks(rec.Z, typeQNs)
+	case NextRec:
+		return collectLinks(rec.Z, typeQNs)
+	case BoxRec:
+		return collectLinks(rec.Z, typeQNs)
+	case DiamondRec:
+		return collectLinks(rec.Z, typeQNs)
+	case AndRec:
+		return collectLinks(rec.Z, typeQNs)
+	case ImplRec:
+		return collectLinks(rec.Z, typeQNs)
+	default:
+		return typeQNs
+	}
+}
+
+// IDs of all states of the expression
+func CollectIDs(r ExpRec) []identity.ADT {
+	return collectIDs(r, []identity.ADT{})
+}
+
+func collectIDs(r ExpRec, expIDs []identity.ADT) []identity.ADT {
+	expIDs = append(expIDs, r.Ident())
+	switch rec := r.(type) {
+	case LinkRec:
+		for _, typeER := range rec.TypeERs {
+			expIDs = collectIDs(typeER, expIDs)
+		}
+		return expIDs
+	case TensorRec:
+		return collectIDs(rec.Z, collectIDs(rec.Y, expIDs))
+	case LolliRec:
+		return collectIDs(rec.Z, collectIDs(rec.Y, expIDs))
+	case PlusRec:
+		for _, label := range sortedLabels(rec.Zs) {
+			expIDs = collectIDs(rec.Zs[label], expIDs)
+		}
+		return expIDs
+	case WithRec:
+		for _, label := range sortedLabels(rec.Zs) {
+			expIDs = collectIDs(rec.Zs[label], expIDs)
+		}
+		return expIDs
+	case UpRec:
+		return collectIDs(rec.Z, expIDs)
+	case DownRec:
+		return collectIDs(rec.Z, expIDs)
+	case ExistsRec:
+		return collectIDs(rec.Z, expIDs)
+	case ForallRec:
+		return collectIDs(rec.Z, expIDs)
+	case AssertRec:
+		return collectIDs(rec.Z, expIDs)
+	case AssumeRec:
+		return collectIDs(rec.Z, expIDs)
+	case NextRec:
+		return collectIDs(rec.Z, expIDs)
+	case BoxRec:
+		return collectIDs(rec.Z, expIDs)
+	case DiamondRec:
+		return collectIDs(rec.Z, expIDs)
+	case AndRec:
+		return collectIDs(rec.Z, expIDs)
+	case ImplRec:
+		return collectIDs(rec.Z, expIDs)
+	default:
+		return expIDs
+	}
+}
+
+// potentials given in the expression
+func CollectPots(r ExpRec) []int64 {
+	return collectPots(r, []int64{})
+}
+
+func collectPots(r ExpRec, pots []int64) []int64 {
+	switch rec := r.(type) {
+	case LinkRec:
+		for _, typeER := range rec.TypeERs {
+			pots = collectPots(typeER, pots)
+		}
+		return pots
+	case TensorRec:
+		return collectPots(rec.Z, collectPots(rec.Y, append(pots, rec.Pot)))
+	case LolliRec:
+		return collectPots(rec.Z, collectPots(rec.Y, append(pots, rec.Pot)))
+	case PlusRec:
+		pots = append(pots, rec.Pot)
+		for _, choice := range rec.Zs {
+			pots = collectPots(choice, pots)
+		}
+		return pots
+	case WithRec:
+		pots = append(pots, rec.Pot)
+		for _, choice := range rec.Zs {
+			pots = collectPots(choice, pots)
+		}
+		return pots
+	case UpRec:
+		return collectPots(rec.Z, pots)
+	case DownRec:
+		return collectPots(rec.Z, pots)
+	case ExistsRec:
+		return collectPots(rec.Z, pots)
+	case ForallRec:
+		return collectPots(rec.Z, pots)
+	case AssertRec:
+		return collectPots(rec.Z, pots)
+	case AssumeRec:
+		return collectPots(rec.Z, pots)
+	case NextRec:
+		return collectPots(rec.Z, pots)
+	case BoxRec:
+		return collectPots(rec.Z, pots)
+	case DiamondRec:
+		return collectPots(rec.Z, pots)
+	case AndRec:
+		return collectPots(rec.Z, pots)
+	case ImplRec:
+		return collectPots(rec.Z, pots)
+	default:
+		return pots
+	}
+}
+
+// type variables occurring in the expression
+func CollectVars(r ExpRec) []symbol.ADT {
+	return collectVars(r, []symbol.ADT{})
+}
+
+func collectVars(r ExpRec, typeVars []symbol.ADT) []symbol.ADT {
+	switch rec := r.(type) {
+	case VarRec:
+		return append(typeVars, rec.TypeVar)
+	case LinkRec:
+		for _, typeER := range rec.TypeERs {
+			typeVars = collectVars(typeER, typeVars)
+		}
+		return typeVars
+	case TensorRec:
+		return collectVars(rec.Z, collectVars(rec.Y, typeVars))
+	case LolliRec:
+		return collectVars(rec.Z, collectVars(rec.Y, typeVars))
+	case PlusRec:
+		for _, choice := range rec.Zs {
+			typeVars = collectVars(choice, typeVars)
+		}
+		return typeVars
+	case WithRec:
+		for _, choice := range rec.Zs {
+			typeVars = collectVars(choice, typeVars)
+		}
+		return typeVars
+	case UpRec:
+		return collectVars(rec.Z, typeVars)
+	case DownRec:
+		return collectVars(rec.Z, typeVars)
+	case ExistsRec:
+		return collectVars(rec.Z, typeVars)
+	case ForallRec:
+		return collectVars(rec.Z, typeVars)
+	case AssertRec:
+		return collectVars(rec.Z, typeVars)
+	case AssumeRec:
+		return collectVars(rec.Z, typeVars)
+	case NextRec:
+		return collectVars(rec.Z, typeVars)
+	case BoxRec:
+		return collectVars(rec.Z, typeVars)
+	case DiamondRec:
+		return collectVars(rec.Z, typeVars)
+	case AndRec:
+		return collectVars(rec.Z, typeVars)
+	case ImplRec:
+		return collectVars(rec.Z, typeVars)
+	default:
+		return typeVars
+	}
+}
+
+// free index variables of the expression
+func CollectIdxVars(r ExpRec) []symbol.ADT {
+	return collectIdxVars(r, []symbol.ADT{})
+}
+
+func collectIdxVars(r ExpRec, idxVars []symbol.ADT) []symbol.ADT {
+	switch rec := r.(type) {
+	case LinkRec:
+		for _, typeER := range rec.TypeERs {
+			idxVars = collectIdxVars(typeER, idxVars)
+		}
+		for _, idxES := range rec.IdxESs {
+			idxVars = append(idxVars, arithexp.CollectVars(idxES)...)
+		}
+		return idxVars
+	case TensorRec:
+		return collectIdxVars(rec.Z, collectIdxVars(rec.Y, idxVars))
+	case LolliRec:
+		return collectIdxVars(rec.Z, collectIdxVars(rec.Y, idxVars))
+	case PlusRec:
+		for _, choice := range rec.Zs {
+			idxVars = collectIdxVars(choice, idxVars)
+		}
+		return idxVars
+	case WithRec:
+		for _, choice := range rec.Zs {
+			idxVars = collectIdxVars(choice, idxVars)
+		}
+		return idxVars
+	case UpRec:
+		return collectIdxVars(rec.Z, idxVars)
+	case DownRec:
+		return collectIdxVars(rec.Z, idxVars)
+	case ExistsRec:
+		return append(idxVars, bound(rec.IdxVar, CollectIdxVars(rec.Z))...)
+	case ForallRec:
+		return append(idxVars, bound(rec.IdxVar, CollectIdxVars(rec.Z))...)
+	case AssertRec:
+		return collectIdxVars(rec.Z, append(idxVars, arithexp.CollectPropVars(rec.Prop)...))
+	case AssumeRec:
+		return collectIdxVars(rec.Z, append(idxVars, arithexp.CollectPropVars(rec.Prop)...))
+	case NextRec:
+		return collectIdxVars(rec.Z, idxVars)
+	case BoxRec:
+		return collectIdxVars(rec.Z, idxVars)
+	case DiamondRec:
+		return collectIdxVars(rec.Z, idxVars)
+	case AndRec:
+		return collectIdxVars(rec.Z, idxVars)
+	case ImplRec:
+		return collectIdxVars(rec.Z, idxVars)
+	default:
+		return idxVars
+	}
+}
+
+// variables except the bound idxVar
+func bound(idxVar symbol.ADT, idxVars []symbol.ADT) []symbol.ADT {
+	return slices.DeleteFunc(idxVars, func(v symbol.ADT) bool { return v == idxVar })
+}
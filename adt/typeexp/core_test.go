@@ -0,0 +1,247 @@
+package typeexp
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+func one() ExpRec {
+	return OneRec{ExpID: identity.New()}
+}
+
+func link(typeQN uniqsym.ADT) ExpRec {
+	return LinkRec{ExpID: identity.New(), TypeQN: typeQN}
+}
+
+func plus(labels ...symbol.ADT) PlusRec {
+	choices := make(map[uniqsym.ADT]ExpRec, len(labels))
+	for _, label := range labels {
+		choices[uniqsym.New(label)] = one()
+	}
+	return PlusRec{ExpID: identity.New(), Zs: choices}
+}
+
+func with(labels ...symbol.ADT) WithRec {
+	choices := make(map[uniqsym.ADT]ExpRec, len(labels))
+	for _, label := range labels {
+		choices[uniqsym.New(label)] = one()
+	}
+	return WithRec{ExpID: identity.New(), Zs: choices}
+}
+
+func lolli(y, z ExpRec) ExpRec {
+	return LolliRec{ExpID: identity.New(), Y: y, Z: z}
+}
+
+// env binds each name to its definition
+func newEnv(defs map[symbol.ADT]ExpRec) Env {
+	env := Env{
+		TypeIDs:  make(map[uniqsym.ADT]identity.ADT, len(defs)),
+		TypeExps: make(map[identity.ADT]ExpRec, len(defs)),
+	}
+	for name, def := range defs {
+		env.TypeIDs[uniqsym.New(name)] = def.Ident()
+		env.TypeExps[def.Ident()] = def
+	}
+	return env
+}
+
+func TestCheckEqualRecursive(t *testing.T) {
+	next := uniqsym.New("next")
+	// stream = &{next: stream}
+	stream := WithRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]ExpRec{next: link(uniqsym.New("stream"))}}
+	// stream2 = &{next: &{next: stream2}}
+	stream2 := WithRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]ExpRec{
+		next: WithRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]ExpRec{next: link(uniqsym.New("stream2"))}},
+	}}
+	// halt = &{next: &{next: 1}}
+	halt := WithRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]ExpRec{
+		next: WithRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]ExpRec{next: one()}},
+	}}
+	env := newEnv(map[symbol.ADT]ExpRec{"stream": stream, "stream2": stream2, "halt": halt})
+	tests := []struct {
+		name      string
+		got, want ExpRec
+		ok        bool
+	}{
+		{"same name", link(uniqsym.New("stream")), link(uniqsym.New("stream")), true},
+		{"unfolded once vs twice", link(uniqsym.New("stream")), link(uniqsym.New("stream2")), true},
+		{"name vs its body", stream, link(uniqsym.New("stream2")), true},
+		{"infinite vs finite", link(uniqsym.New("stream")), link(uniqsym.New("halt")), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckEqual(env, nil, test.got, test.want)
+			if (err == nil) != test.ok {
+				t.Errorf("got %v, want ok %v", err, test.ok)
+			}
+			err = CheckEqual(env, nil, test.want, test.got)
+			if (err == nil) != test.ok {
+				t.Errorf("got %v in reverse, want ok %v", err, test.ok)
+			}
+		})
+	}
+}
+
+func TestCheckSub(t *testing.T) {
+	a := uniqsym.New("a")
+	tests := []struct {
+		name      string
+		got, want ExpRec
+		sub       bool
+		equal     bool
+	}{
+		{"plus same width", plus("a", "b"), plus("a", "b"), true, true},
+		{"plus narrower", plus("a"), plus("a", "b"), true, false},
+		{"plus wider", plus("a", "b"), plus("a"), false, false},
+		{"with wider", with("a", "b"), with("a"), true, false},
+		{"with narrower", with("a"), with("a", "b"), false, false},
+		{"plus in depth",
+			PlusRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]ExpRec{a: plus("x")}},
+			PlusRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]ExpRec{a: plus("x", "y")}},
+			true, false},
+		{"with in depth",
+			WithRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]ExpRec{a: plus("x")}},
+			WithRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]ExpRec{a: plus("x", "y")}},
+			true, false},
+		{"with wider in depth",
+			WithRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]ExpRec{a: plus("x", "y")}},
+			WithRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]ExpRec{a: plus("x")}},
+			false, false},
+		{"lolli wider argument", lolli(plus("a", "b"), one()), lolli(plus("a"), one()), true, false},
+		{"lolli narrower argument", lolli(plus("a"), one()), lolli(plus("a", "b"), one()), false, false},
+		{"lolli narrower result", lolli(plus("a"), plus("a")), lolli(plus("a"), plus("a", "b")), true, false},
+		{"plus vs with", plus("a"), with("a"), false, false},
+	}
+	env := newEnv(nil)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckSub(env, nil, test.got, test.want)
+			if (err == nil) != test.sub {
+				t.Errorf("got %v, want subtype %v", err, test.sub)
+			}
+			err = CheckEqual(env, nil, test.got, test.want)
+			if (err == nil) != test.equal {
+				t.Errorf("got %v, want equal %v", err, test.equal)
+			}
+		})
+	}
+}
+
+func TestUnfoldStableIDs(t *testing.T) {
+	listQN := uniqsym.New("list")
+	nilL, consL := uniqsym.New("nil"), uniqsym.New("cons")
+	a := VarRec{ExpID: identity.New(), TypeVar: "a"}
+	// list[a] = +{nil: 1, cons: a * list[a]}
+	list := PlusRec{ExpID: identity.New(), Zs: map[uniqsym.ADT]ExpRec{
+		nilL: one(),
+		consL: TensorRec{
+			ExpID: identity.New(),
+			Y:     a,
+			Z:     LinkRec{ExpID: identity.New(), TypeQN: listQN, TypeERs: []ExpRec{a}},
+		},
+	}}
+	env := newEnv(map[symbol.ADT]ExpRec{"list": list})
+	env.TypeVars = map[uniqsym.ADT][]symbol.ADT{listQN: {"a"}}
+	first, err := env.Instance(listQN, []ExpRec{one()}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	second, err := env.Instance(listQN, []ExpRec{one()}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if !slices.Equal(CollectIDs(first), CollectIDs(second)) {
+		t.Errorf("got %v, want %v", CollectIDs(second), CollectIDs(first))
+	}
+	ids := CollectIDs(first)
+	if len(slices.Compact(slices.SortedFunc(slices.Values(ids), compareIDs))) != len(ids) {
+		t.Errorf("got duplicate ids %v", ids)
+	}
+	// the tail unfolds to the very same instance
+	tail := first.(PlusRec).Zs[consL].(TensorRec).Z
+	unfolded, err := env.Unfold(tail)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if unfolded.Ident() != first.Ident() {
+		t.Errorf("got %v, want %v", unfolded.Ident(), first.Ident())
+	}
+	other, err := env.Instance(listQN, []ExpRec{plus("x")}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if other.Ident() == first.Ident() {
+		t.Errorf("got same id %v for different args", other.Ident())
+	}
+	err = CheckEqual(env, nil, first, second)
+	if err != nil {
+		t.Errorf("unexpected error %q", err)
+	}
+}
+
+func compareIDs(a, b identity.ADT) int {
+	return strings.Compare(a.String(), b.String())
+}
+
+func TestCheckLabels(t *testing.T) {
+	specs := func(labels ...symbol.ADT) map[uniqsym.ADT]ExpSpec {
+		zs := make(map[uniqsym.ADT]ExpSpec, len(labels))
+		for _, label := range labels {
+			zs[uniqsym.New(label)] = OneSpec{}
+		}
+		return zs
+	}
+	env := newEnv(nil)
+	tests := []struct {
+		name  string
+		check func() error
+		// labels every one of which must be reported
+		want []string
+	}{
+		{"with specs", func() error {
+			return CheckSpec(WithSpec{Zs: specs("a", "b")}, WithSpec{Zs: specs("a", "b")})
+		}, nil},
+		{"with specs apart", func() error {
+			return CheckSpec(WithSpec{Zs: specs("a", "b")}, WithSpec{Zs: specs("c", "d")})
+		}, []string{"c", "d"}},
+		{"plus specs apart", func() error {
+			return CheckSpec(PlusSpec{Zs: specs("a", "b")}, PlusSpec{Zs: specs("c", "d")})
+		}, []string{"c", "d"}},
+		{"plus recs apart", func() error {
+			return CheckRec(plus("a", "b"), plus("c", "d"))
+		}, []string{"c", "d"}},
+		{"with recs apart", func() error {
+			return CheckRec(with("a", "b"), with("c", "d"))
+		}, []string{"c", "d"}},
+		// labels of the provided side are checked
+		{"equal recs apart", func() error {
+			return CheckEqual(env, nil, plus("a", "b"), plus("a", "d"))
+		}, []string{"b"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.check()
+			if len(test.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error %q", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrLabelMismatch) {
+				t.Fatalf("got %v, want %v", err, ErrLabelMismatch)
+			}
+			for _, label := range test.want {
+				if !strings.Contains(err.Error(), label) {
+					t.Errorf("got %q, want label %v reported", err, label)
+				}
+			}
+		})
+	}
+}
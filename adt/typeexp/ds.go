@@ -2,6 +2,7 @@ package typeexp
 
 import (
 	"database/sql"
+	"log/slog"
 
 	"orglang/go-runtime/lib/db"
 
@@ -15,6 +16,18 @@ type Repo interface {
 	SelectEnv(db.Source, []identity.ADT) (map[identity.ADT]ExpRec, error)
 }
 
+// storage adapter is chosen by the operator
+func newDAO(l *slog.Logger, o db.Operator) Repo {
+	switch o.(type) {
+	case *db.OperatorMem:
+		return newMemDAO(l)
+	case *db.OperatorSqlite:
+		return newSqliteDAO(l)
+	default:
+		return newPgxDAO(l)
+	}
+}
+
 type expKindDS int
 
 const (
@@ -25,6 +38,18 @@ const (
 	lolliExp
 	plusExp
 	withExp
+	upExp
+	downExp
+	varExp
+	existsExp
+	forallExp
+	assertExp
+	assumeExp
+	nextExp
+	boxExp
+	diamondExp
+	andExp
+	implExp
 )
 
 type ExpRefDS struct {
@@ -45,11 +70,26 @@ type stateDS struct {
 }
 
 type expSpecDS struct {
-	Link   string  `json:"link,omitempty"`
-	Tensor *prodDS `json:"tensor,omitempty"`
-	Lolli  *prodDS `json:"lolli,omitempty"`
-	Plus   []sumDS `json:"plus,omitempty"`
-	With   []sumDS `json:"with,omitempty"`
+	Link    string   `json:"link,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Idxs    []string `json:"idxs,omitempty"`
+	Var     string   `json:"var,omitempty"`
+	Tensor  *prodDS  `json:"tensor,omitempty"`
+	Lolli   *prodDS  `json:"lolli,omitempty"`
+	Plus    []sumDS  `json:"plus,omitempty"`
+	With    []sumDS  `json:"with,omitempty"`
+	Up      string   `json:"up,omitempty"`
+	Down    string   `json:"down,omitempty"`
+	Exists  *quantDS `json:"exists,omitempty"`
+	Forall  *quantDS `json:"forall,omitempty"`
+	Assert  *condDS  `json:"assert,omitempty"`
+	Assume  *condDS  `json:"assume,omitempty"`
+	Next    string   `json:"next,omitempty"`
+	Box     string   `json:"box,omitempty"`
+	Diamond string   `json:"diamond,omitempty"`
+	And     *dataDS  `json:"and,omitempty"`
+	Impl    *dataDS  `json:"impl,omitempty"`
+	Pot     int64    `json:"pot,omitempty"`
 }
 
 type prodDS struct {
@@ -61,3 +101,18 @@ type sumDS struct {
 	Lab    string `json:"on"`
 	ContES string `json:"to"`
 }
+
+type quantDS struct {
+	Var    string `json:"on"`
+	ContES string `json:"to"`
+}
+
+type condDS struct {
+	Prop   string `json:"on"`
+	ContES string `json:"to"`
+}
+
+type dataDS struct {
+	Type   string `json:"on"`
+	ContES string `json:"to"`
+}
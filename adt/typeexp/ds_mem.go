@@ -0,0 +1,86 @@
+package typeexp
+
+import (
+	"log/slog"
+	"reflect"
+	"slices"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(l *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{l.With(name)}
+}
+
+func (dao *memDAO) InsertRec(source db.Source, rec ExpRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	dto := DataFromExpRec(rec)
+	// derived exps repeat across steps and are stored once
+	stored := make(map[string]bool)
+	for _, st := range db.SelectMem[stateDS](ds, expStates) {
+		stored[st.ExpID] = true
+	}
+	fresh := slices.DeleteFunc(dto.States, func(st stateDS) bool { return stored[st.ExpID] })
+	db.InsertMem(ds, expStates, fresh...)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", slog.Any("expID", rec.Ident()))
+	return nil
+}
+
+func (dao *memDAO) SelectRecByID(source db.Source, expID identity.ADT) (ExpRec, error) {
+	recs, err := dao.SelectRecsByIDs(source, []identity.ADT{expID})
+	if err != nil {
+		return nil, err
+	}
+	return recs[0], nil
+}
+
+func (dao *memDAO) SelectEnv(source db.Source, expIDs []identity.ADT) (map[identity.ADT]ExpRec, error) {
+	recs, err := dao.SelectRecsByIDs(source, expIDs)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[identity.ADT]ExpRec, len(recs))
+	for _, rec := range recs {
+		env[rec.Ident()] = rec
+	}
+	return env, nil
+}
+
+func (dao *memDAO) SelectRecsByIDs(source db.Source, expIDs []identity.ADT) ([]ExpRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	// continuations are found by IDs from the states themselves
+	states := make(map[string]stateDS)
+	for _, st := range db.SelectMem[stateDS](ds, expStates) {
+		states[st.ExpID] = st
+	}
+	recs := make([]ExpRec, 0, len(expIDs))
+	for _, expID := range expIDs {
+		idAttr := slog.Any("expID", expID)
+		st, ok := states[expID.String()]
+		if !ok {
+			dao.log.Error("entity selection failed", idAttr)
+			return nil, ErrDoesNotExist(expID)
+		}
+		rec, err := statesToExpRec(states, st)
+		if err != nil {
+			dao.log.Error("model conversion failed", idAttr)
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("recs", recs))
+	return recs, nil
+}
+
+const (
+	expStates = "type_term_states"
+)
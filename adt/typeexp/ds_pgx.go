@@ -33,11 +33,14 @@ func (dao *pgxDAO) InsertRec(source db.Source, rec ExpRec) (err error) {
 	ds := db.MustConform[db.SourcePgx](source)
 	idAttr := slog.Any("termID", rec.Ident())
 	dto := DataFromExpRec(rec)
+	// derived exps repeat across steps and are stored once
 	query := `
-		INSERT INTO type_term_states (
+		INSERT INTO type_exps (
 			exp_id, kind, from_id, spec
-		) VALUES (
-			@exp_id, @kind, @from_id, @spec
+		)
+		SELECT @exp_id, @kind, @from_id, @spec
+		WHERE NOT EXISTS (
+			SELECT 1 FROM type_exps WHERE exp_id = @exp_id
 		)`
 	batch := pgx.Batch{}
 	for _, st := range dto.States {
@@ -70,13 +73,13 @@ func (dao *pgxDAO) SelectRecByID(source db.Source, termID identity.ADT) (ExpRec,
 	idAttr := slog.Any("termID", termID)
 	query := `
 		WITH RECURSIVE top_states AS (
-			SELECT rs.*
-			FROM type_term_states rs
-			WHERE id = $1
+			SELECT rs.exp_id, rs.kind, rs.from_id, rs.spec
+			FROM type_exps rs
+			WHERE rs.exp_id = $1
 			UNION ALL
-			SELECT bs.*
-			FROM type_term_states bs, top_states ts
-			WHERE bs.from_id = ts.id
+			SELECT bs.exp_id, bs.kind, bs.from_id, bs.spec
+			FROM type_exps bs, top_states ts
+			WHERE bs.from_id = ts.exp_id
 		)
 		SELECT * FROM top_states`
 	rows, err := ds.Conn.Query(ds.Ctx, query, termID.String())
@@ -95,11 +98,11 @@ func (dao *pgxDAO) SelectRecByID(source db.Source, termID identity.ADT) (ExpRec,
 		return nil, fmt.Errorf("no rows selected")
 	}
 	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", slog.Any("dtos", dtos))
-	type_term_states := make(map[string]stateDS, len(dtos))
+	states := make(map[string]stateDS, len(dtos))
 	for _, dto := range dtos {
-		type_term_states[dto.ExpID] = dto
+		states[dto.ExpID] = dto
 	}
-	return statesToExpRec(type_term_states, type_term_states[termID.String()])
+	return statesToExpRec(states, states[termID.String()])
 }
 
 func (dao *pgxDAO) SelectEnv(source db.Source, termIDs []identity.ADT) (map[identity.ADT]ExpRec, error) {
@@ -154,13 +157,13 @@ func (dao *pgxDAO) SelectRecsByIDs(source db.Source, termIDs []identity.ADT) (_
 const (
 	selectByID = `
 		WITH RECURSIVE state_tree AS (
-			SELECT root.*
-			FROM type_term_states root
-			WHERE id = $1
+			SELECT root.exp_id, root.kind, root.from_id, root.spec
+			FROM type_exps root
+			WHERE root.exp_id = $1
 			UNION ALL
-			SELECT child.*
-			FROM type_term_states child, state_tree parent
-			WHERE child.from_id = parent.id
+			SELECT child.exp_id, child.kind, child.from_id, child.spec
+			FROM type_exps child, state_tree parent
+			WHERE child.from_id = parent.exp_id
 		)
 		SELECT * FROM state_tree`
 )
@@ -0,0 +1,116 @@
+package typeexp
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
+)
+
+// Adapter
+type sqliteDAO struct {
+	log *slog.Logger
+}
+
+func newSqliteDAO(l *slog.Logger) *sqliteDAO {
+	name := slog.String("name", reflect.TypeFor[sqliteDAO]().Name())
+	return &sqliteDAO{l.With(name)}
+}
+
+func (dao *sqliteDAO) InsertRec(source db.Source, rec ExpRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	idAttr := slog.Any("expID", rec.Ident())
+	dto := DataFromExpRec(rec)
+	for _, st := range dto.States {
+		args := db.NamedArgsSqlite{
+			"exp_id":  st.ExpID,
+			"kind":    st.K,
+			"from_id": st.FromID,
+			"spec":    st.Spec,
+		}
+		_, err := ds.Conn.Exec(ds.Ctx, insertStateSqlite, args)
+		if err != nil {
+			dao.log.Error("query execution failed", idAttr, slog.String("q", insertStateSqlite))
+			return err
+		}
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", idAttr)
+	return nil
+}
+
+func (dao *sqliteDAO) SelectRecByID(source db.Source, expID identity.ADT) (ExpRec, error) {
+	recs, err := dao.SelectRecsByIDs(source, []identity.ADT{expID})
+	if err != nil {
+		return nil, err
+	}
+	return recs[0], nil
+}
+
+func (dao *sqliteDAO) SelectEnv(source db.Source, expIDs []identity.ADT) (map[identity.ADT]ExpRec, error) {
+	recs, err := dao.SelectRecsByIDs(source, expIDs)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[identity.ADT]ExpRec, len(recs))
+	for _, rec := range recs {
+		env[rec.Ident()] = rec
+	}
+	return env, nil
+}
+
+func (dao *sqliteDAO) SelectRecsByIDs(source db.Source, expIDs []identity.ADT) ([]ExpRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	recs := make([]ExpRec, 0, len(expIDs))
+	for _, expID := range expIDs {
+		idAttr := slog.Any("expID", expID)
+		rows, err := ds.Conn.Query(ds.Ctx, selectByIDSqlite, db.NamedArgsSqlite{"exp_id": expID.String()})
+		if err != nil {
+			dao.log.Error("query execution failed", idAttr, slog.String("q", selectByIDSqlite))
+			return nil, err
+		}
+		dtos, err := db.CollectRowsSqlite[stateDS](rows)
+		if err != nil {
+			dao.log.Error("rows collection failed", idAttr)
+			return nil, err
+		}
+		if len(dtos) == 0 {
+			dao.log.Error("entity selection failed", idAttr)
+			return nil, ErrDoesNotExist(expID)
+		}
+		rec, err := DataToExpRec(&expRecDS{expID.String(), dtos})
+		if err != nil {
+			dao.log.Error("model conversion failed", idAttr)
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("recs", recs))
+	return recs, nil
+}
+
+const (
+	// derived exps repeat across steps and are stored once
+	insertStateSqlite = `
+		insert into type_exps (
+			exp_id, kind, from_id, spec
+		)
+		select :exp_id, :kind, :from_id, :spec
+		where not exists (
+			select 1 from type_exps where exp_id = :exp_id
+		)`
+
+	selectByIDSqlite = `
+		with recursive state_tree as (
+			select root.exp_id, root.kind, root.from_id, root.spec
+			from type_exps root
+			where root.exp_id = :exp_id
+			union
+			select child.exp_id, child.kind, child.from_id, child.spec
+			from type_exps child, state_tree parent
+			where child.from_id = parent.exp_id
+		)
+		select * from state_tree`
+)
@@ -6,7 +6,9 @@ import (
 
 	"golang.org/x/exp/maps"
 
+	"orglang/go-runtime/adt/arithexp"
 	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/symbol"
 	"orglang/go-runtime/adt/uniqsym"
 
 	"github.com/orglang/go-sdk/adt/typeexp"
@@ -20,31 +22,61 @@ func ConvertSpecToRec(s ExpSpec) ExpRec {
 	case OneSpec:
 		return OneRec{ExpID: identity.New()}
 	case LinkSpec:
-		return LinkRec{ExpID: identity.New(), TypeQN: spec.TypeQN}
+		typeERs := make([]ExpRec, len(spec.TypeESs))
+		for i, typeES := range spec.TypeESs {
+			typeERs[i] = ConvertSpecToRec(typeES)
+		}
+		return LinkRec{ExpID: identity.New(), TypeQN: spec.TypeQN, TypeERs: typeERs, IdxESs: spec.IdxESs}
+	case VarSpec:
+		return VarRec{ExpID: identity.New(), TypeVar: spec.TypeVar}
 	case TensorSpec:
 		return TensorRec{
 			ExpID: identity.New(),
 			Y:     ConvertSpecToRec(spec.Y),
 			Z:     ConvertSpecToRec(spec.Z),
+			Pot:   spec.Pot,
 		}
 	case LolliSpec:
 		return LolliRec{
 			ExpID: identity.New(),
 			Y:     ConvertSpecToRec(spec.Y),
 			Z:     ConvertSpecToRec(spec.Z),
+			Pot:   spec.Pot,
 		}
 	case WithSpec:
 		choices := make(map[uniqsym.ADT]ExpRec, len(spec.Zs))
 		for lab, st := range spec.Zs {
 			choices[lab] = ConvertSpecToRec(st)
 		}
-		return WithRec{ExpID: identity.New(), Zs: choices}
+		return WithRec{ExpID: identity.New(), Zs: choices, Pot: spec.Pot}
 	case PlusSpec:
 		choices := make(map[uniqsym.ADT]ExpRec, len(spec.Zs))
 		for lab, rec := range spec.Zs {
 			choices[lab] = ConvertSpecToRec(rec)
 		}
-		return PlusRec{ExpID: identity.New(), Zs: choices}
+		return PlusRec{ExpID: identity.New(), Zs: choices, Pot: spec.Pot}
+	case UpSpec:
+		return UpRec{ExpID: identity.New(), Z: ConvertSpecToRec(spec.Z)}
+	case DownSpec:
+		return DownRec{ExpID: identity.New(), Z: ConvertSpecToRec(spec.Z)}
+	case ExistsSpec:
+		return ExistsRec{ExpID: identity.New(), IdxVar: spec.IdxVar, Z: ConvertSpecToRec(spec.Z)}
+	case ForallSpec:
+		return ForallRec{ExpID: identity.New(), IdxVar: spec.IdxVar, Z: ConvertSpecToRec(spec.Z)}
+	case AssertSpec:
+		return AssertRec{ExpID: identity.New(), Prop: spec.Prop, Z: ConvertSpecToRec(spec.Z)}
+	case AssumeSpec:
+		return AssumeRec{ExpID: identity.New(), Prop: spec.Prop, Z: ConvertSpecToRec(spec.Z)}
+	case NextSpec:
+		return NextRec{ExpID: identity.New(), Z: ConvertSpecToRec(spec.Z)}
+	case BoxSpec:
+		return BoxRec{ExpID: identity.New(), Z: ConvertSpecToRec(spec.Z)}
+	case DiamondSpec:
+		return DiamondRec{ExpID: identity.New(), Z: ConvertSpecToRec(spec.Z)}
+	case AndSpec:
+		return AndRec{ExpID: identity.New(), T: spec.T, Z: ConvertSpecToRec(spec.Z)}
+	case ImplSpec:
+		return ImplRec{ExpID: identity.New(), T: spec.T, Z: ConvertSpecToRec(spec.Z)}
 	default:
 		panic(ErrSpecTypeUnexpected(spec))
 	}
@@ -58,29 +90,59 @@ func ConvertRecToSpec(r ExpRec) ExpSpec {
 	case OneRec:
 		return OneSpec{}
 	case LinkRec:
-		return LinkSpec{TypeQN: rec.TypeQN}
+		typeESs := make([]ExpSpec, len(rec.TypeERs))
+		for i, typeER := range rec.TypeERs {
+			typeESs[i] = ConvertRecToSpec(typeER)
+		}
+		return LinkSpec{TypeQN: rec.TypeQN, TypeESs: typeESs, IdxESs: rec.IdxESs}
+	case VarRec:
+		return VarSpec{TypeVar: rec.TypeVar}
 	case TensorRec:
 		return TensorSpec{
-			Y: ConvertRecToSpec(rec.Y),
-			Z: ConvertRecToSpec(rec.Z),
+			Y:   ConvertRecToSpec(rec.Y),
+			Z:   ConvertRecToSpec(rec.Z),
+			Pot: rec.Pot,
 		}
 	case LolliRec:
 		return LolliSpec{
-			Y: ConvertRecToSpec(rec.Y),
-			Z: ConvertRecToSpec(rec.Z),
+			Y:   ConvertRecToSpec(rec.Y),
+			Z:   ConvertRecToSpec(rec.Z),
+			Pot: rec.Pot,
 		}
 	case WithRec:
 		choices := make(map[uniqsym.ADT]ExpSpec, len(rec.Zs))
 		for lab, rec := range rec.Zs {
 			choices[lab] = ConvertRecToSpec(rec)
 		}
-		return WithSpec{Zs: choices}
+		return WithSpec{Zs: choices, Pot: rec.Pot}
 	case PlusRec:
 		choices := make(map[uniqsym.ADT]ExpSpec, len(rec.Zs))
 		for lab, st := range rec.Zs {
 			choices[lab] = ConvertRecToSpec(st)
 		}
-		return PlusSpec{Zs: choices}
+		return PlusSpec{Zs: choices, Pot: rec.Pot}
+	case UpRec:
+		return UpSpec{Z: ConvertRecToSpec(rec.Z)}
+	case DownRec:
+		return DownSpec{Z: ConvertRecToSpec(rec.Z)}
+	case ExistsRec:
+		return ExistsSpec{IdxVar: rec.IdxVar, Z: ConvertRecToSpec(rec.Z)}
+	case ForallRec:
+		return ForallSpec{IdxVar: rec.IdxVar, Z: ConvertRecToSpec(rec.Z)}
+	case AssertRec:
+		return AssertSpec{Prop: rec.Prop, Z: ConvertRecToSpec(rec.Z)}
+	case AssumeRec:
+		return AssumeSpec{Prop: rec.Prop, Z: ConvertRecToSpec(rec.Z)}
+	case NextRec:
+		return NextSpec{Z: ConvertRecToSpec(rec.Z)}
+	case BoxRec:
+		return BoxSpec{Z: ConvertRecToSpec(rec.Z)}
+	case DiamondRec:
+		return DiamondSpec{Z: ConvertRecToSpec(rec.Z)}
+	case AndRec:
+		return AndSpec{T: rec.T, Z: ConvertRecToSpec(rec.Z)}
+	case ImplRec:
+		return ImplSpec{T: rec.T, Z: ConvertRecToSpec(rec.Z)}
 	default:
 		panic(ErrRecTypeUnexpected(rec))
 	}
@@ -91,15 +153,29 @@ func MsgFromExpSpec(s ExpSpec) typeexp.ExpSpec {
 	case OneSpec:
 		return typeexp.ExpSpec{K: typeexp.One}
 	case LinkSpec:
+		typeESs := make([]typeexp.ExpSpec, len(spec.TypeESs))
+		for i, typeES := range spec.TypeESs {
+			typeESs[i] = MsgFromExpSpec(typeES)
+		}
+		return typeexp.ExpSpec{
+			K: typeexp.Link,
+			Link: &typeexp.LinkSpec{
+				TypeQN:  uniqsym.ConvertToString(spec.TypeQN),
+				TypeESs: typeESs,
+				IdxESs:  arithexp.ConvertExpsToStrings(spec.IdxESs),
+			},
+		}
+	case VarSpec:
 		return typeexp.ExpSpec{
-			K:    typeexp.Link,
-			Link: &typeexp.LinkSpec{TypeQN: uniqsym.ConvertToString(spec.TypeQN)}}
+			K:   typeexp.Var,
+			Var: &typeexp.VarSpec{TypeVar: symbol.ConvertToString(spec.TypeVar)}}
 	case TensorSpec:
 		return typeexp.ExpSpec{
 			K: typeexp.Tensor,
 			Tensor: &typeexp.ProdSpec{
 				ValES:  MsgFromExpSpec(spec.Y),
 				ContES: MsgFromExpSpec(spec.Z),
+				Pot:    spec.Pot,
 			},
 		}
 	case LolliSpec:
@@ -108,6 +184,7 @@ func MsgFromExpSpec(s ExpSpec) typeexp.ExpSpec {
 			Lolli: &typeexp.ProdSpec{
 				ValES:  MsgFromExpSpec(spec.Y),
 				ContES: MsgFromExpSpec(spec.Z),
+				Pot:    spec.Pot,
 			},
 		}
 	case WithSpec:
@@ -118,7 +195,7 @@ func MsgFromExpSpec(s ExpSpec) typeexp.ExpSpec {
 				ContES: MsgFromExpSpec(spec.Zs[l]),
 			}
 		}
-		return typeexp.ExpSpec{K: typeexp.With, With: &typeexp.SumSpec{Choices: choices}}
+		return typeexp.ExpSpec{K: typeexp.With, With: &typeexp.SumSpec{Choices: choices, Pot: spec.Pot}}
 	case PlusSpec:
 		choices := make([]typeexp.ChoiceSpec, len(spec.Zs))
 		for i, l := range maps.Keys(spec.Zs) {
@@ -127,7 +204,70 @@ func MsgFromExpSpec(s ExpSpec) typeexp.ExpSpec {
 				ContES: MsgFromExpSpec(spec.Zs[l]),
 			}
 		}
-		return typeexp.ExpSpec{K: typeexp.Plus, Plus: &typeexp.SumSpec{Choices: choices}}
+		return typeexp.ExpSpec{K: typeexp.Plus, Plus: &typeexp.SumSpec{Choices: choices, Pot: spec.Pot}}
+	case ExistsSpec:
+		return typeexp.ExpSpec{
+			K: typeexp.Exists,
+			Exists: &typeexp.QuantSpec{
+				IdxVar: symbol.ConvertToString(spec.IdxVar),
+				ContES: MsgFromExpSpec(spec.Z),
+			},
+		}
+	case ForallSpec:
+		return typeexp.ExpSpec{
+			K: typeexp.Forall,
+			Forall: &typeexp.QuantSpec{
+				IdxVar: symbol.ConvertToString(spec.IdxVar),
+				ContES: MsgFromExpSpec(spec.Z),
+			},
+		}
+	case AssertSpec:
+		return typeexp.ExpSpec{
+			K: typeexp.Assert,
+			Assert: &typeexp.CondSpec{
+				Prop:   arithexp.ConvertPropToString(spec.Prop),
+				ContES: MsgFromExpSpec(spec.Z),
+			},
+		}
+	case AssumeSpec:
+		return typeexp.ExpSpec{
+			K: typeexp.Assume,
+			Assume: &typeexp.CondSpec{
+				Prop:   arithexp.ConvertPropToString(spec.Prop),
+				ContES: MsgFromExpSpec(spec.Z),
+			},
+		}
+	case NextSpec:
+		return typeexp.ExpSpec{
+			K:    typeexp.Next,
+			Next: &typeexp.ShiftSpec{ContES: MsgFromExpSpec(spec.Z)},
+		}
+	case BoxSpec:
+		return typeexp.ExpSpec{
+			K:   typeexp.Box,
+			Box: &typeexp.ShiftSpec{ContES: MsgFromExpSpec(spec.Z)},
+		}
+	case DiamondSpec:
+		return typeexp.ExpSpec{
+			K:       typeexp.Diamond,
+			Diamond: &typeexp.ShiftSpec{ContES: MsgFromExpSpec(spec.Z)},
+		}
+	case AndSpec:
+		return typeexp.ExpSpec{
+			K: typeexp.And,
+			And: &typeexp.DataSpec{
+				DataType: ConvertDataToString(spec.T),
+				ContES:   MsgFromExpSpec(spec.Z),
+			},
+		}
+	case ImplSpec:
+		return typeexp.ExpSpec{
+			K: typeexp.Impl,
+			Impl: &typeexp.DataSpec{
+				DataType: ConvertDataToString(spec.T),
+				ContES:   MsgFromExpSpec(spec.Z),
+			},
+		}
 	default:
 		panic(ErrSpecTypeUnexpected(s))
 	}
@@ -142,7 +282,25 @@ func MsgToExpSpec(dto typeexp.ExpSpec) (ExpSpec, error) {
 		if err != nil {
 			return nil, err
 		}
-		return LinkSpec{TypeQN: typeQN}, nil
+		var typeESs []ExpSpec
+		for _, arg := range dto.Link.TypeESs {
+			typeES, err := MsgToExpSpec(arg)
+			if err != nil {
+				return nil, err
+			}
+			typeESs = append(typeESs, typeES)
+		}
+		idxESs, err := arithexp.ConvertExpsFromStrings(dto.Link.IdxESs)
+		if err != nil {
+			return nil, err
+		}
+		return LinkSpec{TypeQN: typeQN, TypeESs: typeESs, IdxESs: idxESs}, nil
+	case typeexp.Var:
+		typeVar, err := symbol.ConvertFromString(dto.Var.TypeVar)
+		if err != nil {
+			return nil, err
+		}
+		return VarSpec{TypeVar: typeVar}, nil
 	case typeexp.Tensor:
 		valES, err := MsgToExpSpec(dto.Tensor.ValES)
 		if err != nil {
@@ -152,7 +310,7 @@ func MsgToExpSpec(dto typeexp.ExpSpec) (ExpSpec, error) {
 		if err != nil {
 			return nil, err
 		}
-		return TensorSpec{Y: valES, Z: contES}, nil
+		return TensorSpec{Y: valES, Z: contES, Pot: dto.Tensor.Pot}, nil
 	case typeexp.Lolli:
 		valES, err := MsgToExpSpec(dto.Lolli.ValES)
 		if err != nil {
@@ -162,7 +320,7 @@ func MsgToExpSpec(dto typeexp.ExpSpec) (ExpSpec, error) {
 		if err != nil {
 			return nil, err
 		}
-		return LolliSpec{Y: valES, Z: contES}, nil
+		return LolliSpec{Y: valES, Z: contES, Pot: dto.Lolli.Pot}, nil
 	case typeexp.Plus:
 		choices := make(map[uniqsym.ADT]ExpSpec, len(dto.Plus.Choices))
 		for _, ch := range dto.Plus.Choices {
@@ -176,7 +334,7 @@ func MsgToExpSpec(dto typeexp.ExpSpec) (ExpSpec, error) {
 			}
 			choices[label] = choice
 		}
-		return PlusSpec{Zs: choices}, nil
+		return PlusSpec{Zs: choices, Pot: dto.Plus.Pot}, nil
 	case typeexp.With:
 		choices := make(map[uniqsym.ADT]ExpSpec, len(dto.With.Choices))
 		for _, ch := range dto.With.Choices {
@@ -190,7 +348,85 @@ func MsgToExpSpec(dto typeexp.ExpSpec) (ExpSpec, error) {
 			}
 			choices[label] = choice
 		}
-		return WithSpec{Zs: choices}, nil
+		return WithSpec{Zs: choices, Pot: dto.With.Pot}, nil
+	case typeexp.Exists:
+		idxVar, err := symbol.ConvertFromString(dto.Exists.IdxVar)
+		if err != nil {
+			return nil, err
+		}
+		contES, err := MsgToExpSpec(dto.Exists.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return ExistsSpec{IdxVar: idxVar, Z: contES}, nil
+	case typeexp.Forall:
+		idxVar, err := symbol.ConvertFromString(dto.Forall.IdxVar)
+		if err != nil {
+			return nil, err
+		}
+		contES, err := MsgToExpSpec(dto.Forall.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return ForallSpec{IdxVar: idxVar, Z: contES}, nil
+	case typeexp.Assert:
+		prop, err := arithexp.ConvertPropFromString(dto.Assert.Prop)
+		if err != nil {
+			return nil, err
+		}
+		contES, err := MsgToExpSpec(dto.Assert.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return AssertSpec{Prop: prop, Z: contES}, nil
+	case typeexp.Assume:
+		prop, err := arithexp.ConvertPropFromString(dto.Assume.Prop)
+		if err != nil {
+			return nil, err
+		}
+		contES, err := MsgToExpSpec(dto.Assume.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return AssumeSpec{Prop: prop, Z: contES}, nil
+	case typeexp.Next:
+		contES, err := MsgToExpSpec(dto.Next.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return NextSpec{Z: contES}, nil
+	case typeexp.Box:
+		contES, err := MsgToExpSpec(dto.Box.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return BoxSpec{Z: contES}, nil
+	case typeexp.Diamond:
+		contES, err := MsgToExpSpec(dto.Diamond.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return DiamondSpec{Z: contES}, nil
+	case typeexp.And:
+		t, err := ConvertDataFromString(dto.And.DataType)
+		if err != nil {
+			return nil, err
+		}
+		contES, err := MsgToExpSpec(dto.And.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return AndSpec{T: t, Z: contES}, nil
+	case typeexp.Impl:
+		t, err := ConvertDataFromString(dto.Impl.DataType)
+		if err != nil {
+			return nil, err
+		}
+		contES, err := MsgToExpSpec(dto.Impl.ContES)
+		if err != nil {
+			return nil, err
+		}
+		return ImplSpec{T: t, Z: contES}, nil
 	default:
 		panic(typeexp.ErrKindUnexpected(dto.K))
 	}
@@ -203,6 +439,8 @@ func MsgFromExpRef(r ExpRef) typeexp.ExpRef {
 		return typeexp.ExpRef{K: typeexp.One, ExpID: ident}
 	case LinkRef, LinkRec:
 		return typeexp.ExpRef{K: typeexp.Link, ExpID: ident}
+	case VarRef, VarRec:
+		return typeexp.ExpRef{K: typeexp.Var, ExpID: ident}
 	case TensorRef, TensorRec:
 		return typeexp.ExpRef{K: typeexp.Tensor, ExpID: ident}
 	case LolliRef, LolliRec:
@@ -211,6 +449,24 @@ func MsgFromExpRef(r ExpRef) typeexp.ExpRef {
 		return typeexp.ExpRef{K: typeexp.Plus, ExpID: ident}
 	case WithRef, WithRec:
 		return typeexp.ExpRef{K: typeexp.With, ExpID: ident}
+	case ExistsRef, ExistsRec:
+		return typeexp.ExpRef{K: typeexp.Exists, ExpID: ident}
+	case ForallRef, ForallRec:
+		return typeexp.ExpRef{K: typeexp.Forall, ExpID: ident}
+	case AssertRef, AssertRec:
+		return typeexp.ExpRef{K: typeexp.Assert, ExpID: ident}
+	case AssumeRef, AssumeRec:
+		return typeexp.ExpRef{K: typeexp.Assume, ExpID: ident}
+	case NextRef, NextRec:
+		return typeexp.ExpRef{K: typeexp.Next, ExpID: ident}
+	case BoxRef, BoxRec:
+		return typeexp.ExpRef{K: typeexp.Box, ExpID: ident}
+	case DiamondRef, DiamondRec:
+		return typeexp.ExpRef{K: typeexp.Diamond, ExpID: ident}
+	case AndRef, AndRec:
+		return typeexp.ExpRef{K: typeexp.And, ExpID: ident}
+	case ImplRef, ImplRec:
+		return typeexp.ExpRef{K: typeexp.Impl, ExpID: ident}
 	default:
 		panic(ErrRefTypeUnexpected(r))
 	}
@@ -226,6 +482,8 @@ func MsgToExpRef(dto typeexp.ExpRef) (ExpRef, error) {
 		return OneRef{expID}, nil
 	case typeexp.Link:
 		return LinkRef{expID}, nil
+	case typeexp.Var:
+		return VarRef{expID}, nil
 	case typeexp.Tensor:
 		return TensorRef{expID}, nil
 	case typeexp.Lolli:
@@ -234,6 +492,24 @@ func MsgToExpRef(dto typeexp.ExpRef) (ExpRef, error) {
 		return PlusRef{expID}, nil
 	case typeexp.With:
 		return WithRef{expID}, nil
+	case typeexp.Exists:
+		return ExistsRef{expID}, nil
+	case typeexp.Forall:
+		return ForallRef{expID}, nil
+	case typeexp.Assert:
+		return AssertRef{expID}, nil
+	case typeexp.Assume:
+		return AssumeRef{expID}, nil
+	case typeexp.Next:
+		return NextRef{expID}, nil
+	case typeexp.Box:
+		return BoxRef{expID}, nil
+	case typeexp.Diamond:
+		return DiamondRef{expID}, nil
+	case typeexp.And:
+		return AndRef{expID}, nil
+	case typeexp.Impl:
+		return ImplRef{expID}, nil
 	default:
 		panic(typeexp.ErrKindUnexpected(dto.K))
 	}
@@ -249,6 +525,8 @@ func DataFromExpRef(ref ExpRef) *ExpRefDS {
 		return &ExpRefDS{K: oneExp, ExpID: expID}
 	case LinkRef, LinkRec:
 		return &ExpRefDS{K: linkExp, ExpID: expID}
+	case VarRef, VarRec:
+		return &ExpRefDS{K: varExp, ExpID: expID}
 	case TensorRef, TensorRec:
 		return &ExpRefDS{K: tensorExp, ExpID: expID}
 	case LolliRef, LolliRec:
@@ -257,6 +535,28 @@ func DataFromExpRef(ref ExpRef) *ExpRefDS {
 		return &ExpRefDS{K: plusExp, ExpID: expID}
 	case WithRef, WithRec:
 		return &ExpRefDS{K: withExp, ExpID: expID}
+	case UpRef, UpRec:
+		return &ExpRefDS{K: upExp, ExpID: expID}
+	case DownRef, DownRec:
+		return &ExpRefDS{K: downExp, ExpID: expID}
+	case ExistsRef, ExistsRec:
+		return &ExpRefDS{K: existsExp, ExpID: expID}
+	case ForallRef, ForallRec:
+		return &ExpRefDS{K: forallExp, ExpID: expID}
+	case AssertRef, AssertRec:
+		return &ExpRefDS{K: assertExp, ExpID: expID}
+	case AssumeRef, AssumeRec:
+		return &ExpRefDS{K: assumeExp, ExpID: expID}
+	case NextRef, NextRec:
+		return &ExpRefDS{K: nextExp, ExpID: expID}
+	case BoxRef, BoxRec:
+		return &ExpRefDS{K: boxExp, ExpID: expID}
+	case DiamondRef, DiamondRec:
+		return &ExpRefDS{K: diamondExp, ExpID: expID}
+	case AndRef, AndRec:
+		return &ExpRefDS{K: andExp, ExpID: expID}
+	case ImplRef, ImplRec:
+		return &ExpRefDS{K: implExp, ExpID: expID}
 	default:
 		panic(ErrRefTypeUnexpected(ref))
 	}
@@ -275,6 +575,8 @@ func DataToExpRef(dto *ExpRefDS) (ExpRef, error) {
 		return OneRef{expID}, nil
 	case linkExp:
 		return LinkRef{expID}, nil
+	case varExp:
+		return VarRef{expID}, nil
 	case tensorExp:
 		return TensorRef{expID}, nil
 	case lolliExp:
@@ -283,6 +585,28 @@ func DataToExpRef(dto *ExpRefDS) (ExpRef, error) {
 		return PlusRef{expID}, nil
 	case withExp:
 		return WithRef{expID}, nil
+	case upExp:
+		return UpRef{expID}, nil
+	case downExp:
+		return DownRef{expID}, nil
+	case existsExp:
+		return ExistsRef{expID}, nil
+	case forallExp:
+		return ForallRef{expID}, nil
+	case assertExp:
+		return AssertRef{expID}, nil
+	case assumeExp:
+		return AssumeRef{expID}, nil
+	case nextExp:
+		return NextRef{expID}, nil
+	case boxExp:
+		return BoxRef{expID}, nil
+	case diamondExp:
+		return DiamondRef{expID}, nil
+	case andExp:
+		return AndRef{expID}, nil
+	case implExp:
+		return ImplRef{expID}, nil
 	default:
 		panic(errUnexpectedKind(dto.K))
 	}
@@ -321,7 +645,25 @@ func statesToExpRec(states map[string]stateDS, st stateDS) (ExpRec, error) {
 		if err != nil {
 			return nil, err
 		}
-		return LinkRec{ExpID: stID, TypeQN: roleQN}, nil
+		var typeERs []ExpRec
+		for _, argID := range st.Spec.Args {
+			typeER, err := statesToExpRec(states, states[argID])
+			if err != nil {
+				return nil, err
+			}
+			typeERs = append(typeERs, typeER)
+		}
+		idxESs, err := arithexp.ConvertExpsFromStrings(st.Spec.Idxs)
+		if err != nil {
+			return nil, err
+		}
+		return LinkRec{ExpID: stID, TypeQN: roleQN, TypeERs: typeERs, IdxESs: idxESs}, nil
+	case varExp:
+		typeVar, err := symbol.ConvertFromString(st.Spec.Var)
+		if err != nil {
+			return nil, err
+		}
+		return VarRec{ExpID: stID, TypeVar: typeVar}, nil
 	case tensorExp:
 		b, err := statesToExpRec(states, states[st.Spec.Tensor.ValES])
 		if err != nil {
@@ -331,7 +673,7 @@ func statesToExpRec(states map[string]stateDS, st stateDS) (ExpRec, error) {
 		if err != nil {
 			return nil, err
 		}
-		return TensorRec{ExpID: stID, Y: b, Z: c}, nil
+		return TensorRec{ExpID: stID, Y: b, Z: c, Pot: st.Spec.Pot}, nil
 	case lolliExp:
 		y, err := statesToExpRec(states, states[st.Spec.Lolli.ValES])
 		if err != nil {
@@ -341,7 +683,7 @@ func statesToExpRec(states map[string]stateDS, st stateDS) (ExpRec, error) {
 		if err != nil {
 			return nil, err
 		}
-		return LolliRec{ExpID: stID, Y: y, Z: z}, nil
+		return LolliRec{ExpID: stID, Y: y, Z: z, Pot: st.Spec.Pot}, nil
 	case plusExp:
 		choices := make(map[uniqsym.ADT]ExpRec, len(st.Spec.Plus))
 		for _, ch := range st.Spec.Plus {
@@ -355,7 +697,7 @@ func statesToExpRec(states map[string]stateDS, st stateDS) (ExpRec, error) {
 			}
 			choices[label] = choice
 		}
-		return PlusRec{ExpID: stID, Zs: choices}, nil
+		return PlusRec{ExpID: stID, Zs: choices, Pot: st.Spec.Pot}, nil
 	case withExp:
 		choices := make(map[uniqsym.ADT]ExpRec, len(st.Spec.With))
 		for _, ch := range st.Spec.With {
@@ -369,7 +711,97 @@ func statesToExpRec(states map[string]stateDS, st stateDS) (ExpRec, error) {
 			}
 			choices[label] = choice
 		}
-		return WithRec{ExpID: stID, Zs: choices}, nil
+		return WithRec{ExpID: stID, Zs: choices, Pot: st.Spec.Pot}, nil
+	case upExp:
+		z, err := statesToExpRec(states, states[st.Spec.Up])
+		if err != nil {
+			return nil, err
+		}
+		return UpRec{ExpID: stID, Z: z}, nil
+	case downExp:
+		z, err := statesToExpRec(states, states[st.Spec.Down])
+		if err != nil {
+			return nil, err
+		}
+		return DownRec{ExpID: stID, Z: z}, nil
+	case existsExp:
+		idxVar, err := symbol.ConvertFromString(st.Spec.Exists.Var)
+		if err != nil {
+			return nil, err
+		}
+		z, err := statesToExpRec(states, states[st.Spec.Exists.ContES])
+		if err != nil {
+			return nil, err
+		}
+		return ExistsRec{ExpID: stID, IdxVar: idxVar, Z: z}, nil
+	case forallExp:
+		idxVar, err := symbol.ConvertFromString(st.Spec.Forall.Var)
+		if err != nil {
+			return nil, err
+		}
+		z, err := statesToExpRec(states, states[st.Spec.Forall.ContES])
+		if err != nil {
+			return nil, err
+		}
+		return ForallRec{ExpID: stID, IdxVar: idxVar, Z: z}, nil
+	case assertExp:
+		prop, err := arithexp.ConvertPropFromString(st.Spec.Assert.Prop)
+		if err != nil {
+			return nil, err
+		}
+		z, err := statesToExpRec(states, states[st.Spec.Assert.ContES])
+		if err != nil {
+			return nil, err
+		}
+		return AssertRec{ExpID: stID, Prop: prop, Z: z}, nil
+	case assumeExp:
+		prop, err := arithexp.ConvertPropFromString(st.Spec.Assume.Prop)
+		if err != nil {
+			return nil, err
+		}
+		z, err := statesToExpRec(states, states[st.Spec.Assume.ContES])
+		if err != nil {
+			return nil, err
+		}
+		return AssumeRec{ExpID: stID, Prop: prop, Z: z}, nil
+	case nextExp:
+		z, err := statesToExpRec(states, states[st.Spec.Next])
+		if err != nil {
+			return nil, err
+		}
+		return NextRec{ExpID: stID, Z: z}, nil
+	case boxExp:
+		z, err := statesToExpRec(states, states[st.Spec.Box])
+		if err != nil {
+			return nil, err
+		}
+		return BoxRec{ExpID: stID, Z: z}, nil
+	case diamondExp:
+		z, err := statesToExpRec(states, states[st.Spec.Diamond])
+		if err != nil {
+			return nil, err
+		}
+		return DiamondRec{ExpID: stID, Z: z}, nil
+	case andExp:
+		t, err := ConvertDataFromString(st.Spec.And.Type)
+		if err != nil {
+			return nil, err
+		}
+		z, err := statesToExpRec(states, states[st.Spec.And.ContES])
+		if err != nil {
+			return nil, err
+		}
+		return AndRec{ExpID: stID, T: t, Z: z}, nil
+	case implExp:
+		t, err := ConvertDataFromString(st.Spec.Impl.Type)
+		if err != nil {
+			return nil, err
+		}
+		z, err := statesToExpRec(states, states[st.Spec.Impl.ContES])
+		if err != nil {
+			return nil, err
+		}
+		return ImplRec{ExpID: stID, T: t, Z: z}, nil
 	default:
 		panic(errUnexpectedKind(st.K))
 	}
@@ -387,12 +819,33 @@ func statesFromExpRec(from string, r ExpRec, dto *expRecDS) (string, error) {
 		dto.States = append(dto.States, st)
 		return stID, nil
 	case LinkRec:
+		var args []string
+		for _, typeER := range root.TypeERs {
+			arg, err := statesFromExpRec(stID, typeER, dto)
+			if err != nil {
+				return "", err
+			}
+			args = append(args, arg)
+		}
 		st := stateDS{
 			ExpID:  stID,
 			K:      linkExp,
 			FromID: fromID,
 			Spec: expSpecDS{
 				Link: uniqsym.ConvertToString(root.TypeQN),
+				Args: args,
+				Idxs: arithexp.ConvertExpsToStrings(root.IdxESs),
+			},
+		}
+		dto.States = append(dto.States, st)
+		return stID, nil
+	case VarRec:
+		st := stateDS{
+			ExpID:  stID,
+			K:      varExp,
+			FromID: fromID,
+			Spec: expSpecDS{
+				Var: symbol.ConvertToString(root.TypeVar),
 			},
 		}
 		dto.States = append(dto.States, st)
@@ -412,6 +865,7 @@ func statesFromExpRec(from string, r ExpRec, dto *expRecDS) (string, error) {
 			FromID: fromID,
 			Spec: expSpecDS{
 				Tensor: &prodDS{val, cont},
+				Pot:    root.Pot,
 			},
 		}
 		dto.States = append(dto.States, st)
@@ -431,6 +885,7 @@ func statesFromExpRec(from string, r ExpRec, dto *expRecDS) (string, error) {
 			FromID: fromID,
 			Spec: expSpecDS{
 				Lolli: &prodDS{val, cont},
+				Pot:   root.Pot,
 			},
 		}
 		dto.States = append(dto.States, st)
@@ -448,7 +903,7 @@ func statesFromExpRec(from string, r ExpRec, dto *expRecDS) (string, error) {
 			ExpID:  stID,
 			K:      plusExp,
 			FromID: fromID,
-			Spec:   expSpecDS{Plus: choices},
+			Spec:   expSpecDS{Plus: choices, Pot: root.Pot},
 		}
 		dto.States = append(dto.States, st)
 		return stID, nil
@@ -465,7 +920,162 @@ func statesFromExpRec(from string, r ExpRec, dto *expRecDS) (string, error) {
 			ExpID:  stID,
 			K:      withExp,
 			FromID: fromID,
-			Spec:   expSpecDS{With: choices},
+			Spec:   expSpecDS{With: choices, Pot: root.Pot},
+		}
+		dto.States = append(dto.States, st)
+		return stID, nil
+	case UpRec:
+		cont, err := statesFromExpRec(stID, root.Z, dto)
+		if err != nil {
+			return "", err
+		}
+		st := stateDS{
+			ExpID:  stID,
+			K:      upExp,
+			FromID: fromID,
+			Spec:   expSpecDS{Up: cont},
+		}
+		dto.States = append(dto.States, st)
+		return stID, nil
+	case DownRec:
+		cont, err := statesFromExpRec(stID, root.Z, dto)
+		if err != nil {
+			return "", err
+		}
+		st := stateDS{
+			ExpID:  stID,
+			K:      downExp,
+			FromID: fromID,
+			Spec:   expSpecDS{Down: cont},
+		}
+		dto.States = append(dto.States, st)
+		return stID, nil
+	case ExistsRec:
+		cont, err := statesFromExpRec(stID, root.Z, dto)
+		if err != nil {
+			return "", err
+		}
+		st := stateDS{
+			ExpID:  stID,
+			K:      existsExp,
+			FromID: fromID,
+			Spec: expSpecDS{
+				Exists: &quantDS{symbol.ConvertToString(root.IdxVar), cont},
+			},
+		}
+		dto.States = append(dto.States, st)
+		return stID, nil
+	case ForallRec:
+		cont, err := statesFromExpRec(stID, root.Z, dto)
+		if err != nil {
+			return "", err
+		}
+		st := stateDS{
+			ExpID:  stID,
+			K:      forallExp,
+			FromID: fromID,
+			Spec: expSpecDS{
+				Forall: &quantDS{symbol.ConvertToString(root.IdxVar), cont},
+			},
+		}
+		dto.States = append(dto.States, st)
+		return stID, nil
+	case AssertRec:
+		cont, err := statesFromExpRec(stID, root.Z, dto)
+		if err != nil {
+			return "", err
+		}
+		st := stateDS{
+			ExpID:  stID,
+			K:      assertExp,
+			FromID: fromID,
+			Spec: expSpecDS{
+				Assert: &condDS{arithexp.ConvertPropToString(root.Prop), cont},
+			},
+		}
+		dto.States = append(dto.States, st)
+		return stID, nil
+	case AssumeRec:
+		cont, err := statesFromExpRec(stID, root.Z, dto)
+		if err != nil {
+			return "", err
+		}
+		st := stateDS{
+			ExpID:  stID,
+			K:      assumeExp,
+			FromID: fromID,
+			Spec: expSpecDS{
+				Assume: &condDS{arithexp.ConvertPropToString(root.Prop), cont},
+			},
+		}
+		dto.States = append(dto.States, st)
+		return stID, nil
+	case NextRec:
+		cont, err := statesFromExpRec(stID, root.Z, dto)
+		if err != nil {
+			return "", err
+		}
+		st := stateDS{
+			ExpID:  stID,
+			K:      nextExp,
+			FromID: fromID,
+			Spec:   expSpecDS{Next: cont},
+		}
+		dto.States = append(dto.States, st)
+		return stID, nil
+	case BoxRec:
+		cont, err := statesFromExpRec(stID, root.Z, dto)
+		if err != nil {
+			return "", err
+		}
+		st := stateDS{
+			ExpID:  stID,
+			K:      boxExp,
+			FromID: fromID,
+			Spec:   expSpecDS{Box: cont},
+		}
+		dto.States = append(dto.States, st)
+		return stID, nil
+	case DiamondRec:
+		cont, err := statesFromExpRec(stID, root.Z, dto)
+		if err != nil {
+			return "", err
+		}
+		st := stateDS{
+			ExpID:  stID,
+			K:      diamondExp,
+			FromID: fromID,
+			Spec:   expSpecDS{Diamond: cont},
+		}
+		dto.States = append(dto.States, st)
+		return stID, nil
+	case AndRec:
+		cont, err := statesFromExpRec(stID, root.Z, dto)
+		if err != nil {
+			return "", err
+		}
+		st := stateDS{
+			ExpID:  stID,
+			K:      andExp,
+			FromID: fromID,
+			Spec: expSpecDS{
+				And: &dataDS{ConvertDataToString(root.T), cont},
+			},
+		}
+		dto.States = append(dto.States, st)
+		return stID, nil
+	case ImplRec:
+		cont, err := statesFromExpRec(stID, root.Z, dto)
+		if err != nil {
+			return "", err
+		}
+		st := stateDS{
+			ExpID:  stID,
+			K:      implExp,
+			FromID: fromID,
+			Spec: expSpecDS{
+				Impl: &dataDS{ConvertDataToString(root.T), cont},
+			},
 		}
 		dto.States = append(dto.States, st)
 		return stID, nil
@@ -474,6 +1084,36 @@ func statesFromExpRec(from string, r ExpRec, dto *expRecDS) (string, error) {
 	}
 }
 
+func ConvertDataToString(t DataType) string {
+	switch t {
+	case IntData:
+		return "int"
+	case StrData:
+		return "str"
+	case BoolData:
+		return "bool"
+	case JSONData:
+		return "json"
+	default:
+		panic(ErrDataTypeUnexpected(t))
+	}
+}
+
+func ConvertDataFromString(str string) (DataType, error) {
+	switch str {
+	case "int":
+		return IntData, nil
+	case "str":
+		return StrData, nil
+	case "bool":
+		return BoolData, nil
+	case "json":
+		return JSONData, nil
+	default:
+		return NonData, fmt.Errorf("data type unexpected: %q", str)
+	}
+}
+
 func errUnexpectedKind(k expKindDS) error {
 	return fmt.Errorf("unexpected kind %q", k)
 }
@@ -31,7 +31,10 @@ func (adt ADT) NS() ADT {
 }
 
 func (a ADT) Equal(b ADT) bool {
-	if a.sym == b.sym && a.ns == b.ns {
+	if a.sym != b.sym {
+		return false
+	}
+	if a.ns == b.ns {
 		return true
 	}
 	if a.ns == nil || b.ns == nil {
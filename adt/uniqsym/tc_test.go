@@ -53,3 +53,27 @@ func TestConvertFromStringError(t *testing.T) {
 		})
 	}
 }
+
+func TestEqual(t *testing.T) {
+	var equalTests = []struct {
+		name string
+		a    ADT
+		b    ADT
+		want bool
+	}{
+		{"same sym", ADT{"a", nil}, ADT{"a", nil}, true},
+		{"same sym and ns", ADT{"a", &ADT{"b", nil}}, ADT{"a", &ADT{"b", nil}}, true},
+		{"other sym", ADT{"a", nil}, ADT{"c", nil}, false},
+		{"other sym same ns", ADT{"a", &ADT{"b", nil}}, ADT{"c", &ADT{"b", nil}}, false},
+		{"other ns", ADT{"a", &ADT{"b", nil}}, ADT{"a", &ADT{"c", nil}}, false},
+		{"missing ns", ADT{"a", &ADT{"b", nil}}, ADT{"a", nil}, false},
+	}
+	for _, test := range equalTests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.a.Equal(test.b)
+			if got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -1,18 +1,30 @@
 package xactdef
 
 import (
+	"context"
+	"fmt"
+	"iter"
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+
 	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/revnum"
+	"orglang/go-runtime/adt/symbol"
+	"orglang/go-runtime/adt/syndec"
 	"orglang/go-runtime/adt/uniqref"
 	"orglang/go-runtime/adt/uniqsym"
 	"orglang/go-runtime/adt/xactexp"
 )
 
 type API interface {
-	Incept(uniqsym.ADT) (DefRef, error)
-	Create(DefSpec) (DefSnap, error)
-	Modify(DefSnap) (DefSnap, error)
-	RetrieveSnap(DefRef) (DefSnap, error)
-	RetreiveRefs() ([]DefRef, error)
+	Incept(context.Context, uniqsym.ADT) (DefRef, error)
+	Create(context.Context, DefSpec) (DefSnap, error)
+	Modify(context.Context, DefSnap) (DefSnap, error)
+	RetrieveSnap(context.Context, DefRef) (DefSnap, error)
+	retrieveSnap(context.Context, DefRec) (DefSnap, error)
+	RetreiveRefs(context.Context) ([]DefRef, error)
 }
 
 type DefRef = uniqref.ADT
@@ -25,11 +37,223 @@ type DefSpec struct {
 type DefRec struct {
 	DefRef DefRef
 	Title  string
+	XactQN uniqsym.ADT
 	ExpID  identity.ADT
 }
 
 type DefSnap struct {
-	DefRef  DefRef
-	DefSpec DefSpec
-	Title   string
+	DefRef DefRef
+	Title  string
+	XactQN uniqsym.ADT
+	XactES xactexp.ExpSpec
+}
+
+type service struct {
+	xactDefs Repo
+	xactExps xactexp.Repo
+	synDecs  syndec.Repo
+	operator db.Operator
+	log      *slog.Logger
+}
+
+// for compilation purposes
+func newAPI() API {
+	return &service{}
+}
+
+func newService(
+	xactDefs Repo,
+	xactExps xactexp.Repo,
+	synDecs syndec.Repo,
+	operator db.Operator,
+	l *slog.Logger,
+) *service {
+	name := slog.String("name", reflect.TypeFor[service]().Name())
+	return &service{xactDefs, xactExps, synDecs, operator, l.With(name)}
+}
+
+func (s *service) Incept(ctx context.Context, xactQN uniqsym.ADT) (_ DefRef, err error) {
+	qnAttr := slog.Any("xactQN", xactQN)
+	s.log.Debug("inception started", qnAttr)
+	newSyn := syndec.DecRec{DecQN: xactQN, DecID: identity.New(), DecRN: revnum.New()}
+	newXact := DefRec{
+		DefRef: DefRef{ID: newSyn.DecID, RN: newSyn.DecRN},
+		Title:  symbol.ConvertToString(newSyn.DecQN.Sym()),
+		XactQN: newSyn.DecQN,
+	}
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		err = s.synDecs.Insert(ds, newSyn)
+		if err != nil {
+			return err
+		}
+		return s.xactDefs.Insert(ds, newXact)
+	})
+	if err != nil {
+		s.log.Error("inception failed", qnAttr)
+		return DefRef{}, err
+	}
+	s.log.Debug("inception succeed", qnAttr, slog.Any("defRef", newXact.DefRef))
+	return newXact.DefRef, nil
+}
+
+func (s *service) Create(ctx context.Context, spec DefSpec) (_ DefSnap, err error) {
+	qnAttr := slog.Any("xactQN", spec.XactQN)
+	s.log.Debug("creation started", qnAttr, slog.Any("spec", spec))
+	newSyn := syndec.DecRec{DecQN: spec.XactQN, DecID: identity.New(), DecRN: revnum.New()}
+	newExp := xactexp.ConvertSpecToRec(spec.XactES)
+	newXact := DefRec{
+		DefRef: DefRef{ID: newSyn.DecID, RN: newSyn.DecRN},
+		Title:  symbol.ConvertToString(newSyn.DecQN.Sym()),
+		XactQN: newSyn.DecQN,
+		ExpID:  newExp.Ident(),
+	}
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		err = s.synDecs.Insert(ds, newSyn)
+		if err != nil {
+			return err
+		}
+		err = s.xactExps.InsertRec(ds, newExp)
+		if err != nil {
+			return err
+		}
+		return s.xactDefs.Insert(ds, newXact)
+	})
+	if err != nil {
+		s.log.Error("creation failed", qnAttr)
+		return DefSnap{}, err
+	}
+	s.log.Debug("creation succeed", qnAttr, slog.Any("defRef", newXact.DefRef))
+	return DefSnap{
+		DefRef: newXact.DefRef,
+		Title:  newXact.Title,
+		XactQN: newXact.XactQN,
+		XactES: xactexp.ConvertRecToSpec(newExp),
+	}, nil
+}
+
+func (s *service) Modify(ctx context.Context, snap DefSnap) (_ DefSnap, err error) {
+	refAttr := slog.Any("defRef", snap.DefRef)
+	s.log.Debug("modification started", refAttr)
+	var rec DefRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		rec, err = s.xactDefs.SelectRecByRef(ds, snap.DefRef)
+		return err
+	})
+	if err != nil {
+		s.log.Error("modification failed", refAttr)
+		return DefSnap{}, err
+	}
+	if snap.DefRef.RN != rec.DefRef.RN {
+		s.log.Error("modification failed", refAttr)
+		return DefSnap{}, errConcurrentModification(snap.DefRef.RN, rec.DefRef.RN)
+	}
+	// there is no expression yet after inception
+	if !rec.ExpID.IsEmpty() {
+		curSnap, err := s.retrieveSnap(ctx, rec)
+		if err != nil {
+			s.log.Error("modification failed", refAttr)
+			return DefSnap{}, err
+		}
+		if xactexp.CheckSpec(snap.XactES, curSnap.XactES) == nil {
+			s.log.Debug("modification skipped", refAttr)
+			return curSnap, nil
+		}
+	}
+	newExp := xactexp.ConvertSpecToRec(snap.XactES)
+	rec.ExpID = newExp.Ident()
+	rec.DefRef.RN = revnum.Next(rec.DefRef.RN)
+	err = s.operator.Explicit(ctx, func(ds db.Source) error {
+		err = s.xactExps.InsertRec(ds, newExp)
+		if err != nil {
+			return err
+		}
+		return s.xactDefs.Update(ds, rec)
+	})
+	if err != nil {
+		s.log.Error("modification failed", refAttr)
+		return DefSnap{}, err
+	}
+	s.log.Debug("modification succeed", slog.Any("defRef", rec.DefRef))
+	return DefSnap{
+		DefRef: rec.DefRef,
+		Title:  rec.Title,
+		XactQN: rec.XactQN,
+		XactES: xactexp.ConvertRecToSpec(newExp),
+	}, nil
+}
+
+func (s *service) RetrieveSnap(ctx context.Context, ref DefRef) (_ DefSnap, err error) {
+	refAttr := slog.Any("defRef", ref)
+	var rec DefRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		rec, err = s.xactDefs.SelectRecByRef(ds, ref)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", refAttr)
+		return DefSnap{}, err
+	}
+	return s.retrieveSnap(ctx, rec)
+}
+
+func (s *service) retrieveSnap(ctx context.Context, rec DefRec) (_ DefSnap, err error) {
+	refAttr := slog.Any("defRef", rec.DefRef)
+	if rec.ExpID.IsEmpty() {
+		return DefSnap{}, ErrExpMissing(rec.DefRef.ID)
+	}
+	var expRec xactexp.ExpRec
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		expRec, err = s.xactExps.SelectRecByID(ds, rec.ExpID)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed", refAttr)
+		return DefSnap{}, err
+	}
+	return DefSnap{
+		DefRef: rec.DefRef,
+		Title:  rec.Title,
+		XactQN: rec.XactQN,
+		XactES: xactexp.ConvertRecToSpec(expRec),
+	}, nil
+}
+
+func (s *service) RetreiveRefs(ctx context.Context) (refs []DefRef, err error) {
+	err = s.operator.Implicit(ctx, func(ds db.Source) error {
+		refs, err = s.xactDefs.SelectRefs(ds)
+		return err
+	})
+	if err != nil {
+		s.log.Error("retrieval failed")
+		return nil, err
+	}
+	return refs, nil
+}
+
+func CollectEnv(recs iter.Seq[DefRec]) []identity.ADT {
+	expIDs := []identity.ADT{}
+	for r := range recs {
+		expIDs = append(expIDs, r.ExpID)
+	}
+	return expIDs
+}
+
+func ErrSymMissingInEnv(want uniqsym.ADT) error {
+	return fmt.Errorf("root missing in env: %v", want)
+}
+
+func ErrDoesNotExist(want identity.ADT) error {
+	return fmt.Errorf("root doesn't exist: %v", want)
+}
+
+func ErrExpMissing(want identity.ADT) error {
+	return fmt.Errorf("root exp missing: %v", want)
+}
+
+func errConcurrentModification(got revnum.ADT, want revnum.ADT) error {
+	return fmt.Errorf("entity concurrent modification: want revision %v, got revision %v", want, got)
+}
+
+func errOptimisticUpdate(got revnum.ADT) error {
+	return fmt.Errorf("entity concurrent modification: got revision %v", got)
 }
@@ -0,0 +1,19 @@
+package xactdef
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("adt/xactdef",
+	fx.Provide(
+		fx.Annotate(newService, fx.As(new(API))),
+		newDAO,
+	),
+	fx.Provide(
+		fx.Private,
+		newEchoController,
+	),
+	fx.Invoke(
+		cfgEchoController,
+	),
+)
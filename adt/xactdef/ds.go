@@ -0,0 +1,44 @@
+package xactdef
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"orglang/go-runtime/lib/db"
+
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+type Repo interface {
+	Insert(db.Source, DefRec) error
+	Update(db.Source, DefRec) error
+	SelectRefs(db.Source) ([]DefRef, error)
+	SelectRecByRef(db.Source, DefRef) (DefRec, error)
+	SelectRecByQN(db.Source, uniqsym.ADT) (DefRec, error)
+	SelectEnv(db.Source, []uniqsym.ADT) (map[uniqsym.ADT]DefRec, error)
+}
+
+// storage adapter is chosen by the operator
+func newDAO(l *slog.Logger, o db.Operator) Repo {
+	switch o.(type) {
+	case *db.OperatorMem:
+		return newMemDAO(l)
+	case *db.OperatorSqlite:
+		return newSqliteDAO(l)
+	default:
+		return newPgxDAO(l)
+	}
+}
+
+type defRefDS struct {
+	ID string `db:"def_id"`
+	RN int64  `db:"def_rn"`
+}
+
+type defRecDS struct {
+	ID     string         `db:"def_id"`
+	RN     int64          `db:"def_rn"`
+	Title  string         `db:"title"`
+	XactQN string         `db:"xact_qn"`
+	ExpID  sql.NullString `db:"exp_id"`
+}
@@ -0,0 +1,129 @@
+package xactdef
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/syndec"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(l *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{l.With(name)}
+}
+
+func (dao *memDAO) Insert(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("defRef", rec.DefRef)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	db.InsertMem(ds, xactDefs, dto)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", refAttr)
+	return nil
+}
+
+// each revision is stored as a separate row
+func (dao *memDAO) Update(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("defRef", rec.DefRef)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	prev, err := selectLatest(ds, dto.ID)
+	if err != nil || prev.RN != dto.RN-1 {
+		dao.log.Error("entity update failed", refAttr)
+		return errOptimisticUpdate(rec.DefRef.RN - 1)
+	}
+	// the title does not change on update
+	dto.Title = prev.Title
+	db.InsertMem(ds, xactDefs, dto)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity update succeed", refAttr)
+	return nil
+}
+
+func (dao *memDAO) SelectRefs(source db.Source) ([]DefRef, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	latest := db.LatestMem(db.SelectMem[defRecDS](ds, xactDefs),
+		func(dto defRecDS) string { return dto.ID },
+		func(dto defRecDS) int64 { return dto.RN },
+	)
+	dtos := make([]defRefDS, 0, len(latest))
+	for _, dto := range latest {
+		dtos = append(dtos, defRefDS{ID: dto.ID, RN: dto.RN})
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToDefRefs(dtos)
+}
+
+func (dao *memDAO) SelectRecByRef(source db.Source, ref DefRef) (DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	refAttr := slog.Any("defRef", ref)
+	dto, err := selectLatest(ds, ref.ID.String())
+	if err != nil {
+		dao.log.Error("entity selection failed", refAttr)
+		return DefRec{}, ErrDoesNotExist(ref.ID)
+	}
+	xactQNs := syndec.SelectQNsByID(ds, dto.ID)
+	if len(xactQNs) == 0 {
+		dao.log.Error("entity selection failed", refAttr)
+		return DefRec{}, ErrDoesNotExist(ref.ID)
+	}
+	dto.XactQN = xactQNs[len(xactQNs)-1]
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", refAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *memDAO) SelectRecByQN(source db.Source, xactQN uniqsym.ADT) (DefRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	qnAttr := slog.Any("xactQN", xactQN)
+	defID, err := syndec.SelectIDByQN(ds, uniqsym.ConvertToString(xactQN))
+	if err != nil {
+		dao.log.Error("entity selection failed", qnAttr)
+		return DefRec{}, ErrSymMissingInEnv(xactQN)
+	}
+	dto, err := selectLatest(ds, defID)
+	if err != nil {
+		dao.log.Error("entity selection failed", qnAttr)
+		return DefRec{}, ErrSymMissingInEnv(xactQN)
+	}
+	dto.XactQN = uniqsym.ConvertToString(xactQN)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", qnAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *memDAO) SelectEnv(source db.Source, xactQNs []uniqsym.ADT) (map[uniqsym.ADT]DefRec, error) {
+	env := make(map[uniqsym.ADT]DefRec, len(xactQNs))
+	for _, xactQN := range xactQNs {
+		rec, err := dao.SelectRecByQN(source, xactQN)
+		if err != nil {
+			return nil, err
+		}
+		env[xactQN] = rec
+	}
+	return env, nil
+}
+
+func selectLatest(ds db.SourceMem, defID string) (defRecDS, error) {
+	return db.SelectLatestMem(ds, xactDefs,
+		func(dto defRecDS) bool { return dto.ID == defID },
+		func(dto defRecDS) int64 { return dto.RN },
+	)
+}
+
+const (
+	xactDefs = "xact_defs"
+)
@@ -0,0 +1,270 @@
+package xactdef
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+type pgxDAO struct {
+	log *slog.Logger
+}
+
+func newPgxDAO(l *slog.Logger) *pgxDAO {
+	name := slog.String("name", reflect.TypeFor[pgxDAO]().Name())
+	return &pgxDAO{l.With(name)}
+}
+
+// for compilation purposes
+func newRepo() Repo {
+	return &pgxDAO{}
+}
+
+func (dao *pgxDAO) Insert(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("defRef", rec.DefRef)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion started", refAttr)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	args := pgx.NamedArgs{
+		"def_id":  dto.ID,
+		"def_rn":  dto.RN,
+		"title":   dto.Title,
+		"exp_id":  dto.ExpID,
+		"from_rn": dto.RN,
+		"to_rn":   math.MaxInt64,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertRoot, args)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", insertRoot))
+		return err
+	}
+	// there is no expression yet after inception
+	if !dto.ExpID.Valid {
+		dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", refAttr)
+		return nil
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertExp, args)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", insertExp))
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", refAttr)
+	return nil
+}
+
+func (dao *pgxDAO) Update(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("defRef", rec.DefRef)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity update started", refAttr)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	args := pgx.NamedArgs{
+		"def_id":  dto.ID,
+		"def_rn":  dto.RN,
+		"exp_id":  dto.ExpID,
+		"from_rn": dto.RN,
+		"to_rn":   math.MaxInt64,
+	}
+	ct, err := ds.Conn.Exec(ds.Ctx, updateRoot, args)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", updateRoot))
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		dao.log.Error("entity update failed", refAttr)
+		return errOptimisticUpdate(rec.DefRef.RN - 1)
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, closeExp, args)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", closeExp))
+		return err
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertExp, args)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", insertExp))
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity update succeed", refAttr)
+	return nil
+}
+
+func (dao *pgxDAO) SelectRefs(source db.Source) ([]DefRef, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	query := `
+		select
+			def_id, def_rn
+		from xact_defs`
+	rows, err := ds.Conn.Query(ds.Ctx, query)
+	if err != nil {
+		dao.log.Error("query execution failed", slog.String("q", query))
+		return nil, err
+	}
+	defer rows.Close()
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[defRefDS])
+	if err != nil {
+		dao.log.Error("rows collection failed")
+		return nil, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToDefRefs(dtos)
+}
+
+func (dao *pgxDAO) SelectRecByRef(source db.Source, ref DefRef) (DefRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	refAttr := slog.Any("defRef", ref)
+	rows, err := ds.Conn.Query(ds.Ctx, selectByID, ref.ID.String())
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", selectByID))
+		return DefRec{}, err
+	}
+	defer rows.Close()
+	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[defRecDS])
+	if errors.Is(err, pgx.ErrNoRows) {
+		dao.log.Error("entity selection failed", refAttr)
+		return DefRec{}, ErrDoesNotExist(ref.ID)
+	}
+	if err != nil {
+		dao.log.Error("row collection failed", refAttr)
+		return DefRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", refAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *pgxDAO) SelectRecByQN(source db.Source, xactQN uniqsym.ADT) (DefRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	qnAttr := slog.Any("xactQN", xactQN)
+	rows, err := ds.Conn.Query(ds.Ctx, selectByQN, uniqsym.ConvertToString(xactQN))
+	if err != nil {
+		dao.log.Error("query execution failed", qnAttr, slog.String("q", selectByQN))
+		return DefRec{}, err
+	}
+	defer rows.Close()
+	dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[defRecDS])
+	if errors.Is(err, pgx.ErrNoRows) {
+		dao.log.Error("entity selection failed", qnAttr)
+		return DefRec{}, ErrSymMissingInEnv(xactQN)
+	}
+	if err != nil {
+		dao.log.Error("row collection failed", qnAttr)
+		return DefRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", qnAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *pgxDAO) SelectEnv(source db.Source, xactQNs []uniqsym.ADT) (_ map[uniqsym.ADT]DefRec, err error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	if len(xactQNs) == 0 {
+		return map[uniqsym.ADT]DefRec{}, nil
+	}
+	batch := pgx.Batch{}
+	for _, xactQN := range xactQNs {
+		batch.Queue(selectByQN, uniqsym.ConvertToString(xactQN))
+	}
+	br := ds.Conn.SendBatch(ds.Ctx, &batch)
+	defer func() {
+		err = errors.Join(err, br.Close())
+	}()
+	env := make(map[uniqsym.ADT]DefRec, len(xactQNs))
+	for _, xactQN := range xactQNs {
+		qnAttr := slog.Any("xactQN", xactQN)
+		rows, err := br.Query()
+		if err != nil {
+			dao.log.Error("query execution failed", qnAttr, slog.String("q", selectByQN))
+			return nil, err
+		}
+		dto, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[defRecDS])
+		if errors.Is(err, pgx.ErrNoRows) {
+			dao.log.Error("entity selection failed", qnAttr)
+			return nil, ErrSymMissingInEnv(xactQN)
+		}
+		if err != nil {
+			dao.log.Error("row collection failed", qnAttr)
+			return nil, err
+		}
+		rec, err := DataToDefRec(dto)
+		if err != nil {
+			dao.log.Error("model conversion failed", qnAttr)
+			return nil, err
+		}
+		env[xactQN] = rec
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("env", env))
+	return env, nil
+}
+
+const (
+	insertRoot = `
+		insert into xact_defs (
+			def_id, def_rn, title
+		) values (
+			@def_id, @def_rn, @title
+		)`
+
+	insertExp = `
+		insert into xact_def_exps (
+			def_id, exp_id, from_rn, to_rn
+		) values (
+			@def_id, @exp_id, @from_rn, @to_rn
+		)`
+
+	updateRoot = `
+		update xact_defs
+		set def_rn = @def_rn
+		where def_id = @def_id
+			and def_rn = @def_rn - 1`
+
+	closeExp = `
+		update xact_def_exps
+		set to_rn = @from_rn
+		where def_id = @def_id
+			and to_rn = @to_rn`
+
+	selectByID = `
+		select
+			xd.def_id,
+			xd.def_rn,
+			xd.title,
+			sd.dec_qn::text as xact_qn,
+			xe.exp_id
+		from xact_defs xd
+		join syn_decs sd
+			on sd.dec_id = xd.def_id
+		left join xact_def_exps xe
+			on xe.def_id = xd.def_id
+			and xe.from_rn <= xd.def_rn
+			and xe.to_rn > xd.def_rn
+		where xd.def_id = $1`
+
+	selectByQN = `
+		select
+			xd.def_id,
+			xd.def_rn,
+			xd.title,
+			sd.dec_qn::text as xact_qn,
+			xe.exp_id
+		from syn_decs sd
+		join xact_defs xd
+			on xd.def_id = sd.dec_id
+		left join xact_def_exps xe
+			on xe.def_id = xd.def_id
+			and xe.from_rn <= xd.def_rn
+			and xe.to_rn > xd.def_rn
+		where sd.dec_qn = $1`
+)
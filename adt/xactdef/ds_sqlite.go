@@ -0,0 +1,264 @@
+package xactdef
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+// Adapter
+type sqliteDAO struct {
+	log *slog.Logger
+}
+
+func newSqliteDAO(l *slog.Logger) *sqliteDAO {
+	name := slog.String("name", reflect.TypeFor[sqliteDAO]().Name())
+	return &sqliteDAO{l.With(name)}
+}
+
+func (dao *sqliteDAO) Insert(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("defRef", rec.DefRef)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion started", refAttr)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	rootArgs := db.NamedArgsSqlite{
+		"def_id": dto.ID,
+		"def_rn": dto.RN,
+		"title":  dto.Title,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertRootSqlite, rootArgs)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", insertRootSqlite))
+		return err
+	}
+	// there is no expression yet after inception
+	if !dto.ExpID.Valid {
+		dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", refAttr)
+		return nil
+	}
+	expArgs := db.NamedArgsSqlite{
+		"def_id":  dto.ID,
+		"exp_id":  dto.ExpID,
+		"from_rn": dto.RN,
+		"to_rn":   math.MaxInt64,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertExpSqlite, expArgs)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", insertExpSqlite))
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", refAttr)
+	return nil
+}
+
+func (dao *sqliteDAO) Update(source db.Source, rec DefRec) error {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("defRef", rec.DefRef)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity update started", refAttr)
+	dto, err := DataFromDefRec(rec)
+	if err != nil {
+		dao.log.Error("model conversion failed", refAttr)
+		return err
+	}
+	rootArgs := db.NamedArgsSqlite{
+		"def_id": dto.ID,
+		"def_rn": dto.RN,
+	}
+	res, err := ds.Conn.Exec(ds.Ctx, updateRootSqlite, rootArgs)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", updateRootSqlite))
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		dao.log.Error("entity update failed", refAttr)
+		return errOptimisticUpdate(rec.DefRef.RN - 1)
+	}
+	closeArgs := db.NamedArgsSqlite{
+		"def_id":  dto.ID,
+		"from_rn": dto.RN,
+		"to_rn":   math.MaxInt64,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, closeExpSqlite, closeArgs)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", closeExpSqlite))
+		return err
+	}
+	expArgs := db.NamedArgsSqlite{
+		"def_id":  dto.ID,
+		"exp_id":  dto.ExpID,
+		"from_rn": dto.RN,
+		"to_rn":   math.MaxInt64,
+	}
+	_, err = ds.Conn.Exec(ds.Ctx, insertExpSqlite, expArgs)
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", insertExpSqlite))
+		return err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity update succeed", refAttr)
+	return nil
+}
+
+func (dao *sqliteDAO) SelectRefs(source db.Source) ([]DefRef, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	rows, err := ds.Conn.Query(ds.Ctx, selectRefsSqlite, nil)
+	if err != nil {
+		dao.log.Error("query execution failed", slog.String("q", selectRefsSqlite))
+		return nil, err
+	}
+	dtos, err := db.CollectRowsSqlite[defRefDS](rows)
+	if err != nil {
+		dao.log.Error("rows collection failed")
+		return nil, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("dtos", dtos))
+	return DataToDefRefs(dtos)
+}
+
+func (dao *sqliteDAO) SelectRecByRef(source db.Source, ref DefRef) (DefRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	refAttr := slog.Any("defRef", ref)
+	rows, err := ds.Conn.Query(ds.Ctx, selectByIDSqlite, db.NamedArgsSqlite{"def_id": ref.ID.String()})
+	if err != nil {
+		dao.log.Error("query execution failed", refAttr, slog.String("q", selectByIDSqlite))
+		return DefRec{}, err
+	}
+	dto, err := db.CollectOneRowSqlite[defRecDS](rows)
+	if errors.Is(err, db.ErrNoRows) {
+		dao.log.Error("entity selection failed", refAttr)
+		return DefRec{}, ErrDoesNotExist(ref.ID)
+	}
+	if err != nil {
+		dao.log.Error("row collection failed", refAttr)
+		return DefRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", refAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *sqliteDAO) SelectRecByQN(source db.Source, xactQN uniqsym.ADT) (DefRec, error) {
+	ds := db.MustConform[db.SourceSqlite](source)
+	qnAttr := slog.Any("xactQN", xactQN)
+	args := db.NamedArgsSqlite{"xact_qn": uniqsym.ConvertToString(xactQN)}
+	rows, err := ds.Conn.Query(ds.Ctx, selectByQNSqlite, args)
+	if err != nil {
+		dao.log.Error("query execution failed", qnAttr, slog.String("q", selectByQNSqlite))
+		return DefRec{}, err
+	}
+	dto, err := db.CollectOneRowSqlite[defRecDS](rows)
+	if errors.Is(err, db.ErrNoRows) {
+		dao.log.Error("entity selection failed", qnAttr)
+		return DefRec{}, ErrSymMissingInEnv(xactQN)
+	}
+	if err != nil {
+		dao.log.Error("row collection failed", qnAttr)
+		return DefRec{}, err
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", qnAttr)
+	return DataToDefRec(dto)
+}
+
+func (dao *sqliteDAO) SelectEnv(source db.Source, xactQNs []uniqsym.ADT) (map[uniqsym.ADT]DefRec, error) {
+	env := make(map[uniqsym.ADT]DefRec, len(xactQNs))
+	for _, xactQN := range xactQNs {
+		rec, err := dao.SelectRecByQN(source, xactQN)
+		if err != nil {
+			return nil, err
+		}
+		env[xactQN] = rec
+	}
+	return env, nil
+}
+
+const (
+	insertRootSqlite = `
+		insert into xact_defs (
+			def_id, def_rn, title
+		) values (
+			:def_id, :def_rn, :title
+		)`
+
+	insertExpSqlite = `
+		insert into xact_def_exps (
+			def_id, exp_id, from_rn, to_rn
+		) values (
+			:def_id, :exp_id, :from_rn, :to_rn
+		)`
+
+	updateRootSqlite = `
+		update xact_defs
+		set def_rn = :def_rn
+		where def_id = :def_id
+			and def_rn = :def_rn - 1`
+
+	closeExpSqlite = `
+		update xact_def_exps
+		set to_rn = :from_rn
+		where def_id = :def_id
+			and to_rn = :to_rn`
+
+	selectRefsSqlite = `
+		select
+			def_id, def_rn
+		from xact_defs`
+
+	// the name comes from its latest record
+	selectByIDSqlite = `
+		select
+			xd.def_id,
+			xd.def_rn,
+			xd.title,
+			(
+				select sd.dec_qn
+				from syn_decs sd
+				where sd.dec_id = xd.def_id
+				order by sd.from_rn desc
+				limit 1
+			) as xact_qn,
+			xe.exp_id
+		from xact_defs xd
+		left join xact_def_exps xe
+			on xe.def_id = xd.def_id
+			and xe.from_rn <= xd.def_rn
+			and xe.to_rn > xd.def_rn
+		where xd.def_id = :def_id
+			and exists (
+				select 1
+				from syn_decs sd
+				where sd.dec_id = xd.def_id
+			)`
+
+	selectByQNSqlite = `
+		select
+			xd.def_id,
+			xd.def_rn,
+			xd.title,
+			sd.dec_qn as xact_qn,
+			xe.exp_id
+		from (
+			select dec_id, dec_qn
+			from syn_decs
+			where dec_qn = :xact_qn
+			order by from_rn desc
+			limit 1
+		) sd
+		join xact_defs xd
+			on xd.def_id = sd.dec_id
+		left join xact_def_exps xe
+			on xe.def_id = xd.def_id
+			and xe.from_rn <= xd.def_rn
+			and xe.to_rn > xd.def_rn`
+)
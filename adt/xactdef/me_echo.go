@@ -0,0 +1,120 @@
+package xactdef
+
+import (
+	"log/slog"
+	"net/http"
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/orglang/go-sdk/adt/xactdef"
+
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/uniqref"
+)
+
+// Server-side primary adapter
+type echoController struct {
+	api API
+	log *slog.Logger
+}
+
+func newEchoController(a API, l *slog.Logger) *echoController {
+	name := slog.String("name", reflect.TypeFor[echoController]().Name())
+	return &echoController{a, l.With(name)}
+}
+
+func cfgEchoController(e *echo.Echo, h *echoController) error {
+	e.POST("/api/v1/xacts", h.PostSpec)
+	e.GET("/api/v1/xacts", h.GetRefs)
+	e.GET("/api/v1/xacts/:id", h.GetSnap)
+	e.PATCH("/api/v1/xacts/:id", h.PatchOne)
+	return nil
+}
+
+func (h *echoController) PostSpec(c echo.Context) error {
+	var dto xactdef.DefSpec
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	ctx := c.Request().Context()
+	h.log.Log(ctx, lf.LevelTrace, "posting started", slog.Any("dto", dto))
+	validationErr := dto.Validate()
+	if validationErr != nil {
+		h.log.Error("validation failed", slog.Any("dto", dto))
+		return validationErr
+	}
+	spec, conversionErr := MsgToDefSpec(dto)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	snap, creationErr := h.api.Create(ctx, spec)
+	if creationErr != nil {
+		return creationErr
+	}
+	h.log.Log(ctx, lf.LevelTrace, "posting succeed", slog.Any("defRef", snap.DefRef))
+	return c.JSON(http.StatusCreated, MsgFromDefSnap(snap))
+}
+
+func (h *echoController) GetRefs(c echo.Context) error {
+	refs, retrievalErr := h.api.RetreiveRefs(c.Request().Context())
+	if retrievalErr != nil {
+		return retrievalErr
+	}
+	return c.JSON(http.StatusOK, uniqref.MsgFromADTs(refs))
+}
+
+func (h *echoController) GetSnap(c echo.Context) error {
+	var dto xactdef.DefRef
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	validationErr := dto.Validate()
+	if validationErr != nil {
+		h.log.Error("validation failed", slog.Any("dto", dto))
+		return validationErr
+	}
+	ref, conversionErr := uniqref.MsgToADT(dto)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	snap, retrievalErr := h.api.RetrieveSnap(c.Request().Context(), ref)
+	if retrievalErr != nil {
+		return retrievalErr
+	}
+	return c.JSON(http.StatusOK, MsgFromDefSnap(snap))
+}
+
+func (h *echoController) PatchOne(c echo.Context) error {
+	var dto xactdef.DefSnap
+	bindingErr := c.Bind(&dto)
+	if bindingErr != nil {
+		h.log.Error("binding failed", slog.Any("dto", reflect.TypeOf(dto)))
+		return bindingErr
+	}
+	ctx := c.Request().Context()
+	h.log.Log(ctx, lf.LevelTrace, "patching started", slog.Any("dto", dto))
+	validationErr := dto.Validate()
+	if validationErr != nil {
+		h.log.Error("validation failed", slog.Any("dto", dto))
+		return validationErr
+	}
+	reqSnap, conversionErr := MsgToDefSnap(dto)
+	if conversionErr != nil {
+		h.log.Error("conversion failed", slog.Any("dto", dto))
+		return conversionErr
+	}
+	resSnap, modificationErr := h.api.Modify(ctx, reqSnap)
+	if modificationErr != nil {
+		return modificationErr
+	}
+	h.log.Log(ctx, lf.LevelTrace, "patching succeed", slog.Any("defRef", resSnap.DefRef))
+	return c.JSON(http.StatusOK, MsgFromDefSnap(resSnap))
+}
@@ -1,6 +1,15 @@
 package xactexp
 
-import "orglang/go-runtime/adt/uniqsym"
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/uniqsym"
+)
 
 type ExpSpec interface {
 	spec()
@@ -14,6 +23,8 @@ type LinkSpec struct {
 	XactQN uniqsym.ADT
 }
 
+func (LinkSpec) spec() {}
+
 // aka Internal Choice
 type PlusSpec struct {
 	Choices map[uniqsym.ADT]ExpSpec // conts
@@ -27,3 +38,122 @@ type WithSpec struct {
 }
 
 func (WithSpec) spec() {}
+
+type ExpRec interface {
+	identity.Identifiable
+}
+
+type OneRec struct {
+	ExpID identity.ADT
+}
+
+func (r OneRec) Ident() identity.ADT { return r.ExpID }
+
+type LinkRec struct {
+	ExpID  identity.ADT
+	XactQN uniqsym.ADT
+}
+
+func (r LinkRec) Ident() identity.ADT { return r.ExpID }
+
+// aka Internal Choice
+type PlusRec struct {
+	ExpID   identity.ADT
+	Choices map[uniqsym.ADT]ExpRec
+}
+
+func (r PlusRec) Ident() identity.ADT { return r.ExpID }
+
+func (r PlusRec) Next(l uniqsym.ADT) identity.ADT { return r.Choices[l].Ident() }
+
+// aka External Choice
+type WithRec struct {
+	ExpID   identity.ADT
+	Choices map[uniqsym.ADT]ExpRec
+}
+
+func (r WithRec) Ident() identity.ADT { return r.ExpID }
+
+func (r WithRec) Next(l uniqsym.ADT) identity.ADT { return r.Choices[l].Ident() }
+
+func CheckSpec(got, want ExpSpec) error {
+	switch wantSt := want.(type) {
+	case OneSpec:
+		_, ok := got.(OneSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		return nil
+	case LinkSpec:
+		gotSt, ok := got.(LinkSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		if !gotSt.XactQN.Equal(wantSt.XactQN) {
+			return fmt.Errorf("link mismatch: want %v, got %v", wantSt.XactQN, gotSt.XactQN)
+		}
+		return nil
+	case PlusSpec:
+		gotSt, ok := got.(PlusSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		return checkChoices(gotSt.Choices, wantSt.Choices)
+	case WithSpec:
+		gotSt, ok := got.(WithSpec)
+		if !ok {
+			return ErrSpecTypeMismatch(got, want)
+		}
+		return checkChoices(gotSt.Choices, wantSt.Choices)
+	default:
+		panic(ErrSpecTypeUnexpected(want))
+	}
+}
+
+func checkChoices(got, want map[uniqsym.ADT]ExpSpec) error {
+	if len(got) != len(want) {
+		return fmt.Errorf("choices mismatch: want %v items, got %v items", len(want), len(got))
+	}
+	// mismatches across all labels are reported together
+	var errs []error
+	for _, wantLab := range sortedLabels(want) {
+		gotChoice, ok := got[wantLab]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: want %q, got nothing", ErrLabelMismatch, uniqsym.ConvertToString(wantLab)))
+			continue
+		}
+		err := CheckSpec(gotChoice, want[wantLab])
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var ErrLabelMismatch = errors.New("label mismatch")
+
+func sortedLabels(choices map[uniqsym.ADT]ExpSpec) []uniqsym.ADT {
+	return slices.SortedFunc(maps.Keys(choices), func(a, b uniqsym.ADT) int {
+		return strings.Compare(uniqsym.ConvertToString(a), uniqsym.ConvertToString(b))
+	})
+}
+
+func ErrSpecTypeUnexpected(got ExpSpec) error {
+	return fmt.Errorf("spec type unexpected: %T", got)
+}
+
+func ErrRecTypeUnexpected(got ExpRec) error {
+	return fmt.Errorf("rec type unexpected: %T", got)
+}
+
+func ErrSpecTypeMismatch(got, want ExpSpec) error {
+	return fmt.Errorf("spec type mismatch: want %T, got %T", want, got)
+}
+
+func ErrDoesNotExist(want identity.ADT) error {
+	return fmt.Errorf("root doesn't exist: %v", want)
+}
+
+func ErrMissingInEnv(want identity.ADT) error {
+	return fmt.Errorf("root missing in env: %v", want)
+}
@@ -0,0 +1,11 @@
+package xactexp
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("adt/xactexp",
+	fx.Provide(
+		newDAO,
+	),
+)
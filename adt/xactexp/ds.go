@@ -0,0 +1,61 @@
+package xactexp
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"orglang/go-runtime/lib/db"
+
+	"orglang/go-runtime/adt/identity"
+)
+
+type Repo interface {
+	InsertRec(db.Source, ExpRec) error
+	SelectRecByID(db.Source, identity.ADT) (ExpRec, error)
+	SelectEnv(db.Source, []identity.ADT) (map[identity.ADT]ExpRec, error)
+}
+
+// storage adapter is chosen by the operator
+func newDAO(l *slog.Logger, o db.Operator) Repo {
+	switch o.(type) {
+	case *db.OperatorMem:
+		return newMemDAO(l)
+	case *db.OperatorSqlite:
+		return newSqliteDAO(l)
+	default:
+		return newPgxDAO(l)
+	}
+}
+
+type expKindDS int
+
+const (
+	nonExp expKindDS = iota
+	oneExp
+	linkExp
+	plusExp
+	withExp
+)
+
+type expRecDS struct {
+	ExpID  string
+	States []stateDS
+}
+
+type stateDS struct {
+	ExpID  string         `db:"exp_id"`
+	K      expKindDS      `db:"kind"`
+	FromID sql.NullString `db:"from_id"`
+	Spec   expSpecDS      `db:"spec"`
+}
+
+type expSpecDS struct {
+	Link string    `json:"link,omitempty"`
+	Plus []laborDS `json:"plus,omitempty"`
+	With []laborDS `json:"with,omitempty"`
+}
+
+type laborDS struct {
+	ProcQN string `json:"on"`
+	ContES string `json:"to"`
+}
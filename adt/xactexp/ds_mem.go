@@ -0,0 +1,79 @@
+package xactexp
+
+import (
+	"log/slog"
+	"reflect"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
+)
+
+// Adapter
+type memDAO struct {
+	log *slog.Logger
+}
+
+func newMemDAO(l *slog.Logger) *memDAO {
+	name := slog.String("name", reflect.TypeFor[memDAO]().Name())
+	return &memDAO{l.With(name)}
+}
+
+func (dao *memDAO) InsertRec(source db.Source, rec ExpRec) error {
+	ds := db.MustConform[db.SourceMem](source)
+	dto := DataFromExpRec(rec)
+	db.InsertMem(ds, xactExps, dto.States...)
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", slog.Any("expID", rec.Ident()))
+	return nil
+}
+
+func (dao *memDAO) SelectRecByID(source db.Source, expID identity.ADT) (ExpRec, error) {
+	recs, err := dao.selectRecs(source, []identity.ADT{expID})
+	if err != nil {
+		return nil, err
+	}
+	return recs[0], nil
+}
+
+func (dao *memDAO) SelectEnv(source db.Source, expIDs []identity.ADT) (map[identity.ADT]ExpRec, error) {
+	recs, err := dao.selectRecs(source, expIDs)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[identity.ADT]ExpRec, len(recs))
+	for _, rec := range recs {
+		env[rec.Ident()] = rec
+	}
+	return env, nil
+}
+
+func (dao *memDAO) selectRecs(source db.Source, expIDs []identity.ADT) ([]ExpRec, error) {
+	ds := db.MustConform[db.SourceMem](source)
+	// continuations are found by IDs from the states themselves
+	states := make(map[string]stateDS)
+	for _, st := range db.SelectMem[stateDS](ds, xactExps) {
+		states[st.ExpID] = st
+	}
+	recs := make([]ExpRec, 0, len(expIDs))
+	for _, expID := range expIDs {
+		idAttr := slog.Any("expID", expID)
+		st, ok := states[expID.String()]
+		if !ok {
+			dao.log.Error("entity selection failed", idAttr)
+			return nil, ErrDoesNotExist(expID)
+		}
+		rec, err := statesToExpRec(states, st)
+		if err != nil {
+			dao.log.Error("model conversion failed", idAttr)
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("recs", recs))
+	return recs, nil
+}
+
+const (
+	xactExps = "xact_exps"
+)
@@ -0,0 +1,141 @@
+package xactexp
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+
+	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/lf"
+
+	"orglang/go-runtime/adt/identity"
+)
+
+// Adapter
+type pgxDAO struct {
+	log *slog.Logger
+}
+
+func newPgxDAO(l *slog.Logger) *pgxDAO {
+	name := slog.String("name", reflect.TypeFor[pgxDAO]().Name())
+	return &pgxDAO{l.With(name)}
+}
+
+// for compilation purposes
+func newRepo() Repo {
+	return &pgxDAO{}
+}
+
+func (dao *pgxDAO) InsertRec(source db.Source, rec ExpRec) (err error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("expID", rec.Ident())
+	dto := DataFromExpRec(rec)
+	query := `
+		insert into xact_exps (
+			exp_id, kind, from_id, spec
+		) values (
+			@exp_id, @kind, @from_id, @spec
+		)`
+	batch := pgx.Batch{}
+	for _, st := range dto.States {
+		sa := pgx.NamedArgs{
+			"exp_id":  st.ExpID,
+			"kind":    st.K,
+			"from_id": st.FromID,
+			"spec":    st.Spec,
+		}
+		batch.Queue(query, sa)
+	}
+	br := ds.Conn.SendBatch(ds.Ctx, &batch)
+	defer func() {
+		err = errors.Join(err, br.Close())
+	}()
+	for range dto.States {
+		_, err = br.Exec()
+		if err != nil {
+			dao.log.Error("query execution failed", idAttr, slog.String("q", query))
+			return err
+		}
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity insertion succeed", idAttr)
+	return nil
+}
+
+func (dao *pgxDAO) SelectRecByID(source db.Source, expID identity.ADT) (ExpRec, error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	idAttr := slog.Any("expID", expID)
+	rows, err := ds.Conn.Query(ds.Ctx, selectByID, expID.String())
+	if err != nil {
+		dao.log.Error("query execution failed", idAttr, slog.String("q", selectByID))
+		return nil, err
+	}
+	defer rows.Close()
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[stateDS])
+	if err != nil {
+		dao.log.Error("rows collection failed", idAttr)
+		return nil, err
+	}
+	if len(dtos) == 0 {
+		dao.log.Error("entity selection failed", idAttr)
+		return nil, ErrDoesNotExist(expID)
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entity selection succeed", idAttr)
+	return DataToExpRec(&expRecDS{expID.String(), dtos})
+}
+
+func (dao *pgxDAO) SelectEnv(source db.Source, expIDs []identity.ADT) (_ map[identity.ADT]ExpRec, err error) {
+	ds := db.MustConform[db.SourcePgx](source)
+	if len(expIDs) == 0 {
+		return map[identity.ADT]ExpRec{}, nil
+	}
+	batch := pgx.Batch{}
+	for _, expID := range expIDs {
+		batch.Queue(selectByID, expID.String())
+	}
+	br := ds.Conn.SendBatch(ds.Ctx, &batch)
+	defer func() {
+		err = errors.Join(err, br.Close())
+	}()
+	env := make(map[identity.ADT]ExpRec, len(expIDs))
+	for _, expID := range expIDs {
+		idAttr := slog.Any("expID", expID)
+		rows, err := br.Query()
+		if err != nil {
+			dao.log.Error("query execution failed", idAttr, slog.String("q", selectByID))
+			return nil, err
+		}
+		dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[stateDS])
+		if err != nil {
+			dao.log.Error("rows collection failed", idAttr)
+			return nil, err
+		}
+		if len(dtos) == 0 {
+			dao.log.Error("entity selection failed", idAttr)
+			return nil, ErrDoesNotExist(expID)
+		}
+		rec, err := DataToExpRec(&expRecDS{expID.String(), dtos})
+		if err != nil {
+			dao.log.Error("model conversion failed", idAttr)
+			return nil, err
+		}
+		env[expID] = rec
+	}
+	dao.log.Log(ds.Ctx, lf.LevelTrace, "entities selection succeed", slog.Any("env", env))
+	return env, nil
+}
+
+const (
+	selectByID = `
+		with recursive state_tree as (
+			select root.exp_id, root.kind, root.from_id, root.spec
+			from xact_exps root
+			where root.exp_id = $1
+			union all
+			select child.exp_id, child.kind, child.from_id, child.spec
+			from xact_exps child, state_tree parent
+			where child.from_id = parent.exp_id
+		)
+		select * from state_tree`
+)
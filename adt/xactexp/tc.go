@@ -0,0 +1,258 @@
+package xactexp
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/orglang/go-sdk/adt/xactexp"
+
+	"orglang/go-runtime/adt/identity"
+	"orglang/go-runtime/adt/uniqsym"
+)
+
+func ConvertSpecToRec(s ExpSpec) ExpRec {
+	if s == nil {
+		return nil
+	}
+	switch spec := s.(type) {
+	case OneSpec:
+		return OneRec{ExpID: identity.New()}
+	case LinkSpec:
+		return LinkRec{ExpID: identity.New(), XactQN: spec.XactQN}
+	case PlusSpec:
+		choices := make(map[uniqsym.ADT]ExpRec, len(spec.Choices))
+		for lab, cont := range spec.Choices {
+			choices[lab] = ConvertSpecToRec(cont)
+		}
+		return PlusRec{ExpID: identity.New(), Choices: choices}
+	case WithSpec:
+		choices := make(map[uniqsym.ADT]ExpRec, len(spec.Choices))
+		for lab, cont := range spec.Choices {
+			choices[lab] = ConvertSpecToRec(cont)
+		}
+		return WithRec{ExpID: identity.New(), Choices: choices}
+	default:
+		panic(ErrSpecTypeUnexpected(spec))
+	}
+}
+
+func ConvertRecToSpec(r ExpRec) ExpSpec {
+	if r == nil {
+		return nil
+	}
+	switch rec := r.(type) {
+	case OneRec:
+		return OneSpec{}
+	case LinkRec:
+		return LinkSpec{XactQN: rec.XactQN}
+	case PlusRec:
+		choices := make(map[uniqsym.ADT]ExpSpec, len(rec.Choices))
+		for lab, cont := range rec.Choices {
+			choices[lab] = ConvertRecToSpec(cont)
+		}
+		return PlusSpec{Choices: choices}
+	case WithRec:
+		choices := make(map[uniqsym.ADT]ExpSpec, len(rec.Choices))
+		for lab, cont := range rec.Choices {
+			choices[lab] = ConvertRecToSpec(cont)
+		}
+		return WithSpec{Choices: choices}
+	default:
+		panic(ErrRecTypeUnexpected(rec))
+	}
+}
+
+func MsgFromExpSpec(s ExpSpec) xactexp.ExpSpec {
+	switch spec := s.(type) {
+	case OneSpec:
+		return xactexp.ExpSpec{K: xactexp.One}
+	case LinkSpec:
+		return xactexp.ExpSpec{
+			K:    xactexp.Link,
+			Link: &xactexp.LinkSpec{XactQN: uniqsym.ConvertToString(spec.XactQN)},
+		}
+	case PlusSpec:
+		return xactexp.ExpSpec{K: xactexp.Plus, Plus: msgFromChoices(spec.Choices)}
+	case WithSpec:
+		return xactexp.ExpSpec{K: xactexp.With, With: msgFromChoices(spec.Choices)}
+	default:
+		panic(ErrSpecTypeUnexpected(s))
+	}
+}
+
+func msgFromChoices(conts map[uniqsym.ADT]ExpSpec) *xactexp.LaborSpec {
+	choices := make([]xactexp.ChoiceSpec, 0, len(conts))
+	for lab, cont := range conts {
+		choices = append(choices, xactexp.ChoiceSpec{
+			ProcQN: uniqsym.ConvertToString(lab),
+			ContES: MsgFromExpSpec(cont),
+		})
+	}
+	return &xactexp.LaborSpec{Choices: choices}
+}
+
+func MsgToExpSpec(dto xactexp.ExpSpec) (ExpSpec, error) {
+	switch dto.K {
+	case xactexp.One:
+		return OneSpec{}, nil
+	case xactexp.Link:
+		xactQN, err := uniqsym.ConvertFromString(dto.Link.XactQN)
+		if err != nil {
+			return nil, err
+		}
+		return LinkSpec{XactQN: xactQN}, nil
+	case xactexp.Plus:
+		choices, err := msgToChoices(dto.Plus)
+		if err != nil {
+			return nil, err
+		}
+		return PlusSpec{Choices: choices}, nil
+	case xactexp.With:
+		choices, err := msgToChoices(dto.With)
+		if err != nil {
+			return nil, err
+		}
+		return WithSpec{Choices: choices}, nil
+	default:
+		panic(xactexp.ErrKindUnexpected(dto.K))
+	}
+}
+
+func msgToChoices(dto *xactexp.LaborSpec) (map[uniqsym.ADT]ExpSpec, error) {
+	choices := make(map[uniqsym.ADT]ExpSpec, len(dto.Choices))
+	for _, ch := range dto.Choices {
+		cont, err := MsgToExpSpec(ch.ContES)
+		if err != nil {
+			return nil, err
+		}
+		label, err := uniqsym.ConvertFromString(ch.ProcQN)
+		if err != nil {
+			return nil, err
+		}
+		choices[label] = cont
+	}
+	return choices, nil
+}
+
+func DataToExpRec(dto *expRecDS) (ExpRec, error) {
+	states := make(map[string]stateDS, len(dto.States))
+	for _, st := range dto.States {
+		states[st.ExpID] = st
+	}
+	return statesToExpRec(states, states[dto.ExpID])
+}
+
+func DataFromExpRec(rec ExpRec) *expRecDS {
+	if rec == nil {
+		return nil
+	}
+	dto := &expRecDS{
+		ExpID:  rec.Ident().String(),
+		States: nil,
+	}
+	statesFromExpRec("", rec, dto)
+	return dto
+}
+
+func statesToExpRec(states map[string]stateDS, st stateDS) (ExpRec, error) {
+	stID, err := identity.ConvertFromString(st.ExpID)
+	if err != nil {
+		return nil, err
+	}
+	switch st.K {
+	case oneExp:
+		return OneRec{ExpID: stID}, nil
+	case linkExp:
+		xactQN, err := uniqsym.ConvertFromString(st.Spec.Link)
+		if err != nil {
+			return nil, err
+		}
+		return LinkRec{ExpID: stID, XactQN: xactQN}, nil
+	case plusExp:
+		choices, err := statesToChoices(states, st.Spec.Plus)
+		if err != nil {
+			return nil, err
+		}
+		return PlusRec{ExpID: stID, Choices: choices}, nil
+	case withExp:
+		choices, err := statesToChoices(states, st.Spec.With)
+		if err != nil {
+			return nil, err
+		}
+		return WithRec{ExpID: stID, Choices: choices}, nil
+	default:
+		panic(errUnexpectedKind(st.K))
+	}
+}
+
+func statesToChoices(states map[string]stateDS, dtos []laborDS) (map[uniqsym.ADT]ExpRec, error) {
+	choices := make(map[uniqsym.ADT]ExpRec, len(dtos))
+	for _, ch := range dtos {
+		cont, err := statesToExpRec(states, states[ch.ContES])
+		if err != nil {
+			return nil, err
+		}
+		label, err := uniqsym.ConvertFromString(ch.ProcQN)
+		if err != nil {
+			return nil, err
+		}
+		choices[label] = cont
+	}
+	return choices, nil
+}
+
+func statesFromExpRec(from string, r ExpRec, dto *expRecDS) string {
+	var fromID sql.NullString
+	if len(from) > 0 {
+		fromID = sql.NullString{String: from, Valid: true}
+	}
+	stID := r.Ident().String()
+	switch rec := r.(type) {
+	case OneRec:
+		st := stateDS{ExpID: stID, K: oneExp, FromID: fromID}
+		dto.States = append(dto.States, st)
+		return stID
+	case LinkRec:
+		st := stateDS{
+			ExpID:  stID,
+			K:      linkExp,
+			FromID: fromID,
+			Spec:   expSpecDS{Link: uniqsym.ConvertToString(rec.XactQN)},
+		}
+		dto.States = append(dto.States, st)
+		return stID
+	case PlusRec:
+		st := stateDS{
+			ExpID:  stID,
+			K:      plusExp,
+			FromID: fromID,
+			Spec:   expSpecDS{Plus: statesFromChoices(stID, rec.Choices, dto)},
+		}
+		dto.States = append(dto.States, st)
+		return stID
+	case WithRec:
+		st := stateDS{
+			ExpID:  stID,
+			K:      withExp,
+			FromID: fromID,
+			Spec:   expSpecDS{With: statesFromChoices(stID, rec.Choices, dto)},
+		}
+		dto.States = append(dto.States, st)
+		return stID
+	default:
+		panic(ErrRecTypeUnexpected(r))
+	}
+}
+
+func statesFromChoices(from string, conts map[uniqsym.ADT]ExpRec, dto *expRecDS) []laborDS {
+	choices := make([]laborDS, 0, len(conts))
+	for lab, cont := range conts {
+		contID := statesFromExpRec(from, cont, dto)
+		choices = append(choices, laborDS{uniqsym.ConvertToString(lab), contID})
+	}
+	return choices
+}
+
+func errUnexpectedKind(k expKindDS) error {
+	return fmt.Errorf("unexpected kind %q", k)
+}
@@ -1,32 +1,64 @@
 package main
 
 import (
+	"flag"
+
 	"go.uber.org/fx"
 
 	"orglang/go-runtime/lib/db"
+	"orglang/go-runtime/lib/kv"
 	"orglang/go-runtime/lib/lf"
 	"orglang/go-runtime/lib/ws"
 
+	"orglang/go-runtime/adt/pooldec"
 	"orglang/go-runtime/adt/poolexec"
 	"orglang/go-runtime/adt/procdec"
 	"orglang/go-runtime/adt/procdef"
 	"orglang/go-runtime/adt/procexec"
 	"orglang/go-runtime/adt/syndec"
 	"orglang/go-runtime/adt/typedef"
+	"orglang/go-runtime/adt/typeexp"
+	"orglang/go-runtime/adt/xactdef"
+	"orglang/go-runtime/adt/xactexp"
 
 	"orglang/go-runtime/app/web"
 )
 
+// in-memory storage instead of postgres
+var inMemory = flag.Bool("mem", false, "keep all state in memory")
+
+// offline maintenance; the scheduler of every node must be stopped
+var replay = flag.Bool("replay", false, "rebuild binds and steps from the journal and exit")
+
 func main() {
+	flag.Parse()
+	storage := db.Module
+	if *inMemory {
+		storage = db.MemModule
+	}
+	if *replay {
+		fx.New(
+			storage,
+			kv.Module,
+			lf.Module,
+			procexec.ReplayModule,
+		).Run()
+		return
+	}
 	fx.New(
 		// lib
-		db.Module,
+		storage,
+		kv.Module,
 		lf.Module,
 		ws.Module,
 		// adt
 		syndec.Module,
+		pooldec.Module,
 		poolexec.Module,
+		typeexp.Module,
 		typedef.Module,
+		xactexp.Module,
+		xactdef.Module,
 		procdef.Module,
 		procdec.Module,
 		procexec.Module,
@@ -28,7 +28,7 @@ func cfgEchoController(e *echo.Echo, h *echoController) {
 }
 
 func (h *echoController) Home(c echo.Context) error {
-	refs, err := h.api.RetreiveRefs()
+	refs, err := h.api.RetreiveRefs(c.Request().Context())
 	if err != nil {
 		return err
 	}
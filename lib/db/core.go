@@ -7,6 +7,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/fx"
 
 	"orglang/go-runtime/adt/identity"
 )
@@ -31,6 +32,16 @@ type Operator interface {
 	Implicit(context.Context, func(Source) error) error
 }
 
+// operator is chosen by the storage protocol
+func newOperator(dto storageCS, lc fx.Lifecycle) (Operator, error) {
+	switch dto.Protocol.Mode {
+	case sqliteProto:
+		return newOperatorSqlite(dto, lc)
+	default:
+		return newOperatorPgx(dto, lc)
+	}
+}
+
 type OperatorPgx struct {
 	pool *pgxpool.Pool
 }
@@ -25,6 +25,7 @@ type storageCS struct {
 type protocolCS struct {
 	Mode     protoModeCS `mapstructure:"mode"`
 	Postgres postgresCS  `mapstructure:"postgres"`
+	Sqlite   sqliteCS    `mapstructure:"sqlite"`
 }
 
 type driverCS struct {
@@ -36,16 +37,23 @@ type postgresCS struct {
 	Url string `mapstructure:"url"`
 }
 
+// database file; :memory: keeps it in process memory
+type sqliteCS struct {
+	Path string `mapstructure:"path"`
+}
+
 type pgxCS struct{}
 
 type protoModeCS string
 
 const (
 	postgresProto = protoModeCS("postgres")
+	sqliteProto   = protoModeCS("sqlite")
 )
 
 type driverModeCS string
 
 const (
-	pgxDriver = driverModeCS("pgx")
+	pgxDriver     = driverModeCS("pgx")
+	moderncDriver = driverModeCS("modernc")
 )
@@ -6,11 +6,17 @@ import (
 
 var Module = fx.Module("lib/db",
 	fx.Provide(
-		newPgxDriver,
-		fx.Annotate(newOperator, fx.As(new(Operator))),
+		newOperator,
 	),
 	fx.Provide(
 		fx.Private,
 		newStorageCS,
 	),
 )
+
+// in-memory storage instead of postgres
+var MemModule = fx.Module("lib/db",
+	fx.Provide(
+		fx.Annotate(newOperatorMem, fx.As(new(Operator))),
+	),
+)
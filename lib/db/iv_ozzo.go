@@ -1,20 +1,37 @@
 package db
 
 import (
+	"errors"
+
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 func (dto storageCS) Validate() error {
 	return validation.ValidateStruct(&dto,
 		validation.Field(&dto.Protocol, validation.Required),
-		validation.Field(&dto.Driver, validation.Required),
+		validation.Field(&dto.Driver, validation.Required, validation.By(dto.matchDriver)),
 	)
 }
 
+// each protocol is served by its own driver
+func (dto storageCS) matchDriver(any) error {
+	if protoDrivers[dto.Protocol.Mode] != dto.Driver.Mode {
+		return errors.New("must match protocol mode")
+	}
+	return nil
+}
+
+var protoDrivers = map[protoModeCS]driverModeCS{
+	postgresProto: pgxDriver,
+	sqliteProto:   moderncDriver,
+}
+
 func (dto protocolCS) Validate() error {
 	return validation.ValidateStruct(&dto,
-		validation.Field(&dto.Mode, validation.Required, validation.In(postgresProto)),
-		validation.Field(&dto.Postgres, validation.Required.When(dto.Mode == postgresProto)),
+		validation.Field(&dto.Mode, validation.Required, validation.In(postgresProto, sqliteProto)),
+		// settings of another protocol are not validated
+		validation.Field(&dto.Postgres, validation.Skip.When(dto.Mode != postgresProto), validation.Required),
+		validation.Field(&dto.Sqlite, validation.Skip.When(dto.Mode != sqliteProto), validation.Required),
 	)
 }
 
@@ -24,9 +41,15 @@ func (dto postgresCS) Validate() error {
 	)
 }
 
+func (dto sqliteCS) Validate() error {
+	return validation.ValidateStruct(&dto,
+		validation.Field(&dto.Path, validation.Required),
+	)
+}
+
 func (dto driverCS) Validate() error {
 	return validation.ValidateStruct(&dto,
-		validation.Field(&dto.Mode, validation.Required, validation.In(pgxDriver)),
+		validation.Field(&dto.Mode, validation.Required, validation.In(pgxDriver, moderncDriver)),
 		// validation.Field(&dto.Pgx, validation.Required.When(dto.Mode == pgxMode)),
 	)
 }
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// In-memory storage: a transaction works on its own table snapshot
+// and replaces the shared one on commit. Table rows are immutable,
+// a table change replaces its whole slice.
+type SourceMem struct {
+	Ctx context.Context
+	tx  *txMem
+}
+
+func (SourceMem) source() {}
+
+type txMem struct {
+	tables map[string]any
+}
+
+type OperatorMem struct {
+	// transactions run strictly one at a time
+	mu     sync.Mutex
+	tables map[string]any
+}
+
+func newOperatorMem() *OperatorMem {
+	return &OperatorMem{tables: make(map[string]any)}
+}
+
+func (o *OperatorMem) Explicit(ctx context.Context, op func(Source) error) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	tx := &txMem{maps.Clone(o.tables)}
+	err := op(SourceMem{Ctx: ctx, tx: tx})
+	if err != nil {
+		// rollback: the snapshot is just dropped
+		return err
+	}
+	o.tables = tx.tables
+	return nil
+}
+
+// without a transaction changes persist even on error
+func (o *OperatorMem) Implicit(ctx context.Context, op func(Source) error) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	tx := &txMem{maps.Clone(o.tables)}
+	err := op(SourceMem{Ctx: ctx, tx: tx})
+	o.tables = tx.tables
+	return err
+}
+
+// table rows in the snapshot; the returned slice must not be changed
+func SelectMem[R any](ds SourceMem, table string) []R {
+	rows, _ := ds.tx.tables[table].([]R)
+	return rows
+}
+
+func InsertMem[R any](ds SourceMem, table string, rows ...R) {
+	ds.tx.tables[table] = append(slices.Clip(SelectMem[R](ds, table)), rows...)
+}
+
+// updates rows matching the condition; returns their count
+func UpdateMem[R any](ds SourceMem, table string, match func(R) bool, update func(R) R) int {
+	rows := slices.Clone(SelectMem[R](ds, table))
+	n := 0
+	for i, row := range rows {
+		if match(row) {
+			rows[i] = update(row)
+			n++
+		}
+	}
+	ds.tx.tables[table] = rows
+	return n
+}
+
+// deletes rows matching the condition; returns their count
+func DeleteMem[R any](ds SourceMem, table string, match func(R) bool) int {
+	rows := SelectMem[R](ds, table)
+	kept := slices.DeleteFunc(slices.Clone(rows), match)
+	ds.tx.tables[table] = kept
+	return len(rows) - len(kept)
+}
+
+// latest revisions of rows by key in order of first key appearance;
+// aka distinct on (key) order by abs(rn) desc
+func LatestMem[R any, K comparable](rows []R, key func(R) K, rn func(R) int64) []R {
+	idx := make(map[K]int)
+	latest := []R{}
+	for _, row := range rows {
+		k := key(row)
+		i, ok := idx[k]
+		if !ok {
+			idx[k] = len(latest)
+			latest = append(latest, row)
+			continue
+		}
+		if abs(rn(row)) >= abs(rn(latest[i])) {
+			latest[i] = row
+		}
+	}
+	return latest
+}
+
+// aka order by rn desc limit 1
+func SelectLatestMem[R any](ds SourceMem, table string, match func(R) bool, rn func(R) int64) (R, error) {
+	var latest R
+	found := false
+	for _, row := range SelectMem[R](ds, table) {
+		if match(row) && (!found || rn(row) >= rn(latest)) {
+			latest, found = row, true
+		}
+	}
+	if !found {
+		return latest, ErrNoRows
+	}
+	return latest, nil
+}
+
+// a missing row looks the same as in postgres
+var ErrNoRows = pgx.ErrNoRows
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// aka bigserial
+func NextSeqMem(ds SourceMem, name string) int64 {
+	key := name + "_seq"
+	seq, _ := ds.tx.tables[key].(int64)
+	seq++
+	ds.tx.tables[key] = seq
+	return seq
+}
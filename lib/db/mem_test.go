@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+const memTable = "rows"
+
+func selectRows(t *testing.T, o *OperatorMem) []int {
+	t.Helper()
+	var rows []int
+	err := o.Implicit(context.Background(), func(source Source) error {
+		rows = SelectMem[int](MustConform[SourceMem](source), memTable)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	return rows
+}
+
+func TestOperatorMemCommit(t *testing.T) {
+	o := newOperatorMem()
+	err := o.Explicit(context.Background(), func(source Source) error {
+		ds := MustConform[SourceMem](source)
+		InsertMem(ds, memTable, 1, 2)
+		// the transaction sees its own writes
+		if got := SelectMem[int](ds, memTable); !slices.Equal(got, []int{1, 2}) {
+			t.Errorf("got %v, want %v", got, []int{1, 2})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	got := selectRows(t, o)
+	if !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("got %v, want %v", got, []int{1, 2})
+	}
+}
+
+func TestOperatorMemRollback(t *testing.T) {
+	o := newOperatorMem()
+	err := o.Explicit(context.Background(), func(source Source) error {
+		InsertMem(MustConform[SourceMem](source), memTable, 1, 2)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	snap := selectRows(t, o)
+	want := errors.New("rollback")
+	err = o.Explicit(context.Background(), func(source Source) error {
+		ds := MustConform[SourceMem](source)
+		InsertMem(ds, memTable, 3)
+		UpdateMem(ds, memTable, func(int) bool { return true }, func(row int) int { return -row })
+		NextSeqMem(ds, memTable)
+		return want
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("got %v, want %v", err, want)
+	}
+	got := selectRows(t, o)
+	if !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("got %v, want %v", got, []int{1, 2})
+	}
+	// rows read earlier are never changed in place
+	if !slices.Equal(snap, []int{1, 2}) {
+		t.Errorf("got snapshot %v, want %v", snap, []int{1, 2})
+	}
+	err = o.Explicit(context.Background(), func(source Source) error {
+		ds := MustConform[SourceMem](source)
+		if seq := NextSeqMem(ds, memTable); seq != 1 {
+			t.Errorf("got seq %v, want 1", seq)
+		}
+		InsertMem(ds, memTable, 4)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	got = selectRows(t, o)
+	if !slices.Equal(got, []int{1, 2, 4}) {
+		t.Errorf("got %v, want %v", got, []int{1, 2, 4})
+	}
+}
+
+func TestOperatorMemImplicitKeepsWrites(t *testing.T) {
+	o := newOperatorMem()
+	want := errors.New("failure")
+	err := o.Implicit(context.Background(), func(source Source) error {
+		InsertMem(MustConform[SourceMem](source), memTable, 1)
+		return want
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("got %v, want %v", err, want)
+	}
+	got := selectRows(t, o)
+	if !slices.Equal(got, []int{1}) {
+		t.Errorf("got %v, want %v", got, []int{1})
+	}
+}
+
+func TestOperatorMemConcurrentIsolation(t *testing.T) {
+	o := newOperatorMem()
+	const n = 64
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = o.Explicit(context.Background(), func(source Source) error {
+				ds := MustConform[SourceMem](source)
+				// read-modify-write of the counter row must not lose updates
+				rows := SelectMem[int](ds, memTable)
+				if len(rows) == 0 {
+					InsertMem(ds, memTable, 0)
+				}
+				UpdateMem(ds, memTable, func(int) bool { return true }, func(row int) int { return row + 1 })
+				// odd transactions roll back their increment
+				if i%2 == 1 {
+					return errors.New("rollback")
+				}
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	got := selectRows(t, o)
+	if !slices.Equal(got, []int{n / 2}) {
+		t.Errorf("got %v, want %v", got, []int{n / 2})
+	}
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Migrations are embedded in the build and applied in order on start.
+// Applied versions with checksums are kept in schema_version,
+// so a database that diverged from the build stops the start.
+type migration struct {
+	Version  int64
+	Name     string
+	Query    string
+	Checksum string
+	// tables and columns the migration creates
+	Marks []tableReq
+}
+
+type versionDS struct {
+	Version  int64  `db:"version"`
+	Checksum string `db:"checksum"`
+}
+
+// files are named like 0001_tables.sql, versions go in a row from one
+func loadMigrations(fsys fs.FS, dir string) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	migrations := make([]migration, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("migration name malformed: %v", entry.Name())
+		}
+		if version != int64(len(migrations))+1 {
+			return nil, fmt.Errorf("migration out of order: want version %v, got %v", len(migrations)+1, version)
+		}
+		query, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(query)
+		migrations = append(migrations, migration{
+			Version:  version,
+			Name:     name,
+			Query:    string(query),
+			Checksum: hex.EncodeToString(sum[:]),
+			Marks:    marksOf(string(query)),
+		})
+	}
+	return migrations, nil
+}
+
+// checks applied versions against embedded ones and returns the missing
+func pendingMigrations(known []migration, applied []versionDS) ([]migration, error) {
+	for i, dto := range applied {
+		if i >= len(known) {
+			return nil, errSchemaAhead(dto.Version, known)
+		}
+		if dto.Version != known[i].Version {
+			return nil, errSchemaDiverged(known[i].Version, dto.Version)
+		}
+		if dto.Checksum != known[i].Checksum {
+			return nil, errChecksumMismatch(known[i])
+		}
+	}
+	return known[len(applied):], nil
+}
+
+var (
+	createTableRE = regexp.MustCompile(`(?i)create\s+table\s+(?:if\s+not\s+exists\s+)?(\w+)`)
+	addColumnRE   = regexp.MustCompile(`(?i)alter\s+table\s+(\w+)\s+add\s+column\s+(?:if\s+not\s+exists\s+)?(\w+)`)
+)
+
+func marksOf(query string) []tableReq {
+	var marks []tableReq
+	for _, match := range createTableRE.FindAllStringSubmatch(query, -1) {
+		marks = append(marks, tableReq{Table: strings.ToLower(match[1])})
+	}
+	for _, match := range addColumnRE.FindAllStringSubmatch(query, -1) {
+		marks = append(marks, tableReq{strings.ToLower(match[1]), []string{strings.ToLower(match[2])}})
+	}
+	return marks
+}
+
+// A database created before schema_version existed, e.g. by the
+// Liquibase changelog, already holds a prefix of the migrations.
+// That prefix is recognized by what it created and gets stamped
+// instead of applied, the rest goes the usual way.
+func baselineMigrations(known []migration, cols []columnDS) ([]migration, error) {
+	have := make(map[string]map[string]bool)
+	for _, dto := range cols {
+		if have[dto.Table] == nil {
+			have[dto.Table] = make(map[string]bool)
+		}
+		have[dto.Table][dto.Column] = true
+	}
+	found := func(m migration) int {
+		count := 0
+		for _, mark := range m.Marks {
+			tableCols, ok := have[mark.Table]
+			if ok && (len(mark.Columns) == 0 || tableCols[mark.Columns[0]]) {
+				count++
+			}
+		}
+		return count
+	}
+	baseline := 0
+	for baseline < len(known) {
+		count := found(known[baseline])
+		if count == 0 || count < len(known[baseline].Marks) {
+			break
+		}
+		baseline++
+	}
+	// whatever follows the baseline must be absent altogether
+	for _, m := range known[baseline:] {
+		if found(m) > 0 {
+			return nil, errSchemaPartial(m)
+		}
+	}
+	return known[:baseline], nil
+}
+
+func errSchemaPartial(m migration) error {
+	return fmt.Errorf("schema holds part of version %v (%v) without schema_version", m.Version, m.Name)
+}
+
+func errSchemaAhead(got int64, known []migration) error {
+	return fmt.Errorf("schema is ahead of build: want at most version %v, got %v", len(known), got)
+}
+
+func errSchemaDiverged(want, got int64) error {
+	return fmt.Errorf("schema diverged from build: want version %v, got %v", want, got)
+}
+
+func errChecksumMismatch(want migration) error {
+	return fmt.Errorf("schema version %v (%v) applied with different checksum", want.Version, want.Name)
+}
+
+func errMigrationFailed(m migration) error {
+	return fmt.Errorf("schema version %v (%v) failed", m.Version, m.Name)
+}
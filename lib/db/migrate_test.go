@@ -0,0 +1,270 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func migrationsFS(names ...string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for _, name := range names {
+		fsys["sql/"+name] = &fstest.MapFile{Data: []byte("-- " + name)}
+	}
+	return fsys
+}
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []int64
+		err   string
+	}{
+		{"in order", []string{"0001_tables.sql", "0002_runs.sql"}, []int64{1, 2}, ""},
+		{"non-sql skipped", []string{"0001_tables.sql", "README.md"}, []int64{1}, ""},
+		{"gap", []string{"0001_tables.sql", "0003_runs.sql"}, nil, "out of order"},
+		{"not from one", []string{"0002_runs.sql"}, nil, "out of order"},
+		{"duplicate", []string{"0001_tables.sql", "0001_runs.sql"}, nil, "out of order"},
+		{"no version", []string{"tables.sql"}, nil, "malformed"},
+		{"no name", []string{"0001.sql"}, nil, "malformed"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := loadMigrations(migrationsFS(test.files...), "sql")
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got %v, want error with %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v migrations, want %v", len(got), len(test.want))
+			}
+			for i, m := range got {
+				if m.Version != test.want[i] {
+					t.Errorf("got version %v, want %v", m.Version, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadMigrationsChecksum(t *testing.T) {
+	fsys := migrationsFS("0001_tables.sql")
+	before, err := loadMigrations(fsys, "sql")
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	fsys["sql/0001_tables.sql"] = &fstest.MapFile{Data: []byte("-- edited")}
+	after, err := loadMigrations(fsys, "sql")
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if before[0].Checksum == after[0].Checksum {
+		t.Errorf("got same checksum %v for different queries", before[0].Checksum)
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	known, err := loadMigrations(migrationsFS("0001_tables.sql", "0002_runs.sql", "0003_mods.sql"), "sql")
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	applied := func(versions ...int64) []versionDS {
+		dtos := make([]versionDS, 0, len(versions))
+		for _, v := range versions {
+			dtos = append(dtos, versionDS{Version: v, Checksum: known[v-1].Checksum})
+		}
+		return dtos
+	}
+	drifted := applied(1, 2)
+	drifted[1].Checksum = "drifted"
+	tests := []struct {
+		name    string
+		applied []versionDS
+		want    []int64
+		err     string
+	}{
+		{"fresh", nil, []int64{1, 2, 3}, ""},
+		{"partial", applied(1), []int64{2, 3}, ""},
+		{"current", applied(1, 2, 3), nil, ""},
+		{"checksum drift", drifted, nil, "different checksum"},
+		{"missing applied version", applied(1, 3), nil, "diverged"},
+		{"ahead of build", append(applied(1, 2, 3), versionDS{Version: 4}), nil, "ahead"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := pendingMigrations(known, test.applied)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got %v, want error with %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v pending, want %v", len(got), len(test.want))
+			}
+			for i, m := range got {
+				if m.Version != test.want[i] {
+					t.Errorf("got version %v, want %v", m.Version, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	reqs := map[string][]tableReq{
+		"dao": {{"runs", []string{"run_id", "status"}}},
+	}
+	tests := []struct {
+		name string
+		cols []columnDS
+		err  string
+	}{
+		{"complete", []columnDS{{"runs", "run_id"}, {"runs", "status"}, {"runs", "extra"}}, ""},
+		{"missing table", []columnDS{{"mods", "seq"}}, "table runs"},
+		{"missing column", []columnDS{{"runs", "run_id"}}, "column runs.status"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkSchema(reqs, test.cols)
+			if test.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error %q", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("got %v, want error with %q", err, test.err)
+			}
+		})
+	}
+}
+
+// embedded migrations give every adapter what it declares
+func TestMigrateSqlite(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	err = migrateSqlite(context.Background(), db)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	// a second start finds nothing to apply
+	err = migrateSqlite(context.Background(), db)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+}
+
+func TestBaselineMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0001_tables.sql":    {Data: []byte("CREATE TABLE runs (run_id text);\nCREATE TABLE mods (seq bigint);")},
+		"sql/0002_revisions.sql": {Data: []byte("ALTER TABLE runs ADD COLUMN rev_at text;")},
+		"sql/0003_claims.sql":    {Data: []byte("create table if not exists claims (proc_id text);")},
+	}
+	known, err := loadMigrations(fsys, "sql")
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	tests := []struct {
+		name string
+		cols []columnDS
+		want []int64
+		err  string
+	}{
+		{"fresh", nil, nil, ""},
+		{"foreign tables only", []columnDS{{"databasechangelog", "id"}}, nil, ""},
+		{"first version", []columnDS{{"runs", "run_id"}, {"mods", "seq"}}, []int64{1}, ""},
+		{"two versions", []columnDS{{"runs", "run_id"}, {"runs", "rev_at"}, {"mods", "seq"}}, []int64{1, 2}, ""},
+		{"all versions", []columnDS{{"runs", "run_id"}, {"runs", "rev_at"}, {"mods", "seq"}, {"claims", "proc_id"}}, []int64{1, 2, 3}, ""},
+		{"part of a version", []columnDS{{"runs", "run_id"}}, nil, "version 1"},
+		{"version skipped", []columnDS{{"runs", "run_id"}, {"mods", "seq"}, {"claims", "proc_id"}}, nil, "version 3"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := baselineMigrations(known, test.cols)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got %v, want error with %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v stamped, want %v", len(got), len(test.want))
+			}
+			for i, m := range got {
+				if m.Version != test.want[i] {
+					t.Errorf("got version %v, want %v", m.Version, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+// a schema left by the Liquibase changelog is adopted, not recreated
+func TestMigrateSqliteBaseline(t *testing.T) {
+	known, err := loadMigrations(migrationsSqlite, "sqlite")
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE databasechangelog (id text)")
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	for _, m := range known[:2] {
+		_, err = db.ExecContext(ctx, m.Query)
+		if err != nil {
+			t.Fatalf("unexpected error %q", err)
+		}
+	}
+	err = migrateSqlite(ctx, db)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT version, checksum FROM schema_version ORDER BY version")
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	defer rows.Close()
+	var got []versionDS
+	for rows.Next() {
+		var dto versionDS
+		err = rows.Scan(&dto.Version, &dto.Checksum)
+		if err != nil {
+			t.Fatalf("unexpected error %q", err)
+		}
+		got = append(got, dto)
+	}
+	if len(got) != len(known) {
+		t.Fatalf("got %v versions, want %v", len(got), len(known))
+	}
+	for i, dto := range got {
+		if dto.Version != known[i].Version || dto.Checksum != known[i].Checksum {
+			t.Errorf("got version %v, want %v", dto, known[i].Version)
+		}
+	}
+}
@@ -2,13 +2,27 @@ package db
 
 import (
 	"context"
+	"embed"
+	"errors"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/fx"
 )
 
-func newOperator(pool *pgxpool.Pool) Operator {
-	return &OperatorPgx{pool}
+func newOperatorPgx(dto storageCS, lc fx.Lifecycle) (*OperatorPgx, error) {
+	pool, err := newPgxDriver(dto, lc)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(
+		fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				return migratePgx(ctx, pool)
+			},
+		},
+	)
+	return &OperatorPgx{pool}, nil
 }
 
 func newPgxDriver(dto storageCS, lc fx.Lifecycle) (*pgxpool.Pool, error) {
@@ -32,3 +46,141 @@ func newPgxDriver(dto storageCS, lc fx.Lifecycle) (*pgxpool.Pool, error) {
 	)
 	return pgx, nil
 }
+
+//go:embed postgres/*.sql
+var migrationsPgx embed.FS
+
+// all migrations are applied in one transaction
+func migratePgx(ctx context.Context, pool *pgxpool.Pool) (err error) {
+	known, err := loadMigrations(migrationsPgx, "postgres")
+	if err != nil {
+		return err
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback(ctx))
+		}
+	}()
+	_, err = tx.Exec(ctx, lockVersionPgx)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, createVersionPgx)
+	if err != nil {
+		return err
+	}
+	rows, err := tx.Query(ctx, selectVersionsPgx)
+	if err != nil {
+		return err
+	}
+	applied, err := pgx.CollectRows(rows, pgx.RowToStructByName[versionDS])
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		applied, err = baselinePgx(ctx, tx, known)
+		if err != nil {
+			return err
+		}
+	}
+	pending, err := pendingMigrations(known, applied)
+	if err != nil {
+		return err
+	}
+	for _, m := range pending {
+		_, err = tx.Exec(ctx, m.Query)
+		if err != nil {
+			return errors.Join(errMigrationFailed(m), err)
+		}
+		args := pgx.NamedArgs{
+			"version":  m.Version,
+			"name":     m.Name,
+			"checksum": m.Checksum,
+		}
+		_, err = tx.Exec(ctx, insertVersionPgx, args)
+		if err != nil {
+			return err
+		}
+	}
+	rows, err = tx.Query(ctx, selectColumnsPgx)
+	if err != nil {
+		return err
+	}
+	cols, err := pgx.CollectRows(rows, pgx.RowToStructByName[columnDS])
+	if err != nil {
+		return err
+	}
+	err = checkSchema(schemaReqs, cols)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// stamps migrations found in a schema that predates schema_version
+func baselinePgx(ctx context.Context, tx pgx.Tx, known []migration) ([]versionDS, error) {
+	rows, err := tx.Query(ctx, selectColumnsPgx)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := pgx.CollectRows(rows, pgx.RowToStructByName[columnDS])
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := baselineMigrations(known, cols)
+	if err != nil {
+		return nil, err
+	}
+	applied := make([]versionDS, 0, len(baseline))
+	for _, m := range baseline {
+		args := pgx.NamedArgs{
+			"version":  m.Version,
+			"name":     m.Name,
+			"checksum": m.Checksum,
+		}
+		_, err = tx.Exec(ctx, insertVersionPgx, args)
+		if err != nil {
+			return nil, err
+		}
+		applied = append(applied, versionDS{m.Version, m.Checksum})
+	}
+	return applied, nil
+}
+
+const (
+	// nodes starting at once migrate one at a time
+	lockVersionPgx = `
+		select pg_advisory_xact_lock(hashtext('schema_version'))`
+
+	createVersionPgx = `
+		create table if not exists schema_version (
+			version bigint primary key,
+			name text not null,
+			checksum text not null,
+			applied_at timestamptz not null default now()
+		)`
+
+	selectVersionsPgx = `
+		select
+			version, checksum
+		from schema_version
+		order by version`
+
+	// table columns of the current search path
+	selectColumnsPgx = `
+		select
+			table_name::text, column_name::text
+		from information_schema.columns
+		where table_schema = current_schema()`
+
+	insertVersionPgx = `
+		insert into schema_version (
+			version, name, checksum
+		) values (
+			@version, @name, @checksum
+		)`
+)
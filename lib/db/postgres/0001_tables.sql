@@ -0,0 +1,218 @@
+-- Tables are created in the owner's search_path,
+-- the schema and the ltree extension come from db/postgres/owner.
+
+-- each definition revision is stored as a separate row
+CREATE TABLE type_defs (
+	def_id varchar(36),
+	def_rn bigint,
+	title varchar(64),
+	exp_id varchar(36),
+	type_vars jsonb,
+	idx_vars jsonb
+);
+
+CREATE UNIQUE INDEX type_defs_rn_idx ON type_defs (def_id, def_rn);
+
+CREATE TABLE type_exps (
+	exp_id varchar(36),
+	from_id varchar(36),
+	kind smallint,
+	spec jsonb
+);
+
+CREATE TABLE xact_defs (
+	def_id varchar(36),
+	def_rn bigint,
+	title varchar(64)
+);
+
+-- binding of an expression to definition revisions
+CREATE TABLE xact_def_exps (
+	def_id varchar(36),
+	exp_id varchar(36),
+	from_rn bigint,
+	to_rn bigint
+);
+
+CREATE TABLE xact_exps (
+	exp_id varchar(36),
+	from_id varchar(36),
+	kind smallint,
+	spec jsonb
+);
+
+CREATE TABLE proc_decs (
+	dec_id varchar(36),
+	dec_rn bigint,
+	title text,
+	idx_vars jsonb,
+	pot bigint
+);
+
+CREATE TABLE dec_pes (
+	dec_id varchar(36),
+	chnl_ph varchar(64),
+	type_qn ltree,
+	type_args jsonb,
+	idx_args jsonb,
+	from_rn bigint,
+	to_rn bigint
+);
+
+CREATE TABLE dec_ces (
+	dec_id varchar(36),
+	chnl_ph varchar(64),
+	type_qn ltree,
+	type_args jsonb,
+	idx_args jsonb,
+	from_rn bigint,
+	to_rn bigint
+);
+
+CREATE TABLE dec_subs (
+	dec_id varchar(36),
+	dec_qn ltree,
+	from_rn bigint,
+	to_rn bigint
+);
+
+CREATE TABLE pool_decs (
+	dec_id varchar(36),
+	dec_rn bigint,
+	ipbs jsonb,
+	irbs jsonb,
+	opbs jsonb,
+	orbs jsonb
+);
+
+CREATE TABLE pool_execs (
+	exec_id varchar(36),
+	exec_rn bigint,
+	pool_qn ltree,
+	title varchar(64),
+	proc_id varchar(36),
+	sup_exec_id varchar(36)
+);
+
+CREATE TABLE pool_caps (
+	pool_id varchar(36),
+	sig_id varchar(36),
+	rev bigint
+);
+
+CREATE TABLE pool_deps (
+	pool_id varchar(36),
+	sig_id varchar(36),
+	rev bigint
+);
+
+-- channel transfers (provider side)
+-- the transfer history gives the current provider
+CREATE TABLE pool_liabs (
+	proc_id varchar(36),
+	pool_id varchar(36),
+	rev bigint
+);
+
+-- held channels (client side)
+CREATE TABLE pool_assets (
+	pool_id varchar(36),
+	chnl_ph varchar(36),
+	chnl_id varchar(36),
+	proc_id varchar(36),
+	sig_id varchar(36),
+	rev bigint
+);
+
+CREATE TABLE proc_defs (
+	def_id varchar(36),
+	def_rn bigint,
+	proc_es jsonb
+);
+
+CREATE TABLE proc_execs (
+	exec_id varchar(36),
+	exec_rn bigint,
+	dec_id varchar(36),
+	dec_rn bigint
+);
+
+-- channel substitutions into a process
+CREATE TABLE proc_binds (
+	exec_id varchar(36),
+	chnl_bs smallint,
+	chnl_ph varchar(36),
+	chnl_id varchar(36),
+	state_id varchar(36),
+	exec_rn bigint
+);
+
+-- work steps are not bound to a channel and keep the amount in proc_er
+CREATE TABLE proc_steps (
+	exec_id varchar(36),
+	exec_rn bigint,
+	chnl_id varchar(36),
+	kind smallint,
+	proc_er jsonb
+);
+
+-- acquire queue of shared channels
+CREATE TABLE proc_acqs (
+	exec_id varchar(36),
+	exec_rn bigint,
+	chnl_id varchar(36),
+	kind smallint,
+	proc_er jsonb,
+	acq_seq bigserial
+);
+
+-- acquired shared channels
+CREATE TABLE proc_leases (
+	chnl_id varchar(36),
+	provider_id varchar(36),
+	provider_ph varchar(36),
+	client_id varchar(36),
+	client_ph varchar(36)
+);
+
+-- step run queue
+CREATE TABLE proc_runs (
+	run_id varchar(36),
+	ticket_id varchar(36),
+	exec_id varchar(36),
+	exec_rn bigint,
+	proc_es jsonb,
+	status smallint,
+	reason text,
+	claimed_at timestamptz,
+	-- a delayed step is available no earlier than this
+	wake_at timestamptz,
+	run_seq bigserial
+);
+
+CREATE INDEX proc_runs_status_idx ON proc_runs (status, run_seq);
+CREATE INDEX proc_runs_ticket_idx ON proc_runs (ticket_id);
+
+-- detected deadlocks and dangling waits
+CREATE TABLE proc_findings (
+	finding_key text UNIQUE,
+	kind smallint,
+	waits jsonb,
+	detected_at timestamptz DEFAULT now()
+);
+
+CREATE TABLE pool_sups (
+	pool_id varchar(36),
+	sup_pool_id varchar(36),
+	rev bigint
+);
+
+CREATE TABLE syn_decs (
+	dec_id varchar(36),
+	dec_qn ltree UNIQUE,
+	from_rn bigint,
+	to_rn bigint,
+	kind smallint
+);
+
+CREATE INDEX syn_decs_qn_idx ON syn_decs USING GIST (dec_qn);
@@ -0,0 +1,5 @@
+-- Point-in-time queries need the revision creation time.
+-- Revisions created before this version get the migration time.
+ALTER TABLE type_defs ADD COLUMN rev_at timestamptz NOT NULL DEFAULT now();
+
+ALTER TABLE proc_decs ADD COLUMN rev_at timestamptz NOT NULL DEFAULT now();
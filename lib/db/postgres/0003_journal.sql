@@ -0,0 +1,18 @@
+-- Execution journal: every ExecMod is appended in the same
+-- transaction as the change itself, and the journal rebuilds
+-- proc_binds and proc_steps.
+CREATE TABLE proc_mods (
+	seq bigserial PRIMARY KEY,
+	exec_mod jsonb NOT NULL,
+	recorded_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE FUNCTION proc_mods_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'proc_mods is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER proc_mods_append_only
+	BEFORE UPDATE OR DELETE OR TRUNCATE ON proc_mods
+	FOR EACH STATEMENT EXECUTE FUNCTION proc_mods_append_only();
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Tables and columns the storage adapters rely on. After
+// migrations they are checked against the live schema, so a database
+// that lacks what an adapter needs stops the start instead of failing
+// the first query. Tests keep the lists in step with the adapter queries.
+type tableReq struct {
+	Table   string
+	Columns []string
+}
+
+// adapters by package; foreign tables are listed where they are read
+var schemaReqs = map[string][]tableReq{
+	"pooldec": {
+		{"pool_decs", []string{"dec_id", "dec_rn", "ipbs", "irbs", "opbs", "orbs"}},
+	},
+	"poolexec": {
+		{"pool_execs", []string{"exec_id", "exec_rn", "pool_qn", "title", "proc_id", "sup_exec_id"}},
+		{"pool_liabs", []string{"proc_id", "pool_id", "rev"}},
+		{"pool_caps", []string{"pool_id", "sig_id", "rev"}},
+		{"pool_deps", []string{"pool_id", "sig_id", "rev"}},
+		{"pool_assets", []string{"pool_id", "chnl_ph", "chnl_id", "proc_id", "sig_id", "rev"}},
+		{"pool_claims", []string{"proc_id", "claimed_at"}},
+		{"proc_execs", []string{"exec_id", "exec_rn"}},
+		{"proc_binds", []string{"exec_id", "exec_rn", "chnl_ph", "chnl_id"}},
+		{"proc_steps", []string{"exec_id", "exec_rn", "chnl_id"}},
+	},
+	"procdec": {
+		{"proc_decs", []string{"dec_id", "dec_rn", "title", "idx_vars", "pot", "rev_at"}},
+		{"dec_pes", []string{"dec_id", "chnl_ph", "type_qn", "type_args", "idx_args", "from_rn", "to_rn"}},
+		{"dec_ces", []string{"dec_id", "chnl_ph", "type_qn", "type_args", "idx_args", "from_rn", "to_rn"}},
+	},
+	"procdef": {
+		{"proc_defs", []string{"def_id", "def_rn", "proc_es"}},
+	},
+	"procexec": {
+		{"proc_execs", []string{"exec_id", "exec_rn", "dec_id", "dec_rn"}},
+		{"proc_binds", []string{"exec_id", "exec_rn", "chnl_bs", "chnl_ph", "chnl_id", "state_id"}},
+		{"proc_steps", []string{"exec_id", "exec_rn", "chnl_id", "kind", "proc_er"}},
+		{"proc_acqs", []string{"exec_id", "exec_rn", "chnl_id", "kind", "proc_er", "acq_seq"}},
+		{"proc_leases", []string{"chnl_id", "provider_id", "provider_ph", "client_id", "client_ph"}},
+		{"proc_runs", []string{"run_id", "ticket_id", "exec_id", "exec_rn", "proc_es", "status", "reason", "claimed_at", "wake_at", "run_seq"}},
+		{"proc_findings", []string{"finding_key", "kind", "waits"}},
+		{"proc_mods", []string{"seq", "exec_mod"}},
+		{"proc_decs", []string{"dec_id", "dec_rn", "pot"}},
+	},
+	"procstep": {
+		{"proc_steps", []string{"exec_id", "exec_rn", "chnl_id", "kind", "proc_er"}},
+	},
+	"syndec": {
+		{"syn_decs", []string{"dec_id", "dec_qn", "from_rn", "to_rn"}},
+	},
+	"typedef": {
+		{"type_defs", []string{"def_id", "def_rn", "title", "exp_id", "type_vars", "idx_vars", "rev_at"}},
+		{"syn_decs", []string{"dec_id", "dec_qn", "from_rn", "to_rn"}},
+		{"proc_decs", []string{"dec_id", "dec_rn", "title", "idx_vars", "rev_at"}},
+		{"dec_pes", []string{"dec_id", "chnl_ph", "type_qn", "from_rn", "to_rn"}},
+		{"dec_ces", []string{"dec_id", "chnl_ph", "type_qn", "from_rn", "to_rn"}},
+		{"proc_defs", []string{"def_id", "def_rn"}},
+		{"proc_execs", []string{"exec_id", "exec_rn", "dec_id", "dec_rn"}},
+		{"proc_binds", []string{"exec_id", "exec_rn", "chnl_ph", "state_id"}},
+	},
+	"typeexp": {
+		{"type_exps", []string{"exp_id", "from_id", "kind", "spec"}},
+	},
+	"xactdef": {
+		{"xact_defs", []string{"def_id", "def_rn", "title"}},
+		{"xact_def_exps", []string{"def_id", "exp_id", "from_rn", "to_rn"}},
+		{"syn_decs", []string{"dec_id", "dec_qn", "from_rn", "to_rn"}},
+	},
+	"xactexp": {
+		{"xact_exps", []string{"exp_id", "from_id", "kind", "spec"}},
+	},
+}
+
+type columnDS struct {
+	Table  string `db:"table_name"`
+	Column string `db:"column_name"`
+}
+
+// checks adapter requirements against live schema columns
+func checkSchema(reqs map[string][]tableReq, cols []columnDS) error {
+	have := make(map[string]map[string]bool)
+	for _, dto := range cols {
+		if have[dto.Table] == nil {
+			have[dto.Table] = make(map[string]bool)
+		}
+		have[dto.Table][dto.Column] = true
+	}
+	// mismatches across all adapters are reported together
+	var errs []error
+	for _, dao := range slices.Sorted(maps.Keys(reqs)) {
+		for _, req := range reqs[dao] {
+			tableCols, ok := have[req.Table]
+			if !ok {
+				errs = append(errs, errTableMissing(dao, req.Table))
+				continue
+			}
+			for _, col := range req.Columns {
+				if !tableCols[col] {
+					errs = append(errs, errColumnMissing(dao, req.Table, col))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func errTableMissing(dao, table string) error {
+	return fmt.Errorf("schema lacks table %v required by %v", table, dao)
+}
+
+func errColumnMissing(dao, table, col string) error {
+	return fmt.Errorf("schema lacks column %v.%v required by %v", table, col, dao)
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type queryConst struct {
+	Pkg   string
+	Name  string
+	Query string
+}
+
+// string constants of the storage adapters, as written in the sources
+func daoQueries(t *testing.T, file string) []queryConst {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("..", "..", "adt", "*", file))
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	var queries []queryConst
+	for _, path := range paths {
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			t.Fatalf("unexpected error %q", err)
+		}
+		pkg := filepath.Base(filepath.Dir(path))
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, value := range vs.Values {
+					lit, ok := value.(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING {
+						continue
+					}
+					query, err := strconv.Unquote(lit.Value)
+					if err != nil {
+						t.Fatalf("unexpected error %q", err)
+					}
+					queries = append(queries, queryConst{pkg, vs.Names[i].Name, query})
+				}
+			}
+		}
+	}
+	if len(queries) == 0 {
+		t.Fatalf("got no queries in %v", file)
+	}
+	return queries
+}
+
+// every sqlite query compiles against the migrated schema
+func TestSqliteQueries(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	ctx := context.Background()
+	err = migrateSqlite(ctx, db)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	for _, q := range daoQueries(t, "ds_sqlite.go") {
+		t.Run(q.Pkg+"/"+q.Name, func(t *testing.T) {
+			stmt, err := db.PrepareContext(ctx, q.Query)
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			stmt.Close()
+		})
+	}
+}
+
+var (
+	// row locks and upserts update no table of their own
+	tableRefRE = regexp.MustCompile(`(?i)\b(for\s+update|do\s+update|from|join|into|update|table)\s+(\w+)`)
+	cteRE      = regexp.MustCompile(`(?i)(?:\bwith(?:\s+recursive)?|,)\s+(\w+)\s+as\s+(?:not\s+)?(?:materialized\s*)?\(`)
+)
+
+// tables a query reads or writes, named common table expressions aside
+func tableRefs(query string) []string {
+	ctes := map[string]bool{}
+	for _, match := range cteRE.FindAllStringSubmatch(query, -1) {
+		ctes[strings.ToLower(match[1])] = true
+	}
+	var tables []string
+	for _, match := range tableRefRE.FindAllStringSubmatch(query, -1) {
+		if len(strings.Fields(match[1])) > 1 {
+			continue
+		}
+		table := strings.ToLower(match[2])
+		if !ctes[table] && !slices.Contains(tables, table) {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// the startup check knows every table the postgres queries touch
+func TestSchemaReqsCoverPgxQueries(t *testing.T) {
+	for _, q := range daoQueries(t, "ds_pgx.go") {
+		for _, table := range tableRefs(q.Query) {
+			found := slices.ContainsFunc(schemaReqs[q.Pkg], func(req tableReq) bool {
+				return req.Table == table
+			})
+			if !found {
+				t.Errorf("got table %v in %v.%v, want it in schemaReqs[%q]", table, q.Pkg, q.Name, q.Pkg)
+			}
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/fx"
+	_ "modernc.org/sqlite"
+)
+
+// Embedded storage for single-node and local deployments.
+// Composite values are stored as json, time instants as TimeSqlite.
+type SourceSqlite struct {
+	Ctx  context.Context
+	Conn ConnSqlite
+}
+
+func (SourceSqlite) source() {}
+
+type OperatorSqlite struct {
+	db *sql.DB
+}
+
+func newOperatorSqlite(dto storageCS, lc fx.Lifecycle) (*OperatorSqlite, error) {
+	db, err := sql.Open("sqlite", dto.Protocol.Sqlite.Path)
+	if err != nil {
+		return nil, err
+	}
+	// sqlite allows a single writer, and an in-memory database lives
+	// only within a connection
+	db.SetMaxOpenConns(1)
+	lc.Append(
+		fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				return migrateSqlite(ctx, db)
+			},
+			OnStop: func(ctx context.Context) error {
+				return db.Close()
+			},
+		},
+	)
+	return &OperatorSqlite{db}, nil
+}
+
+//go:embed sqlite/*.sql
+var migrationsSqlite embed.FS
+
+// all migrations are applied in one transaction, sqlite has one writer
+func migrateSqlite(ctx context.Context, db *sql.DB) (err error) {
+	known, err := loadMigrations(migrationsSqlite, "sqlite")
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback())
+		}
+	}()
+	conn := ConnSqlite{tx}
+	_, err = conn.Exec(ctx, createVersionSqlite, nil)
+	if err != nil {
+		return err
+	}
+	rows, err := conn.Query(ctx, selectVersionsSqlite, nil)
+	if err != nil {
+		return err
+	}
+	applied, err := CollectRowsSqlite[versionDS](rows)
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		applied, err = baselineSqlite(ctx, conn, known)
+		if err != nil {
+			return err
+		}
+	}
+	pending, err := pendingMigrations(known, applied)
+	if err != nil {
+		return err
+	}
+	for _, m := range pending {
+		_, err = conn.Exec(ctx, m.Query, nil)
+		if err != nil {
+			return errors.Join(errMigrationFailed(m), err)
+		}
+		args := NamedArgsSqlite{
+			"version":  m.Version,
+			"name":     m.Name,
+			"checksum": m.Checksum,
+		}
+		_, err = conn.Exec(ctx, insertVersionSqlite, args)
+		if err != nil {
+			return err
+		}
+	}
+	rows, err = conn.Query(ctx, selectColumnsSqlite, nil)
+	if err != nil {
+		return err
+	}
+	cols, err := CollectRowsSqlite[columnDS](rows)
+	if err != nil {
+		return err
+	}
+	err = checkSchema(schemaReqs, cols)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// stamps migrations found in a schema that predates schema_version
+func baselineSqlite(ctx context.Context, conn ConnSqlite, known []migration) ([]versionDS, error) {
+	rows, err := conn.Query(ctx, selectColumnsSqlite, nil)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := CollectRowsSqlite[columnDS](rows)
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := baselineMigrations(known, cols)
+	if err != nil {
+		return nil, err
+	}
+	applied := make([]versionDS, 0, len(baseline))
+	for _, m := range baseline {
+		args := NamedArgsSqlite{
+			"version":  m.Version,
+			"name":     m.Name,
+			"checksum": m.Checksum,
+		}
+		_, err = conn.Exec(ctx, insertVersionSqlite, args)
+		if err != nil {
+			return nil, err
+		}
+		applied = append(applied, versionDS{m.Version, m.Checksum})
+	}
+	return applied, nil
+}
+
+const (
+	createVersionSqlite = `
+		create table if not exists schema_version (
+			version integer primary key,
+			name text not null,
+			checksum text not null,
+			applied_at text not null default (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+		)`
+
+	selectVersionsSqlite = `
+		select
+			version, checksum
+		from schema_version
+		order by version`
+
+	// aka information_schema.columns
+	selectColumnsSqlite = `
+		select
+			tbl.name as table_name, col.name as column_name
+		from sqlite_master tbl
+		join pragma_table_info(tbl.name) col
+		where tbl.type = 'table'`
+
+	insertVersionSqlite = `
+		insert into schema_version (
+			version, name, checksum
+		) values (
+			:version, :name, :checksum
+		)`
+)
+
+func (o *OperatorSqlite) Explicit(ctx context.Context, op func(Source) error) error {
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	err = op(SourceSqlite{Ctx: ctx, Conn: ConnSqlite{tx}})
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+func (o *OperatorSqlite) Implicit(ctx context.Context, op func(Source) error) error {
+	return op(SourceSqlite{Ctx: ctx, Conn: ConnSqlite{o.db}})
+}
+
+// common to a transaction and a database
+type execerSqlite interface {
+	ExecContext(context.Context, string, ...any) (sql.Result, error)
+	QueryContext(context.Context, string, ...any) (*sql.Rows, error)
+}
+
+type ConnSqlite struct {
+	execer execerSqlite
+}
+
+func (c ConnSqlite) Exec(ctx context.Context, query string, args NamedArgsSqlite) (sql.Result, error) {
+	return c.execer.ExecContext(ctx, query, args.named()...)
+}
+
+func (c ConnSqlite) Query(ctx context.Context, query string, args NamedArgsSqlite) (*sql.Rows, error) {
+	return c.execer.QueryContext(ctx, query, args.named()...)
+}
+
+// aka pgx.NamedArgs; queries spell names as :name
+type NamedArgsSqlite map[string]any
+
+func (args NamedArgsSqlite) named() []any {
+	named := make([]any, 0, len(args))
+	for name, arg := range args {
+		named = append(named, sql.Named(name, valueSqlite(arg)))
+	}
+	return named
+}
+
+// scalar values are passed to the driver as is,
+// composite ones are encoded as json
+func valueSqlite(arg any) any {
+	switch v := arg.(type) {
+	case time.Time:
+		return TimeSqlite(v)
+	case sql.NullTime:
+		if !v.Valid {
+			return nil
+		}
+		return TimeSqlite(v.Time)
+	case nil, driver.Valuer, []byte:
+		return arg
+	}
+	rv := reflect.ValueOf(arg)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+	case reflect.Struct, reflect.Array:
+	default:
+		return arg
+	}
+	data, err := json.Marshal(arg)
+	if err != nil {
+		panic(fmt.Sprintf("json value: %v", err))
+	}
+	return string(data)
+}
+
+// A time instant is stored as a fixed-length UTC string,
+// so instants compare directly in queries.
+func TimeSqlite(t time.Time) string {
+	return t.UTC().Format(timeLayoutSqlite)
+}
+
+const timeLayoutSqlite = "2006-01-02 15:04:05.000000"
+
+// aka pgx.CollectRows(rows, pgx.RowToStructByName[T])
+func CollectRowsSqlite[T any](rows *sql.Rows) (_ []T, err error) {
+	defer func() {
+		err = errors.Join(err, rows.Close())
+	}()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	idx, err := fieldsSqlite(reflect.TypeFor[T](), cols)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	dtos := []T{}
+	for rows.Next() {
+		err = rows.Scan(ptrs...)
+		if err != nil {
+			return nil, err
+		}
+		var dto T
+		rv := reflect.ValueOf(&dto).Elem()
+		for i, val := range vals {
+			err = assignSqlite(rv.FieldByIndex(idx[i]), val)
+			if err != nil {
+				return nil, fmt.Errorf("column %v: %w", cols[i], err)
+			}
+		}
+		dtos = append(dtos, dto)
+	}
+	return dtos, rows.Err()
+}
+
+// aka pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[T])
+func CollectOneRowSqlite[T any](rows *sql.Rows) (T, error) {
+	dtos, err := CollectRowsSqlite[T](rows)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	switch len(dtos) {
+	case 0:
+		var zero T
+		return zero, ErrNoRows
+	case 1:
+		return dtos[0], nil
+	default:
+		var zero T
+		return zero, ErrTooManyRows
+	}
+}
+
+var ErrTooManyRows = pgx.ErrTooManyRows
+
+var fieldCacheSqlite sync.Map
+
+// columns are matched to fields by the db tag
+func fieldsSqlite(t reflect.Type, cols []string) ([][]int, error) {
+	byName, ok := fieldCacheSqlite.Load(t)
+	if !ok {
+		fields := make(map[string][]int)
+		for _, f := range reflect.VisibleFields(t) {
+			name, ok := f.Tag.Lookup("db")
+			if ok && name != "-" && f.IsExported() {
+				fields[name] = f.Index
+			}
+		}
+		byName, _ = fieldCacheSqlite.LoadOrStore(t, fields)
+	}
+	idx := make([][]int, 0, len(cols))
+	for _, col := range cols {
+		i, ok := byName.(map[string][]int)[col]
+		if !ok {
+			return nil, fmt.Errorf("%v has no field for column %v", t, col)
+		}
+		idx = append(idx, i)
+	}
+	return idx, nil
+}
+
+func assignSqlite(field reflect.Value, val any) error {
+	if val == nil {
+		return nil
+	}
+	if b, ok := val.([]byte); ok {
+		val = string(b)
+	}
+	switch field.Addr().Interface().(type) {
+	case *time.Time:
+		t, err := parseTimeSqlite(val)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	case *sql.NullTime:
+		t, err := parseTimeSqlite(val)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(sql.NullTime{Time: t, Valid: true}))
+		return nil
+	case sql.Scanner:
+		return field.Addr().Interface().(sql.Scanner).Scan(val)
+	}
+	switch field.Kind() {
+	case reflect.String:
+		s, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("cannot assign %T to %v", val, field.Type())
+		}
+		field.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := val.(int64)
+		if !ok {
+			return fmt.Errorf("cannot assign %T to %v", val, field.Type())
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := val.(int64)
+		if !ok || n < 0 {
+			return fmt.Errorf("cannot assign %v to %v", val, field.Type())
+		}
+		field.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		switch n := val.(type) {
+		case float64:
+			field.SetFloat(n)
+		case int64:
+			field.SetFloat(float64(n))
+		default:
+			return fmt.Errorf("cannot assign %T to %v", val, field.Type())
+		}
+	case reflect.Bool:
+		n, ok := val.(int64)
+		if !ok {
+			return fmt.Errorf("cannot assign %T to %v", val, field.Type())
+		}
+		field.SetBool(n != 0)
+	default:
+		// composite values are stored as json
+		s, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("cannot assign %T to %v", val, field.Type())
+		}
+		return json.Unmarshal([]byte(s), field.Addr().Interface())
+	}
+	return nil
+}
+
+// the driver may itself detect an instant by the declared column type
+func parseTimeSqlite(val any) (time.Time, error) {
+	switch v := val.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return time.ParseInLocation(timeLayoutSqlite, v, time.UTC)
+	default:
+		return time.Time{}, fmt.Errorf("cannot assign %T to time", val)
+	}
+}
@@ -0,0 +1,238 @@
+-- Portable variant of the postgres schema for embedded storage:
+-- ltree is replaced with dotted text, jsonb with json text,
+-- GiST index with a plain one, bigserial with a rowid alias,
+-- timestamptz with fixed-length UTC text.
+-- IF NOT EXISTS is kept for databases created before versioning.
+
+CREATE TABLE IF NOT EXISTS type_defs (
+	def_id text,
+	def_rn integer,
+	title text,
+	exp_id text,
+	type_vars text,
+	idx_vars text
+);
+
+CREATE INDEX IF NOT EXISTS type_defs_id_idx ON type_defs (def_id, def_rn);
+
+CREATE TABLE IF NOT EXISTS type_exps (
+	exp_id text,
+	from_id text,
+	kind integer,
+	spec text
+);
+
+CREATE INDEX IF NOT EXISTS type_exps_id_idx ON type_exps (exp_id);
+CREATE INDEX IF NOT EXISTS type_exps_from_idx ON type_exps (from_id);
+
+CREATE TABLE IF NOT EXISTS xact_defs (
+	def_id text,
+	def_rn integer,
+	title text
+);
+
+-- binding of an expression to definition revisions
+CREATE TABLE IF NOT EXISTS xact_def_exps (
+	def_id text,
+	exp_id text,
+	from_rn integer,
+	to_rn integer
+);
+
+CREATE TABLE IF NOT EXISTS xact_exps (
+	exp_id text,
+	from_id text,
+	kind integer,
+	spec text
+);
+
+CREATE INDEX IF NOT EXISTS xact_exps_id_idx ON xact_exps (exp_id);
+CREATE INDEX IF NOT EXISTS xact_exps_from_idx ON xact_exps (from_id);
+
+CREATE TABLE IF NOT EXISTS proc_decs (
+	dec_id text,
+	dec_rn integer,
+	title text,
+	idx_vars text,
+	pot integer
+);
+
+CREATE INDEX IF NOT EXISTS proc_decs_id_idx ON proc_decs (dec_id, dec_rn);
+
+CREATE TABLE IF NOT EXISTS dec_pes (
+	dec_id text,
+	chnl_ph text,
+	type_qn text,
+	type_args text,
+	idx_args text,
+	from_rn integer,
+	to_rn integer
+);
+
+CREATE INDEX IF NOT EXISTS dec_pes_qn_idx ON dec_pes (type_qn);
+
+CREATE TABLE IF NOT EXISTS dec_ces (
+	dec_id text,
+	chnl_ph text,
+	type_qn text,
+	type_args text,
+	idx_args text,
+	from_rn integer,
+	to_rn integer
+);
+
+CREATE INDEX IF NOT EXISTS dec_ces_qn_idx ON dec_ces (type_qn);
+
+CREATE TABLE IF NOT EXISTS dec_subs (
+	dec_id text,
+	dec_qn text,
+	from_rn integer,
+	to_rn integer
+);
+
+CREATE TABLE IF NOT EXISTS pool_decs (
+	dec_id text,
+	dec_rn integer,
+	ipbs text,
+	irbs text,
+	opbs text,
+	orbs text
+);
+
+CREATE TABLE IF NOT EXISTS pool_execs (
+	exec_id text,
+	exec_rn integer,
+	pool_qn text,
+	title text,
+	proc_id text,
+	sup_exec_id text
+);
+
+CREATE TABLE IF NOT EXISTS pool_caps (
+	pool_id text,
+	sig_id text,
+	rev integer
+);
+
+CREATE TABLE IF NOT EXISTS pool_deps (
+	pool_id text,
+	sig_id text,
+	rev integer
+);
+
+-- channel transfers (provider side)
+-- the transfer history gives the current provider
+CREATE TABLE IF NOT EXISTS pool_liabs (
+	proc_id text,
+	pool_id text,
+	rev integer
+);
+
+-- held channels (client side)
+CREATE TABLE IF NOT EXISTS pool_assets (
+	pool_id text,
+	chnl_ph text,
+	chnl_id text,
+	proc_id text,
+	sig_id text,
+	rev integer
+);
+
+CREATE TABLE IF NOT EXISTS proc_defs (
+	def_id text,
+	def_rn integer,
+	proc_es text
+);
+
+CREATE TABLE IF NOT EXISTS proc_execs (
+	exec_id text,
+	exec_rn integer,
+	dec_id text,
+	dec_rn integer
+);
+
+-- channel substitutions into a process
+CREATE TABLE IF NOT EXISTS proc_binds (
+	exec_id text,
+	chnl_bs integer,
+	chnl_ph text,
+	chnl_id text,
+	state_id text,
+	exec_rn integer
+);
+
+CREATE INDEX IF NOT EXISTS proc_binds_exec_idx ON proc_binds (exec_id, chnl_ph);
+
+-- work steps are not bound to a channel and keep the amount in proc_er
+CREATE TABLE IF NOT EXISTS proc_steps (
+	exec_id text,
+	exec_rn integer,
+	chnl_id text,
+	kind integer,
+	proc_er text
+);
+
+CREATE INDEX IF NOT EXISTS proc_steps_chnl_idx ON proc_steps (chnl_id);
+
+-- acquire queue of shared channels
+CREATE TABLE IF NOT EXISTS proc_acqs (
+	exec_id text,
+	exec_rn integer,
+	chnl_id text,
+	kind integer,
+	proc_er text,
+	acq_seq integer PRIMARY KEY
+);
+
+-- acquired shared channels
+CREATE TABLE IF NOT EXISTS proc_leases (
+	chnl_id text,
+	provider_id text,
+	provider_ph text,
+	client_id text,
+	client_ph text
+);
+
+-- step run queue
+CREATE TABLE IF NOT EXISTS proc_runs (
+	run_id text,
+	ticket_id text,
+	exec_id text,
+	exec_rn integer,
+	proc_es text,
+	status integer,
+	reason text,
+	claimed_at text,
+	-- a delayed step is available no earlier than this
+	wake_at text,
+	run_seq integer PRIMARY KEY
+);
+
+CREATE INDEX IF NOT EXISTS proc_runs_status_idx ON proc_runs (status, run_seq);
+CREATE INDEX IF NOT EXISTS proc_runs_ticket_idx ON proc_runs (ticket_id);
+
+-- detected deadlocks and dangling waits
+CREATE TABLE IF NOT EXISTS proc_findings (
+	finding_key text UNIQUE,
+	kind integer,
+	waits text,
+	detected_at text DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS pool_sups (
+	pool_id text,
+	sup_pool_id text,
+	rev integer
+);
+
+CREATE TABLE IF NOT EXISTS syn_decs (
+	dec_id text,
+	dec_qn text,
+	from_rn integer,
+	to_rn integer,
+	kind integer
+);
+
+-- instead of a GiST index on ltree: names are looked up by exact match
+CREATE INDEX IF NOT EXISTS syn_decs_qn_idx ON syn_decs (dec_qn, from_rn);
+CREATE INDEX IF NOT EXISTS syn_decs_id_idx ON syn_decs (dec_id);
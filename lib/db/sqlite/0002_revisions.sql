@@ -0,0 +1,11 @@
+-- Point-in-time queries need the revision creation time.
+-- SQLite cannot add a column with a computed default,
+-- so insert queries set the time, and revisions created
+-- before this version get the migration time.
+ALTER TABLE type_defs ADD COLUMN rev_at text;
+
+UPDATE type_defs SET rev_at = strftime('%Y-%m-%d %H:%M:%f000', 'now');
+
+ALTER TABLE proc_decs ADD COLUMN rev_at text;
+
+UPDATE proc_decs SET rev_at = strftime('%Y-%m-%d %H:%M:%f000', 'now');
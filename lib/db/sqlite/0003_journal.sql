@@ -0,0 +1,18 @@
+-- Execution journal: every ExecMod is appended in the same
+-- transaction as the change itself, and the journal rebuilds
+-- proc_binds and proc_steps. AUTOINCREMENT never reuses numbers.
+CREATE TABLE proc_mods (
+	seq integer PRIMARY KEY AUTOINCREMENT,
+	exec_mod text NOT NULL,
+	recorded_at text NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+
+CREATE TRIGGER proc_mods_no_update BEFORE UPDATE ON proc_mods
+BEGIN
+	SELECT RAISE(ABORT, 'proc_mods is append-only');
+END;
+
+CREATE TRIGGER proc_mods_no_delete BEFORE DELETE ON proc_mods
+BEGIN
+	SELECT RAISE(ABORT, 'proc_mods is append-only');
+END;
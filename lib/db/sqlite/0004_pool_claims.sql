@@ -0,0 +1,6 @@
+-- Claims of ready pool executions: an execution handed to one
+-- worker is not handed to others until the claim expires.
+CREATE TABLE pool_claims (
+	proc_id text PRIMARY KEY,
+	claimed_at text NOT NULL
+);
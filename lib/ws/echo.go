@@ -13,6 +13,7 @@ import (
 func newEchoServer(dto exchangeCS, l *slog.Logger, lc fx.Lifecycle) *echo.Echo {
 	e := echo.New()
 	log := l.With(slog.String("name", "echoServer"))
+	e.HTTPErrorHandler = newProblemHandler(e, log)
 	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		LogMethod:   true,
 		LogURI:      true,
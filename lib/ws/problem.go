@@ -0,0 +1,74 @@
+package ws
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// aka RFC 7807
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// extension: detailed diagnostics
+	Errors any `json:"errors,omitempty"`
+}
+
+// API errors are rendered as problem+json, other pages
+// are handled as usual.
+func newProblemHandler(e *echo.Echo, log *slog.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+		if !strings.HasPrefix(c.Request().URL.Path, "/api/") {
+			e.DefaultHTTPErrorHandler(err, c)
+			return
+		}
+		problem := ProblemFromError(err)
+		problem.Instance = c.Request().URL.Path
+		c.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+		c.Response().WriteHeader(problem.Status)
+		if c.Request().Method == http.MethodHead {
+			return
+		}
+		encodingErr := c.Echo().JSONSerializer.Serialize(c, problem, "")
+		if encodingErr != nil {
+			log.Error("problem encoding failed", slog.Any("reason", encodingErr))
+		}
+	}
+}
+
+// The HTTPError message becomes the detail if it is a string
+// and the errors extension otherwise.
+func ProblemFromError(err error) Problem {
+	var httpErr *echo.HTTPError
+	if !errors.As(err, &httpErr) {
+		// internal failure details are not exposed
+		return newProblem(http.StatusInternalServerError)
+	}
+	problem := newProblem(httpErr.Code)
+	switch msg := httpErr.Message.(type) {
+	case Problem:
+		msg.Status = httpErr.Code
+		return msg
+	case string:
+		problem.Detail = msg
+	case nil:
+	default:
+		problem.Errors = msg
+	}
+	return problem
+}
+
+func newProblem(status int) Problem {
+	return Problem{Type: "about:blank", Title: http.StatusText(status), Status: status}
+}
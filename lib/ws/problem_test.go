@@ -0,0 +1,86 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestProblemHandler(t *testing.T) {
+	diagnostics := []map[string]any{{"code": "type_mismatch", "path": []any{"close(z)"}}}
+	tests := []struct {
+		name  string
+		path  string
+		err   error
+		code  int
+		mime  string
+		want  map[string]any
+		plain bool
+	}{
+		{"diagnostics", "/api/v1/defs",
+			echo.NewHTTPError(http.StatusUnprocessableEntity, diagnostics),
+			http.StatusUnprocessableEntity, MIMEApplicationProblemJSON,
+			map[string]any{
+				"type":     "about:blank",
+				"title":    "Unprocessable Entity",
+				"status":   float64(http.StatusUnprocessableEntity),
+				"instance": "/api/v1/defs",
+				"errors":   []any{map[string]any{"code": "type_mismatch", "path": []any{"close(z)"}}},
+			}, false},
+		{"detail", "/api/v1/types",
+			echo.NewHTTPError(http.StatusNotFound, "type missing"),
+			http.StatusNotFound, MIMEApplicationProblemJSON,
+			map[string]any{
+				"type":     "about:blank",
+				"title":    "Not Found",
+				"status":   float64(http.StatusNotFound),
+				"detail":   "type missing",
+				"instance": "/api/v1/types",
+			}, false},
+		// internal failure details stay inside
+		{"internal", "/api/v1/types",
+			errors.New("connection refused"),
+			http.StatusInternalServerError, MIMEApplicationProblemJSON,
+			map[string]any{
+				"type":     "about:blank",
+				"title":    "Internal Server Error",
+				"status":   float64(http.StatusInternalServerError),
+				"instance": "/api/v1/types",
+			}, false},
+		{"page", "/ssr/types",
+			echo.NewHTTPError(http.StatusNotFound, "type missing"),
+			http.StatusNotFound, echo.MIMEApplicationJSON,
+			map[string]any{"message": "type missing"}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = newProblemHandler(e, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			req := httptest.NewRequest(http.MethodGet, test.path, nil)
+			rec := httptest.NewRecorder()
+			e.HTTPErrorHandler(test.err, e.NewContext(req, rec))
+			if rec.Code != test.code {
+				t.Errorf("got status %v, want %v", rec.Code, test.code)
+			}
+			mime := rec.Header().Get(echo.HeaderContentType)
+			if len(mime) < len(test.mime) || mime[:len(test.mime)] != test.mime {
+				t.Errorf("got content type %q, want %q", mime, test.mime)
+			}
+			var got map[string]any
+			err := json.Unmarshal(rec.Body.Bytes(), &got)
+			if err != nil {
+				t.Fatalf("unexpected error %q", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"slices"
+	"strings"
 	"testing"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -70,18 +71,55 @@ func (s *suite) beforeAll(t *testing.T) {
 }
 
 func (s *suite) beforeEach(t *testing.T) {
-	tables := []string{
-		"syn_decs",
-		"pool_decs", "pool_execs", "pool_liabs", "pool_steps",
-		"proc_decs", "proc_execs", "proc_binds", "proc_steps",
-		"dec_pes", "dec_ces",
-		"type_defs", "type_exps",
-	}
-	for _, table := range tables {
-		_, err := s.db.Exec(fmt.Sprintf("truncate table %v", table))
+	// the list comes from the migrated schema, so new tables are not missed
+	rows, err := s.db.Query(`
+		select table_name
+		from information_schema.tables
+		where table_schema = current_schema()
+			and table_type = 'BASE TABLE'
+			and table_name != 'schema_version'
+		order by table_name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var table string
+		err = rows.Scan(&table)
 		if err != nil {
 			t.Fatal(err)
 		}
+		tables = append(tables, table)
+	}
+	err = rows.Err()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(tables, "proc_mods") {
+		t.Fatal("no journal to reset, schema not migrated")
+	}
+	// the journal is append-only for the runtime, the reset lifts that
+	// within its own transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	queries := []string{
+		"alter table proc_mods disable trigger proc_mods_append_only",
+		fmt.Sprintf("truncate table %v restart identity", strings.Join(tables, ", ")),
+		"alter table proc_mods enable trigger proc_mods_append_only",
+	}
+	for _, query := range queries {
+		_, err = tx.Exec(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		t.Fatal(err)
 	}
 }
 